@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewContextCommand(pbURL *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Work with project context sections",
+	}
+
+	cmd.AddCommand(NewContextPushCommand(pbURL))
+	cmd.AddCommand(NewContextDiffSessionsCommand(pbURL))
+	cmd.AddCommand(NewContextPullAllCommand(pbURL))
+	cmd.AddCommand(NewContextExportCommand(pbURL))
+	cmd.AddCommand(NewContextRenderCommand(pbURL))
+	cmd.AddCommand(NewContextValidateLinksCommand(pbURL))
+
+	return cmd
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func NewContextDiffSessionsCommand(pbURL *string) *cobra.Command {
+	var section string
+
+	cmd := &cobra.Command{
+		Use:   "diff-sessions <project-slug> <session-a> <session-b>",
+		Short: "Diff the project's context sections as they were at two sessions",
+		Long: `Diff the project's context sections as they were at the end of two
+sessions, using the versions recorded by context push.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diffContextSessions(*pbURL, args[0], args[1], args[2], section)
+		},
+	}
+
+	cmd.Flags().StringVar(&section, "section", "", "Only diff the section with this title")
+
+	return cmd
+}
+
+func diffContextSessions(pbURL, projectSlug, sessionA, sessionB, section string) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	endA, err := sessionEnd(pbURL, project.ID, sessionA)
+	if err != nil {
+		return err
+	}
+	endB, err := sessionEnd(pbURL, project.ID, sessionB)
+	if err != nil {
+		return err
+	}
+
+	versions, err := listRecords[sectionVersionRecord](pbURL, "context_section_versions",
+		fmt.Sprintf("project='%s'", project.ID), "created")
+	if err != nil {
+		return fmt.Errorf("failed to fetch section versions: %w", err)
+	}
+
+	if len(versions) == 0 {
+		fmt.Println("No section versions recorded yet; they're saved by cct context push")
+		return nil
+	}
+
+	atA := sectionsAt(versions, endA)
+	atB := sectionsAt(versions, endB)
+
+	// Sections in the order they first appeared
+	var ids []string
+	seen := make(map[string]bool)
+	for _, version := range versions {
+		if !seen[version.Section] {
+			seen[version.Section] = true
+			ids = append(ids, version.Section)
+		}
+	}
+
+	fmt.Printf("📄 Context of %s: session %s → %s\n\n", project.Name, sessionA, sessionB)
+
+	shown, unchanged := 0, 0
+	for _, id := range ids {
+		a, b := atA[id], atB[id]
+		title := b.Title
+		if title == "" {
+			title = a.Title
+		}
+		if title == "" || (section != "" && !strings.EqualFold(title, section)) {
+			continue
+		}
+		shown++
+
+		diff := unifiedDiff(a.Content, b.Content, title+" @ "+sessionA, title+" @ "+sessionB)
+		if diff == "" {
+			unchanged++
+			continue
+		}
+		fmt.Printf("## %s\n%s\n", title, diff)
+	}
+
+	if section != "" && shown == 0 {
+		return fmt.Errorf("section not found: %s", section)
+	}
+	if unchanged > 0 {
+		fmt.Printf("%d section(s) unchanged\n", unchanged)
+	}
+	return nil
+}
+
+// sessionEnd returns when a project's session ended, falling back to when it
+// was recorded
+func sessionEnd(pbURL, projectID, sessionID string) (time.Time, error) {
+	sessions, err := listRecords[sessionRecord](pbURL, "session_history",
+		fmt.Sprintf("id='%s' && project='%s'", sessionID, projectID), "")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	if len(sessions) == 0 {
+		return time.Time{}, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	end := sessions[0].SessionEnd
+	if end == "" {
+		end = sessions[0].Created
+	}
+	return parsePBTime(end)
+}
+
+// sectionsAt returns each section's latest version created at or before t,
+// keyed by section ID. versions must be sorted oldest first.
+func sectionsAt(versions []sectionVersionRecord, t time.Time) map[string]sectionVersionRecord {
+	result := make(map[string]sectionVersionRecord)
+	for _, version := range versions {
+		created, err := parsePBTime(version.Created)
+		if err != nil || created.After(t) {
+			continue
+		}
+		result[version.Section] = version
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var exportNumbered = regexp.MustCompile(`^(\s*)\d+[.)]\s+(.*)$`)
+
+type exportOptions struct {
+	project string
+	format  string
+	output  string
+}
+
+func NewContextExportCommand(pbURL *string) *cobra.Command {
+	var opts exportOptions
+
+	cmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export CLAUDE.md as markdown or a Word document",
+		Long: `Export CLAUDE.md for sharing outside the repo. Reads the given file
+(default: CLAUDE.md), or with --project the project's context straight from
+PocketBase.
+
+--format docx writes a Word document with a title page (project name, date,
+and cct version) and a table of contents. Headings use Word's heading styles,
+lists become Word lists, and code is set in Courier New.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.format {
+			case "markdown":
+			case "docx":
+				if opts.output == "" {
+					return fmt.Errorf("--output is required with --format docx")
+				}
+			default:
+				return fmt.Errorf("unknown format %q: expected markdown or docx", opts.format)
+			}
+
+			file := "CLAUDE.md"
+			if len(args) == 1 {
+				file = args[0]
+			}
+			return exportContext(*pbURL, file, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.project, "project", "", "Export this project's context from PocketBase instead of a file")
+	cmd.Flags().StringVar(&opts.format, "format", "markdown", "Output format: markdown or docx")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Write to this file (default: stdout; required for docx)")
+
+	return cmd
+}
+
+func exportContext(pbURL, file string, opts exportOptions) error {
+	var markdown, fallbackTitle string
+	if opts.project != "" {
+		content, _, err := buildContext(pbURL, opts.project)
+		if err != nil {
+			return err
+		}
+		markdown, fallbackTitle = content, opts.project
+	} else {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		markdown = stripProjectMarker(string(data))
+		if abs, err := filepath.Abs(file); err == nil {
+			fallbackTitle = filepath.Base(filepath.Dir(abs))
+		}
+	}
+
+	if opts.format == "markdown" {
+		if opts.output == "" {
+			fmt.Print(markdown)
+			return nil
+		}
+		if err := os.WriteFile(opts.output, []byte(markdown), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.output, err)
+		}
+		fmt.Printf("✓ Exported context to %s\n", opts.output)
+		return nil
+	}
+
+	blocks := parseDocBlocks(markdown)
+	title, blocks := takeTitle(blocks, fallbackTitle)
+
+	out, err := os.Create(opts.output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", opts.output, err)
+	}
+	defer out.Close()
+
+	doc := docxDocument{Title: title, Date: time.Now(), Version: Version, Blocks: blocks}
+	if err := doc.Write(out); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.output, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.output, err)
+	}
+
+	fmt.Printf("✓ Exported context to %s\n", opts.output)
+	return nil
+}
+
+// docBlockKind is the kind of markdown block a docBlock holds
+type docBlockKind int
+
+const (
+	docParagraph docBlockKind = iota
+	docHeading
+	docBullet
+	docNumbered
+	docCode
+)
+
+// docBlock is a markdown block ready to become a document paragraph. Level
+// is the heading level, or a list item's nesting depth from 0.
+type docBlock struct {
+	Kind  docBlockKind
+	Level int
+	Text  string
+}
+
+// parseDocBlocks splits markdown into headings, paragraphs, list items, and
+// code lines. Paragraph lines are joined with spaces, as markdown renders
+// them, and indented lines continue the list item above them.
+func parseDocBlocks(markdown string) []docBlock {
+	var blocks []docBlock
+	inCode := false
+	inParagraph := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			inParagraph = false
+			continue
+		}
+		if inCode {
+			blocks = append(blocks, docBlock{Kind: docCode, Text: strings.ReplaceAll(line, "\t", "    ")})
+			continue
+		}
+
+		if m := renderHeading.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, docBlock{Kind: docHeading, Level: len(m[1]), Text: strings.TrimSpace(strings.TrimRight(m[2], "#"))})
+			inParagraph = false
+			continue
+		}
+		if m := renderBullet.FindStringSubmatch(line); m != nil && trimmed != "---" && trimmed != "***" {
+			blocks = append(blocks, docBlock{Kind: docBullet, Level: listLevel(m[1]), Text: m[2]})
+			inParagraph = true
+			continue
+		}
+		if m := exportNumbered.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, docBlock{Kind: docNumbered, Level: listLevel(m[1]), Text: m[2]})
+			inParagraph = true
+			continue
+		}
+
+		switch {
+		case trimmed == "" || trimmed == "---" || trimmed == "***":
+			inParagraph = false
+		case inParagraph:
+			last := &blocks[len(blocks)-1]
+			last.Text += " " + strings.TrimPrefix(trimmed, "> ")
+		default:
+			blocks = append(blocks, docBlock{Kind: docParagraph, Text: strings.TrimPrefix(trimmed, "> ")})
+			inParagraph = true
+		}
+	}
+	return blocks
+}
+
+// listLevel converts a list item's indentation to a nesting depth of at most 2
+func listLevel(indent string) int {
+	return min(len(strings.ReplaceAll(indent, "\t", "  "))/2, 2)
+}
+
+// takeTitle removes the document's first top-level heading, which CLAUDE.md
+// uses for the project name, and returns it as the title. Without one the
+// fallback is used.
+func takeTitle(blocks []docBlock, fallback string) (string, []docBlock) {
+	for i, block := range blocks {
+		if block.Kind == docHeading && block.Level == 1 {
+			return block.Text, append(blocks[:i:i], blocks[i+1:]...)
+		}
+	}
+	return fallback, blocks
+}
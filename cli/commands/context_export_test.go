@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const exportSample = `<!-- ccd:project app -->
+# App
+
+## Architecture
+
+A **Go** daemon feeds
+the ` + "`cct`" + ` CLI.
+
+- Daemon watches logs
+  - Parses transcripts
+- CLI pulls context
+
+1. Install
+2. Run
+
+### Build
+
+` + "```sh" + `
+go build ./...
+	make install
+` + "```" + `
+`
+
+func TestParseDocBlocks(t *testing.T) {
+	_, blocks := takeTitle(parseDocBlocks(stripProjectMarker(exportSample)), "")
+	want := []docBlock{
+		{Kind: docHeading, Level: 2, Text: "Architecture"},
+		{Kind: docParagraph, Text: "A **Go** daemon feeds the `cct` CLI."},
+		{Kind: docBullet, Text: "Daemon watches logs"},
+		{Kind: docBullet, Level: 1, Text: "Parses transcripts"},
+		{Kind: docBullet, Text: "CLI pulls context"},
+		{Kind: docNumbered, Text: "Install"},
+		{Kind: docNumbered, Text: "Run"},
+		{Kind: docHeading, Level: 3, Text: "Build"},
+		{Kind: docCode, Text: "go build ./..."},
+		{Kind: docCode, Text: "    make install"},
+	}
+	if !reflect.DeepEqual(blocks, want) {
+		t.Errorf("blocks = %+v\nwant %+v", blocks, want)
+	}
+}
+
+// readZipPart returns one part of a zip archive
+func readZipPart(t *testing.T, path, name string) string {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	f, err := r.Open(name)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExportContextDocx(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "CLAUDE.md")
+	if err := os.WriteFile(file, []byte(exportSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "context.docx")
+
+	captureStdout(t, func() {
+		if err := exportContext("", file, exportOptions{format: "docx", output: output}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "docProps/core.xml", "word/_rels/document.xml.rels", "word/styles.xml", "word/numbering.xml"} {
+		readZipPart(t, output, part)
+	}
+	doc := readZipPart(t, output, "word/document.xml")
+
+	// The document is well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("document.xml: %v", err)
+		}
+	}
+
+	text := func(s string) string { return `<w:t xml:space="preserve">` + s + `</w:t>` }
+	for _, want := range []string{
+		// Title page and contents
+		`<w:pStyle w:val="Title"/></w:pPr><w:r>` + text("App"),
+		"Generated by cct version " + Version,
+		`<w:pStyle w:val="TOC2"/></w:pPr><w:hyperlink w:anchor="_Toc0" w:history="1"><w:r>` + text("Architecture"),
+		`<w:pStyle w:val="TOC3"/></w:pPr><w:hyperlink w:anchor="_Toc7" w:history="1"><w:r>` + text("Build"),
+		// Headings, bookmarked for the contents
+		`<w:pStyle w:val="Heading2"/></w:pPr><w:bookmarkStart w:id="0" w:name="_Toc0"/><w:r>` + text("Architecture"),
+		`<w:pStyle w:val="Heading3"/></w:pPr><w:bookmarkStart w:id="7" w:name="_Toc7"/>`,
+		// Bold and code spans
+		`<w:r><w:rPr><w:b/></w:rPr>` + text("Go") + `</w:r>`,
+		`<w:r><w:rPr>` + docxCodeFont + `</w:rPr>` + text("cct") + `</w:r>`,
+		// Bullets share a list; nesting sets the level; numbered lists get their own
+		`<w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r>` + text("Daemon watches logs"),
+		`<w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r>` + text("Parses transcripts"),
+		`<w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r>` + text("Install"),
+		// Code lines keep their indentation in the code style
+		`<w:pStyle w:val="Code"/></w:pPr><w:r>` + text("go build ./..."),
+		`<w:pStyle w:val="Code"/></w:pPr><w:r>` + text("    make install"),
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document.xml lacks %s", want)
+		}
+	}
+	if strings.Contains(doc, "ccd:project") || strings.Contains(doc, "**") {
+		t.Error("document.xml holds raw markdown")
+	}
+
+	if styles := readZipPart(t, output, "word/styles.xml"); !strings.Contains(styles, `w:styleId="Code"`) || !strings.Contains(styles, "Courier New") {
+		t.Error("styles.xml has no Courier New code style")
+	}
+}
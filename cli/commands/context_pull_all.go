@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+type pullResult struct {
+	slug     string
+	sections int
+	err      error
+	// skipped marks projects whose repo directory doesn't exist locally
+	skipped bool
+}
+
+func NewContextPullAllCommand(pbURL *string) *cobra.Command {
+	var (
+		concurrency int
+		createDirs  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull-all <output-dir>",
+		Short: "Pull context for every active project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputDir := args[0]
+			if concurrency < 1 {
+				concurrency = 1
+			}
+			return pullAllContexts(*pbURL, outputDir, concurrency, createDirs)
+		},
+	}
+
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of projects to pull at once")
+	cmd.Flags().BoolVar(&createDirs, "create-dirs", false, "Create missing repo directories instead of skipping the project")
+
+	return cmd
+}
+
+func pullAllContexts(pbURL, outputDir string, concurrency int, createDirs bool) error {
+	projects, err := listRecords[projectRecord](pbURL, "projects", "status='active'", "name")
+	if err != nil {
+		return fmt.Errorf("failed to fetch projects: %w", err)
+	}
+
+	if len(projects) == 0 {
+		fmt.Println("No active projects")
+		return nil
+	}
+
+	results := make([]pullResult, len(projects))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, project := range projects {
+		wg.Add(1)
+		go func(i int, project projectRecord) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = pullProjectContext(pbURL, outputDir, project, createDirs)
+		}(i, project)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			if !result.skipped {
+				failed++
+			}
+			fmt.Printf("✗ %s (%v)\n", result.slug, result.err)
+			continue
+		}
+		fmt.Printf("✓ %s (%d sections)\n", result.slug, result.sections)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects failed", failed, len(projects))
+	}
+	return nil
+}
+
+func pullProjectContext(pbURL, outputDir string, project projectRecord, createDirs bool) pullResult {
+	result := pullResult{slug: project.Slug}
+
+	repoDir := filepath.Join(outputDir, project.RepoPath)
+	if _, err := os.Stat(repoDir); err != nil {
+		if !os.IsNotExist(err) {
+			result.err = err
+			return result
+		}
+		if !createDirs {
+			result.err = fmt.Errorf("repo path not found")
+			result.skipped = true
+			return result
+		}
+		if err := os.MkdirAll(repoDir, 0755); err != nil {
+			result.err = err
+			return result
+		}
+	}
+
+	result.sections, result.err = writeContext(pbURL, project.Slug, filepath.Join(repoDir, "CLAUDE.md"))
+	return result
+}
@@ -0,0 +1,355 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// projectInfoTitle is the section pull generates from the project record;
+// it isn't stored as a context section
+const projectInfoTitle = "Project Info"
+
+// contextWatchInterval is how often push --watch checks the file for changes
+const contextWatchInterval = 2 * time.Second
+
+// sectionTypes maps section titles to the context_sections section_type
+// values; any other title is a custom section
+var sectionTypes = map[string]string{
+	"architecture":  "architecture",
+	"current state": "current_state",
+	"next steps":    "next_steps",
+	"gotchas":       "gotchas",
+	"decisions":     "decisions",
+}
+
+// markdownSection is a "## " section of a CLAUDE.md file
+type markdownSection struct {
+	Title   string
+	Content string
+}
+
+type contextPushOptions struct {
+	diffOnly bool
+	force    bool
+	watch    bool
+
+	// transform is a shell command CLAUDE.md is piped through before pushing
+	transform        string
+	transformTimeout time.Duration
+	transformEnv     []string
+}
+
+func NewContextPushCommand(pbURL *string) *cobra.Command {
+	var opts contextPushOptions
+
+	cmd := &cobra.Command{
+		Use:   "push <project-slug> [file]",
+		Short: "Save CLAUDE.md's sections back to the project's context",
+		Long: `Save the "## " sections of CLAUDE.md (default) back to the project's
+context sections. Changed and new sections are updated and a version of each
+is recorded, so earlier contents can be compared with context diff-sessions.
+Sections whose content hash matches PocketBase are skipped unless --force is
+given. Sections missing from the file are left as they are.
+
+--transform pipes the file through a shell command first, for stripping
+internal notes or filling in dynamic content. The command reads the file on
+stdin and writes the content to push on stdout; sections are parsed and
+hashed from its output. For example:
+
+  cct context push my-app --transform "sed '/^<!-- internal -->/,/^<!-- \\/internal -->/d'"`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug := args[0]
+			file := "CLAUDE.md"
+			if len(args) == 2 {
+				file = args[1]
+			}
+			if opts.diffOnly && opts.force {
+				return fmt.Errorf("--diff-only and --force can't be used together")
+			}
+			if opts.transformTimeout <= 0 {
+				return fmt.Errorf("--transform-timeout must be positive")
+			}
+			for _, env := range opts.transformEnv {
+				if !strings.Contains(env, "=") {
+					return fmt.Errorf("invalid --transform-env %q: expected KEY=VALUE", env)
+				}
+			}
+			if opts.watch {
+				return watchContextPush(*pbURL, projectSlug, file, opts)
+			}
+			return pushContext(*pbURL, projectSlug, file, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.diffOnly, "diff-only", false, "Only push sections whose hash differs, printing every section's status")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Push every section, even when unchanged")
+	cmd.Flags().BoolVar(&opts.watch, "watch", false, "Keep running and push whenever the file changes (implies --diff-only unless --force)")
+	cmd.Flags().StringVar(&opts.transform, "transform", "", "Shell command to pipe the file through before parsing sections")
+	cmd.Flags().DurationVar(&opts.transformTimeout, "transform-timeout", 10*time.Second, "How long --transform may run")
+	cmd.Flags().StringArrayVar(&opts.transformEnv, "transform-env", nil, "Extra KEY=VALUE environment variable for --transform (repeatable)")
+
+	return cmd
+}
+
+func pushContext(pbURL, projectSlug, file string, opts contextPushOptions) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if opts.transform != "" {
+		if data, err = transformContext(data, opts); err != nil {
+			return err
+		}
+	}
+
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	existing, err := listRecords[contextSectionRecord](pbURL, "context_sections",
+		fmt.Sprintf("project='%s'", project.ID), "order")
+	if err != nil {
+		return fmt.Errorf("failed to fetch context sections: %w", err)
+	}
+
+	byTitle := make(map[string]contextSectionRecord)
+	nextOrder := 1
+	for _, section := range existing {
+		byTitle[strings.ToLower(section.Title)] = section
+		if section.Order >= nextOrder {
+			nextOrder = section.Order + 1
+		}
+	}
+
+	// Versions are attributed to the latest session, if there is one
+	sessionID := ""
+	sessions, err := firstRecords[sessionRecord](pbURL, "session_history",
+		fmt.Sprintf("project='%s'", project.ID), "-created", 1)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	if len(sessions) > 0 {
+		sessionID = sessions[0].ID
+	}
+
+	// status reports a section's outcome: every section with --diff-only,
+	// otherwise just the ones that changed
+	status := func(state, title string) {
+		switch {
+		case opts.diffOnly:
+			fmt.Printf("  %-10s %s\n", state, title)
+		case state == "new":
+			fmt.Printf("  + %s\n", title)
+		case state == "updated":
+			fmt.Printf("  ~ %s\n", title)
+		}
+	}
+
+	pushed := make(map[string]bool)
+	created, updated, unchanged := 0, 0, 0
+	for _, parsed := range parseMarkdownSections(string(data)) {
+		hash := snapshotHash(parsed.Content)
+		section, exists := byTitle[strings.ToLower(parsed.Title)]
+		pushed[strings.ToLower(parsed.Title)] = true
+
+		switch {
+		case !exists:
+			section = contextSectionRecord{
+				Project:     project.ID,
+				SectionType: sectionType(parsed.Title),
+				Title:       parsed.Title,
+				Content:     parsed.Content,
+				Order:       nextOrder,
+			}
+			err = createRecord(pbURL, "context_sections", map[string]interface{}{
+				"project":        section.Project,
+				"section_type":   section.SectionType,
+				"title":          section.Title,
+				"content":        section.Content,
+				"order":          section.Order,
+				"auto_extracted": false,
+				"snapshot_hash":  hash,
+			}, &section)
+			nextOrder++
+			if err == nil {
+				created++
+				status("new", parsed.Title)
+			}
+
+		case section.SnapshotHash == hash && !opts.force:
+			unchanged++
+			status("unchanged", parsed.Title)
+			continue
+
+		// Sections pushed before versioning get a baseline version even
+		// when their content hasn't changed
+		case section.Content == parsed.Content && !opts.force:
+			err = updateRecord(pbURL, "context_sections", section.ID, map[string]interface{}{
+				"snapshot_hash": hash,
+			})
+			if err == nil {
+				unchanged++
+				status("unchanged", parsed.Title)
+			}
+
+		default:
+			err = updateRecord(pbURL, "context_sections", section.ID, map[string]interface{}{
+				"content":       parsed.Content,
+				"snapshot_hash": hash,
+			})
+			if err == nil {
+				updated++
+				status("updated", parsed.Title)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save section %q: %w", parsed.Title, err)
+		}
+
+		if err := recordSectionVersion(pbURL, section.ID, project.ID, sessionID, parsed, hash); err != nil {
+			return fmt.Errorf("failed to record version of %q: %w", parsed.Title, err)
+		}
+	}
+
+	// Removing a section would also remove its version history, so sections
+	// missing from the file are only reported
+	deleted := 0
+	for _, section := range existing {
+		if !pushed[strings.ToLower(section.Title)] {
+			deleted++
+			if opts.diffOnly {
+				fmt.Printf("  %-10s %s (not in %s; kept in PocketBase)\n", "deleted", section.Title, file)
+			}
+		}
+	}
+
+	if opts.diffOnly {
+		fmt.Printf("✓ Context pushed: %d updated, %d new, %d unchanged, %d deleted\n", updated, created, unchanged, deleted)
+	} else {
+		fmt.Printf("✓ Context pushed: %d updated, %d new, %d unchanged\n", updated, created, unchanged)
+	}
+	return nil
+}
+
+// watchContextPush pushes file every time it changes until interrupted
+func watchContextPush(pbURL, projectSlug, file string, opts contextPushOptions) error {
+	if !opts.force {
+		opts.diffOnly = true
+	}
+
+	fmt.Printf("👀 Watching %s (Ctrl+C to stop)\n", file)
+
+	var lastMod time.Time
+	for {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		if !info.ModTime().Equal(lastMod) {
+			lastMod = info.ModTime()
+			fmt.Printf("\n[%s] Pushing %s\n", time.Now().Format("15:04:05"), file)
+			// A failed push is retried on the next change rather than ending the watch
+			if err := pushContext(pbURL, projectSlug, file, opts); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+
+		time.Sleep(contextWatchInterval)
+	}
+}
+
+// transformContext pipes content through the --transform command, passing
+// its stderr through so the script's own errors are visible
+func transformContext(content []byte, opts contextPushOptions) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.transformTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", opts.transform)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), opts.transformEnv...)
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("transform timed out after %s", opts.transformTimeout)
+		}
+		return nil, fmt.Errorf("failed to run transform: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func recordSectionVersion(pbURL, sectionID, projectID, sessionID string, section markdownSection, hash string) error {
+	data := map[string]interface{}{
+		"section":       sectionID,
+		"project":       projectID,
+		"title":         section.Title,
+		"content":       section.Content,
+		"snapshot_hash": hash,
+	}
+	if sessionID != "" {
+		data["session"] = sessionID
+	}
+	return createRecord(pbURL, "context_section_versions", data, nil)
+}
+
+// parseMarkdownSections splits markdown into its "## " sections, skipping
+// anything before the first one and the generated Project Info and handoff
+// sections
+func parseMarkdownSections(markdown string) []markdownSection {
+	var sections []markdownSection
+	var current *markdownSection
+	var lines []string
+	inCode := false
+
+	flush := func() {
+		if current != nil && !strings.EqualFold(current.Title, projectInfoTitle) &&
+			!strings.HasPrefix(current.Title, handoffTitlePrefix) {
+			current.Content = strings.TrimSpace(strings.Join(lines, "\n"))
+			sections = append(sections, *current)
+		}
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if m := sectionHeading.FindStringSubmatch(line); m != nil && !inCode {
+			flush()
+			current = &markdownSection{Title: strings.TrimSpace(m[1])}
+			lines = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return sections
+}
+
+func sectionType(title string) string {
+	if t, ok := sectionTypes[strings.ToLower(title)]; ok {
+		return t
+	}
+	return "custom"
+}
+
+// snapshotHash identifies a version of a section's content
+func snapshotHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
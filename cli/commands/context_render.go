@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	ansiReset     = "\033[0m"
+	ansiBold      = "\033[1m"
+	ansiUnderline = "\033[4m"
+	ansiCode      = "\033[48;5;236m"
+
+	// defaultRenderWidth is used when the output isn't a terminal
+	defaultRenderWidth = 80
+)
+
+var (
+	renderBullet   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	renderHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	renderLink     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	renderStrong   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	renderInline   = regexp.MustCompile("`([^`]+)`")
+	ansiEscape     = regexp.MustCompile(`\033\[[0-9;]*m`)
+	sectionHeading = regexp.MustCompile(`^##\s+(.*)$`)
+)
+
+type renderOptions struct {
+	project string
+	section string
+	pager   bool
+}
+
+func NewContextRenderCommand(pbURL *string) *cobra.Command {
+	var opts renderOptions
+
+	cmd := &cobra.Command{
+		Use:   "render [file]",
+		Short: "Preview CLAUDE.md in the terminal with formatting",
+		Long: `Preview CLAUDE.md in the terminal with formatting. Reads the given file
+(default: CLAUDE.md), or with --project renders the project's context
+straight from PocketBase.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := "CLAUDE.md"
+			if len(args) == 1 {
+				file = args[0]
+			}
+			return renderContext(*pbURL, file, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.project, "project", "", "Render this project's context from PocketBase instead of a file")
+	cmd.Flags().StringVar(&opts.section, "section", "", "Only render the section with this title")
+	cmd.Flags().BoolVar(&opts.pager, "pager", false, "Page the output through $PAGER (default: less)")
+
+	return cmd
+}
+
+func renderContext(pbURL, file string, opts renderOptions) error {
+	var markdown string
+	if opts.project != "" {
+		content, _, err := buildContext(pbURL, opts.project)
+		if err != nil {
+			return err
+		}
+		markdown = content
+	} else {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		markdown = stripProjectMarker(string(data))
+	}
+
+	if opts.section != "" {
+		section, ok := extractSection(markdown, opts.section)
+		if !ok {
+			return fmt.Errorf("section not found: %s", opts.section)
+		}
+		markdown = section
+	}
+
+	width := defaultRenderWidth
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		width = w
+	}
+
+	rendered := renderMarkdown(markdown, width)
+
+	if !opts.pager {
+		fmt.Print(rendered)
+		return nil
+	}
+	return page(rendered)
+}
+
+// extractSection returns the "## <title>" section of markdown, heading
+// included, matching the title case-insensitively
+func extractSection(markdown, title string) (string, bool) {
+	var lines []string
+	found := false
+	inCode := false
+
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if m := sectionHeading.FindStringSubmatch(line); m != nil && !inCode {
+			if found {
+				break
+			}
+			found = strings.EqualFold(strings.TrimSpace(m[1]), strings.TrimSpace(title))
+		}
+		if found {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n", found
+}
+
+// renderMarkdown formats markdown for an ANSI terminal of the given width
+func renderMarkdown(markdown string, width int) string {
+	var out strings.Builder
+	inCode := false
+
+	for _, line := range strings.Split(strings.TrimRight(markdown, "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+
+		if inCode {
+			// Code keeps its layout; the padding extends the background to the edge
+			code := strings.ReplaceAll(line, "\t", "    ")
+			if pad := width - len([]rune(code)) - 2; pad > 0 {
+				code += strings.Repeat(" ", pad)
+			}
+			out.WriteString("  " + ansiCode + code + ansiReset + "\n")
+			continue
+		}
+
+		if m := renderHeading.FindStringSubmatch(line); m != nil {
+			style := ansiBold
+			if len(m[1]) == 1 {
+				style += ansiUnderline
+			}
+			out.WriteString(style + renderInlineMarkdown(m[2]) + ansiReset + "\n")
+			continue
+		}
+
+		if m := renderBullet.FindStringSubmatch(line); m != nil {
+			indent := m[1] + "  "
+			out.WriteString(wrapText(renderInlineMarkdown(m[2]), width, m[1]+"• ", indent))
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			out.WriteString("\n")
+			continue
+		}
+
+		out.WriteString(wrapText(renderInlineMarkdown(line), width, "", ""))
+	}
+
+	return out.String()
+}
+
+// renderInlineMarkdown styles bold text and inline code and writes links as
+// "text (url)"
+func renderInlineMarkdown(text string) string {
+	text = renderLink.ReplaceAllString(text, "$1 ($2)")
+	text = renderStrong.ReplaceAllString(text, ansiBold+"$1"+ansiReset)
+	return renderInline.ReplaceAllString(text, ansiCode+"$1"+ansiReset)
+}
+
+// wrapText word-wraps text to width, starting the first line with first and
+// the rest with indent. Escape sequences don't count towards the width.
+func wrapText(text string, width int, first, indent string) string {
+	var out strings.Builder
+	line := first
+	lineLen := visibleLen(first)
+	empty := true
+
+	for _, word := range strings.Fields(text) {
+		wordLen := visibleLen(word)
+		if !empty && lineLen+1+wordLen > width {
+			out.WriteString(line + "\n")
+			line, lineLen, empty = indent, visibleLen(indent), true
+		}
+		if !empty {
+			line += " "
+			lineLen++
+		}
+		line += word
+		lineLen += wordLen
+		empty = false
+	}
+
+	out.WriteString(line + "\n")
+	return out.String()
+}
+
+func visibleLen(s string) int {
+	return len([]rune(ansiEscape.ReplaceAllString(s, "")))
+}
+
+// page pipes text through $PAGER, falling back to less
+func page(text string) error {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// less shows escape sequences literally unless told to pass colors through
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=-R")
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run pager: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownHeading = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	anchorStrip     = regexp.MustCompile(`[^a-z0-9 _-]`)
+)
+
+type brokenLink struct {
+	section string
+	text    string
+	target  string
+	reason  string
+}
+
+func NewContextValidateLinksCommand(pbURL *string) *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "validate-links [file]",
+		Short: "Check CLAUDE.md for broken section anchors and links",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "CLAUDE.md"
+			if len(args) == 1 {
+				path = args[0]
+			}
+			return validateLinks(path, fix)
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Replace broken links with their plain text")
+
+	return cmd
+}
+
+func validateLinks(path string, fix bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	content := string(data)
+
+	lines := strings.Split(content, "\n")
+	anchors := headingAnchors(lines)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	var broken []brokenLink
+	section := "(top)"
+	for i, line := range lines {
+		if match := markdownHeading.FindStringSubmatch(line); match != nil {
+			section = match[1]
+		}
+
+		lines[i] = markdownLink.ReplaceAllStringFunc(line, func(link string) string {
+			match := markdownLink.FindStringSubmatch(link)
+			text, target := match[1], match[2]
+
+			reason := checkLinkTarget(client, anchors, target)
+			if reason == "" {
+				return link
+			}
+
+			broken = append(broken, brokenLink{section: section, text: text, target: target, reason: reason})
+			if fix {
+				return text
+			}
+			return link
+		})
+	}
+
+	if len(broken) == 0 {
+		fmt.Println("✓ All links valid")
+		return nil
+	}
+
+	for _, link := range broken {
+		fmt.Printf("✗ %s: [%s](%s) - %s\n", link.section, link.text, link.target, link.reason)
+	}
+
+	if fix {
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		fmt.Printf("✓ Removed %d broken link(s) from %s\n", len(broken), path)
+		return nil
+	}
+
+	return fmt.Errorf("%d broken link(s) found", len(broken))
+}
+
+// checkLinkTarget returns why a link target is broken, or "" if it is fine.
+// Relative file links aren't checked.
+func checkLinkTarget(client *http.Client, anchors map[string]bool, target string) string {
+	switch {
+	case strings.HasPrefix(target, "#"):
+		if !anchors[strings.ToLower(strings.TrimPrefix(target, "#"))] {
+			return "no matching heading"
+		}
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		resp, err := client.Head(target)
+		if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+			// Some servers don't support HEAD
+			resp.Body.Close()
+			resp, err = client.Get(target)
+		}
+		if err != nil {
+			return err.Error()
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Sprintf("status %d", resp.StatusCode)
+		}
+	}
+	return ""
+}
+
+// headingAnchors collects the anchor slug of every heading in the document
+func headingAnchors(lines []string) map[string]bool {
+	anchors := make(map[string]bool)
+	for _, line := range lines {
+		if match := markdownHeading.FindStringSubmatch(line); match != nil {
+			anchors[anchorSlug(match[1])] = true
+		}
+	}
+	return anchors
+}
+
+// anchorSlug converts heading text to its anchor: lowercase, punctuation
+// dropped, spaces to hyphens
+func anchorSlug(heading string) string {
+	slug := anchorStrip.ReplaceAllString(strings.ToLower(heading), "")
+	return strings.ReplaceAll(slug, " ", "-")
+}
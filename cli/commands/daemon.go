@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewDaemonCommand(pbURL *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Manage the context tracker daemon",
+	}
+
+	cmd.AddCommand(NewDaemonListCommand())
+	cmd.AddCommand(NewDaemonStatusCommand())
+	cmd.AddCommand(NewSelfUpdateCommand())
+	cmd.AddCommand(NewDaemonUpgradeSchemaCommand(pbURL))
+
+	return cmd
+}
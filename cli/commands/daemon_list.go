@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/instance"
+	"github.com/spf13/cobra"
+)
+
+type daemonListOptions struct {
+	all      bool
+	killDead bool
+}
+
+func NewDaemonListCommand() *cobra.Command {
+	var opts daemonListOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show running daemon instances",
+		Long: `Show the daemons registered in ~/.local/run/ccd, one per project. Daemons
+that exited without cleaning up leave their PID file behind; --all shows them
+and --kill-dead removes their files.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listDaemons(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.all, "all", false, "Also show daemons that are no longer running")
+	cmd.Flags().BoolVar(&opts.killDead, "kill-dead", false, "Remove PID files left by daemons that are no longer running")
+
+	return cmd
+}
+
+func listDaemons(opts daemonListOptions) error {
+	instances, err := daemonInstances()
+	if err != nil {
+		return err
+	}
+
+	var shown, dead []instance.Instance
+	for _, inst := range instances {
+		if !inst.Running {
+			dead = append(dead, inst)
+		}
+		if inst.Running || opts.all {
+			shown = append(shown, inst)
+		}
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("No daemons running")
+	} else {
+		printDaemonTable(shown)
+	}
+
+	if opts.killDead {
+		removed := 0
+		for _, inst := range dead {
+			if err := instance.Remove(inst); err != nil {
+				fmt.Printf("Warning: failed to remove %s: %v\n", inst.PIDFile, err)
+				continue
+			}
+			removed++
+		}
+		fmt.Printf("\n🧹 Removed %d stale PID file(s)\n", removed)
+	} else if len(dead) > 0 && !opts.all {
+		fmt.Printf("\n%d dead daemon(s) not shown; use --all to see them or --kill-dead to clean up\n", len(dead))
+	}
+
+	return nil
+}
+
+// daemonInstances lists the daemons registered in the default run directory
+func daemonInstances() ([]instance.Instance, error) {
+	dir, err := instance.DefaultDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate run directory: %w", err)
+	}
+	instances, err := instance.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run directory: %w", err)
+	}
+	return instances, nil
+}
+
+// defaultRunDir is the run directory daemons register in by default, or
+// empty when it can't be located
+func defaultRunDir() string {
+	dir, err := instance.DefaultDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+func printDaemonTable(instances []instance.Instance) {
+	fmt.Printf("%-8s %-24s %-40s %-8s %s\n", "PID", "PROJECT", "LOG PATH", "RUNNING", "UPTIME")
+	for _, inst := range instances {
+		project := inst.ProjectSlug
+		if project == "" {
+			project = inst.ProjectID
+		}
+		running, uptime := "no", "-"
+		if inst.Running {
+			running = "yes"
+			if !inst.StartedAt.IsZero() {
+				uptime = formatMinutes(time.Since(inst.StartedAt).Minutes())
+			}
+		}
+		fmt.Printf("%-8d %-24s %-40s %-8s %s\n", inst.PID, project, inst.LogPath, running, uptime)
+	}
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/instance"
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/spf13/cobra"
+)
+
+// daemonStatus is the --format json form of cct daemon status
+type daemonStatus struct {
+	instance.Info
+	Running bool                  `json:"running"`
+	Ledger  *ledger.LedgerMetrics `json:"ledger,omitempty"`
+}
+
+func NewDaemonStatusCommand() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "status [project-slug]",
+		Short: "Show the daemon running for a project, or all running daemons",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("invalid --format %q: use text or json", format)
+			}
+			if len(args) == 0 {
+				return listDaemons(daemonListOptions{})
+			}
+			return showDaemonStatus(args[0], format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format for a project's daemon: text or json")
+
+	return cmd
+}
+
+func showDaemonStatus(projectSlug, format string) error {
+	instances, err := daemonInstances()
+	if err != nil {
+		return err
+	}
+
+	for _, inst := range instances {
+		if inst.ProjectSlug != projectSlug && inst.ProjectID != projectSlug {
+			continue
+		}
+
+		// How much the ledger holds, when the daemon serves it
+		var metrics *ledger.LedgerMetrics
+		var metricsErr error
+		if inst.Running && inst.HTTPAddr != "" {
+			metrics, metricsErr = fetchLedgerMetrics(inst.HTTPAddr)
+		}
+
+		if format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(daemonStatus{Info: inst.Info, Running: inst.Running, Ledger: metrics})
+		}
+
+		if !inst.Running {
+			fmt.Printf("⚠️  Daemon for %s is not running (stale PID file %s)\n", projectSlug, inst.PIDFile)
+			return nil
+		}
+
+		fmt.Printf("✓ Daemon for %s is running\n\n", projectSlug)
+		fmt.Printf("  PID:      %d\n", inst.PID)
+		fmt.Printf("  Version:  %s\n", inst.Version)
+		fmt.Printf("  Started:  %s (%s ago)\n", inst.StartedAt.Local().Format("2006-01-02 15:04"),
+			formatMinutes(time.Since(inst.StartedAt).Minutes()))
+		fmt.Printf("  Logs:     %s\n", inst.LogPath)
+		fmt.Printf("  Repo:     %s\n", inst.RepoPath)
+		if inst.HTTPAddr != "" {
+			fmt.Printf("  HTTP:     %s\n", inst.HTTPAddr)
+		}
+		switch {
+		case metrics != nil:
+			fmt.Printf("  Ledger:   %d entries, %d facts, %d sessions (%s)\n", metrics.TotalEntries,
+				metrics.TotalFacts, metrics.TotalSessions, formatBytes(metrics.FileSizeBytes))
+		case metricsErr != nil:
+			fmt.Printf("  Ledger:   unavailable (%v)\n", metricsErr)
+		}
+		return nil
+	}
+
+	if format == "json" {
+		return fmt.Errorf("no daemon registered for %s", projectSlug)
+	}
+	fmt.Printf("No daemon registered for %s\n", projectSlug)
+	return nil
+}
@@ -0,0 +1,539 @@
+package commands
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaMigrations are the collection changes made since the initial schema,
+// mirroring pocketbase/pb_migrations for instances that don't run them
+//
+//go:embed migrations/*.json
+var schemaMigrations embed.FS
+
+// migrationsCollection records which schema migrations have been applied
+const migrationsCollection = "ccd_migrations"
+
+const (
+	adminEmailEnv    = "CCD_PB_ADMIN_EMAIL"
+	adminPasswordEnv = "CCD_PB_ADMIN_PASSWORD"
+)
+
+// schemaMigration is a numbered migrations/*.json file
+type schemaMigration struct {
+	Name        string          `json:"-"`
+	Description string          `json:"description"`
+	Steps       []migrationStep `json:"steps"`
+}
+
+// migrationStep changes one collection. Each change is skipped when the
+// schema already has it, and undone on rollback.
+type migrationStep struct {
+	Collection string `json:"collection"`
+	// AddFields are schema fields added to the collection
+	AddFields []map[string]interface{} `json:"add_fields,omitempty"`
+	// AddSelectValues are values added to select fields, keyed by field name
+	AddSelectValues map[string][]string `json:"add_select_values,omitempty"`
+	// Create is the payload of a new collection. Relation fields may name
+	// their target collection in options.collectionId.
+	Create map[string]interface{} `json:"create,omitempty"`
+}
+
+type upgradeSchemaOptions struct {
+	dryRun        bool
+	rollback      int
+	adminEmail    string
+	adminPassword string
+}
+
+func NewDaemonUpgradeSchemaCommand(pbURL *string) *cobra.Command {
+	var opts upgradeSchemaOptions
+
+	cmd := &cobra.Command{
+		Use:   "upgrade-schema",
+		Short: "Bring PocketBase collections up to the current CCD schema",
+		Long: `Apply the schema migrations built into cct that haven't been applied to
+PocketBase yet, in order. Applied migrations are tracked in the ccd_migrations
+collection; changes already made by pocketbase/pb_migrations are detected and
+just recorded. Changing collections needs a PocketBase admin account, given
+with --admin-email and --admin-password or $` + adminEmailEnv + ` and
+$` + adminPasswordEnv + `.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.adminEmail == "" {
+				opts.adminEmail = os.Getenv(adminEmailEnv)
+			}
+			if opts.adminPassword == "" {
+				opts.adminPassword = os.Getenv(adminPasswordEnv)
+			}
+			if opts.adminEmail == "" || opts.adminPassword == "" {
+				return fmt.Errorf("PocketBase admin credentials are required (--admin-email and --admin-password, or $%s and $%s)",
+					adminEmailEnv, adminPasswordEnv)
+			}
+			if opts.rollback < 0 {
+				return fmt.Errorf("--rollback must be positive")
+			}
+			return upgradeSchema(*pbURL, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would change without changing anything")
+	cmd.Flags().IntVar(&opts.rollback, "rollback", 0, "Undo the last N applied migrations instead of upgrading")
+	cmd.Flags().StringVar(&opts.adminEmail, "admin-email", "", "PocketBase admin email (default: $"+adminEmailEnv+")")
+	cmd.Flags().StringVar(&opts.adminPassword, "admin-password", "", "PocketBase admin password (default: $"+adminPasswordEnv+")")
+
+	return cmd
+}
+
+func upgradeSchema(pbURL string, opts upgradeSchemaOptions) error {
+	migrations, err := loadSchemaMigrations()
+	if err != nil {
+		return err
+	}
+
+	admin, err := newPBAdmin(pbURL, opts.adminEmail, opts.adminPassword)
+	if err != nil {
+		return err
+	}
+
+	applied, err := admin.appliedMigrations(opts.dryRun)
+	if err != nil {
+		return err
+	}
+
+	if opts.rollback > 0 {
+		return rollbackSchema(admin, migrations, applied, opts)
+	}
+
+	var pending []schemaMigration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Name]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	fmt.Printf("📦 Schema migrations: %d available, %d applied\n", len(migrations), len(migrations)-len(pending))
+	if len(pending) == 0 {
+		fmt.Println("✓ Schema is up to date")
+		return nil
+	}
+
+	for _, migration := range pending {
+		fmt.Printf("\n→ %s: %s\n", migration.Name, migration.Description)
+		for _, step := range migration.Steps {
+			if err := admin.applyStep(step, opts.dryRun); err != nil {
+				return fmt.Errorf("migration %s failed: %w", migration.Name, err)
+			}
+		}
+		if opts.dryRun {
+			continue
+		}
+		if err := admin.send(http.MethodPost, "/api/collections/"+migrationsCollection+"/records",
+			map[string]interface{}{"name": migration.Name}, nil); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", migration.Name, err)
+		}
+		fmt.Printf("✓ Applied %s\n", migration.Name)
+	}
+
+	if opts.dryRun {
+		fmt.Printf("\nDry run: %d migration(s) would be applied\n", len(pending))
+	}
+	return nil
+}
+
+func rollbackSchema(admin *pbAdmin, migrations []schemaMigration, applied map[string]string, opts upgradeSchemaOptions) error {
+	byName := make(map[string]schemaMigration)
+	for _, migration := range migrations {
+		byName[migration.Name] = migration
+	}
+
+	// Newest first; names are numbered so they sort in order
+	names := make([]string, 0, len(applied))
+	for name := range applied {
+		names = append(names, name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	if opts.rollback > len(names) {
+		return fmt.Errorf("only %d migration(s) are applied", len(names))
+	}
+
+	for _, name := range names[:opts.rollback] {
+		migration, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("applied migration %s isn't known to this version of cct", name)
+		}
+
+		fmt.Printf("\n← %s: %s\n", migration.Name, migration.Description)
+		for i := len(migration.Steps) - 1; i >= 0; i-- {
+			if err := admin.revertStep(migration.Steps[i], opts.dryRun); err != nil {
+				return fmt.Errorf("rollback of %s failed: %w", name, err)
+			}
+		}
+		if opts.dryRun {
+			continue
+		}
+		if err := admin.send(http.MethodDelete, "/api/collections/"+migrationsCollection+"/records/"+applied[name], nil, nil); err != nil {
+			return fmt.Errorf("failed to unrecord migration %s: %w", name, err)
+		}
+		fmt.Printf("✓ Rolled back %s\n", name)
+	}
+
+	if opts.dryRun {
+		fmt.Printf("\nDry run: %d migration(s) would be rolled back\n", opts.rollback)
+	}
+	return nil
+}
+
+// loadSchemaMigrations reads the embedded migrations in order
+func loadSchemaMigrations() ([]schemaMigration, error) {
+	files, err := schemaMigrations.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []schemaMigration
+	for _, file := range files {
+		data, err := schemaMigrations.ReadFile(path.Join("migrations", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var migration schemaMigration
+		if err := json.Unmarshal(data, &migration); err != nil {
+			return nil, fmt.Errorf("invalid migration %s: %w", file.Name(), err)
+		}
+		migration.Name = strings.TrimSuffix(file.Name(), ".json")
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Name < migrations[j].Name })
+	return migrations, nil
+}
+
+// pbAdmin makes authenticated requests to PocketBase's admin APIs
+type pbAdmin struct {
+	baseURL string
+	token   string
+}
+
+func newPBAdmin(pbURL, email, password string) (*pbAdmin, error) {
+	admin := &pbAdmin{baseURL: pbURL}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	err := admin.send(http.MethodPost, "/api/admins/auth-with-password",
+		map[string]interface{}{"identity": email, "password": password}, &auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign in as PocketBase admin: %w", err)
+	}
+	admin.token = auth.Token
+	return admin, nil
+}
+
+func (a *pbAdmin) send(method, endpoint string, data interface{}, result interface{}) error {
+	var body io.Reader
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, a.baseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.token != "" {
+		req.Header.Set("Authorization", a.token)
+	}
+
+	resp, err := httpDo(req)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, result)
+}
+
+// collection fetches a collection's definition, returning nil if it doesn't
+// exist
+func (a *pbAdmin) collection(name string) (map[string]interface{}, error) {
+	req, err := http.NewRequest(http.MethodGet, a.baseURL+"/api/collections/"+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", a.token)
+
+	resp, err := httpDo(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, nil
+	}
+
+	var collection map[string]interface{}
+	if err := decodeResponse(resp, &collection); err != nil {
+		return nil, fmt.Errorf("failed to fetch collection %s: %w", name, err)
+	}
+	return collection, nil
+}
+
+// appliedMigrations returns the applied migrations' record IDs keyed by
+// name, creating the tracking collection unless this is a dry run
+func (a *pbAdmin) appliedMigrations(dryRun bool) (map[string]string, error) {
+	applied := make(map[string]string)
+
+	existing, err := a.collection(migrationsCollection)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		if dryRun {
+			return applied, nil
+		}
+		err := a.send(http.MethodPost, "/api/collections", map[string]interface{}{
+			"name": migrationsCollection,
+			"type": "base",
+			"schema": []map[string]interface{}{
+				{"name": "name", "type": "text", "required": true},
+			},
+			"indexes": []string{
+				"CREATE UNIQUE INDEX idx_ccd_migrations_name ON " + migrationsCollection + " (name)",
+			},
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s collection: %w", migrationsCollection, err)
+		}
+		return applied, nil
+	}
+
+	for page := 1; ; page++ {
+		var list struct {
+			TotalPages int `json:"totalPages"`
+			Items      []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"items"`
+		}
+		endpoint := fmt.Sprintf("/api/collections/%s/records?page=%d&perPage=%d", migrationsCollection, page, pbPageSize)
+		if err := a.send(http.MethodGet, endpoint, nil, &list); err != nil {
+			return nil, fmt.Errorf("failed to fetch applied migrations: %w", err)
+		}
+		for _, item := range list.Items {
+			applied[item.Name] = item.ID
+		}
+		if page >= list.TotalPages {
+			break
+		}
+	}
+	return applied, nil
+}
+
+func (a *pbAdmin) applyStep(step migrationStep, dryRun bool) error {
+	collection, err := a.collection(step.Collection)
+	if err != nil {
+		return err
+	}
+
+	if step.Create != nil {
+		if collection != nil {
+			fmt.Printf("  · collection %s already exists\n", step.Collection)
+			return nil
+		}
+		fmt.Printf("  + create collection %s\n", step.Collection)
+		if dryRun {
+			return nil
+		}
+		payload, err := a.resolveRelations(step.Create)
+		if err != nil {
+			return err
+		}
+		payload["name"] = step.Collection
+		return a.send(http.MethodPost, "/api/collections", payload, nil)
+	}
+
+	if collection == nil {
+		return fmt.Errorf("collection %s not found", step.Collection)
+	}
+	schema := schemaFields(collection)
+	changed := false
+
+	for _, field := range step.AddFields {
+		name, _ := field["name"].(string)
+		if findSchemaField(schema, name) != nil {
+			fmt.Printf("  · %s.%s already exists\n", step.Collection, name)
+			continue
+		}
+		fmt.Printf("  + add field %s.%s (%v)\n", step.Collection, name, field["type"])
+		schema = append(schema, field)
+		changed = true
+	}
+
+	for name, values := range step.AddSelectValues {
+		field := findSchemaField(schema, name)
+		if field == nil {
+			return fmt.Errorf("field %s.%s not found", step.Collection, name)
+		}
+		options, _ := field["options"].(map[string]interface{})
+		if options == nil {
+			options = make(map[string]interface{})
+			field["options"] = options
+		}
+		current, _ := options["values"].([]interface{})
+		for _, value := range values {
+			if containsValue(current, value) {
+				fmt.Printf("  · %s.%s already allows %q\n", step.Collection, name, value)
+				continue
+			}
+			fmt.Printf("  + allow %q in %s.%s\n", value, step.Collection, name)
+			current = append(current, value)
+			changed = true
+		}
+		options["values"] = current
+	}
+
+	if !changed || dryRun {
+		return nil
+	}
+	return a.send(http.MethodPatch, "/api/collections/"+url.PathEscape(step.Collection),
+		map[string]interface{}{"schema": schema}, nil)
+}
+
+func (a *pbAdmin) revertStep(step migrationStep, dryRun bool) error {
+	collection, err := a.collection(step.Collection)
+	if err != nil {
+		return err
+	}
+	if collection == nil {
+		fmt.Printf("  · collection %s doesn't exist\n", step.Collection)
+		return nil
+	}
+
+	if step.Create != nil {
+		fmt.Printf("  - delete collection %s\n", step.Collection)
+		if dryRun {
+			return nil
+		}
+		return a.send(http.MethodDelete, "/api/collections/"+url.PathEscape(step.Collection), nil, nil)
+	}
+
+	schema := schemaFields(collection)
+	changed := false
+
+	for name, values := range step.AddSelectValues {
+		field := findSchemaField(schema, name)
+		if field == nil {
+			continue
+		}
+		options, _ := field["options"].(map[string]interface{})
+		current, _ := options["values"].([]interface{})
+		var kept []interface{}
+		for _, value := range current {
+			if s, ok := value.(string); ok && containsString(values, s) {
+				fmt.Printf("  - disallow %q in %s.%s\n", s, step.Collection, name)
+				changed = true
+				continue
+			}
+			kept = append(kept, value)
+		}
+		if options != nil {
+			options["values"] = kept
+		}
+	}
+
+	for _, field := range step.AddFields {
+		name, _ := field["name"].(string)
+		for i, existing := range schema {
+			if existing["name"] == name {
+				fmt.Printf("  - remove field %s.%s\n", step.Collection, name)
+				schema = append(schema[:i], schema[i+1:]...)
+				changed = true
+				break
+			}
+		}
+	}
+
+	if !changed || dryRun {
+		return nil
+	}
+	return a.send(http.MethodPatch, "/api/collections/"+url.PathEscape(step.Collection),
+		map[string]interface{}{"schema": schema}, nil)
+}
+
+// resolveRelations copies a collection payload, replacing collection names
+// in relation fields' options.collectionId with the collections' IDs
+func (a *pbAdmin) resolveRelations(create map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(create)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	fields, _ := payload["schema"].([]interface{})
+	for _, f := range fields {
+		field, _ := f.(map[string]interface{})
+		options, _ := field["options"].(map[string]interface{})
+		target, _ := options["collectionId"].(string)
+		if field["type"] != "relation" || target == "" {
+			continue
+		}
+		collection, err := a.collection(target)
+		if err != nil {
+			return nil, err
+		}
+		if collection == nil {
+			return nil, fmt.Errorf("relation target %s not found", target)
+		}
+		options["collectionId"] = collection["id"]
+	}
+	return payload, nil
+}
+
+func schemaFields(collection map[string]interface{}) []map[string]interface{} {
+	raw, _ := collection["schema"].([]interface{})
+	fields := make([]map[string]interface{}, 0, len(raw))
+	for _, f := range raw {
+		if field, ok := f.(map[string]interface{}); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func findSchemaField(schema []map[string]interface{}, name string) map[string]interface{} {
+	for _, field := range schema {
+		if field["name"] == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func containsValue(values []interface{}, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
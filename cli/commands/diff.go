@@ -4,34 +4,60 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/angelfreak/ccd/daemon/smart"
 	"github.com/spf13/cobra"
 )
 
 func NewDiffCommand(pbURL *string) *cobra.Command {
 	var count int
+	var againstHandoff bool
+	var repoPath string
+	var tag string
+	var report diffReportOptions
 
 	cmd := &cobra.Command{
-		Use:   "diff <project-slug>",
+		Use:   "diff [project-slug]",
 		Short: "Show differences between recent sessions",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			projectSlug := args[0]
-			return showDiff(*pbURL, projectSlug, count)
+			switch report.format {
+			case "text", "markdown", "html":
+			default:
+				return fmt.Errorf("unknown format %q: expected text, markdown, or html", report.format)
+			}
+
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			if againstHandoff && tag != "" {
+				return fmt.Errorf("--tag can't be combined with --against-handoff")
+			}
+			if againstHandoff {
+				return showHandoffDiff(*pbURL, projectSlug, repoPath, report)
+			}
+			return showDiff(*pbURL, projectSlug, tag, count, report)
 		},
 	}
 
 	cmd.Flags().IntVarP(&count, "count", "n", 5, "Number of sessions to compare")
+	cmd.Flags().BoolVar(&againstHandoff, "against-handoff", false, "Compare current facts with the latest handoff instead of past sessions")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only compare sessions with this tag or git branch")
+	cmd.Flags().StringVar(&repoPath, "repo", "", "With --against-handoff, the repo holding thoughts/shared/handoffs (default: the project's repo path)")
+	cmd.Flags().StringVar(&report.format, "format", "text", "Output format: text, markdown, or html (a standalone page to share)")
+	cmd.Flags().StringVarP(&report.output, "output", "o", "", "With --format markdown or html, write the report to this file instead of stdout")
 
 	return cmd
 }
 
-func showDiff(pbURL, projectSlug string, count int) error {
+func showDiff(pbURL, projectSlug, tag string, count int, report diffReportOptions) error {
 	// Get project by slug
 	url := fmt.Sprintf("%s/api/collections/projects/records?filter=slug='%s'", pbURL, projectSlug)
-	resp, err := http.Get(url)
+	resp, err := httpGet(url)
 	if err != nil {
 		return fmt.Errorf("failed to fetch project: %w", err)
 	}
@@ -58,44 +84,47 @@ func showDiff(pbURL, projectSlug string, count int) error {
 
 	projectID := result.Items[0].ID
 
-	// Get session history
-	url = fmt.Sprintf("%s/api/collections/session_history/records?filter=project='%s'&sort=-created&limit=%d",
-		pbURL, projectID, count)
-	resp, err = http.Get(url)
+	sessions, err := getSessions(pbURL, projectID, tag, count)
 	if err != nil {
 		return fmt.Errorf("failed to fetch sessions: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err = io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
 
-	var sessions struct {
-		Items []struct {
-			ID         string `json:"id"`
-			Summary    string `json:"summary"`
-			TokenCount int    `json:"token_count"`
-			Created    string `json:"created"`
-		} `json:"items"`
+	if len(sessions) == 0 {
+		fmt.Println("No session history found")
+		return nil
 	}
 
-	if err := json.Unmarshal(body, &sessions); err != nil {
-		return err
+	// Older sessions may have no facts; they still get a token comparison
+	sessionFacts := make([][]factRecord, len(sessions))
+	for i, session := range sessions {
+		facts, err := fetchSessionFacts(pbURL, projectID, session)
+		if err != nil {
+			return fmt.Errorf("failed to fetch facts: %w", err)
+		}
+		sessionFacts[i] = facts
 	}
 
-	if len(sessions.Items) == 0 {
-		fmt.Println("No session history found")
-		return nil
+	if report.format != "text" {
+		generator := smart.NewDiffGenerator()
+		var comparisons []smart.SessionComparison
+		for i := 1; i < len(sessions); i++ {
+			previous := sessionSnapshot(sessions[i], sessionFacts[i])
+			current := sessionSnapshot(sessions[i-1], sessionFacts[i-1])
+			comparisons = append(comparisons, smart.SessionComparison{
+				Previous: previous,
+				Current:  current,
+				Diff:     generator.GenerateDiff(previous, current),
+			})
+		}
+		return writeDiffReport(report, "Session Diff for "+projectSlug, comparisons)
 	}
 
 	fmt.Printf("📊 Session Diff for %s\n\n", projectSlug)
 
 	// Calculate and display diffs
-	for i := 1; i < len(sessions.Items); i++ {
-		current := sessions.Items[i-1]
-		previous := sessions.Items[i]
+	for i := 1; i < len(sessions); i++ {
+		current := sessions[i-1]
+		previous := sessions[i]
 
 		tokenDelta := current.TokenCount - previous.TokenCount
 
@@ -110,14 +139,110 @@ func showDiff(pbURL, projectSlug string, count int) error {
 			fmt.Printf("Tokens:  no change\n")
 		}
 
+		printFactDiff(sessionFacts[i], sessionFacts[i-1], previous, current)
+
 		fmt.Println()
 	}
 
 	return nil
 }
 
+// printFactDiff lists facts added and removed between two sessions, or notes
+// which sessions have no facts to compare
+func printFactDiff(previousFacts, currentFacts []factRecord, previous, current sessionRecord) {
+	var factless []string
+	if len(previousFacts) == 0 {
+		factless = append(factless, formatTime(previous.Created))
+	}
+	if len(currentFacts) == 0 {
+		factless = append(factless, formatTime(current.Created))
+	}
+	if len(factless) > 0 {
+		fmt.Printf("Facts:   unavailable for %s (token comparison only)\n", joinStrings(factless, " and "))
+		return
+	}
+
+	previousKeys := make(map[string]bool)
+	for _, fact := range previousFacts {
+		previousKeys[smart.FactKey(fact.FactType, fact.Content)] = true
+	}
+	currentKeys := make(map[string]bool)
+	for _, fact := range currentFacts {
+		currentKeys[smart.FactKey(fact.FactType, fact.Content)] = true
+	}
+
+	for _, fact := range currentFacts {
+		if !previousKeys[smart.FactKey(fact.FactType, fact.Content)] {
+			fmt.Printf("  + [%s] %s\n", fact.FactType, fact.Content)
+		}
+	}
+	for _, fact := range previousFacts {
+		if !currentKeys[smart.FactKey(fact.FactType, fact.Content)] {
+			fmt.Printf("  - [%s] %s\n", fact.FactType, fact.Content)
+		}
+	}
+}
+
+// diffReportOptions choose how a diff is rendered: as text printed directly,
+// or as a markdown or HTML report
+type diffReportOptions struct {
+	format string
+	output string
+	// location is the time zone text reports show times in; nil means
+	// local time
+	location *time.Location
+}
+
+// writeDiffReport renders comparisons as a markdown or HTML report and writes
+// it to the output file, or stdout
+func writeDiffReport(report diffReportOptions, title string, comparisons []smart.SessionComparison) error {
+	generator := smart.NewDiffGenerator()
+
+	var content string
+	if report.format == "html" {
+		var err error
+		if content, err = generator.FormatHTML(title, comparisons); err != nil {
+			return fmt.Errorf("failed to render report: %w", err)
+		}
+	} else {
+		var parts []string
+		for _, c := range comparisons {
+			parts = append(parts, generator.FormatDiff(c.Diff, c.Previous, c.Current))
+		}
+		content = strings.Join(parts, "\n")
+	}
+
+	if report.output == "" {
+		fmt.Print(content)
+		return nil
+	}
+	if err := os.WriteFile(report.output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", report.output, err)
+	}
+	fmt.Printf("✓ Wrote %s\n", report.output)
+	return nil
+}
+
+// sessionSnapshot converts a session and its facts for smart.DiffGenerator
+func sessionSnapshot(session sessionRecord, facts []factRecord) smart.SessionSnapshot {
+	snapshot := smart.SessionSnapshot{
+		SessionID:        session.ID,
+		TokenCount:       session.TokenCount,
+		FactsUnavailable: len(facts) == 0,
+	}
+	snapshot.Timestamp, _ = parsePBTime(session.Created)
+	for _, fact := range facts {
+		snapshot.Facts = append(snapshot.Facts, smart.CompressibleFact{
+			Type:       fact.FactType,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+		})
+	}
+	return snapshot
+}
+
 func formatTime(timeStr string) string {
-	t, err := time.Parse(time.RFC3339, timeStr)
+	t, err := parsePBTime(timeStr)
 	if err != nil {
 		return timeStr
 	}
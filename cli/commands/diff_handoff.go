@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// showHandoffDiff compares the project's current facts with those captured in
+// its latest handoff, answering "what has changed since my last handoff?"
+func showHandoffDiff(pbURL, projectSlug, repoPath string, report diffReportOptions) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	if repoPath == "" {
+		repoPath = project.RepoPath
+	}
+	if repoPath == "" {
+		return fmt.Errorf("project %s has no repo path, use --repo", projectSlug)
+	}
+
+	handoff, err := ledger.LatestHandoffIn(ledger.HandoffDirFor(repoPath))
+	if err != nil {
+		return fmt.Errorf("failed to read handoffs: %w", err)
+	}
+	if handoff == nil {
+		fmt.Printf("No handoffs found in %s\n", ledger.HandoffDirFor(repoPath))
+		return nil
+	}
+
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s' && stale=false", project.ID), "-importance")
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+
+	previous := handoffSnapshot(handoff)
+
+	current := smart.SessionSnapshot{SessionID: "current facts", Timestamp: time.Now()}
+	for _, fact := range facts {
+		current.Facts = append(current.Facts, smart.CompressibleFact{
+			Type:       fact.FactType,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+		})
+	}
+
+	diff := smart.NewDiffGenerator().GenerateDiff(previous, current)
+
+	if report.format != "text" {
+		return writeDiffReport(report, fmt.Sprintf("Changes in %s since handoff %s", projectSlug, handoff.SessionID),
+			[]smart.SessionComparison{{Previous: previous, Current: current, Diff: diff}})
+	}
+
+	fmt.Printf("📊 Changes in %s since handoff %s (%s)\n\n", projectSlug, handoff.SessionID,
+		handoff.Timestamp.Local().Format("Jan 2, 2006 3:04 PM"))
+	fmt.Printf("Summary: %s\n", diff.Summary)
+
+	printCompressibleFacts("+", diff.Added)
+	printCompressibleFacts("-", diff.Removed)
+	return nil
+}
+
+// printCompressibleFacts lists facts most important first, each marked with
+// prefix
+func printCompressibleFacts(prefix string, facts []smart.CompressibleFact) {
+	sort.SliceStable(facts, func(i, j int) bool {
+		if facts[i].Importance != facts[j].Importance {
+			return facts[i].Importance > facts[j].Importance
+		}
+		return facts[i].Content < facts[j].Content
+	})
+	for _, fact := range facts {
+		fmt.Printf("  %s [%s] %s\n", prefix, fact.Type, fact.Content)
+	}
+}
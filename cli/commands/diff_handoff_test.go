@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+)
+
+// newFixtureRepo creates a repo holding the handoffs in testdata/handoffs
+func newFixtureRepo(t *testing.T) string {
+	t.Helper()
+	return newHandoffRepo(t, "handoffs")
+}
+
+// newHandoffRepo creates a repo holding the handoffs in testdata/fixture
+func newHandoffRepo(t *testing.T, fixture string) string {
+	t.Helper()
+	repo := t.TempDir()
+	dir := ledger.HandoffDirFor(repo)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", fixture, "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(file)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func TestDiffAgainstHandoff(t *testing.T) {
+	repo := newFixtureRepo(t)
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "repo_path": repo})
+	pb.add("extracted_facts",
+		map[string]interface{}{"id": "f1", "project": "p1", "fact_type": "decision", "content": "Use Postgres", "importance": 4},
+		map[string]interface{}{"id": "f2", "project": "p1", "fact_type": "todo", "content": "Write the migration guide", "importance": 3},
+		map[string]interface{}{"id": "f3", "project": "p1", "fact_type": "decision", "content": "Deploy with Fly.io", "importance": 4},
+		map[string]interface{}{"id": "f4", "project": "p1", "fact_type": "todo", "content": "Add a health check", "importance": 3},
+	)
+
+	out := captureStdout(t, func() {
+		if err := showHandoffDiff(pb.URL, "app", "", diffReportOptions{format: "text"}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	for _, want := range []string{
+		"Changes in app since handoff s1",
+		"Summary: 2 new facts, 1 resolved\n",
+		"  + [decision] Deploy with Fly.io\n  + [todo] Add a health check\n  - [blocker] CI is red on main\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	for _, unchanged := range []string{"Use Postgres", "Write the migration guide"} {
+		if strings.Contains(out, unchanged) {
+			t.Errorf("output lists the unchanged fact %q:\n%s", unchanged, out)
+		}
+	}
+	if filters := pb.listFilters("extracted_facts"); len(filters) != 1 || filters[0] != "project='p1' && stale=false" {
+		t.Errorf("fact filters = %q, want the project's current facts", filters)
+	}
+}
+
+func TestDiffAgainstHandoffWithoutHandoffs(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "repo_path": t.TempDir()})
+
+	out := captureStdout(t, func() {
+		if err := showHandoffDiff(pb.URL, "app", "", diffReportOptions{format: "text"}); err != nil {
+			t.Error(err)
+		}
+	})
+	if !strings.HasPrefix(out, "No handoffs found in ") {
+		t.Errorf("output = %q, want a note that there are no handoffs", out)
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestPrintFactDiffWithFactlessSession(t *testing.T) {
+	older := sessionRecord{ID: "s1", Created: "2026-03-01 12:00:00.000Z", TokenCount: 1000}
+	newer := sessionRecord{ID: "s2", Created: "2026-03-01 14:00:00.000Z", TokenCount: 1500}
+	facts := []factRecord{
+		{FactType: "decision", Content: "Use Postgres", Importance: 4},
+		{FactType: "blocker", Content: "CI is red", Importance: 5},
+	}
+
+	out := captureStdout(t, func() { printFactDiff(nil, facts, older, newer) })
+	want := "Facts:   unavailable for " + formatTime(older.Created) + " (token comparison only)\n"
+	if out != want {
+		t.Errorf("fact-less diff printed %q, want %q", out, want)
+	}
+
+	later := []factRecord{
+		{FactType: "decision", Content: "Use Postgres", Importance: 4},
+		{FactType: "todo", Content: "Add migrations", Importance: 3},
+	}
+	out = captureStdout(t, func() { printFactDiff(facts, later, older, newer) })
+	want = "  + [todo] Add migrations\n  - [blocker] CI is red\n"
+	if out != want {
+		t.Errorf("fact diff printed %q, want %q", out, want)
+	}
+}
+
+func TestPrintFactDiffMatchesNormalizedContent(t *testing.T) {
+	older := sessionRecord{ID: "s1", Created: "2026-03-01 12:00:00.000Z"}
+	newer := sessionRecord{ID: "s2", Created: "2026-03-01 14:00:00.000Z"}
+	previous := []factRecord{{FactType: "decision", Content: "Use Postgres", Importance: 4}}
+	current := []factRecord{{FactType: "decision", Content: "use  postgres.", Importance: 4}}
+
+	// Matched by the same normalized key as the daemon's diffs and dedup
+	if out := captureStdout(t, func() { printFactDiff(previous, current, older, newer) }); out != "" {
+		t.Errorf("fact diff of reworded content printed %q, want nothing", out)
+	}
+}
+
+func TestSessionSnapshotMarksFactlessSessions(t *testing.T) {
+	session := sessionRecord{ID: "s1", Created: "2026-03-01 12:00:00.000Z", TokenCount: 1000}
+
+	if snapshot := sessionSnapshot(session, nil); !snapshot.FactsUnavailable {
+		t.Error("a session without facts isn't marked fact-less")
+	}
+	snapshot := sessionSnapshot(session, []factRecord{{FactType: "decision", Content: "Use Postgres", Importance: 4}})
+	if snapshot.FactsUnavailable || len(snapshot.Facts) != 1 {
+		t.Errorf("snapshot = %+v, want one fact", snapshot)
+	}
+	if want := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC); !snapshot.Timestamp.Equal(want) {
+		t.Errorf("snapshot timestamp = %s, want the session's creation time", snapshot.Timestamp)
+	}
+}
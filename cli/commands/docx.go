@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// docxInline matches the inline markdown kept in Word output: bold, code
+// spans, and links
+var docxInline = regexp.MustCompile("\\*\\*([^*]+)\\*\\*|`([^`]+)`|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)")
+
+const (
+	// docxMaxHeading is the deepest heading style; deeper headings use it
+	docxMaxHeading = 4
+	// docxTOCLevels is how many heading levels the table of contents lists
+	docxTOCLevels = 3
+	// docxBulletNumID is the numbering instance shared by all bullet lists;
+	// numbered lists get their own from docxBulletNumID+1 so each restarts at 1
+	docxBulletNumID = 1
+)
+
+// docxDocument is a minimal WordprocessingML document: a title page, a table
+// of contents, and the body blocks
+type docxDocument struct {
+	Title   string
+	Date    time.Time
+	Version string
+	Blocks  []docBlock
+}
+
+// Write packages the document as a .docx file
+func (d docxDocument) Write(w io.Writer) error {
+	body, numberedLists := d.body()
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"docProps/core.xml", d.coreProperties()},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/document.xml", body},
+		{"word/styles.xml", docxStyles()},
+		{"word/numbering.xml", docxNumbering(numberedLists)},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// body renders word/document.xml, returning it with the number of numbered
+// lists, each of which needs its own numbering instance
+func (d docxDocument) body() (string, int) {
+	var b strings.Builder
+	b.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>`)
+
+	// Title page
+	docxParagraph(&b, "Title", "", docxText(d.Title))
+	docxParagraph(&b, "Subtitle", "", docxText(d.Date.Format("January 2, 2006")))
+	docxParagraph(&b, "Subtitle", "", docxText("Generated by cct version "+d.Version))
+	docxPageBreak(&b)
+
+	// Headings are bookmarked so the contents entries can link to them
+	docxParagraph(&b, "TOCHeading", "", docxText("Contents"))
+	for i, block := range d.Blocks {
+		if block.Kind == docHeading && block.Level <= docxTOCLevels {
+			link := fmt.Sprintf(`<w:hyperlink w:anchor="_Toc%d" w:history="1">%s</w:hyperlink>`, i, docxText(block.Text))
+			docxParagraph(&b, fmt.Sprintf("TOC%d", block.Level), "", link)
+		}
+	}
+	docxPageBreak(&b)
+
+	numberedLists := 0
+	for i, block := range d.Blocks {
+		switch block.Kind {
+		case docHeading:
+			level := min(block.Level, docxMaxHeading)
+			runs := fmt.Sprintf(`<w:bookmarkStart w:id="%d" w:name="_Toc%d"/>%s<w:bookmarkEnd w:id="%d"/>`,
+				i, i, docxRuns(block.Text), i)
+			docxParagraph(&b, fmt.Sprintf("Heading%d", level), "", runs)
+		case docBullet:
+			docxParagraph(&b, "ListParagraph", docxNumPr(block.Level, docxBulletNumID), docxRuns(block.Text))
+		case docNumbered:
+			if i == 0 || d.Blocks[i-1].Kind != docNumbered {
+				numberedLists++
+			}
+			docxParagraph(&b, "ListParagraph", docxNumPr(block.Level, docxBulletNumID+numberedLists), docxRuns(block.Text))
+		case docCode:
+			docxParagraph(&b, "Code", "", docxText(block.Text))
+		default:
+			docxParagraph(&b, "", "", docxRuns(block.Text))
+		}
+	}
+
+	b.WriteString(`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="720" w:footer="720" w:gutter="0"/></w:sectPr>`)
+	b.WriteString(`</w:body></w:document>`)
+	return b.String(), numberedLists
+}
+
+// docxParagraph writes a paragraph with an optional style and extra
+// paragraph properties around already-rendered runs
+func docxParagraph(b *strings.Builder, style, props, runs string) {
+	b.WriteString("<w:p>")
+	if style != "" || props != "" {
+		b.WriteString("<w:pPr>")
+		if style != "" {
+			fmt.Fprintf(b, `<w:pStyle w:val="%s"/>`, style)
+		}
+		b.WriteString(props)
+		b.WriteString("</w:pPr>")
+	}
+	b.WriteString(runs)
+	b.WriteString("</w:p>")
+}
+
+func docxPageBreak(b *strings.Builder) {
+	b.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+}
+
+func docxNumPr(level, numID int) string {
+	return fmt.Sprintf(`<w:numPr><w:ilvl w:val="%d"/><w:numId w:val="%d"/></w:numPr>`, level, numID)
+}
+
+// docxRuns renders markdown text as runs, with bold and code spans formatted
+// and links reduced to their text
+func docxRuns(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range docxInline.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(docxRun(text[last:m[0]], ""))
+		switch {
+		case m[2] >= 0:
+			b.WriteString(docxRun(text[m[2]:m[3]], "<w:b/>"))
+		case m[4] >= 0:
+			b.WriteString(docxRun(text[m[4]:m[5]], docxCodeFont))
+		default:
+			b.WriteString(docxRun(text[m[6]:m[7]], `<w:u w:val="single"/>`))
+		}
+		last = m[1]
+	}
+	b.WriteString(docxRun(text[last:], ""))
+	return b.String()
+}
+
+// docxText renders plain text as a single run
+func docxText(text string) string {
+	return docxRun(text, "")
+}
+
+func docxRun(text, props string) string {
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<w:r>")
+	if props != "" {
+		b.WriteString("<w:rPr>" + props + "</w:rPr>")
+	}
+	b.WriteString(`<w:t xml:space="preserve">`)
+	xml.EscapeText(&b, []byte(text))
+	b.WriteString("</w:t></w:r>")
+	return b.String()
+}
+
+const docxCodeFont = `<w:rFonts w:ascii="Courier New" w:hAnsi="Courier New" w:cs="Courier New"/>`
+
+func (d docxDocument) coreProperties() string {
+	var title strings.Builder
+	xml.EscapeText(&title, []byte(d.Title))
+	return fmt.Sprintf(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">`+
+		`<dc:title>%s</dc:title><dc:creator>cct %s</dc:creator>`+
+		`<dcterms:created xsi:type="dcterms:W3CDTF">%s</dcterms:created></cp:coreProperties>`,
+		title.String(), d.Version, d.Date.UTC().Format(time.RFC3339))
+}
+
+// docxStyles defines the paragraph styles the body uses. Headings carry outline
+// levels so Word's navigation pane and its own TOC feature pick them up.
+func docxStyles() string {
+	var b strings.Builder
+	b.WriteString(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`)
+	b.WriteString(`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="22"/></w:rPr></w:rPrDefault>` +
+		`<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="264" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>`)
+	b.WriteString(`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>`)
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:qFormat/>` +
+		`<w:pPr><w:spacing w:before="2400" w:after="240"/><w:jc w:val="center"/></w:pPr><w:rPr><w:b/><w:sz w:val="56"/></w:rPr></w:style>`)
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:qFormat/>` +
+		`<w:pPr><w:jc w:val="center"/></w:pPr><w:rPr><w:color w:val="595959"/><w:sz w:val="26"/></w:rPr></w:style>`)
+
+	sizes := []int{32, 28, 24, 22}
+	for level := 1; level <= docxMaxHeading; level++ {
+		fmt.Fprintf(&b, `<w:style w:type="paragraph" w:styleId="Heading%d"><w:name w:val="heading %d"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>`+
+			`<w:pPr><w:keepNext/><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="%d"/></w:pPr><w:rPr><w:b/><w:sz w:val="%d"/></w:rPr></w:style>`,
+			level, level, level-1, sizes[level-1])
+	}
+
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="TOCHeading"><w:name w:val="TOC Heading"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/>` +
+		`<w:pPr><w:spacing w:before="240" w:after="240"/></w:pPr><w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>`)
+	for level := 1; level <= docxTOCLevels; level++ {
+		fmt.Fprintf(&b, `<w:style w:type="paragraph" w:styleId="TOC%d"><w:name w:val="toc %d"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/>`+
+			`<w:pPr><w:spacing w:after="60"/><w:ind w:left="%d"/></w:pPr></w:style>`,
+			level, level, (level-1)*440)
+	}
+
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:basedOn w:val="Normal"/><w:qFormat/>` +
+		`<w:pPr><w:spacing w:after="60"/><w:contextualSpacing/></w:pPr></w:style>`)
+	b.WriteString(`<w:style w:type="paragraph" w:styleId="Code"><w:name w:val="Code"/><w:basedOn w:val="Normal"/>` +
+		`<w:pPr><w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>` +
+		`<w:rPr>` + docxCodeFont + `<w:sz w:val="20"/></w:rPr></w:style>`)
+	b.WriteString(`</w:styles>`)
+	return b.String()
+}
+
+// docxNumbering defines a bullet list and numberedLists numbered lists,
+// three levels deep
+func docxNumbering(numberedLists int) string {
+	var b strings.Builder
+	b.WriteString(`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`)
+
+	bullets := []string{"•", "◦", "▪"}
+	b.WriteString(`<w:abstractNum w:abstractNumId="0"><w:multiLevelType w:val="hybridMultilevel"/>`)
+	for level, bullet := range bullets {
+		fmt.Fprintf(&b, `<w:lvl w:ilvl="%d"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="%s"/><w:lvlJc w:val="left"/>`+
+			`<w:pPr><w:ind w:left="%d" w:hanging="360"/></w:pPr></w:lvl>`, level, bullet, 720*(level+1))
+	}
+	b.WriteString(`</w:abstractNum>`)
+
+	formats := []string{"decimal", "lowerLetter", "lowerRoman"}
+	b.WriteString(`<w:abstractNum w:abstractNumId="1"><w:multiLevelType w:val="hybridMultilevel"/>`)
+	for level, format := range formats {
+		fmt.Fprintf(&b, `<w:lvl w:ilvl="%d"><w:start w:val="1"/><w:numFmt w:val="%s"/><w:lvlText w:val="%%%d."/><w:lvlJc w:val="left"/>`+
+			`<w:pPr><w:ind w:left="%d" w:hanging="360"/></w:pPr></w:lvl>`, level, format, level+1, 720*(level+1))
+	}
+	b.WriteString(`</w:abstractNum>`)
+
+	fmt.Fprintf(&b, `<w:num w:numId="%d"><w:abstractNumId w:val="0"/></w:num>`, docxBulletNumID)
+	for i := 1; i <= numberedLists; i++ {
+		fmt.Fprintf(&b, `<w:num w:numId="%d"><w:abstractNumId w:val="1"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/></w:lvlOverride></w:num>`,
+			docxBulletNumID+i)
+	}
+	b.WriteString(`</w:numbering>`)
+	return b.String()
+}
+
+const docxContentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxRootRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+const docxDocumentRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering" Target="numbering.xml"/>` +
+	`</Relationships>`
@@ -0,0 +1,15 @@
+package commands
+
+// ExitError ends the CLI with a specific exit code instead of the default 1
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
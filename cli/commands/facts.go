@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewFactsCommand(pbURL *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "facts",
+		Short: "Inspect and manage extracted facts",
+	}
+
+	cmd.AddCommand(NewFactsAgeHeatmapCommand(pbURL))
+	cmd.AddCommand(NewFactsCreateCommand(pbURL))
+	cmd.AddCommand(NewFactsCorrelateCommand(pbURL))
+	cmd.AddCommand(NewFactsCorrectCommand(pbURL))
+	cmd.AddCommand(NewFactsDeleteCommand(pbURL))
+	cmd.AddCommand(NewFactsExportCommand(pbURL))
+	cmd.AddCommand(NewFactsUndoDeleteCommand(pbURL))
+	cmd.AddCommand(NewFactsSentimentCommand(pbURL))
+	cmd.AddCommand(NewFactsPruneBySessionCommand(pbURL))
+	cmd.AddCommand(NewFactsRecalculateCommand(pbURL))
+	cmd.AddCommand(NewFactsSearchCommand(pbURL))
+	cmd.AddCommand(NewFactsSummarizeCommand(pbURL))
+	cmd.AddCommand(NewFactsVerifyCommand(pbURL))
+
+	return cmd
+}
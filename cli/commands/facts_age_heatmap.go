@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// heatmapWeeks is how many weeks, ending with the current one, the heatmap
+// covers
+const heatmapWeeks = 12
+
+// heatmapShades are the cell shades from no activity to the busiest day
+var heatmapShades = []string{" ", "░", "▒", "▓", "█"}
+
+// factTypeColors color heatmap cells by the day's dominant fact type
+var factTypeColors = map[string]string{
+	"decision":      "\033[34m",
+	"blocker":       "\033[31m",
+	"todo":          "\033[33m",
+	"insight":       "\033[35m",
+	"file_change":   "\033[32m",
+	"dependency":    "\033[36m",
+	"config_change": "\033[37m",
+	"test_status":   "\033[91m",
+}
+
+// heatmapDay is the activity on one calendar day
+type heatmapDay struct {
+	total  int
+	byType map[string]int
+}
+
+// dominantType is the type with the most weight that day, ties broken by name
+func (d heatmapDay) dominantType() string {
+	best := ""
+	for factType, weight := range d.byType {
+		if best == "" || weight > d.byType[best] || (weight == d.byType[best] && factType < best) {
+			best = factType
+		}
+	}
+	return best
+}
+
+func NewFactsAgeHeatmapCommand(pbURL *string) *cobra.Command {
+	var factType string
+	var byImportance bool
+
+	cmd := &cobra.Command{
+		Use:   "age-heatmap [project-slug]",
+		Short: "Show when facts were captured as a calendar heatmap",
+		Long: fmt.Sprintf(`Show a calendar heatmap of when the project's facts were created over
+the last %d weeks. Rows are days of the week and columns are weeks, oldest
+first; each day is shaded by how many facts it captured relative to the
+busiest day. In a terminal, days are colored by their most common fact type.`, heatmapWeeks),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return showFactsHeatmap(*pbURL, projectSlug, factType, byImportance)
+		},
+	}
+
+	cmd.Flags().StringVarP(&factType, "type", "t", "", "Only count facts of this type")
+	cmd.Flags().BoolVar(&byImportance, "importance", false, "Weight each fact by its importance instead of counting it once")
+
+	return cmd
+}
+
+func showFactsHeatmap(pbURL, projectSlug, factType string, byImportance bool) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	// Weeks start on Monday
+	start := today.AddDate(0, 0, -(int(today.Weekday())+6)%7-7*(heatmapWeeks-1))
+
+	filter := fmt.Sprintf("project='%s' && created>='%s'", project.ID, start.UTC().Format(pbTimeLayout))
+	if factType != "" {
+		filter += fmt.Sprintf(" && fact_type='%s'", factType)
+	}
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts", filter, "created")
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+
+	days := make(map[string]*heatmapDay)
+	for _, fact := range facts {
+		created, err := parsePBTime(fact.Created)
+		if err != nil {
+			continue
+		}
+		key := created.Local().Format("2006-01-02")
+		day, ok := days[key]
+		if !ok {
+			day = &heatmapDay{byType: make(map[string]int)}
+			days[key] = day
+		}
+		weight := 1
+		if byImportance {
+			weight = fact.Importance
+		}
+		day.total += weight
+		day.byType[fact.FactType] += weight
+	}
+
+	peak := 0
+	for _, day := range days {
+		peak = max(peak, day.total)
+	}
+
+	measure := "facts"
+	if byImportance {
+		measure = "importance"
+	}
+	scope := ""
+	if factType != "" {
+		scope = factType + " facts, "
+	}
+	fmt.Printf("📅 Fact activity for %s (%slast %d weeks, by %s)\n\n", project.Name, scope, heatmapWeeks, measure)
+
+	if peak == 0 {
+		fmt.Println("No facts created in this period")
+		return nil
+	}
+
+	color := isTerminal(os.Stdout)
+	fmt.Println("    " + heatmapMonthLabels(start))
+	seen := make(map[string]bool)
+	for weekday := 0; weekday < 7; weekday++ {
+		var row strings.Builder
+		row.WriteString(start.AddDate(0, 0, weekday).Format("Mon") + " ")
+		for week := 0; week < heatmapWeeks; week++ {
+			date := start.AddDate(0, 0, week*7+weekday)
+			day, ok := days[date.Format("2006-01-02")]
+			if !ok || date.After(today) {
+				row.WriteString("  ")
+				continue
+			}
+
+			shade := heatmapShades[(day.total*(len(heatmapShades)-1)+peak-1)/peak]
+			dominant := day.dominantType()
+			seen[dominant] = true
+			if style, ok := factTypeColors[dominant]; ok && color {
+				shade = style + shade + ansiReset
+			}
+			row.WriteString(shade + " ")
+		}
+		fmt.Println(strings.TrimRight(row.String(), " "))
+	}
+
+	fmt.Printf("\nLess %s More   (busiest day: %d %s)\n", strings.Join(heatmapShades[1:], ""), peak, measure)
+	if color && factType == "" {
+		var types []string
+		for t := range seen {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		legend := make([]string, 0, len(types))
+		for _, t := range types {
+			if style, ok := factTypeColors[t]; ok {
+				legend = append(legend, style+"█"+ansiReset+" "+t)
+			}
+		}
+		if len(legend) > 0 {
+			fmt.Println(strings.Join(legend, "  "))
+		}
+	}
+	return nil
+}
+
+// heatmapMonthLabels names each month above the first week column that
+// starts in it. A label that would run into the next one is dropped, which
+// only happens to a partial first month.
+func heatmapMonthLabels(start time.Time) string {
+	type label struct {
+		col  int
+		name string
+	}
+	var labels []label
+	lastMonth := time.Month(0)
+	for week := 0; week < heatmapWeeks; week++ {
+		monday := start.AddDate(0, 0, week*7)
+		if monday.Month() == lastMonth {
+			continue
+		}
+		lastMonth = monday.Month()
+		if n := len(labels); n > 0 && labels[n-1].col+4 > week*2 {
+			labels = labels[:n-1]
+		}
+		labels = append(labels, label{col: week * 2, name: monday.Format("Jan")})
+	}
+
+	var line strings.Builder
+	for _, l := range labels {
+		line.WriteString(strings.Repeat(" ", l.col-line.Len()) + l.name)
+	}
+	return line.String()
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/smart"
+	"github.com/spf13/cobra"
+)
+
+// correlation is a fact that occurred near some of the target's occurrences
+type correlation struct {
+	fact factRecord
+	// count is how many of the target's occurrences it appeared near
+	count int
+}
+
+func NewFactsCorrelateCommand(pbURL *string) *cobra.Command {
+	var window time.Duration
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "correlate <fact-id>",
+		Short: "Show facts that often occur around a given fact",
+		Long: `Show which facts tend to be extracted around the given one. Every
+recurrence of the fact in the project's history (the same type and content,
+matched as in deduplication) is an occurrence; other facts created within
+--window of an occurrence count once towards it. Facts are ranked by the
+number of occurrences they appeared near.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if window <= 0 {
+				return fmt.Errorf("--window must be positive")
+			}
+			return correlateFact(*pbURL, args[0], window, limit)
+		},
+	}
+
+	cmd.Flags().DurationVar(&window, "window", time.Hour, "How close in time, before or after, a fact must be to count")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum number of facts to show")
+
+	return cmd
+}
+
+func correlateFact(pbURL, factID string, window time.Duration, limit int) error {
+	targets, err := listRecords[factRecord](pbURL, "extracted_facts", fmt.Sprintf("id='%s'", factID), "")
+	if err != nil {
+		return fmt.Errorf("failed to fetch fact: %w", err)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("fact not found: %s", factID)
+	}
+	target := targets[0]
+
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s'", target.Project), "created")
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+
+	targetKey := smart.FactKey(target.FactType, target.Content)
+	var occurrences []time.Time
+	for _, fact := range facts {
+		if smart.FactKey(fact.FactType, fact.Content) != targetKey {
+			continue
+		}
+		if created, err := parsePBTime(fact.Created); err == nil {
+			occurrences = append(occurrences, created)
+		}
+	}
+	if len(occurrences) == 0 {
+		return fmt.Errorf("failed to parse fact creation time: %s", target.Created)
+	}
+
+	correlations := findCorrelations(facts, targetKey, occurrences, window)
+
+	fmt.Printf("🔗 Facts within %s of [%s] %s\n", window, target.FactType, target.Content)
+	fmt.Printf("   (%d occurrence(s))\n\n", len(occurrences))
+
+	if len(correlations) == 0 {
+		fmt.Println("No facts occurred nearby")
+		return nil
+	}
+	if len(correlations) > limit {
+		correlations = correlations[:limit]
+	}
+
+	for _, c := range correlations {
+		share := float64(c.count) / float64(len(occurrences)) * 100
+		fmt.Printf("%4.0f%%  (%d/%d)  [%s] %s\n", share, c.count, len(occurrences), c.fact.FactType, c.fact.Content)
+	}
+	return nil
+}
+
+// findCorrelations counts, for each distinct fact other than the target, how
+// many occurrences it was created within window of. Results are ranked by
+// count, then by importance.
+func findCorrelations(facts []factRecord, targetKey string, occurrences []time.Time, window time.Duration) []correlation {
+	byKey := make(map[string]*correlation)
+	for _, occurrence := range occurrences {
+		seen := make(map[string]bool)
+		for _, fact := range facts {
+			key := smart.FactKey(fact.FactType, fact.Content)
+			if key == targetKey || seen[key] {
+				continue
+			}
+			created, err := parsePBTime(fact.Created)
+			if err != nil || created.Sub(occurrence).Abs() > window {
+				continue
+			}
+			seen[key] = true
+
+			c, ok := byKey[key]
+			if !ok {
+				c = &correlation{fact: fact}
+				byKey[key] = c
+			}
+			c.count++
+		}
+	}
+
+	correlations := make([]correlation, 0, len(byKey))
+	for _, c := range byKey {
+		correlations = append(correlations, *c)
+	}
+	sort.Slice(correlations, func(i, j int) bool {
+		if correlations[i].count != correlations[j].count {
+			return correlations[i].count > correlations[j].count
+		}
+		if correlations[i].fact.Importance != correlations[j].fact.Importance {
+			return correlations[i].fact.Importance > correlations[j].fact.Importance
+		}
+		return correlations[i].fact.Content < correlations[j].fact.Content
+	})
+	return correlations
+}
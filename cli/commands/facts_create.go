@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// knownFactTypes are the values allowed by the extracted_facts fact_type field
+var knownFactTypes = []string{"decision", "blocker", "file_change", "dependency", "todo", "insight", "config_change", "test_status"}
+
+type factsCreateOptions struct {
+	factType        string
+	content         string
+	importance      int
+	sessionID       string
+	pin             bool
+	allowCustomType bool
+}
+
+func NewFactsCreateCommand(pbURL *string) *cobra.Command {
+	var opts factsCreateOptions
+
+	cmd := &cobra.Command{
+		Use:     "create [project-slug]",
+		Aliases: []string{"add"},
+		Short:   "Record a fact by hand",
+		Long: `Record a fact by hand, for things the daemon didn't capture. Without
+--content, $EDITOR is opened to write it. With --pin the fact is kept when
+stale facts are deleted, and the daemon never marks it stale.
+
+With --session-id the fact is linked to that session record. Otherwise, like
+the daemon's own facts, it belongs to whichever session's time window it was
+created in.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return createFact(*pbURL, projectSlug, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.factType, "type", "t", "", "Fact type: "+strings.Join(knownFactTypes, ", "))
+	cmd.Flags().StringVarP(&opts.content, "content", "c", "", "Fact content (default: write it in $EDITOR)")
+	cmd.Flags().IntVarP(&opts.importance, "importance", "i", 3, "Importance, 1-5")
+	cmd.Flags().StringVar(&opts.sessionID, "session-id", "", "ID of the session record to link the fact to")
+	cmd.Flags().BoolVar(&opts.pin, "pin", false, "Pin the fact so it is never marked stale or deleted by retention")
+	cmd.Flags().BoolVar(&opts.allowCustomType, "allow-custom-type", false, "Allow a type not in the list (PocketBase must also accept it)")
+	cmd.MarkFlagRequired("type")
+
+	return cmd
+}
+
+func createFact(pbURL, projectSlug string, opts factsCreateOptions) error {
+	if !opts.allowCustomType && !containsString(knownFactTypes, opts.factType) {
+		return fmt.Errorf("unknown fact type %q: use one of %s, or --allow-custom-type",
+			opts.factType, strings.Join(knownFactTypes, ", "))
+	}
+	if opts.importance < 1 || opts.importance > 5 {
+		return fmt.Errorf("importance must be between 1 and 5")
+	}
+
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	if opts.sessionID != "" {
+		sessions, err := listRecords[sessionRecord](pbURL, "session_history",
+			fmt.Sprintf("id='%s' && project='%s'", opts.sessionID, project.ID), "")
+		if err != nil {
+			return fmt.Errorf("failed to fetch session: %w", err)
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("session not found in %s: %s", projectSlug, opts.sessionID)
+		}
+	}
+
+	content := strings.TrimSpace(opts.content)
+	if content == "" {
+		if content, err = editFactContent(opts.factType); err != nil {
+			return err
+		}
+	}
+	if content == "" {
+		return fmt.Errorf("fact content is empty, nothing created")
+	}
+
+	data := map[string]interface{}{
+		"project":    project.ID,
+		"fact_type":  opts.factType,
+		"content":    content,
+		"importance": opts.importance,
+		"stale":      false,
+	}
+	if opts.pin {
+		data["pinned"] = true
+	}
+	if opts.sessionID != "" {
+		data["session"] = opts.sessionID
+	}
+
+	var created factRecord
+	if err := createRecord(pbURL, "extracted_facts", data, &created); err != nil {
+		return fmt.Errorf("failed to create fact: %w", err)
+	}
+
+	fmt.Printf("✓ Fact created: %s\n", created.ID)
+	fmt.Printf("  [%s] %s (importance %d)\n", opts.factType, content, opts.importance)
+	if opts.pin {
+		fmt.Println("  📌 Pinned")
+	}
+	return nil
+}
+
+// editFactContent opens the user's editor on a temp file and returns what
+// was written, minus comment lines
+func editFactContent(factType string) (string, error) {
+	tmp, err := os.CreateTemp("", "cct-fact-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	fmt.Fprintf(tmp, "\n# Write the %s fact above. Lines starting with # are ignored.\n", factType)
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := runEditor(tmp.Name()); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
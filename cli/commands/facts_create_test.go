@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runFactsAdd runs cct facts add with args, returning its error
+func runFactsAdd(t *testing.T, pbURL string, args ...string) error {
+	t.Helper()
+	var err error
+	captureStdout(t, func() {
+		cmd := NewFactsCommand(&pbURL)
+		cmd.SetArgs(append([]string{"add"}, args...))
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		err = cmd.Execute()
+	})
+	return err
+}
+
+func TestFactsAddPostsManualFact(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app"})
+
+	err := runFactsAdd(t, pb.URL, "app", "--type", "decision", "--content", "  Payments go through Stripe  ", "--importance", "5", "--pin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = runFactsAdd(t, pb.URL, "app", "--type", "todo", "--content", "Rotate the webhook secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := pb.createdRecords("extracted_facts")
+	if len(created) != 2 {
+		t.Fatalf("created %d facts, want 2", len(created))
+	}
+	want := map[string]interface{}{
+		"id": "extracted_facts1", "project": "p1", "fact_type": "decision", "content": "Payments go through Stripe",
+		"importance": 5.0, "stale": false, "pinned": true,
+	}
+	if !reflect.DeepEqual(created[0], want) {
+		t.Errorf("posted %v, want %v", created[0], want)
+	}
+	// Unpinned facts leave pinned out, and importance defaults to 3
+	want = map[string]interface{}{
+		"id": "extracted_facts2", "project": "p1", "fact_type": "todo", "content": "Rotate the webhook secret",
+		"importance": 3.0, "stale": false,
+	}
+	if !reflect.DeepEqual(created[1], want) {
+		t.Errorf("posted %v, want %v", created[1], want)
+	}
+}
+
+func TestFactsAddValidates(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app"})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"app", "--type", "rumor", "--content", "x"}, `unknown fact type "rumor"`},
+		{[]string{"app", "--type", "todo", "--content", "x", "--importance", "0"}, "importance must be between 1 and 5"},
+		{[]string{"app", "--type", "todo", "--content", "x", "--importance", "6"}, "importance must be between 1 and 5"},
+		{[]string{"app", "--content", "x"}, `required flag(s) "type" not set`},
+	}
+	for _, tt := range tests {
+		err := runFactsAdd(t, pb.URL, tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("add %q: error %v, want %q", tt.args, err, tt.want)
+		}
+	}
+	if created := pb.createdRecords("extracted_facts"); len(created) != 0 {
+		t.Errorf("created %v from invalid input", created)
+	}
+
+	// Custom types are allowed on request
+	if err := runFactsAdd(t, pb.URL, "app", "--type", "rumor", "--content", "x", "--allow-custom-type"); err != nil {
+		t.Errorf("custom type with --allow-custom-type: %v", err)
+	}
+}
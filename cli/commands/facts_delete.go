@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// undoRetention is how long deleted facts can be restored
+	undoRetention = 30 * 24 * time.Hour
+	// undoListLimit caps how many deleted facts undo-delete --list shows
+	undoListLimit = 10
+)
+
+// deletedFact is an entry in the undo file
+type deletedFact struct {
+	DeletedAt time.Time              `json:"deleted_at"`
+	Record    map[string]interface{} `json:"record"`
+}
+
+func NewFactsDeleteCommand(pbURL *string) *cobra.Command {
+	var noUndo bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <fact-id>",
+		Short: "Delete a fact, keeping a copy for facts undo-delete",
+		Long: `Delete a fact from PocketBase. A copy is appended to
+~/.config/ccd/deleted_facts.jsonl first so cct facts undo-delete can restore
+it for 30 days.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteFact(*pbURL, args[0], noUndo)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noUndo, "no-undo", false, "Delete permanently without keeping a copy")
+
+	return cmd
+}
+
+func NewFactsUndoDeleteCommand(pbURL *string) *cobra.Command {
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:   "undo-delete",
+		Short: "Restore the most recently deleted fact",
+		Long: `Restore the most recently deleted fact from ~/.config/ccd/deleted_facts.jsonl.
+The restored fact gets a new ID and creation time.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return listDeletedFacts()
+			}
+			return undoDeleteFact(*pbURL)
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, fmt.Sprintf("Show the last %d deleted facts that can be restored", undoListLimit))
+
+	return cmd
+}
+
+func deleteFact(pbURL, factID string, noUndo bool) error {
+	records, err := listRecords[map[string]interface{}](pbURL, "extracted_facts", fmt.Sprintf("id='%s'", factID), "")
+	if err != nil {
+		return fmt.Errorf("failed to fetch fact: %w", err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("fact not found: %s", factID)
+	}
+	record := records[0]
+
+	if !noUndo {
+		if err := appendUndoFile(deletedFact{DeletedAt: time.Now(), Record: record}); err != nil {
+			return err
+		}
+	}
+
+	if err := deleteRecord(pbURL, "extracted_facts", factID); err != nil {
+		if !noUndo {
+			// Nothing was deleted, so there's nothing to undo
+			if entries, readErr := readUndoFile(); readErr == nil && len(entries) > 0 {
+				writeUndoFile(entries[:len(entries)-1])
+			}
+		}
+		return fmt.Errorf("failed to delete fact: %w", err)
+	}
+
+	fmt.Printf("🗑️  Deleted [%v] %v\n", record["fact_type"], record["content"])
+	if !noUndo {
+		fmt.Println("   Restore it with: cct facts undo-delete")
+	}
+	return nil
+}
+
+func undoDeleteFact(pbURL string) error {
+	entries, err := readUndoFile()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No deleted facts to restore")
+		return nil
+	}
+
+	last := entries[len(entries)-1]
+	var created factRecord
+	if err := createRecord(pbURL, "extracted_facts", copyableFields(last.Record), &created); err != nil {
+		return fmt.Errorf("failed to restore fact: %w", err)
+	}
+
+	if err := writeUndoFile(entries[:len(entries)-1]); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Restored [%s] %s (new ID %s)\n", created.FactType, created.Content, created.ID)
+	return nil
+}
+
+func listDeletedFacts() error {
+	entries, err := readUndoFile()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No deleted facts to restore")
+		return nil
+	}
+
+	fmt.Printf("Deleted facts (newest first, restorable for %d days):\n\n", int(undoRetention.Hours()/24))
+	for i := len(entries) - 1; i >= 0 && i >= len(entries)-undoListLimit; i-- {
+		entry := entries[i]
+		fmt.Printf("  %s  [%v] %v\n", entry.DeletedAt.Local().Format("Jan 2 15:04"), entry.Record["fact_type"], entry.Record["content"])
+	}
+	return nil
+}
+
+func undoFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate undo file: %w", err)
+	}
+	return filepath.Join(home, ".config", "ccd", "deleted_facts.jsonl"), nil
+}
+
+// readUndoFile returns the undo entries that haven't expired, oldest first
+func readUndoFile() ([]deletedFact, error) {
+	path, err := undoFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read undo file: %w", err)
+	}
+	defer f.Close()
+
+	cutoff := time.Now().Add(-undoRetention)
+	var entries []deletedFact
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry deletedFact
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("invalid entry in %s: %w", path, err)
+		}
+		if entry.DeletedAt.After(cutoff) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read undo file: %w", err)
+	}
+	return entries, nil
+}
+
+func appendUndoFile(entry deletedFact) error {
+	path, err := undoFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write undo file: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write undo file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write undo file: %w", err)
+	}
+	return f.Close()
+}
+
+// writeUndoFile replaces the undo file's entries, which also drops any that
+// expired
+func writeUndoFile(entries []deletedFact) error {
+	path, err := undoFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write undo file: %w", err)
+	}
+
+	var out strings.Builder
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		out.Write(data)
+		out.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(path, []byte(out.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write undo file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ankiFrontWords is how many words of a fact the flashcard question quotes
+const ankiFrontWords = 5
+
+type factsExportOptions struct {
+	format        string
+	output        string
+	minImportance int
+	tag           string
+}
+
+func NewFactsExportCommand(pbURL *string) *cobra.Command {
+	var opts factsExportOptions
+
+	cmd := &cobra.Command{
+		Use:   "export [project-slug]",
+		Short: "Export a project's current facts, e.g. as Anki flashcards",
+		Long: `Export the project's facts that aren't stale, most important first.
+
+--format anki writes an Anki text import file and anki-csv an Anki CSV file:
+one card per fact asking "What is the <type> about <first words>?", answered
+by the fact, tagged ccd, the fact type, and the project slug. Importance 5
+facts are also tagged marked.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.format {
+			case "json", "anki", "anki-csv":
+			default:
+				return fmt.Errorf("unknown format %q: expected json, anki, or anki-csv", opts.format)
+			}
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return exportFacts(*pbURL, projectSlug, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "json", "Output format: json, anki (tab-separated), or anki-csv")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Write to this file instead of stdout")
+	cmd.Flags().IntVar(&opts.minImportance, "min-importance", 1, "Only export facts with at least this importance")
+	cmd.Flags().StringVar(&opts.tag, "tag", "", "Only export facts from sessions with this tag or git branch")
+
+	return cmd
+}
+
+func exportFacts(pbURL, projectSlug string, opts factsExportOptions) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s' && stale=false && importance>=%d", project.ID, opts.minImportance),
+		"-verified,-importance,-created")
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+	if opts.tag != "" {
+		if facts, err = filterFactsByTag(pbURL, project.ID, opts.tag, facts); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.output != "" {
+		file, err := os.Create(opts.output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", opts.output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch opts.format {
+	case "anki":
+		err = writeAnkiText(out, facts, projectSlug)
+	case "anki-csv":
+		err = writeAnkiCSV(out, facts, projectSlug)
+	default:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(facts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	if opts.output != "" {
+		fmt.Printf("✓ Exported %d facts to %s\n", len(facts), opts.output)
+	}
+	return nil
+}
+
+// writeAnkiText writes facts as an Anki tab-separated import file. The
+// header lines tell Anki the separator and which column holds tags.
+func writeAnkiText(w io.Writer, facts []factRecord, projectSlug string) error {
+	if _, err := fmt.Fprint(w, "#separator:tab\n#html:false\n#tags column:3\n"); err != nil {
+		return err
+	}
+	for _, fact := range facts {
+		front, back, tags := ankiCard(fact, projectSlug)
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", front, back, tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAnkiCSV writes facts as an Anki CSV import file
+func writeAnkiCSV(w io.Writer, facts []factRecord, projectSlug string) error {
+	if _, err := fmt.Fprint(w, "#separator:comma\n#html:false\n#tags column:3\n"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	for _, fact := range facts {
+		front, back, tags := ankiCard(fact, projectSlug)
+		if err := cw.Write([]string{front, back, tags}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ankiCard returns a fact's flashcard fields, each on a single line
+func ankiCard(fact factRecord, projectSlug string) (front, back, tags string) {
+	words := strings.Fields(fact.Content)
+	about := strings.Join(words[:min(len(words), ankiFrontWords)], " ")
+	if len(words) > ankiFrontWords {
+		about += "..."
+	}
+	factType := strings.ReplaceAll(fact.FactType, "_", " ")
+	front = fmt.Sprintf("What is the %s about %s?", factType, about)
+
+	back = strings.Join(words, " ")
+
+	tags = fmt.Sprintf("ccd %s %s", fact.FactType, projectSlug)
+	if fact.Importance >= 5 {
+		tags += " marked"
+	}
+	return front, back, tags
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewFactsPruneBySessionCommand(pbURL *string) *cobra.Command {
+	var (
+		keepLast     int
+		keepCritical bool
+		dryRun       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune-by-session [project-slug]",
+		Short: "Delete facts that belong to sessions older than the last N",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			if keepLast < 1 {
+				return fmt.Errorf("--keep-last-sessions must be at least 1")
+			}
+			return pruneFactsBySession(*pbURL, projectSlug, keepLast, keepCritical, dryRun)
+		},
+	}
+
+	cmd.Flags().IntVar(&keepLast, "keep-last-sessions", 10, "Number of most recent sessions whose facts are kept")
+	cmd.Flags().BoolVar(&keepCritical, "keep-importance-5", false, "Keep importance 5 facts even from old sessions")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the facts that would be deleted without deleting them")
+
+	return cmd
+}
+
+func pruneFactsBySession(pbURL, projectSlug string, keepLast int, keepCritical, dryRun bool) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := listRecords[sessionRecord](pbURL, "session_history",
+		fmt.Sprintf("project='%s'", project.ID), "-created")
+	if err != nil {
+		return fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	if len(sessions) <= keepLast {
+		fmt.Printf("Only %d session(s) recorded; nothing to prune\n", len(sessions))
+		return nil
+	}
+
+	// A fact can fall in more than one session's window; delete it once
+	seen := make(map[string]bool)
+	var prune []factRecord
+	for _, session := range sessions[keepLast:] {
+		facts, err := fetchSessionFacts(pbURL, project.ID, session)
+		if err != nil {
+			return fmt.Errorf("failed to fetch facts: %w", err)
+		}
+		for _, fact := range facts {
+			if seen[fact.ID] || (keepCritical && fact.Importance >= 5) {
+				continue
+			}
+			seen[fact.ID] = true
+			prune = append(prune, fact)
+		}
+	}
+
+	if len(prune) == 0 {
+		fmt.Println("No facts to prune")
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("Would delete %d fact(s) from %d old session(s):\n", len(prune), len(sessions)-keepLast)
+		for _, fact := range prune {
+			fmt.Printf("  • [%s] %s\n", fact.FactType, fact.Content)
+		}
+		return nil
+	}
+
+	deleted := 0
+	for _, fact := range prune {
+		if err := deleteRecord(pbURL, "extracted_facts", fact.ID); err != nil {
+			fmt.Printf("Warning: failed to delete fact %s: %v\n", fact.ID, err)
+			continue
+		}
+		deleted++
+	}
+
+	fmt.Printf("✓ Deleted %d fact(s) from %d old session(s)\n", deleted, len(sessions)-keepLast)
+	return nil
+}
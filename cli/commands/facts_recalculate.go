@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/smart"
+	"github.com/spf13/cobra"
+)
+
+func NewFactsRecalculateCommand(pbURL *string) *cobra.Command {
+	var (
+		dryRun   bool
+		minDelta int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "recalculate-importance [project-slug]",
+		Short: "Rescore every fact with the current importance scorer",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return recalculateImportance(*pbURL, projectSlug, dryRun, minDelta)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show score changes without updating facts")
+	cmd.Flags().IntVar(&minDelta, "min-delta", 1, "Only update facts whose score changed by at least this much")
+
+	return cmd
+}
+
+func recalculateImportance(pbURL, projectSlug string, dryRun bool, minDelta int) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts", fmt.Sprintf("project='%s'", project.ID), "")
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+
+	scorer := smart.NewImportanceScorer()
+	updated := make(map[string]int)
+	total := 0
+
+	for _, fact := range facts {
+		created, err := parsePBTime(fact.Created)
+		if err != nil {
+			created = time.Now()
+		}
+
+		score := scorer.CalculateImportance(fact.FactType, fact.Content, created)
+		delta := score - fact.Importance
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta == 0 || delta < minDelta {
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("  [%s] %d → %d: %s\n", fact.FactType, fact.Importance, score, fact.Content)
+		} else if err := updateRecord(pbURL, "extracted_facts", fact.ID, map[string]interface{}{"importance": score}); err != nil {
+			fmt.Printf("Warning: failed to update fact %s: %v\n", fact.ID, err)
+			continue
+		}
+
+		updated[fact.FactType]++
+		total++
+	}
+
+	verb := "Updated"
+	if dryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("✓ %s %d of %d facts\n", verb, total, len(facts))
+
+	types := make([]string, 0, len(updated))
+	for factType := range updated {
+		types = append(types, factType)
+	}
+	sort.Strings(types)
+	for _, factType := range types {
+		fmt.Printf("  %s: %d\n", factType, updated[factType])
+	}
+
+	return nil
+}
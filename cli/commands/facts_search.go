@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/embed"
+	"github.com/spf13/cobra"
+)
+
+type factsSearchOptions struct {
+	embedding      string
+	embeddingModel string
+	embeddingURL   string
+	limit          int
+	tag            string
+	withSession    bool
+	// location is the time zone sessions are shown in; nil means local time
+	location *time.Location
+}
+
+type scoredFact struct {
+	fact  factRecord
+	score float64
+}
+
+func NewFactsSearchCommand(pbURL *string) *cobra.Command {
+	var opts factsSearchOptions
+
+	cmd := &cobra.Command{
+		Use:   "search <project-slug> [query]",
+		Short: "Search a project's facts by keyword or meaning",
+		Long: `Search a project's facts. A query matches facts containing it; with
+--embedding, facts are ranked by semantic similarity to the given text using
+the embeddings the daemon stores with -compute-embeddings.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug := args[0]
+			if opts.embedding != "" {
+				if len(args) == 2 {
+					return fmt.Errorf("give either a query or --embedding, not both")
+				}
+				return searchFactsByEmbedding(*pbURL, projectSlug, opts)
+			}
+			if len(args) < 2 {
+				return fmt.Errorf("a query or --embedding is required")
+			}
+			return searchFacts(*pbURL, projectSlug, args[1], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.embedding, "embedding", "", "Rank facts by semantic similarity to this text")
+	cmd.Flags().StringVar(&opts.embeddingModel, "embedding-model", embed.DefaultModel, "Embedding model; must match the daemon's -embedding-model")
+	cmd.Flags().StringVar(&opts.embeddingURL, "embedding-url", embed.DefaultURL, "Embeddings API endpoint")
+	cmd.Flags().IntVarP(&opts.limit, "limit", "n", 10, "Maximum number of facts to show")
+	cmd.Flags().StringVar(&opts.tag, "tag", "", "Only search facts from sessions with this tag or git branch")
+	cmd.Flags().BoolVar(&opts.withSession, "with-session", false, "Show the session each fact was recorded in")
+
+	return cmd
+}
+
+func searchFacts(pbURL, projectSlug, query string, opts factsSearchOptions) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	// PocketBase filter strings are single-quoted
+	escaped := strings.ReplaceAll(query, "'", "\\'")
+	filter := fmt.Sprintf("project='%s' && content~'%s'", project.ID, escaped)
+
+	var expand []string
+	if opts.withSession {
+		expand = []string{"session"}
+	}
+
+	var facts []factRecord
+	if opts.tag == "" {
+		facts, err = firstRecords[factRecord](pbURL, "extracted_facts", filter, "-importance,-created", opts.limit, expand...)
+	} else {
+		facts, err = listRecords[factRecord](pbURL, "extracted_facts", filter, "-importance,-created", expand...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to search facts: %w", err)
+	}
+	if opts.tag != "" {
+		if facts, err = filterFactsByTag(pbURL, project.ID, opts.tag, facts); err != nil {
+			return err
+		}
+		if len(facts) > opts.limit {
+			facts = facts[:opts.limit]
+		}
+	}
+
+	if len(facts) == 0 {
+		fmt.Println("No matching facts")
+		return nil
+	}
+
+	fmt.Printf("🔍 %d fact(s) matching %q\n\n", len(facts), query)
+	for _, fact := range facts {
+		fmt.Printf("• [%s] %s (importance %d)\n", fact.FactType, fact.Content, fact.Importance)
+		if opts.withSession {
+			printFactSession(fact, opts.location)
+		}
+	}
+	return nil
+}
+
+func searchFactsByEmbedding(pbURL, projectSlug string, opts factsSearchOptions) error {
+	apiKey := os.Getenv(embed.APIKeyEnv)
+	if apiKey == "" {
+		return fmt.Errorf("--embedding requires $%s", embed.APIKeyEnv)
+	}
+
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	var expand []string
+	if opts.withSession {
+		expand = []string{"session"}
+	}
+
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts", fmt.Sprintf("project='%s'", project.ID), "", expand...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+	if opts.tag != "" {
+		if facts, err = filterFactsByTag(pbURL, project.ID, opts.tag, facts); err != nil {
+			return err
+		}
+	}
+
+	client := embed.NewClient(opts.embeddingURL, apiKey, opts.embeddingModel)
+	vectors, err := client.Embed([]string{opts.embedding})
+	if err != nil {
+		return err
+	}
+	query := vectors[0]
+
+	var scored []scoredFact
+	missing := 0
+	for _, fact := range facts {
+		if len(fact.Embedding) == 0 {
+			missing++
+			continue
+		}
+		scored = append(scored, scoredFact{fact: fact, score: embed.Cosine(query, fact.Embedding)})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	if len(scored) > opts.limit {
+		scored = scored[:opts.limit]
+	}
+
+	if len(scored) == 0 {
+		fmt.Println("No facts have embeddings yet; run the daemon with -compute-embeddings")
+		return nil
+	}
+
+	fmt.Printf("🔍 Facts most similar to %q\n\n", opts.embedding)
+	for _, s := range scored {
+		fmt.Printf("%.3f  [%s] %s\n", s.score, s.fact.FactType, s.fact.Content)
+		if opts.withSession {
+			printFactSession(s.fact, opts.location)
+		}
+	}
+	if missing > 0 {
+		fmt.Printf("\n%d fact(s) without embeddings were skipped\n", missing)
+	}
+	return nil
+}
+
+// printFactSession prints the session a fact was fetched with, expanded from
+// its session relation, with times in loc (local time when nil). Facts
+// without a linked session print nothing.
+func printFactSession(fact factRecord, loc *time.Location) {
+	sessions, err := expandedRecords[sessionRecord](fact.Expand, "session")
+	if err != nil || len(sessions) == 0 {
+		return
+	}
+
+	if loc == nil {
+		loc = time.Local
+	}
+	session := sessions[0]
+	when := session.Created
+	if t, err := parsePBTime(session.Created); err == nil {
+		when = t.In(loc).Format("2006-01-02 15:04")
+	}
+	if session.Branch != "" {
+		when += " on " + session.Branch
+	}
+	fmt.Printf("    ↳ session %s: %s\n", when, session.Summary)
+}
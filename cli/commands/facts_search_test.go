@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSearchFactsWithSession(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app"})
+	pb.add("extracted_facts",
+		map[string]interface{}{
+			"id": "f1", "fact_type": "decision", "content": "Use Postgres", "importance": 4, "session": "s1",
+			"expand": map[string]interface{}{"session": map[string]interface{}{
+				"id": "s1", "summary": "Picked a database", "branch": "feat/db", "created": "2026-03-01 10:00:00.000Z",
+			}},
+		},
+		// A fact recorded outside any session has nothing to expand
+		map[string]interface{}{"id": "f2", "fact_type": "todo", "content": "Use Postgres migrations", "importance": 3},
+	)
+
+	out := captureStdout(t, func() {
+		if err := searchFacts(pb.URL, "app", "Postgres", factsSearchOptions{limit: 10, withSession: true, location: time.UTC}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	want := "🔍 2 fact(s) matching \"Postgres\"\n\n" +
+		"• [decision] Use Postgres (importance 4)\n" +
+		"    ↳ session 2026-03-01 10:00 on feat/db: Picked a database\n" +
+		"• [todo] Use Postgres migrations (importance 3)\n"
+	if out != want {
+		t.Errorf("search printed:\n%s\nwant:\n%s", out, want)
+	}
+	if got := pb.listExpands("extracted_facts"); !reflect.DeepEqual(got, []string{"session"}) {
+		t.Errorf("expand parameters = %q, want session", got)
+	}
+
+	// Sessions are only fetched when asked for
+	captureStdout(t, func() {
+		if err := searchFacts(pb.URL, "app", "Postgres", factsSearchOptions{limit: 10}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if got := pb.listExpands("extracted_facts"); len(got) != 2 || got[1] != "" {
+		t.Errorf("expand parameters = %q, want none without --with-session", got)
+	}
+}
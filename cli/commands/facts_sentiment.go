@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	positiveKeywords = []string{"resolved", "fixed", "improved", "works", "success"}
+	negativeKeywords = []string{"blocked", "broken", "error", "failed", "critical"}
+)
+
+func NewFactsSentimentCommand(pbURL *string) *cobra.Command {
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "sentiment [project-slug]",
+		Short: "Classify the tone of a project's facts",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return showSentiment(*pbURL, projectSlug, since)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only include facts created since a date (2006-01-02) or a number of days back (30d)")
+
+	return cmd
+}
+
+func showSentiment(pbURL, projectSlug, since string) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	filter := fmt.Sprintf("project='%s'", project.ID)
+	if since != "" {
+		sinceTime, err := parseSince(since, time.Local)
+		if err != nil {
+			return err
+		}
+		filter += fmt.Sprintf(" && created>='%s'", sinceTime.UTC().Format(pbTimeLayout))
+	}
+
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts", filter, "")
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+
+	if len(facts) == 0 {
+		fmt.Println("No facts found")
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, fact := range facts {
+		counts[classifySentiment(fact.FactType, fact.Content)]++
+	}
+
+	percent := func(label string) float64 {
+		return float64(counts[label]) * 100 / float64(len(facts))
+	}
+
+	fmt.Printf("Sentiment for %s (%d facts)\n", projectSlug, len(facts))
+	fmt.Printf("%.0f%% negative, %.0f%% neutral, %.0f%% positive\n",
+		percent("negative"), percent("neutral"), percent("positive"))
+
+	return nil
+}
+
+// classifySentiment labels a fact positive, negative, or neutral by keyword
+// counts. Blockers with no positive keywords are negative by nature.
+func classifySentiment(factType, content string) string {
+	lower := strings.ToLower(content)
+
+	positive := countKeywords(lower, positiveKeywords)
+	negative := countKeywords(lower, negativeKeywords)
+	if factType == "blocker" && positive == 0 {
+		negative++
+	}
+
+	switch {
+	case positive > negative:
+		return "positive"
+	case negative > positive:
+		return "negative"
+	default:
+		return "neutral"
+	}
+}
+
+func countKeywords(text string, keywords []string) int {
+	count := 0
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// summaryFactLimit is how many of the most important facts are summarized
+	summaryFactLimit = 10
+	// summaryMinImportance excludes routine facts from summaries
+	summaryMinImportance = 4
+)
+
+// summaryLength limits how much of each fact type a summary mentions
+type summaryLength struct {
+	// perType is how many facts of each type are named; 0 names them all
+	perType int
+	// maxChars clips each fact's content; 0 keeps it whole
+	maxChars int
+}
+
+var summaryLengths = map[string]summaryLength{
+	"short":  {perType: 1, maxChars: 60},
+	"medium": {perType: 2, maxChars: 120},
+	"long":   {},
+}
+
+// summaryTemplates render a summaryData for different audiences
+var summaryTemplates = map[string]string{
+	"executive": `The project is currently
+{{- if .Blockers}} blocked by: {{list .Blockers}}{{else}} unblocked{{end}}
+{{- if .Decisions}} and has made decisions: {{list .Decisions}}{{end}}.
+{{- if .Todos}} Next steps include {{list .Todos}}.{{end}}
+{{- if .Insights}} Key insights: {{list .Insights}}.{{end}}
+{{- if .Changes}} Recent changes: {{list .Changes}}.{{end}}`,
+
+	"technical": `{{if .Changes}}Recent changes: {{list .Changes}}. {{end}}
+{{- if .Decisions}}Design decisions: {{list .Decisions}}. {{end}}
+{{- if .Blockers}}Open blockers: {{list .Blockers}}. {{else}}No open blockers. {{end}}
+{{- if .Insights}}Technical notes: {{list .Insights}}. {{end}}
+{{- if .Todos}}Outstanding work: {{list .Todos}}.{{end}}`,
+
+	"standup": `Done: {{if or .Decisions .Changes}}{{list .Decisions .Changes}}{{else}}nothing notable{{end}}.
+Next: {{if .Todos}}{{list .Todos}}{{else}}nothing planned{{end}}.
+Blockers: {{if .Blockers}}{{list .Blockers}}{{else}}none{{end}}.`,
+}
+
+// summaryData is a project's important facts grouped by type
+type summaryData struct {
+	Project   string
+	Blockers  []string
+	Decisions []string
+	Todos     []string
+	Insights  []string
+	// Changes covers file, dependency, and config changes
+	Changes []string
+}
+
+func NewFactsSummarizeCommand(pbURL *string) *cobra.Command {
+	var format, length string
+
+	cmd := &cobra.Command{
+		Use:   "summarize [project-slug]",
+		Short: "Summarize a project's most important facts in a paragraph",
+		Long: fmt.Sprintf(`Summarize the project's %d most important current facts (importance %d
+and up) as prose, grouped into blockers, decisions, next steps, insights, and
+changes. --output-format picks the audience and --length how much of each
+group is mentioned.`, summaryFactLimit, summaryMinImportance),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := summaryTemplates[format]; !ok {
+				return fmt.Errorf("unknown output format %q: expected executive, technical, or standup", format)
+			}
+			if _, ok := summaryLengths[length]; !ok {
+				return fmt.Errorf("unknown length %q: expected short, medium, or long", length)
+			}
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return summarizeFacts(*pbURL, projectSlug, format, summaryLengths[length])
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "output-format", "executive", "Audience: executive, technical, or standup")
+	cmd.Flags().StringVar(&length, "length", "medium", "Verbosity: short, medium, or long")
+
+	return cmd
+}
+
+func summarizeFacts(pbURL, projectSlug, format string, length summaryLength) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	facts, err := firstRecords[factRecord](pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s' && importance>=%d && stale=false", project.ID, summaryMinImportance),
+		"-verified,-importance,-created", summaryFactLimit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+
+	if len(facts) == 0 {
+		fmt.Printf("No facts with importance %d or higher to summarize\n", summaryMinImportance)
+		return nil
+	}
+
+	data := summaryData{Project: project.Name}
+	for _, fact := range facts {
+		group := summaryGroup(&data, fact.FactType, fact.Content)
+		if group == nil || (length.perType > 0 && len(*group) >= length.perType) {
+			continue
+		}
+		*group = append(*group, clipSummaryText(fact.Content, length.maxChars))
+	}
+
+	summary, err := renderSummary(format, data)
+	if err != nil {
+		return err
+	}
+	fmt.Println(summary)
+	return nil
+}
+
+// summaryGroup returns the list a fact is summarized in, or nil for facts
+// summaries leave out. Failing test runs are summarized as blockers.
+func summaryGroup(data *summaryData, factType, content string) *[]string {
+	switch factType {
+	case "blocker":
+		return &data.Blockers
+	case "test_status":
+		if extractor.IsTestFailure(content) {
+			return &data.Blockers
+		}
+	case "decision":
+		return &data.Decisions
+	case "todo":
+		return &data.Todos
+	case "insight":
+		return &data.Insights
+	case "file_change", "dependency", "config_change":
+		return &data.Changes
+	}
+	return nil
+}
+
+func renderSummary(format string, data summaryData) (string, error) {
+	tmpl, err := template.New(format).Funcs(template.FuncMap{
+		"list": summaryList,
+	}).Parse(summaryTemplates[format])
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render summary: %w", err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// summaryList joins one or more lists of fact contents into a phrase.
+// Items are separated by semicolons since facts often contain commas.
+func summaryList(lists ...[]string) string {
+	var items []string
+	for _, list := range lists {
+		items = append(items, list...)
+	}
+	return strings.Join(items, "; ")
+}
+
+// clipSummaryText trims a fact for inclusion in a sentence, shortening it
+// to maxChars at a word boundary when maxChars is set
+func clipSummaryText(content string, maxChars int) string {
+	text := strings.Join(strings.Fields(content), " ")
+	text = strings.TrimRight(text, ".!;:, ")
+
+	if maxChars <= 0 || len(text) <= maxChars {
+		return text
+	}
+	cut := strings.LastIndex(text[:maxChars], " ")
+	if cut <= 0 {
+		cut = maxChars
+	}
+	return strings.TrimRight(text[:cut], ".!;:, ") + "..."
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewFactsVerifyCommand(pbURL *string) *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "verify <fact-id>",
+		Short: "Mark a fact as reviewed and correct",
+		Long: `Mark a fact as verified: a human has confirmed it is correct. Verified
+facts are listed ahead of unverified ones when context is rendered or
+compressed, and the daemon never marks them stale. Verifying a stale fact
+makes it active again.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifyFact(*pbURL, args[0], !unset)
+		},
+	}
+
+	cmd.Flags().BoolVar(&unset, "unset", false, "Remove the verified mark instead")
+
+	return cmd
+}
+
+func NewFactsCorrectCommand(pbURL *string) *cobra.Command {
+	var content string
+	var importance int
+
+	cmd := &cobra.Command{
+		Use:   "correct <fact-id> --content <text>",
+		Short: "Replace a fact's content and mark it verified",
+		Long: `Replace the content of a fact that was extracted wrongly or has since
+changed, marking it verified and active. The importance can be corrected at
+the same time.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if content == "" {
+				return fmt.Errorf("--content is required")
+			}
+			if cmd.Flags().Changed("importance") && (importance < 1 || importance > 5) {
+				return fmt.Errorf("--importance must be between 1 and 5")
+			}
+			return correctFact(*pbURL, args[0], content, importance)
+		},
+	}
+
+	cmd.Flags().StringVar(&content, "content", "", "The corrected fact")
+	cmd.Flags().IntVarP(&importance, "importance", "i", 0, "The corrected importance (1-5); unchanged by default")
+
+	return cmd
+}
+
+func getFact(pbURL, factID string) (*factRecord, error) {
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts", fmt.Sprintf("id='%s'", factID), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fact: %w", err)
+	}
+	if len(facts) == 0 {
+		return nil, fmt.Errorf("fact not found: %s", factID)
+	}
+	return &facts[0], nil
+}
+
+func verifyFact(pbURL, factID string, verified bool) error {
+	fact, err := getFact(pbURL, factID)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{"verified": verified}
+	if verified {
+		data["stale"] = false
+	}
+	if err := updateRecord(pbURL, "extracted_facts", fact.ID, data); err != nil {
+		return fmt.Errorf("failed to update fact: %w", err)
+	}
+
+	if verified {
+		fmt.Printf("✓ Verified [%s] %s\n", fact.FactType, fact.Content)
+	} else {
+		fmt.Printf("✓ Removed verification from [%s] %s\n", fact.FactType, fact.Content)
+	}
+	return nil
+}
+
+// correctFact replaces a fact's content, and its importance when non-zero
+func correctFact(pbURL, factID, content string, importance int) error {
+	fact, err := getFact(pbURL, factID)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"content":  content,
+		"verified": true,
+		"stale":    false,
+	}
+	if importance > 0 {
+		data["importance"] = importance
+	}
+	if err := updateRecord(pbURL, "extracted_facts", fact.ID, data); err != nil {
+		return fmt.Errorf("failed to update fact: %w", err)
+	}
+
+	fmt.Printf("✓ Corrected [%s] fact %s\n", fact.FactType, fact.ID)
+	fmt.Printf("  - %s\n", fact.Content)
+	fmt.Printf("  + %s\n", content)
+	return nil
+}
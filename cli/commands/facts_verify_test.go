@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerifyFact(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("extracted_facts", map[string]interface{}{
+		"id": "f1", "fact_type": "decision", "content": "Use Postgres", "importance": 4, "stale": true,
+	})
+
+	out := captureStdout(t, func() {
+		if err := verifyFact(pb.URL, "f1", true); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if want := "✓ Verified [decision] Use Postgres\n"; out != want {
+		t.Errorf("verify printed %q, want %q", out, want)
+	}
+	if filters := pb.listFilters("extracted_facts"); len(filters) != 1 || filters[0] != "id='f1'" {
+		t.Errorf("fact looked up with filters %q, want id='f1'", filters)
+	}
+	// Verifying a stale fact makes it active again
+	if want := map[string]interface{}{"verified": true, "stale": false}; !reflect.DeepEqual(pb.updated["f1"], want) {
+		t.Errorf("verify sent %v, want %v", pb.updated["f1"], want)
+	}
+
+	// Unsetting leaves staleness alone
+	captureStdout(t, func() {
+		if err := verifyFact(pb.URL, "f1", false); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if want := map[string]interface{}{"verified": false}; !reflect.DeepEqual(pb.updated["f1"], want) {
+		t.Errorf("unverify sent %v, want %v", pb.updated["f1"], want)
+	}
+}
+
+func TestCorrectFact(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("extracted_facts", map[string]interface{}{
+		"id": "f1", "fact_type": "decision", "content": "Use MySQL", "importance": 3, "stale": true,
+	})
+
+	out := captureStdout(t, func() {
+		if err := correctFact(pb.URL, "f1", "Use Postgres", 0); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if want := "✓ Corrected [decision] fact f1\n  - Use MySQL\n  + Use Postgres\n"; out != want {
+		t.Errorf("correct printed %q, want %q", out, want)
+	}
+	// Without an importance only the content changes, and the fact is verified
+	want := map[string]interface{}{"content": "Use Postgres", "verified": true, "stale": false}
+	if !reflect.DeepEqual(pb.updated["f1"], want) {
+		t.Errorf("correct sent %v, want %v", pb.updated["f1"], want)
+	}
+
+	captureStdout(t, func() {
+		if err := correctFact(pb.URL, "f1", "Use Postgres 16", 5); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if got := pb.updated["f1"]["importance"]; got != 5.0 {
+		t.Errorf("corrected importance = %v, want 5", got)
+	}
+}
+
+func TestVerifyMissingFact(t *testing.T) {
+	pb := newFakePocketBase(t)
+
+	err := verifyFact(pb.URL, "nope", true)
+	if err == nil || !strings.Contains(err.Error(), "fact not found: nope") {
+		t.Errorf("verify of a missing fact returned %v", err)
+	}
+	err = correctFact(pb.URL, "nope", "anything", 0)
+	if err == nil || !strings.Contains(err.Error(), "fact not found: nope") {
+		t.Errorf("correct of a missing fact returned %v", err)
+	}
+	if len(pb.updated) != 0 {
+		t.Errorf("updated %v, want nothing", pb.updated)
+	}
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePocketBase serves fixed records per collection and records what the
+// CLI writes. List requests ignore filters and expand, which tests read back
+// from filters and expands, but honor sort and perPage.
+type fakePocketBase struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	collections map[string][]map[string]interface{}
+	filters     map[string][]string
+	expands     map[string][]string
+	created     map[string][]map[string]interface{}
+	updated     map[string]map[string]interface{}
+	deleted     []string
+}
+
+func newFakePocketBase(t *testing.T) *fakePocketBase {
+	t.Helper()
+	pb := &fakePocketBase{
+		collections: make(map[string][]map[string]interface{}),
+		filters:     make(map[string][]string),
+		expands:     make(map[string][]string),
+		created:     make(map[string][]map[string]interface{}),
+		updated:     make(map[string]map[string]interface{}),
+	}
+	pb.Server = httptest.NewServer(http.HandlerFunc(pb.serve))
+	t.Cleanup(pb.Close)
+	return pb
+}
+
+// add stores records in a collection
+func (pb *fakePocketBase) add(collection string, records ...map[string]interface{}) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.collections[collection] = append(pb.collections[collection], records...)
+}
+
+func (pb *fakePocketBase) serve(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "api" || parts[1] != "collections" || parts[3] != "records" {
+		http.NotFound(w, r)
+		return
+	}
+	collection := parts[2]
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 4:
+		pb.filters[collection] = append(pb.filters[collection], r.URL.Query().Get("filter"))
+		pb.expands[collection] = append(pb.expands[collection], r.URL.Query().Get("expand"))
+		writeTestJSON(w, pb.list(collection, r))
+
+	case r.Method == http.MethodGet && len(parts) == 5:
+		for _, record := range pb.collections[collection] {
+			if record["id"] == parts[4] {
+				writeTestJSON(w, record)
+				return
+			}
+		}
+		http.NotFound(w, r)
+
+	case r.Method == http.MethodPost && len(parts) == 4:
+		body["id"] = fmt.Sprintf("%s%d", collection, len(pb.created[collection])+1)
+		pb.created[collection] = append(pb.created[collection], body)
+		writeTestJSON(w, body)
+
+	case r.Method == http.MethodPatch && len(parts) == 5:
+		pb.updated[parts[4]] = body
+		writeTestJSON(w, body)
+
+	case r.Method == http.MethodDelete && len(parts) == 5:
+		pb.deleted = append(pb.deleted, parts[4])
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// list returns one page of a collection, sorted as asked
+func (pb *fakePocketBase) list(collection string, r *http.Request) map[string]interface{} {
+	items := append([]map[string]interface{}(nil), pb.collections[collection]...)
+	query := r.URL.Query()
+
+	if field := query.Get("sort"); field != "" {
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := fmt.Sprint(items[i][field]), fmt.Sprint(items[j][field])
+			if x, ok := items[i][field].(float64); ok {
+				y, _ := items[j][field].(float64)
+				if desc {
+					return x > y
+				}
+				return x < y
+			}
+			if desc {
+				return a > b
+			}
+			return a < b
+		})
+	}
+
+	perPage, err := strconv.Atoi(query.Get("perPage"))
+	if err != nil || perPage <= 0 {
+		perPage = 30
+	}
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	totalPages := (len(items) + perPage - 1) / perPage
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	return map[string]interface{}{
+		"page":       page,
+		"perPage":    perPage,
+		"totalPages": totalPages,
+		"totalItems": len(items),
+		"items":      items[start:end],
+	}
+}
+
+func writeTestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// createdRecords returns the records posted to a collection so far
+func (pb *fakePocketBase) createdRecords(collection string) []map[string]interface{} {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]map[string]interface{}(nil), pb.created[collection]...)
+}
+
+// listFilters returns the filter of each list request to a collection
+func (pb *fakePocketBase) listFilters(collection string) []string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]string(nil), pb.filters[collection]...)
+}
+
+// listExpands returns the expand parameter of each list request to a
+// collection
+func (pb *fakePocketBase) listExpands(collection string) []string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]string(nil), pb.expands[collection]...)
+}
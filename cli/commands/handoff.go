@@ -0,0 +1,288 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/angelfreak/ccd/daemon/monitor"
+	"github.com/angelfreak/ccd/daemon/smart"
+	"github.com/spf13/cobra"
+)
+
+func NewHandoffCommand(pbURL *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "handoff",
+		Short: "List and compare the daemon's handoff documents",
+	}
+
+	cmd.AddCommand(NewHandoffListCommand(pbURL))
+	cmd.AddCommand(NewHandoffDiffCommand(pbURL))
+	cmd.AddCommand(NewHandoffRebuildCommand(pbURL))
+
+	return cmd
+}
+
+func NewHandoffListCommand(pbURL *string) *cobra.Command {
+	var projectSlug string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List handoffs, newest first",
+		Long: `List the handoffs in the current directory's repo, or the repo of
+--project, newest first. The ID shown is what handoff diff takes.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listHandoffs(*pbURL, projectSlug)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectSlug, "project", "", "List the handoffs in this project's repo instead of the current directory")
+
+	return cmd
+}
+
+func NewHandoffDiffCommand(pbURL *string) *cobra.Command {
+	var projectSlug, from, to string
+	var report diffReportOptions
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show what changed between two handoffs",
+		Long: `Compare the key facts captured in two handoffs: the facts added and removed
+between them. By default the newest handoff is compared with the one before
+it; --to picks another later handoff, again compared with the one before it
+unless --from is given.
+
+A handoff is given by the ID from handoff list (its file name without .md),
+or by a session ID for that session's latest handoff.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch report.format {
+			case "text", "markdown", "html":
+			default:
+				return fmt.Errorf("unknown format %q: expected text, markdown, or html", report.format)
+			}
+			return diffHandoffs(*pbURL, projectSlug, from, to, report)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectSlug, "project", "", "Compare handoffs in this project's repo instead of the current directory")
+	cmd.Flags().StringVar(&from, "from", "", "The earlier handoff (default: the one before --to)")
+	cmd.Flags().StringVar(&to, "to", "", "The later handoff (default: the newest)")
+	cmd.Flags().StringVar(&report.format, "format", "text", "Output format: text, markdown, or html (a standalone page to share)")
+	cmd.Flags().StringVarP(&report.output, "output", "o", "", "With --format markdown or html, write the report to this file instead of stdout")
+
+	return cmd
+}
+
+type handoffRebuildOptions struct {
+	since           string
+	force           bool
+	summaryTemplate string
+	noEmoji         bool
+}
+
+func NewHandoffRebuildCommand(pbURL *string) *cobra.Command {
+	var opts handoffRebuildOptions
+
+	cmd := &cobra.Command{
+		Use:   "rebuild [project-slug]",
+		Short: "Regenerate handoffs from the continuity ledger",
+		Long: `Write a handoff for every session in the project's ledger from the session's
+latest entry, in the current handoff format: for handoffs that were lost, or
+to pick up a newer format. Sessions that already have a handoff are left alone
+unless --force is given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return rebuildHandoffs(*pbURL, projectSlug, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only use ledger entries from this date (2024-01-31) or day count (30d)")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Also rewrite sessions that already have a handoff")
+	cmd.Flags().StringVar(&opts.summaryTemplate, "summary-template", "", "Path to a Go text/template used to render summaries, as for the daemon's -summary-template")
+	cmd.Flags().BoolVar(&opts.noEmoji, "no-emoji", false, "Use plain-text markers instead of emoji, as for the daemon's -no-emoji")
+
+	return cmd
+}
+
+func rebuildHandoffs(pbURL, projectSlug string, opts handoffRebuildOptions) error {
+	var since time.Time
+	if opts.since != "" {
+		var err error
+		if since, err = parseSince(opts.since, time.Local); err != nil {
+			return err
+		}
+	}
+
+	var tmpl *template.Template
+	if opts.summaryTemplate != "" {
+		data, err := os.ReadFile(opts.summaryTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to read summary template: %w", err)
+		}
+		if tmpl, err = monitor.ParseSummaryTemplate(string(data)); err != nil {
+			return fmt.Errorf("invalid summary template: %w", err)
+		}
+	}
+
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+	if project.RepoPath == "" {
+		return fmt.Errorf("project %s has no repo path", projectSlug)
+	}
+	if _, err := os.Stat(ledger.LedgerDirFor(project.RepoPath)); err != nil {
+		return fmt.Errorf("no ledger found at %s", ledger.LedgerDirFor(project.RepoPath))
+	}
+
+	l := ledger.NewLedgerWithConfig(ledger.LedgerConfig{
+		ProjectID: project.ID,
+		RepoPath:  project.RepoPath,
+		NoEmoji:   opts.noEmoji,
+	})
+	summarize := func(entry *ledger.LedgerEntry) string {
+		return monitor.RenderSummary(tmpl, entry)
+	}
+	count, err := l.RebuildHandoffs(since, opts.force, summarize)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild handoffs: %w", err)
+	}
+
+	fmt.Printf("✓ Rebuilt %d handoff(s) in %s\n", count, l.HandoffDir())
+	return nil
+}
+
+// handoffID is how a handoff is named on the command line: its file name
+// without the extension
+func handoffID(handoff *ledger.Handoff) string {
+	return strings.TrimSuffix(filepath.Base(handoff.Path), ".md")
+}
+
+// findHandoff returns the index of the handoff with the given ID, or of the
+// newest one for a session ID. handoffs must be newest first.
+func findHandoff(handoffs []*ledger.Handoff, id string) (int, error) {
+	id = strings.TrimSuffix(id, ".md")
+	for i, handoff := range handoffs {
+		if handoffID(handoff) == id {
+			return i, nil
+		}
+	}
+	for i, handoff := range handoffs {
+		if handoff.SessionID == id {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("handoff not found: %s", id)
+}
+
+// handoffSnapshot converts a handoff's key facts for smart.DiffGenerator
+func handoffSnapshot(handoff *ledger.Handoff) smart.SessionSnapshot {
+	snapshot := smart.SessionSnapshot{
+		SessionID: handoff.SessionID,
+		Timestamp: handoff.Timestamp,
+	}
+	for _, fact := range handoff.Facts {
+		snapshot.Facts = append(snapshot.Facts, smart.CompressibleFact{
+			Type:       fact.Type,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+			Created:    fact.Timestamp,
+		})
+	}
+	return snapshot
+}
+
+func listHandoffs(pbURL, projectSlug string) error {
+	repoPath, err := projectRepoPath(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	dir := ledger.HandoffDirFor(repoPath)
+	handoffs, err := ledger.ListHandoffsIn(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list handoffs: %w", err)
+	}
+	if len(handoffs) == 0 {
+		fmt.Printf("No handoffs found in %s\n", dir)
+		return nil
+	}
+
+	fmt.Printf("📋 %d handoff(s) in %s\n\n", len(handoffs), dir)
+	for _, handoff := range handoffs {
+		fmt.Printf("%s  %s  %d fact(s)\n", handoffID(handoff),
+			handoff.Timestamp.Local().Format("Jan 2, 2006 3:04 PM"), len(handoff.Facts))
+		if handoff.Summary != "" {
+			fmt.Printf("   %s\n", clipDescription(handoff.Summary, 100))
+		}
+	}
+	return nil
+}
+
+func diffHandoffs(pbURL, projectSlug, from, to string, report diffReportOptions) error {
+	repoPath, err := projectRepoPath(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	dir := ledger.HandoffDirFor(repoPath)
+	handoffs, err := ledger.ListHandoffsIn(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list handoffs: %w", err)
+	}
+
+	// Default to the newest handoff and the one before it
+	toIndex, fromIndex := 0, 1
+	if to != "" {
+		if toIndex, err = findHandoff(handoffs, to); err != nil {
+			return err
+		}
+		fromIndex = toIndex + 1
+	}
+	if from != "" {
+		if fromIndex, err = findHandoff(handoffs, from); err != nil {
+			return err
+		}
+	}
+	if fromIndex >= len(handoffs) {
+		fmt.Printf("No earlier handoff to compare with in %s\n", dir)
+		return nil
+	}
+	if fromIndex == toIndex {
+		return fmt.Errorf("--from and --to are the same handoff")
+	}
+	previous, current := handoffs[fromIndex], handoffs[toIndex]
+
+	before, after := handoffSnapshot(previous), handoffSnapshot(current)
+	diff := smart.NewDiffGenerator().GenerateDiff(before, after)
+	title := fmt.Sprintf("Changes from %s to %s", handoffID(previous), handoffID(current))
+
+	if report.format != "text" {
+		return writeDiffReport(report, title,
+			[]smart.SessionComparison{{Previous: before, Current: after, Diff: diff}})
+	}
+
+	loc := report.location
+	if loc == nil {
+		loc = time.Local
+	}
+	fmt.Printf("📊 %s\n", title)
+	fmt.Printf("   %s → %s\n\n", previous.Timestamp.In(loc).Format("Jan 2, 2006 3:04 PM"),
+		current.Timestamp.In(loc).Format("Jan 2, 2006 3:04 PM"))
+	fmt.Printf("Summary: %s\n", diff.Summary)
+
+	printCompressibleFacts("+", diff.Added)
+	printCompressibleFacts("-", diff.Removed)
+	return nil
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffHandoffs(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "repo_path": newHandoffRepo(t, "handoff-diff")})
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"newest two", "", "", "📊 Changes from handoff_s2_20260302_090000 to handoff_s3_20260303_090000\n" +
+			"   Mar 2, 2026 9:00 AM → Mar 3, 2026 9:00 AM\n\n" +
+			"Summary: 1 new facts, 1 resolved\n" +
+			"  + [todo] Add a health check\n" +
+			"  - [todo] Write the migration guide\n"},
+		{"to a session", "", "s2", "📊 Changes from handoff_s1_20260301_120000 to handoff_s2_20260302_090000\n" +
+			"   Mar 1, 2026 12:00 PM → Mar 2, 2026 9:00 AM\n\n" +
+			"Summary: 1 new facts, 1 resolved\n" +
+			"  + [decision] Deploy with Fly.io\n" +
+			"  - [blocker] CI is red on main\n"},
+		{"from an ID", "handoff_s1_20260301_120000.md", "", "📊 Changes from handoff_s1_20260301_120000 to handoff_s3_20260303_090000\n" +
+			"   Mar 1, 2026 12:00 PM → Mar 3, 2026 9:00 AM\n\n" +
+			"Summary: 2 new facts, 2 resolved\n" +
+			"  + [decision] Deploy with Fly.io\n" +
+			"  + [todo] Add a health check\n" +
+			"  - [blocker] CI is red on main\n" +
+			"  - [todo] Write the migration guide\n"},
+	}
+	for _, tt := range tests {
+		out := captureStdout(t, func() {
+			if err := diffHandoffs(pb.URL, "app", tt.from, tt.to, diffReportOptions{format: "text", location: time.UTC}); err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+		})
+		if out != tt.want {
+			t.Errorf("%s: printed:\n%s\nwant:\n%s", tt.name, out, tt.want)
+		}
+	}
+}
+
+func TestDiffHandoffsErrors(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "repo_path": newHandoffRepo(t, "handoff-diff")})
+
+	for _, tt := range []struct {
+		from, to string
+		want     string
+	}{
+		{"s9", "", "handoff not found: s9"},
+		{"s2", "s2", "--from and --to are the same handoff"},
+	} {
+		err := diffHandoffs(pb.URL, "app", tt.from, tt.to, diffReportOptions{format: "text"})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("from %q to %q: error %v, want %q", tt.from, tt.to, err, tt.want)
+		}
+	}
+
+	// The oldest handoff has nothing before it
+	out := captureStdout(t, func() {
+		if err := diffHandoffs(pb.URL, "app", "", "s1", diffReportOptions{format: "text"}); err != nil {
+			t.Error(err)
+		}
+	})
+	if !strings.HasPrefix(out, "No earlier handoff to compare with in ") {
+		t.Errorf("diff to the oldest handoff printed %q", out)
+	}
+}
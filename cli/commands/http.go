@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultTimeout is how long a request may take before the command gives
+// up, unless --timeout says otherwise
+const DefaultTimeout = 30 * time.Second
+
+// httpClient makes every request the commands send, to PocketBase and
+// elsewhere, so that a hung server fails the command instead of hanging it
+var httpClient = &http.Client{Timeout: DefaultTimeout}
+
+// downloadTransport carries file downloads, such as release assets, which
+// may take longer than any request timeout to arrive. Only the wait for the
+// response to start is limited.
+var downloadTransport = newDownloadTransport()
+
+var downloadClient = &http.Client{Transport: downloadTransport}
+
+func newDownloadTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = DefaultTimeout
+	return transport
+}
+
+// SetTimeout sets how long each request may take, set by main from the
+// global --timeout flag. Zero waits indefinitely.
+func SetTimeout(timeout time.Duration) {
+	httpClient.Timeout = timeout
+	downloadTransport.ResponseHeaderTimeout = timeout
+}
+
+func httpGet(endpoint string) (*http.Response, error) {
+	return explainTimeouts(httpClient.Get(endpoint))
+}
+
+func httpPost(endpoint, contentType string, body io.Reader) (*http.Response, error) {
+	return explainTimeouts(httpClient.Post(endpoint, contentType, body))
+}
+
+func httpDo(req *http.Request) (*http.Response, error) {
+	return explainTimeouts(httpClient.Do(req))
+}
+
+// httpDownload fetches a file with no limit on how long its body takes
+func httpDownload(endpoint string) (*http.Response, error) {
+	return explainTimeouts(downloadClient.Get(endpoint))
+}
+
+// explainTimeouts passes the request's error through timeoutError, and wraps
+// the response body so errors reading it are explained the same way
+func explainTimeouts(resp *http.Response, err error) (*http.Response, error) {
+	if err != nil {
+		return resp, timeoutError(err)
+	}
+	resp.Body = timeoutBody{resp.Body}
+	return resp, nil
+}
+
+// timeoutBody is a response body whose read errors go through timeoutError
+type timeoutBody struct {
+	io.ReadCloser
+}
+
+func (b timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	return n, timeoutError(err)
+}
+
+// timeoutError explains a request that ran out of time, which the client
+// reports as an exceeded deadline, either before the response arrived or
+// while its body was being read
+func timeoutError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return fmt.Errorf("%s %s: no response within %s (raise it with --timeout)", urlErr.Op, urlErr.URL, httpClient.Timeout)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("response not fully received within %s (raise it with --timeout)", httpClient.Timeout)
+	}
+	return err
+}
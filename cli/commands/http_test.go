@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHungServerTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	SetTimeout(100 * time.Millisecond)
+	defer SetTimeout(DefaultTimeout)
+
+	for name, run := range map[string]func() error{
+		"get":    func() error { return showStatus(server.URL, false) },
+		"update": func() error { return updateRecord(server.URL, "extracted_facts", "f1", map[string]bool{"stale": true}) },
+	} {
+		start := time.Now()
+		err := run()
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s: returned after %s, want about the 100ms timeout", name, elapsed)
+		}
+		if err == nil || !strings.Contains(err.Error(), "no response within 100ms (raise it with --timeout)") {
+			t.Errorf("%s: error %v, want a timeout", name, err)
+		}
+	}
+}
+
+func TestTimeoutErrorLeavesOtherErrors(t *testing.T) {
+	// A refused connection fails for another reason than time
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, err := httpGet(url)
+	if err == nil || strings.Contains(err.Error(), "--timeout") {
+		t.Errorf("refused connection error %v, want it reported as is", err)
+	}
+}
+
+func TestSlowBodyTimeoutIsExplained(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": [`))
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	SetTimeout(100 * time.Millisecond)
+	defer SetTimeout(DefaultTimeout)
+
+	resp, err := httpGet(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	_, err = io.ReadAll(resp.Body)
+	if err == nil || !strings.Contains(err.Error(), "response not fully received within 100ms (raise it with --timeout)") {
+		t.Errorf("reading a stalled body returned %v, want a timeout", err)
+	}
+}
+
+func TestDownloadOutlastsTimeout(t *testing.T) {
+	chunks := []string{"cct-", "daemon-", "binary"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hung" {
+			time.Sleep(time.Second)
+			return
+		}
+		// Each chunk arrives within the timeout, but the whole body doesn't
+		for _, chunk := range chunks {
+			w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+			time.Sleep(80 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	SetTimeout(100 * time.Millisecond)
+	defer SetTimeout(DefaultTimeout)
+
+	sum := sha256.Sum256([]byte(strings.Join(chunks, "")))
+	path, err := downloadAsset(server.URL, t.TempDir(), hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("slow download failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "cct-daemon-binary" {
+		t.Errorf("downloaded %q", data)
+	}
+
+	// A download that never starts still times out
+	_, err = downloadAsset(server.URL+"/hung", t.TempDir(), hex.EncodeToString(sum[:]))
+	if err == nil || !strings.Contains(err.Error(), "no response within 100ms (raise it with --timeout)") {
+		t.Errorf("hung download returned %v, want a timeout", err)
+	}
+}
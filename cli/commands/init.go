@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/angelfreak/ccd/daemon/detect"
+	"github.com/spf13/cobra"
+)
+
+var (
+	slugValid   = regexp.MustCompile(`^[a-z0-9-]+$`)
+	slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type initOptions struct {
+	name      string
+	slug      string
+	repoPath  string
+	techStack []string
+	priority  int
+	status    string
+	// fromReadme and readmeSection say where to take the description from
+	fromReadme    string
+	readmeSection string
+}
+
+// prompter asks the user for a value, returning def when they accept the default
+type prompter interface {
+	Ask(label, def string) (string, error)
+}
+
+// linePrompter prompts on out and reads answers line by line from in
+type linePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *linePrompter) Ask(label, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", label)
+	}
+
+	answer, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", err
+	}
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+func NewInitCommand(pbURL *string) *cobra.Command {
+	var opts initOptions
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Register a project with the context tracker",
+		Long: `Register a project with the context tracker. Run without flags in a
+terminal to be prompted for each field, with defaults taken from the
+current directory.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.LocalFlags().NFlag() == 0 && isTerminal(os.Stdin) {
+				p := &linePrompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+				if err := promptInit(p, &opts); err != nil {
+					return err
+				}
+			} else if err := fillInitDefaults(&opts); err != nil {
+				return err
+			}
+			return initProject(*pbURL, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.name, "name", "", "Project name (default: directory name)")
+	cmd.Flags().StringVar(&opts.slug, "slug", "", "Project slug (default: derived from the name)")
+	cmd.Flags().StringVar(&opts.repoPath, "repo", "", "Repository path (default: current directory)")
+	cmd.Flags().StringSliceVar(&opts.techStack, "tech-stack", nil, "Comma-separated tech stack (default: detected)")
+	cmd.Flags().IntVar(&opts.priority, "priority", 3, "Priority, 1-5")
+	cmd.Flags().StringVar(&opts.status, "status", "active", "Status: active, paused, idea, or archived")
+	cmd.Flags().StringVar(&opts.fromReadme, "from-readme", "", "Take the description from this README, or the README.md in this directory")
+	cmd.Flags().StringVar(&opts.readmeSection, "readme-section", "", "Use the first paragraph under this README heading instead of the intro")
+
+	return cmd
+}
+
+// fillInitDefaults derives any fields not given by flags from the repo
+func fillInitDefaults(opts *initOptions) error {
+	if opts.repoPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		opts.repoPath = cwd
+	}
+	if opts.name == "" {
+		opts.name = filepath.Base(opts.repoPath)
+	}
+	if opts.slug == "" {
+		opts.slug = suggestSlug(opts.name)
+	}
+	if opts.techStack == nil {
+		opts.techStack = detect.TechStack(opts.repoPath)
+	}
+	return nil
+}
+
+// promptInit asks for each field in turn, offering defaults from the repo
+func promptInit(p prompter, opts *initOptions) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if opts.repoPath, err = p.Ask("Repo path", cwd); err != nil {
+		return err
+	}
+	if opts.name, err = p.Ask("Project name", filepath.Base(opts.repoPath)); err != nil {
+		return err
+	}
+	if opts.slug, err = p.Ask("Slug", suggestSlug(opts.name)); err != nil {
+		return err
+	}
+
+	stack, err := p.Ask("Tech stack (comma-separated)", strings.Join(detect.TechStack(opts.repoPath), ", "))
+	if err != nil {
+		return err
+	}
+	opts.techStack = splitList(stack)
+
+	priority, err := p.Ask("Priority (1-5)", strconv.Itoa(opts.priority))
+	if err != nil {
+		return err
+	}
+	if opts.priority, err = strconv.Atoi(priority); err != nil {
+		return fmt.Errorf("invalid priority: %s", priority)
+	}
+
+	return nil
+}
+
+func initProject(pbURL string, opts initOptions) error {
+	if !slugValid.MatchString(opts.slug) {
+		return fmt.Errorf("invalid slug %q: use lowercase letters, digits, and hyphens", opts.slug)
+	}
+	if opts.priority < 1 || opts.priority > 5 {
+		return fmt.Errorf("priority must be between 1 and 5")
+	}
+
+	repoPath, err := filepath.Abs(opts.repoPath)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"name":       opts.name,
+		"slug":       opts.slug,
+		"repo_path":  repoPath,
+		"status":     opts.status,
+		"priority":   opts.priority,
+		"tech_stack": opts.techStack,
+	}
+	if opts.fromReadme != "" {
+		description, err := readmeDescription(opts.fromReadme, opts.readmeSection)
+		if err != nil {
+			return err
+		}
+		data["description"] = description
+	}
+
+	if err := createRecord(pbURL, "projects", data, nil); err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	fmt.Printf("✓ Project created: %s (%s)\n", opts.name, opts.slug)
+	if len(opts.techStack) > 0 {
+		fmt.Printf("🧰 Tech Stack: %s\n", joinStrings(opts.techStack, ", "))
+	}
+	return nil
+}
+
+// suggestSlug turns a name into a valid slug: lowercase, with runs of other
+// characters collapsed to single hyphens
+func suggestSlug(name string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestSlug(t *testing.T) {
+	tests := map[string]string{
+		"my-app":            "my-app",
+		"My App":            "my-app",
+		"  CCD_Daemon v2 ":  "ccd-daemon-v2",
+		"hello...world!!":   "hello-world",
+		"--already-sluggy-": "already-sluggy",
+	}
+	for name, want := range tests {
+		if got := suggestSlug(name); got != want {
+			t.Errorf("suggestSlug(%q) = %q, want %q", name, got, want)
+		}
+		if got := suggestSlug(name); !slugValid.MatchString(got) {
+			t.Errorf("suggestSlug(%q) = %q, which isn't a valid slug", name, got)
+		}
+	}
+}
+
+// scriptedPrompter answers prompts from a map by label, accepting the
+// default for the rest, and records the defaults offered
+type scriptedPrompter struct {
+	answers  map[string]string
+	defaults map[string]string
+}
+
+func (p *scriptedPrompter) Ask(label, def string) (string, error) {
+	p.defaults[label] = def
+	if answer, ok := p.answers[label]; ok {
+		return answer, nil
+	}
+	return def, nil
+}
+
+func TestPromptInitSuggestsDefaults(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "Billing Service")
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"go.mod", "Dockerfile"} {
+		if err := os.WriteFile(filepath.Join(repo, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &scriptedPrompter{
+		answers:  map[string]string{"Repo path": repo, "Priority (1-5)": "2"},
+		defaults: make(map[string]string),
+	}
+	opts := initOptions{priority: 3}
+	if err := promptInit(p, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := p.defaults["Slug"]; got != "billing-service" {
+		t.Errorf("suggested slug %q, want billing-service", got)
+	}
+	if got := p.defaults["Tech stack (comma-separated)"]; got != "Go, Docker" {
+		t.Errorf("suggested tech stack %q, want \"Go, Docker\"", got)
+	}
+	if got := p.defaults["Priority (1-5)"]; got != "3" {
+		t.Errorf("suggested priority %q, want 3", got)
+	}
+
+	want := initOptions{
+		name:      "Billing Service",
+		slug:      "billing-service",
+		repoPath:  repo,
+		techStack: []string{"Go", "Docker"},
+		priority:  2,
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("options = %+v, want %+v", opts, want)
+	}
+}
+
+func TestPromptInitRejectsBadPriority(t *testing.T) {
+	p := &scriptedPrompter{
+		answers:  map[string]string{"Repo path": t.TempDir(), "Priority (1-5)": "high"},
+		defaults: make(map[string]string),
+	}
+	opts := initOptions{priority: 3}
+	if err := promptInit(p, &opts); err == nil {
+		t.Error("promptInit accepted a non-numeric priority")
+	}
+}
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewLedgerCommand(pbURL *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ledger",
+		Short: "Manage the daemon's continuity ledger",
+	}
+
+	cmd.AddCommand(NewLedgerMergeCommand(pbURL))
+	// cct verify-ledger under its older name
+	verify := NewVerifyLedgerCommand(pbURL)
+	verify.Use = "verify"
+	verify.Short += " (same as cct verify-ledger)"
+	cmd.AddCommand(verify)
+	cmd.AddCommand(NewLedgerStatsCommand())
+
+	return cmd
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/spf13/cobra"
+)
+
+func NewLedgerMergeCommand(pbURL *string) *cobra.Command {
+	var projectSlug string
+
+	cmd := &cobra.Command{
+		Use:   "merge <dir>",
+		Short: "Merge another machine's continuity ledger into this repo's",
+		Long: `Merge the continuity files in <dir> into the current directory's repo
+ledger, or that of --project. <dir> may be a ledger directory or a repo root
+containing thoughts/ledgers. Day files present in both are combined in
+timestamp order and identical entries are kept once.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mergeLedger(*pbURL, args[0], projectSlug)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectSlug, "project", "", "Merge into this project's repo instead of the current directory")
+
+	return cmd
+}
+
+func mergeLedger(pbURL, otherDir, projectSlug string) error {
+	repoPath, err := projectRepoPath(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	// Accept a repo root as well as its ledger directory
+	if path, _ := ledger.LatestLedgerFileIn(otherDir); path == "" {
+		otherDir = ledger.LedgerDirFor(otherDir)
+	}
+
+	target := ledger.NewLedger("", repoPath)
+	if absPath(otherDir) == absPath(target.Dir()) {
+		return fmt.Errorf("%s is this repo's own ledger", otherDir)
+	}
+
+	result, err := target.Merge(otherDir)
+	if err != nil {
+		return fmt.Errorf("failed to merge ledger: %w", err)
+	}
+
+	fmt.Printf("✓ Merged %d entries into %s (%d day files updated, %d duplicates skipped)\n",
+		result.Added, target.Dir(), result.Files, result.Duplicates)
+	return nil
+}
+
+func absPath(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		return p
+	}
+	return path
+}
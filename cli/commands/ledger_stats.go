@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/instance"
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/spf13/cobra"
+)
+
+func NewLedgerStatsCommand() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "stats [project-slug]",
+		Short: "Show how much the daemon has recorded in its ledger",
+		Long: `Ask the daemon running for the project how much its continuity ledger holds:
+entries, facts by type, sessions, the peak token count, resolved blockers, and
+the ledger's size on disk. The daemon must be running with -http-addr.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("invalid --format %q: use text or json", format)
+			}
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return showLedgerStats(projectSlug, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func showLedgerStats(projectSlug, format string) error {
+	inst, err := runningDaemon(projectSlug)
+	if err != nil {
+		return err
+	}
+	if inst.HTTPAddr == "" {
+		return fmt.Errorf("the daemon for %s has no HTTP server (start it with -http-addr)", projectSlug)
+	}
+
+	metrics, err := fetchLedgerMetrics(inst.HTTPAddr)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(metrics)
+	}
+
+	printLedgerStats(os.Stdout, projectSlug, metrics)
+	return nil
+}
+
+// runningDaemon returns the running daemon registered for a project, by
+// slug or ID
+func runningDaemon(projectSlug string) (instance.Instance, error) {
+	instances, err := daemonInstances()
+	if err != nil {
+		return instance.Instance{}, err
+	}
+	for _, inst := range instances {
+		if inst.ProjectSlug != projectSlug && inst.ProjectID != projectSlug {
+			continue
+		}
+		if inst.Running {
+			return inst, nil
+		}
+	}
+	return instance.Instance{}, fmt.Errorf("no daemon running for %s", projectSlug)
+}
+
+// fetchLedgerMetrics asks a daemon's HTTP server for its ledger metrics
+func fetchLedgerMetrics(httpAddr string) (*ledger.LedgerMetrics, error) {
+	resp, err := httpGet(fmt.Sprintf("http://%s/metrics/ledger", httpAddr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach daemon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("the daemon's ledger is disabled")
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch ledger metrics: %s", body)
+	}
+
+	var metrics ledger.LedgerMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		return nil, fmt.Errorf("failed to parse ledger metrics: %w", err)
+	}
+	return &metrics, nil
+}
+
+func printLedgerStats(out io.Writer, projectSlug string, metrics *ledger.LedgerMetrics) {
+	fmt.Fprintf(out, "📒 Ledger for %s\n\n", projectSlug)
+	fmt.Fprintf(out, "  Entries:   %d\n", metrics.TotalEntries)
+	fmt.Fprintf(out, "  Sessions:  %d\n", metrics.TotalSessions)
+	fmt.Fprintf(out, "  Facts:     %d\n", metrics.TotalFacts)
+
+	types := make([]string, 0, len(metrics.FactsByType))
+	for factType := range metrics.FactsByType {
+		types = append(types, factType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if metrics.FactsByType[types[i]] != metrics.FactsByType[types[j]] {
+			return metrics.FactsByType[types[i]] > metrics.FactsByType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	for _, factType := range types {
+		fmt.Fprintf(out, "    %-14s %d\n", factType, metrics.FactsByType[factType])
+	}
+
+	if metrics.PeakTokenSession != "" {
+		fmt.Fprintf(out, "  Peak:      %d tokens (session %s)\n", metrics.PeakTokenCount, metrics.PeakTokenSession)
+	}
+	if metrics.ResolvedBlockers > 0 {
+		fmt.Fprintf(out, "  Blockers:  %d resolved, %s on average, %s at most\n", metrics.ResolvedBlockers,
+			formatMinutes((time.Duration(metrics.AvgBlockedSeconds) * time.Second).Minutes()),
+			formatMinutes((time.Duration(metrics.MaxBlockedSeconds) * time.Second).Minutes()))
+	}
+	fmt.Fprintf(out, "  Size:      %s\n", formatBytes(metrics.FileSizeBytes))
+}
+
+// formatBytes renders a size in B, KB, or MB
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
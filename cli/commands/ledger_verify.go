@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/spf13/cobra"
+)
+
+func NewVerifyLedgerCommand(pbURL *string) *cobra.Command {
+	var projectSlug string
+
+	cmd := &cobra.Command{
+		Use:   "verify-ledger",
+		Short: "Check the continuity ledger for corruption",
+		Long: `Check the current directory's repo ledger, or that of --project, without
+changing it. Every line must parse, timestamps must not go backwards within a
+day file, every entry needs a session ID and the ledger's project, and no
+entry may be recorded twice. Problems are listed by file and line, and the
+command fails if there are any.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verifyLedger(*pbURL, projectSlug)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectSlug, "project", "", "Verify this project's repo ledger instead of the current directory's")
+
+	return cmd
+}
+
+func verifyLedger(pbURL, projectSlug string) error {
+	repoPath, err := projectRepoPath(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	dir := ledger.LedgerDirFor(repoPath)
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("no ledger found at %s", dir)
+	}
+
+	result, err := ledger.NewLedger("", repoPath).Verify()
+	if err != nil {
+		return fmt.Errorf("failed to verify ledger: %w", err)
+	}
+
+	for _, problem := range result.Problems {
+		fmt.Printf("✗ %s\n", problem)
+	}
+	if len(result.Problems) > 0 {
+		return fmt.Errorf("%d problem(s) found in %s", len(result.Problems), dir)
+	}
+
+	fmt.Printf("✓ Ledger OK: %d entries in %d day files\n", result.Entries, result.Files)
+	return nil
+}
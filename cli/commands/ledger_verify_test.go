@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+)
+
+func TestVerifyLedgerReportsCorruption(t *testing.T) {
+	repo := t.TempDir()
+	dir := ledger.LedgerDirFor(repo)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	entry := `{"schema_version":2,"timestamp":"2026-03-01T10:00:00Z","session_id":"s1","project_id":"p1"}`
+	day := filepath.Join(dir, "CONTINUITY_2026-03-01.jsonl")
+	if err := os.WriteFile(day, []byte(entry+"\n{\"timestamp\":\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "repo_path": repo})
+
+	var err error
+	out := captureStdout(t, func() { err = verifyLedger(pb.URL, "app") })
+	if err == nil || !strings.Contains(err.Error(), "1 problem(s) found") {
+		t.Errorf("verify returned %v, want a failure for the corrupt line", err)
+	}
+	if !strings.HasPrefix(out, "✗ CONTINUITY_2026-03-01.jsonl:2: unparseable entry") {
+		t.Errorf("verify printed %q, want the corrupt line located", out)
+	}
+
+	// Once repaired the ledger verifies
+	if err := os.WriteFile(day, []byte(entry+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out = captureStdout(t, func() { err = verifyLedger(pb.URL, "app") })
+	if err != nil || out != "✓ Ledger OK: 1 entries in 1 day files\n" {
+		t.Errorf("verify of a clean ledger returned %v and printed %q", err, out)
+	}
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/spf13/cobra"
+)
+
+func NewOpenCommand(pbURL *string) *cobra.Command {
+	var projectSlug string
+
+	cmd := &cobra.Command{
+		Use:   "open [handoff|ledger]",
+		Short: "Open the latest handoff or continuity ledger in your editor",
+		Long: `Open the latest handoff (default) or the latest day's continuity ledger in
+$EDITOR, falling back to $VISUAL and then vi. Files are looked up in the
+current directory's repo, or the repo of --project.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"handoff", "ledger"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind := "handoff"
+			if len(args) == 1 {
+				kind = args[0]
+			}
+			if kind != "handoff" && kind != "ledger" {
+				return fmt.Errorf("unknown file %q: expected handoff or ledger", kind)
+			}
+
+			path, err := resolveOpenPath(*pbURL, kind, projectSlug)
+			if err != nil {
+				return err
+			}
+			if path == "" {
+				return nil
+			}
+			return runEditor(path)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectSlug, "project", "", "Open the file in this project's repo instead of the current directory")
+
+	return cmd
+}
+
+// resolveOpenPath returns the latest handoff or ledger file for the repo, or
+// "" after explaining that there isn't one
+func resolveOpenPath(pbURL, kind, projectSlug string) (string, error) {
+	repoPath, err := projectRepoPath(pbURL, projectSlug)
+	if err != nil {
+		return "", err
+	}
+
+	if kind == "ledger" {
+		dir := ledger.LedgerDirFor(repoPath)
+		path, err := ledger.LatestLedgerFileIn(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to find ledger: %w", err)
+		}
+		if path == "" {
+			fmt.Printf("No continuity ledger found in %s\n", dir)
+		}
+		return path, nil
+	}
+
+	dir := ledger.HandoffDirFor(repoPath)
+	handoff, err := ledger.LatestHandoffIn(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to find handoff: %w", err)
+	}
+	if handoff == nil {
+		fmt.Printf("No handoffs found in %s\n", dir)
+		return "", nil
+	}
+	return handoff.Path, nil
+}
+
+// projectRepoPath returns the repo of the project with slug, or the current
+// directory when slug is empty
+func projectRepoPath(pbURL, slug string) (string, error) {
+	if slug == "" {
+		return ".", nil
+	}
+	project, err := getProject(pbURL, slug)
+	if err != nil {
+		return "", err
+	}
+	if project.RepoPath == "" {
+		return "", fmt.Errorf("project %s has no repo path", slug)
+	}
+	return project.RepoPath, nil
+}
+
+// editorCommand returns the user's editor: $EDITOR, then $VISUAL, then vi
+func editorCommand() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// runEditor opens path in the user's editor and waits for it to exit. The
+// editor is run through the shell so it may include arguments.
+func runEditor(path string) error {
+	cmd := exec.Command("sh", "-c", editorCommand()+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+	return nil
+}
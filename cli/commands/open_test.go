@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+)
+
+// stubEditor sets $EDITOR to a script that records the file it's asked to
+// open, returning where it records it
+func stubEditor(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	record := filepath.Join(dir, "opened")
+	script := filepath.Join(dir, "editor")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+record+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", script)
+	t.Setenv("VISUAL", "")
+	return record
+}
+
+func TestOpenLatestFiles(t *testing.T) {
+	repo := newFixtureRepo(t)
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "repo_path": repo})
+
+	// An older handoff, and continuity files for two days
+	older := filepath.Join(ledger.HandoffDirFor(repo), "handoff_s0_20260228_090000.md")
+	if err := os.WriteFile(older, []byte("---\nsession_id: s0\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ledgerDir := ledger.LedgerDirFor(repo)
+	if err := os.MkdirAll(ledgerDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"CONTINUITY_2026-02-28.jsonl", "CONTINUITY_2026-03-01.jsonl"} {
+		if err := os.WriteFile(filepath.Join(ledgerDir, name), []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--project", "app"}, filepath.Join(ledger.HandoffDirFor(repo), "handoff_s1_20260301_120000.md")},
+		{[]string{"handoff", "--project", "app"}, filepath.Join(ledger.HandoffDirFor(repo), "handoff_s1_20260301_120000.md")},
+		{[]string{"ledger", "--project", "app"}, filepath.Join(ledgerDir, "CONTINUITY_2026-03-01.jsonl")},
+	}
+	for _, tt := range tests {
+		record := stubEditor(t)
+		cmd := NewOpenCommand(&pb.URL)
+		cmd.SetArgs(tt.args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("open %v: %v", tt.args, err)
+		}
+		opened, err := os.ReadFile(record)
+		if err != nil {
+			t.Fatalf("open %v didn't run the editor: %v", tt.args, err)
+		}
+		if got := strings.TrimSpace(string(opened)); got != tt.want {
+			t.Errorf("open %v opened %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestOpenWithoutFiles(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "repo_path": t.TempDir()})
+
+	for _, kind := range []string{"handoff", "ledger"} {
+		record := stubEditor(t)
+		out := captureStdout(t, func() {
+			cmd := NewOpenCommand(&pb.URL)
+			cmd.SetArgs([]string{kind, "--project", "app"})
+			if err := cmd.Execute(); err != nil {
+				t.Errorf("open %s: %v", kind, err)
+			}
+		})
+		if !strings.HasPrefix(out, "No ") {
+			t.Errorf("open %s without files printed %q, want an explanation", kind, out)
+		}
+		if _, err := os.Stat(record); err == nil {
+			t.Errorf("open %s without files ran the editor", kind)
+		}
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("EDITOR", "")
+	t.Setenv("VISUAL", "")
+	if got := editorCommand(); got != "vi" {
+		t.Errorf("default editor %q, want vi", got)
+	}
+	t.Setenv("VISUAL", "code --wait")
+	if got := editorCommand(); got != "code --wait" {
+		t.Errorf("editor %q, want $VISUAL", got)
+	}
+	t.Setenv("EDITOR", "nano")
+	if got := editorCommand(); got != "nano" {
+		t.Errorf("editor %q, want $EDITOR ahead of $VISUAL", got)
+	}
+}
@@ -0,0 +1,410 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pbTimeLayout is the format PocketBase uses for dates in records and filters
+const pbTimeLayout = "2006-01-02 15:04:05.000Z"
+
+// pbPageSize is how many records are requested per page when listing
+const pbPageSize = 200
+
+type projectRecord struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Slug        string   `json:"slug"`
+	RepoPath    string   `json:"repo_path"`
+	Status      string   `json:"status"`
+	Priority    int      `json:"priority"`
+	TechStack   []string `json:"tech_stack"`
+	Description string   `json:"description"`
+	// Expand holds relations fetched with expand, such as a linked team
+	Expand recordExpand `json:"expand,omitempty"`
+}
+
+type factRecord struct {
+	ID         string `json:"id"`
+	Project    string `json:"project"`
+	Session    string `json:"session"`
+	FactType   string `json:"fact_type"`
+	Content    string `json:"content"`
+	Importance int    `json:"importance"`
+	Stale      bool   `json:"stale"`
+	Created    string `json:"created"`
+	// Verified is set by cct facts verify and correct
+	Verified bool `json:"verified"`
+	// Embedding is set by the daemon's -compute-embeddings task
+	Embedding []float64 `json:"embedding,omitempty"`
+	// Expand holds relations fetched with expand: "project" and "session"
+	Expand recordExpand `json:"expand,omitempty"`
+}
+
+// recordExpand is a record's expand object: the records PocketBase returns
+// for the relation fields named by the expand query parameter, keyed by
+// field. They are decoded on demand with expandedRecords, so relations the
+// CLI has no struct for are carried through rather than dropped.
+type recordExpand map[string]json.RawMessage
+
+// expandedRecords decodes the records expanded for a relation field. A
+// single relation expands to one record and a multiple relation to a list;
+// either way they are returned as a slice, empty when the field wasn't
+// expanded or the relation is unset.
+func expandedRecords[T any](expand recordExpand, field string) ([]T, error) {
+	raw, ok := expand[field]
+	if !ok || string(raw) == "null" {
+		return nil, nil
+	}
+
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []T
+		if err := json.Unmarshal(raw, &records); err != nil {
+			return nil, fmt.Errorf("failed to decode expanded %s: %w", field, err)
+		}
+		return records, nil
+	}
+
+	var record T
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode expanded %s: %w", field, err)
+	}
+	return []T{record}, nil
+}
+
+type sessionRecord struct {
+	ID           string `json:"id"`
+	Project      string `json:"project"`
+	Summary      string `json:"summary"`
+	TokenCount   int    `json:"token_count"`
+	SessionStart string `json:"session_start"`
+	SessionEnd   string `json:"session_end"`
+	Created      string `json:"created"`
+	// Model and Cwd come from the transcript's metadata, when it has any
+	Model string `json:"model,omitempty"`
+	Cwd   string `json:"cwd,omitempty"`
+	// Branch is the git branch the session worked on and Tag a workstream
+	// label, such as a ticket, given to cct push
+	Branch string `json:"branch,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+}
+
+type contextSectionRecord struct {
+	ID            string `json:"id"`
+	Project       string `json:"project"`
+	SectionType   string `json:"section_type"`
+	Title         string `json:"title"`
+	Content       string `json:"content"`
+	Order         int    `json:"order"`
+	AutoExtracted bool   `json:"auto_extracted"`
+	SnapshotHash  string `json:"snapshot_hash"`
+}
+
+// sectionVersionRecord is a context section's content as of one push
+type sectionVersionRecord struct {
+	ID           string `json:"id"`
+	Section      string `json:"section"`
+	Project      string `json:"project"`
+	Session      string `json:"session"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	SnapshotHash string `json:"snapshot_hash"`
+	Created      string `json:"created"`
+}
+
+// getProject looks up a project by slug
+func getProject(pbURL, projectSlug string) (*projectRecord, error) {
+	projects, err := listRecords[projectRecord](pbURL, "projects", fmt.Sprintf("slug='%s'", projectSlug), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("project not found: %s", projectSlug)
+	}
+	return &projects[0], nil
+}
+
+// listRecords fetches every record in a collection matching filter, following
+// pagination. Relation fields named in expand are fetched too, into each
+// record's Expand.
+func listRecords[T any](pbURL, collection, filter, sort string, expand ...string) ([]T, error) {
+	var records []T
+	err := eachPage(pbURL, collection, filter, sort, expand, func(items []T) error {
+		records = append(records, items...)
+		return nil
+	})
+	return records, err
+}
+
+// eachPage calls fn with each page of records matching filter as it arrives,
+// stopping at the first error
+func eachPage[T any](pbURL, collection, filter, sort string, expand []string, fn func([]T) error) error {
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("perPage", strconv.Itoa(pbPageSize))
+		if filter != "" {
+			params.Set("filter", filter)
+		}
+		if sort != "" {
+			params.Set("sort", sort)
+		}
+		setExpand(params, expand)
+
+		resp, err := httpGet(fmt.Sprintf("%s/api/collections/%s/records?%s", pbURL, collection, params.Encode()))
+		if err != nil {
+			return err
+		}
+
+		var result struct {
+			Page       int `json:"page"`
+			TotalPages int `json:"totalPages"`
+			Items      []T `json:"items"`
+		}
+		err = decodeResponse(resp, &result)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(result.Items); err != nil {
+			return err
+		}
+		if result.Page >= result.TotalPages || len(result.Items) == 0 {
+			return nil
+		}
+	}
+}
+
+// countRecords returns how many records in a collection match filter
+func countRecords(pbURL, collection, filter string) (int, error) {
+	params := url.Values{}
+	params.Set("perPage", "1")
+	if filter != "" {
+		params.Set("filter", filter)
+	}
+
+	resp, err := httpGet(fmt.Sprintf("%s/api/collections/%s/records?%s", pbURL, collection, params.Encode()))
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		TotalItems int `json:"totalItems"`
+	}
+	if err := decodeResponse(resp, &result); err != nil {
+		return 0, err
+	}
+	return result.TotalItems, nil
+}
+
+// firstRecords fetches up to limit records matching filter, in sort order,
+// with the relation fields named in expand
+func firstRecords[T any](pbURL, collection, filter, sort string, limit int, expand ...string) ([]T, error) {
+	params := url.Values{}
+	params.Set("perPage", strconv.Itoa(limit))
+	if filter != "" {
+		params.Set("filter", filter)
+	}
+	if sort != "" {
+		params.Set("sort", sort)
+	}
+	setExpand(params, expand)
+
+	resp, err := httpGet(fmt.Sprintf("%s/api/collections/%s/records?%s", pbURL, collection, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Items []T `json:"items"`
+	}
+	if err := decodeResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// setExpand asks PocketBase to expand the named relation fields, if any
+func setExpand(params url.Values, expand []string) {
+	if len(expand) > 0 {
+		params.Set("expand", strings.Join(expand, ","))
+	}
+}
+
+// createRecord posts a new record and decodes the created record into out,
+// which may be nil
+func createRecord(pbURL, collection string, data interface{}, out interface{}) error {
+	return sendRecord(http.MethodPost, fmt.Sprintf("%s/api/collections/%s/records", pbURL, collection), data, out)
+}
+
+// updateRecord patches fields on an existing record
+func updateRecord(pbURL, collection, id string, data interface{}) error {
+	return sendRecord(http.MethodPatch, fmt.Sprintf("%s/api/collections/%s/records/%s", pbURL, collection, id), data, nil)
+}
+
+func deleteRecord(pbURL, collection, id string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/collections/%s/records/%s", pbURL, collection, id), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpDo(req)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, nil)
+}
+
+func sendRecord(method, endpoint string, data interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpDo(req)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, out)
+}
+
+// decodeResponse checks the status code and decodes the body into out, which
+// may be nil. The response body is always closed.
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// parsePBTime parses a PocketBase timestamp, also accepting RFC3339
+func parsePBTime(value string) (time.Time, error) {
+	if t, err := time.Parse(pbTimeLayout, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseSince accepts either a date (2006-01-02), the start of that day in
+// loc, or a number of days back such as "30d"
+func parseSince(value string, loc *time.Location) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err == nil && days >= 0 {
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: use a date like 2024-01-31 or a day count like 30d", value)
+	}
+	return t, nil
+}
+
+// parseUntil parses an --until date, returning the end of that day in loc so
+// the whole day is included
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --until %q: use a date like 2024-01-31", value)
+	}
+	return day.AddDate(0, 0, 1), nil
+}
+
+// getSessions returns a project's sessions newest first, at most limit of
+// them when limit is positive
+func getSessions(pbURL, projectID, tag string, limit int) ([]sessionRecord, error) {
+	filter := fmt.Sprintf("project='%s'", projectID)
+	if tag != "" {
+		filter += " && " + sessionTagFilter(tag)
+	}
+	if limit > 0 {
+		return firstRecords[sessionRecord](pbURL, "session_history", filter, "-created", limit)
+	}
+	return listRecords[sessionRecord](pbURL, "session_history", filter, "-created")
+}
+
+// sessionTagFilter matches sessions tagged tag or recorded on branch tag
+func sessionTagFilter(tag string) string {
+	escaped := strings.ReplaceAll(tag, "'", "\\'")
+	return fmt.Sprintf("(tag='%s' || branch='%s')", escaped, escaped)
+}
+
+// taggedFactIDs returns the IDs of the facts from a project's sessions
+// matching tag, found as fetchSessionFacts finds them
+func taggedFactIDs(pbURL, projectID, tag string) (map[string]bool, error) {
+	sessions, err := getSessions(pbURL, projectID, tag, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	ids := make(map[string]bool)
+	for _, session := range sessions {
+		facts, err := fetchSessionFacts(pbURL, projectID, session)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch facts: %w", err)
+		}
+		for _, fact := range facts {
+			ids[fact.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// filterFactsByTag keeps the facts that came from sessions matching tag
+func filterFactsByTag(pbURL, projectID, tag string, facts []factRecord) ([]factRecord, error) {
+	ids, err := taggedFactIDs(pbURL, projectID, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []factRecord
+	for _, fact := range facts {
+		if ids[fact.ID] {
+			kept = append(kept, fact)
+		}
+	}
+	return kept, nil
+}
+
+// fetchSessionFacts returns the facts linked to a session. Facts posted by the
+// daemon aren't linked, so those created during the session's time window are
+// used instead.
+func fetchSessionFacts(pbURL, projectID string, session sessionRecord) ([]factRecord, error) {
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s' && session='%s'", projectID, session.ID), "-importance")
+	if err != nil || len(facts) > 0 {
+		return facts, err
+	}
+
+	start, end := session.SessionStart, session.SessionEnd
+	if end == "" {
+		end = session.Created
+	}
+	if start == "" || end == "" {
+		return nil, nil
+	}
+
+	return listRecords[factRecord](pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s' && created>='%s' && created<='%s'", projectID, start, end), "-importance")
+}
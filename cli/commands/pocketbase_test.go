@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+// teamRecord is a relation the CLI has no struct of its own for
+type teamRecord struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestListRecordsExpandsRelations(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{
+		"id": "p1", "slug": "app", "team": "t1",
+		"expand": map[string]interface{}{
+			"team":     map[string]interface{}{"id": "t1", "name": "Platform"},
+			"watchers": []map[string]interface{}{{"id": "t2", "name": "SRE"}, {"id": "t3", "name": "Data"}},
+		},
+	})
+
+	projects, err := listRecords[projectRecord](pb.URL, "projects", "", "", "team", "watchers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pb.listExpands("projects"); !reflect.DeepEqual(got, []string{"team,watchers"}) {
+		t.Errorf("expand parameters = %q, want team,watchers", got)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("got %d projects, want 1", len(projects))
+	}
+
+	// A single relation decodes to one record, a multiple one to a list
+	teams, err := expandedRecords[teamRecord](projects[0].Expand, "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []teamRecord{{ID: "t1", Name: "Platform"}}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("team = %+v, want %+v", teams, want)
+	}
+	watchers, err := expandedRecords[teamRecord](projects[0].Expand, "watchers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []teamRecord{{ID: "t2", Name: "SRE"}, {ID: "t3", Name: "Data"}}; !reflect.DeepEqual(watchers, want) {
+		t.Errorf("watchers = %+v, want %+v", watchers, want)
+	}
+
+	// Without expand nothing is asked for
+	if _, err := listRecords[projectRecord](pb.URL, "projects", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := pb.listExpands("projects"); len(got) != 2 || got[1] != "" {
+		t.Errorf("expand parameters = %q, want none on the second request", got)
+	}
+}
+
+func TestExpandedRecordsMissingOrInvalid(t *testing.T) {
+	expand := recordExpand{
+		"unset": []byte("null"),
+		"bad":   []byte(`{"id": 7}`),
+	}
+	for _, field := range []string{"unset", "absent"} {
+		records, err := expandedRecords[teamRecord](expand, field)
+		if err != nil || len(records) != 0 {
+			t.Errorf("%s: got %+v, %v; want nothing", field, records, err)
+		}
+	}
+	if _, err := expandedRecords[teamRecord](expand, "bad"); err == nil {
+		t.Error("a record that doesn't decode wasn't an error")
+	}
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// projectMarkerPattern matches the comment pull writes at the top of
+// CLAUDE.md naming the project it came from
+var projectMarkerPattern = regexp.MustCompile(`^<!--\s*ccd:project\s+(\S+)\s*-->$`)
+
+// projectMarkerLines is how far into CLAUDE.md the marker is looked for
+const projectMarkerLines = 10
+
+// projectMarker is the comment pull writes at the top of CLAUDE.md
+func projectMarker(projectSlug string) string {
+	return fmt.Sprintf("<!-- ccd:project %s -->\n", projectSlug)
+}
+
+// projectSlugArg returns the project slug given as the command's first
+// argument or, without one, the slug marked in the nearest CLAUDE.md
+func projectSlugArg(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	slug, err := markedProjectSlug(cwd)
+	if err != nil {
+		return "", err
+	}
+	if slug == "" {
+		return "", fmt.Errorf("no project slug given, and no CLAUDE.md written by cct pull found from %s up", cwd)
+	}
+	return slug, nil
+}
+
+// markedProjectSlug walks up from dir to the nearest CLAUDE.md with a
+// project marker and returns its slug, or "" when there is none
+func markedProjectSlug(dir string) (string, error) {
+	for {
+		slug, err := readProjectMarker(filepath.Join(dir, "CLAUDE.md"))
+		if err != nil || slug != "" {
+			return slug, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// readProjectMarker returns the slug marked near the top of the file at
+// path, or "" when the file is missing or has no marker
+func readProjectMarker(path string) (string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for i := 0; i < projectMarkerLines && scanner.Scan(); i++ {
+		if m := projectMarkerPattern.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// stripProjectMarker removes pull's project marker from a CLAUDE.md being
+// shown or exported, where it means nothing to the reader
+func stripProjectMarker(markdown string) string {
+	first, rest, found := strings.Cut(markdown, "\n")
+	if found && projectMarkerPattern.MatchString(first) {
+		return rest
+	}
+	return markdown
+}
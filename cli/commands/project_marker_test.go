@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeClaudeMD writes a CLAUDE.md into dir, creating it
+func writeClaudeMD(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarkedProjectSlug(t *testing.T) {
+	repo := t.TempDir()
+	writeClaudeMD(t, repo, projectMarker("billing-service")+"# Billing Service\n")
+	// A hand-written CLAUDE.md further down has no marker and is passed over
+	nested := filepath.Join(repo, "services", "api")
+	writeClaudeMD(t, nested, "# API notes\n")
+
+	for _, dir := range []string{repo, nested} {
+		slug, err := markedProjectSlug(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slug != "billing-service" {
+			t.Errorf("slug from %s = %q, want billing-service", dir, slug)
+		}
+	}
+
+	// The nearest marked CLAUDE.md wins
+	writeClaudeMD(t, nested, "\n<!--  ccd:project api-gateway  -->\n# API\n")
+	if slug, _ := markedProjectSlug(nested); slug != "api-gateway" {
+		t.Errorf("slug = %q, want the nearer api-gateway", slug)
+	}
+
+	// Only the top of the file is searched
+	unmarked := t.TempDir()
+	writeClaudeMD(t, unmarked, strings.Repeat("text\n", projectMarkerLines)+projectMarker("late"))
+	if slug, err := markedProjectSlug(unmarked); slug != "" || err != nil {
+		t.Errorf("slug = %q, %v; want none for a marker past the top", slug, err)
+	}
+}
+
+func TestProjectSlugDefaultsToMarker(t *testing.T) {
+	repo := t.TempDir()
+	writeClaudeMD(t, repo, projectMarker("app")+"# App\n")
+	chdir(t, repo)
+
+	if slug, err := projectSlugArg([]string{"other"}); err != nil || slug != "other" {
+		t.Errorf("projectSlugArg with a slug = %q, %v; want the argument", slug, err)
+	}
+	if slug, err := projectSlugArg(nil); err != nil || slug != "app" {
+		t.Errorf("projectSlugArg without a slug = %q, %v; want app from the marker", slug, err)
+	}
+
+	// A command run without the slug uses the marked project
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app"})
+	if err := runFactsAdd(t, pb.URL, "--type", "todo", "--content", "Add migrations"); err != nil {
+		t.Fatal(err)
+	}
+	if filters := pb.listFilters("projects"); len(filters) != 1 || filters[0] != "slug='app'" {
+		t.Errorf("project looked up with filters %q, want slug='app'", filters)
+	}
+	if created := pb.createdRecords("extracted_facts"); len(created) != 1 || created[0]["project"] != "p1" {
+		t.Errorf("created %v, want a fact in p1", created)
+	}
+}
+
+func TestProjectSlugWithoutMarker(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	_, err := projectSlugArg(nil)
+	if err == nil || !strings.Contains(err.Error(), "no project slug given, and no CLAUDE.md written by cct pull found") {
+		t.Errorf("projectSlugArg without a slug or marker = %v", err)
+	}
+}
+
+func TestStripProjectMarker(t *testing.T) {
+	if got := stripProjectMarker(projectMarker("app") + "# App\n"); got != "# App\n" {
+		t.Errorf("stripProjectMarker = %q, want the marker gone", got)
+	}
+	if got := stripProjectMarker("# App\n<!-- ccd:project app -->\n"); got != "# App\n<!-- ccd:project app -->\n" {
+		t.Errorf("stripProjectMarker = %q, want a marker past the first line kept", got)
+	}
+}
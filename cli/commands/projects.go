@@ -0,0 +1,18 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewProjectsCommand(pbURL *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "projects",
+		Short: "Manage projects",
+	}
+
+	cmd.AddCommand(NewProjectsSetPriorityFromBlockersCommand(pbURL))
+	cmd.AddCommand(NewProjectsSetDescriptionCommand(pbURL))
+	cmd.AddCommand(NewProjectsTransferCommand(pbURL))
+
+	return cmd
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// maxDescriptionLength is the longest description taken from a README
+const maxDescriptionLength = 500
+
+var (
+	// readmeHeading captures a heading's level as well as its text
+	readmeHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	emptyLink     = regexp.MustCompile(`\[\s*\]\([^)]*\)`)
+)
+
+type setDescriptionOptions struct {
+	fromReadme    string
+	readmeSection string
+	confirm       bool
+}
+
+func NewProjectsSetDescriptionCommand(pbURL *string) *cobra.Command {
+	var opts setDescriptionOptions
+
+	cmd := &cobra.Command{
+		Use:   "set-description <project-slug> [description]",
+		Short: "Set a project's description, optionally from its README",
+		Long: `Set a project's description. With --from-readme it is taken from the
+README's first paragraph after the title, skipping badges and code blocks, or
+from the first paragraph of --readme-section. Descriptions from a README are
+cut to 500 characters.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var description string
+			switch {
+			case len(args) == 2 && opts.fromReadme != "":
+				return fmt.Errorf("give a description or --from-readme, not both")
+			case len(args) == 2:
+				description = args[1]
+			case opts.fromReadme != "":
+				var err error
+				if description, err = readmeDescription(opts.fromReadme, opts.readmeSection); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("give a description or --from-readme")
+			}
+			return setProjectDescription(*pbURL, args[0], description, opts.confirm)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.fromReadme, "from-readme", "", "Take the description from this README, or the README.md in this directory")
+	cmd.Flags().StringVar(&opts.readmeSection, "readme-section", "", "Use the first paragraph under this README heading instead of the intro")
+	cmd.Flags().BoolVar(&opts.confirm, "confirm", false, "Preview the description and ask before updating")
+
+	return cmd
+}
+
+func setProjectDescription(pbURL, projectSlug, description string, confirm bool) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	if confirm {
+		fmt.Printf("📝 Description for %s:\n\n%s\n\n", project.Name, description)
+		p := &linePrompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+		answer, err := p.Ask("Update the project? (y/N)", "n")
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Println("Update cancelled")
+			return nil
+		}
+	}
+
+	if err := updateRecord(pbURL, "projects", project.ID, map[string]interface{}{"description": description}); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	fmt.Printf("✓ Updated description for %s\n", project.Name)
+	return nil
+}
+
+// readmeDescription extracts a project description from a README: the first
+// paragraph after its title, or with section set, the first paragraph under
+// that heading. path may be the README itself or the directory holding it.
+func readmeDescription(path, section string) (string, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "README.md")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read README: %w", err)
+	}
+
+	paragraph := readmeParagraph(strings.Split(string(data), "\n"), section)
+	if paragraph == "" {
+		if section != "" {
+			return "", fmt.Errorf("no paragraph found under %q in %s", section, path)
+		}
+		return "", fmt.Errorf("no intro paragraph found in %s", path)
+	}
+	return clipDescription(paragraph, maxDescriptionLength), nil
+}
+
+// readmeParagraph returns the first prose paragraph after the title, or under
+// the section heading when one is given, joined onto one line. Code blocks,
+// badge and HTML lines, and subheadings are skipped; the paragraph search
+// ends at the next heading of the section's level or above.
+func readmeParagraph(lines []string, section string) string {
+	level := 0 // heading level of the section being searched
+	found := section == ""
+	inCode := false
+	var paragraph []string
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		if m := readmeHeading.FindStringSubmatch(trimmed); m != nil {
+			if len(paragraph) > 0 {
+				break
+			}
+			switch {
+			case !found && strings.EqualFold(m[2], section):
+				found, level = true, len(m[1])
+			case found && level > 0 && len(m[1]) <= level:
+				return ""
+			}
+			continue
+		}
+
+		if !found || isBadgeLine(trimmed) {
+			continue
+		}
+		if trimmed == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+
+	text := markdownImage.ReplaceAllString(strings.Join(paragraph, " "), "")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// isBadgeLine reports whether a line holds only images, such as CI and
+// version badges, or is HTML, which READMEs use for centered logos
+func isBadgeLine(line string) bool {
+	if strings.HasPrefix(line, "<") {
+		return true
+	}
+	if !strings.Contains(line, "![") {
+		return false
+	}
+	// Badges are usually images wrapped in links, which are left empty
+	// once the images are removed
+	rest := markdownImage.ReplaceAllString(line, "")
+	return strings.TrimSpace(emptyLink.ReplaceAllString(rest, "")) == ""
+}
+
+// clipDescription shortens text to at most maxLen characters at a word
+// boundary, ending it with "..."
+func clipDescription(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	cut := string(runes[:maxLen-3])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, ".,;: ") + "..."
+}
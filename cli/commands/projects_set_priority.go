@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/angelfreak/ccd/daemon/smart"
+	"github.com/spf13/cobra"
+)
+
+func NewProjectsSetPriorityFromBlockersCommand(pbURL *string) *cobra.Command {
+	var dryRun, blendCurrent bool
+
+	cmd := &cobra.Command{
+		Use:   "set-priority-from-blockers",
+		Short: "Rank active projects by open blockers and update their priorities",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPriorityFromBlockers(*pbURL, dryRun, blendCurrent)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the new priorities without updating projects")
+	cmd.Flags().BoolVar(&blendCurrent, "blend-current", false, "Average the blocker-based priority with the current one")
+
+	return cmd
+}
+
+func setPriorityFromBlockers(pbURL string, dryRun, blendCurrent bool) error {
+	projects, err := listRecords[projectRecord](pbURL, "projects", "status='active'", "")
+	if err != nil {
+		return fmt.Errorf("failed to fetch projects: %w", err)
+	}
+
+	if len(projects) == 0 {
+		fmt.Println("No active projects")
+		return nil
+	}
+
+	counts := make([]smart.ProjectBlockers, 0, len(projects))
+	blockers := make(map[string]int)
+	for _, project := range projects {
+		facts, err := listRecords[factRecord](pbURL, "extracted_facts",
+			fmt.Sprintf("project='%s' && fact_type='blocker' && stale=false", project.ID), "")
+		if err != nil {
+			return fmt.Errorf("failed to fetch blockers for %s: %w", project.Slug, err)
+		}
+		blockers[project.ID] = len(facts)
+		counts = append(counts, smart.ProjectBlockers{
+			ProjectID: project.ID,
+			Blockers:  len(facts),
+			Priority:  project.Priority,
+		})
+	}
+
+	priorities := smart.PrioritizeByBlockers(counts, blendCurrent)
+
+	// Highest new priority first, matching how the dashboard orders projects
+	sort.SliceStable(projects, func(i, j int) bool {
+		return priorities[projects[i].ID] > priorities[projects[j].ID]
+	})
+
+	fmt.Printf("%-30s %8s %8s %8s\n", "PROJECT", "BLOCKERS", "BEFORE", "AFTER")
+	changed := 0
+	for _, project := range projects {
+		priority := priorities[project.ID]
+		marker := ""
+		if priority != project.Priority {
+			marker = " *"
+			changed++
+		}
+		fmt.Printf("%-30s %8d %8d %8d%s\n", project.Slug, blockers[project.ID], project.Priority, priority, marker)
+	}
+
+	if dryRun {
+		fmt.Printf("\nWould update %d project(s)\n", changed)
+		return nil
+	}
+
+	updated := 0
+	for _, project := range projects {
+		priority := priorities[project.ID]
+		if priority == project.Priority {
+			continue
+		}
+		if err := updateRecord(pbURL, "projects", project.ID, map[string]interface{}{"priority": priority}); err != nil {
+			fmt.Printf("Warning: failed to update %s: %v\n", project.Slug, err)
+			continue
+		}
+		updated++
+	}
+
+	fmt.Printf("\n✓ Updated %d project(s)\n", updated)
+	return nil
+}
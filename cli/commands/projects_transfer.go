@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// systemFields are set by PocketBase and can't be copied to a new record
+var systemFields = []string{"id", "created", "updated", "collectionId", "collectionName", "expand"}
+
+type transferOptions struct {
+	newPBURL     string
+	concurrency  int
+	deleteSource bool
+}
+
+// transferStep copies one collection's records for a project
+type transferStep struct {
+	label      string
+	collection string
+	// remap rewrites relation fields on a copied record before it's created
+	remap func(record map[string]interface{})
+	// ids, when set, records each source ID's new ID so later steps can
+	// remap relations to it
+	ids *idMap
+}
+
+// idMap maps source record IDs to the IDs of their copies
+type idMap struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+func (m *idMap) set(source, target string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids[source] = target
+}
+
+func (m *idMap) get(source string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	target, ok := m.ids[source]
+	return target, ok
+}
+
+func NewProjectsTransferCommand(pbURL *string) *cobra.Command {
+	var opts transferOptions
+
+	cmd := &cobra.Command{
+		Use:   "transfer <project-slug>",
+		Short: "Copy a project and all its data to another PocketBase instance",
+		Long: `Copy a project with its context sections, sessions, and facts to another
+PocketBase instance. Record timestamps are assigned by the target instance.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug := args[0]
+			if opts.newPBURL == "" {
+				return fmt.Errorf("--new-pb-url is required")
+			}
+			if opts.concurrency < 1 {
+				opts.concurrency = 1
+			}
+			return transferProject(*pbURL, projectSlug, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.newPBURL, "new-pb-url", "", "URL of the PocketBase instance to copy the project to")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 4, "Number of records to write at once")
+	cmd.Flags().BoolVar(&opts.deleteSource, "delete-source", false, "Delete the project from the source once every record is copied")
+
+	return cmd
+}
+
+func transferProject(pbURL, projectSlug string, opts transferOptions) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	existing, err := listRecords[projectRecord](opts.newPBURL, "projects", fmt.Sprintf("slug='%s'", projectSlug), "")
+	if err != nil {
+		return fmt.Errorf("failed to reach target PocketBase: %w", err)
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("project %s already exists in %s", projectSlug, opts.newPBURL)
+	}
+
+	source, err := listRecords[map[string]interface{}](pbURL, "projects", fmt.Sprintf("id='%s'", project.ID), "")
+	if err != nil || len(source) == 0 {
+		return fmt.Errorf("failed to fetch project: %v", err)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := createRecord(opts.newPBURL, "projects", copyableFields(source[0]), &created); err != nil {
+		return fmt.Errorf("failed to create project in target: %w", err)
+	}
+	fmt.Printf("✓ Project: %s\n", project.Name)
+
+	setProject := func(record map[string]interface{}) { record["project"] = created.ID }
+	sessions := &idMap{ids: make(map[string]string)}
+
+	// Sessions go before facts so facts can be linked to the copied sessions
+	steps := []transferStep{
+		{label: "Context sections", collection: "context_sections", remap: setProject},
+		{label: "Sessions", collection: "session_history", remap: setProject, ids: sessions},
+		{label: "Facts", collection: "extracted_facts", remap: func(record map[string]interface{}) {
+			setProject(record)
+			if id, ok := record["session"].(string); ok && id != "" {
+				record["session"], _ = sessions.get(id)
+			}
+		}},
+	}
+
+	filter := fmt.Sprintf("project='%s'", project.ID)
+	targetFilter := fmt.Sprintf("project='%s'", created.ID)
+	complete := true
+
+	for _, step := range steps {
+		copied, err := transferRecords(pbURL, opts.newPBURL, filter, step, opts.concurrency)
+		if err != nil {
+			return fmt.Errorf("failed to transfer %s: %w (the partial copy of %s remains in the target)",
+				step.collection, err, projectSlug)
+		}
+
+		sourceCount, err := countRecords(pbURL, step.collection, filter)
+		if err != nil {
+			return fmt.Errorf("failed to count source %s: %w", step.collection, err)
+		}
+		targetCount, err := countRecords(opts.newPBURL, step.collection, targetFilter)
+		if err != nil {
+			return fmt.Errorf("failed to count target %s: %w", step.collection, err)
+		}
+
+		if sourceCount == targetCount {
+			fmt.Printf("✓ %s: %d/%d\n", step.label, targetCount, sourceCount)
+		} else {
+			complete = false
+			fmt.Printf("✗ %s: %d/%d (%d copied this run)\n", step.label, targetCount, sourceCount, copied)
+		}
+	}
+
+	if !complete {
+		return fmt.Errorf("transfer incomplete: record counts differ, source left untouched")
+	}
+
+	fmt.Printf("\n✓ Transferred %s to %s\n", projectSlug, opts.newPBURL)
+
+	if opts.deleteSource {
+		// Deleting the project cascades to its sections, sessions, and facts
+		if err := deleteRecord(pbURL, "projects", project.ID); err != nil {
+			return fmt.Errorf("failed to delete source project: %w", err)
+		}
+		fmt.Printf("🗑️  Deleted %s from %s\n", projectSlug, pbURL)
+	}
+
+	return nil
+}
+
+// transferRecords copies one step's records, reading pages in the background
+// while up to concurrency writes run at once. It returns how many records
+// were created.
+func transferRecords(sourceURL, targetURL, filter string, step transferStep, concurrency int) (int, error) {
+	records := make(chan map[string]interface{}, pbPageSize)
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(records)
+		readErr <- eachPage(sourceURL, step.collection, filter, "created", nil, func(page []map[string]interface{}) error {
+			for _, record := range page {
+				records <- record
+			}
+			return nil
+		})
+	}()
+
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		copied   int
+		writeErr error
+	)
+
+	for record := range records {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(record map[string]interface{}) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			data := copyableFields(record)
+			step.remap(data)
+
+			var created struct {
+				ID string `json:"id"`
+			}
+			err := createRecord(targetURL, step.collection, data, &created)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if writeErr == nil {
+					writeErr = err
+				}
+				return
+			}
+			copied++
+			if step.ids != nil {
+				if id, ok := record["id"].(string); ok {
+					step.ids.set(id, created.ID)
+				}
+			}
+		}(record)
+	}
+	wg.Wait()
+
+	if err := <-readErr; err != nil {
+		return copied, err
+	}
+	return copied, writeErr
+}
+
+// copyableFields returns a copy of record without PocketBase's system fields
+func copyableFields(record map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		data[key] = value
+	}
+	for _, field := range systemFields {
+		delete(data, field)
+	}
+	return data
+}
@@ -4,42 +4,220 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+type pullOptions struct {
+	tokenReport bool
+	// trims maps lowercased section titles to their token limits
+	trims map[string]int
+	// splitBy is "section" to write each section to its own file in
+	// splitDir, or "" for a single file
+	splitBy  string
+	splitDir string
+	// includeHandoffs appends this many of the latest handoffs, using at
+	// most handoffsBudget tokens (0 = unlimited)
+	includeHandoffs int
+	handoffsBudget  int
+	// mergeStrategy reconciles local edits with remote changes: "lww",
+	// "ours", or "3way", using conflict markers in markerStyle
+	mergeStrategy string
+	markerStyle   string
+}
+
 func NewPullCommand(pbURL *string) *cobra.Command {
-	var output string
+	var (
+		output string
+		trims  []string
+		opts   pullOptions
+	)
 
 	cmd := &cobra.Command{
-		Use:   "pull <project-slug>",
+		Use:   "pull [project-slug]",
 		Short: "Pull project context and write to CLAUDE.md",
-		Args:  cobra.ExactArgs(1),
+		Long: `Pull a project's context from PocketBase and write it to CLAUDE.md.
+
+Each pull is recorded in .ccd-state next to the file. When CLAUDE.md has
+been edited since the last pull, --merge-strategy decides what happens:
+
+  lww   last write wins: the remote context replaces the file (default)
+  ours  keep the local file as it is
+  3way  merge by section against the last pull: sections changed on one side
+        take that change, and sections changed on both are merged line by
+        line, with conflict markers where the same lines differ or around
+        a section deleted on one side and edited on the other
+
+The file starts with a <!-- ccd:project <slug> --> comment. Commands that
+take a project slug default to the one in the nearest such CLAUDE.md, so
+the slug can be left off inside a pulled repo: cct pull alone refreshes it.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			projectSlug := args[0]
-			return pullContext(*pbURL, projectSlug, output)
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			if opts.splitBy != "" && opts.splitBy != "section" {
+				return fmt.Errorf("invalid --split-by %q: only \"section\" is supported", opts.splitBy)
+			}
+			limits, err := parseTrimSections(trims)
+			if err != nil {
+				return err
+			}
+			opts.trims = limits
+			if opts.includeHandoffs < 0 || opts.handoffsBudget < 0 {
+				return fmt.Errorf("--include-handoffs and --handoffs-token-budget can't be negative")
+			}
+			switch opts.mergeStrategy {
+			case "lww", "ours", "3way":
+			default:
+				return fmt.Errorf("unknown merge strategy %q: expected lww, ours, or 3way", opts.mergeStrategy)
+			}
+			if opts.markerStyle != "git" && opts.markerStyle != "diff3" {
+				return fmt.Errorf("unknown conflict marker style %q: expected git or diff3", opts.markerStyle)
+			}
+			if opts.splitBy != "" && opts.mergeStrategy != "lww" {
+				return fmt.Errorf("--merge-strategy %s can't be used with --split-by", opts.mergeStrategy)
+			}
+			return pullContext(*pbURL, projectSlug, output, opts)
 		},
 	}
 
 	cmd.Flags().StringVarP(&output, "output", "o", "CLAUDE.md", "Output file")
+	cmd.Flags().BoolVar(&opts.tokenReport, "token-report", false, "Print each section's estimated token usage after writing")
+	cmd.Flags().StringArrayVar(&trims, "trim-section", nil, "Truncate a section to a token limit, as \"<title>=<max-tokens>\" (repeatable)")
+	cmd.Flags().StringVar(&opts.splitBy, "split-by", "", "Write each section to its own file (\"section\"), with the output file as an index")
+	cmd.Flags().StringVar(&opts.splitDir, "split-dir", "context", "With --split-by, directory for the section files, relative to the output file")
+	cmd.Flags().IntVar(&opts.includeHandoffs, "include-handoffs", 0, "Append the latest N handoff documents from the repo as \"## Handoff: <session-id>\" sections")
+	cmd.Flags().IntVar(&opts.handoffsBudget, "handoffs-token-budget", 2000, "With --include-handoffs, the most tokens the handoffs may use in total (0 = unlimited)")
+	cmd.Flags().StringVar(&opts.mergeStrategy, "merge-strategy", "lww", "How to handle local edits since the last pull: lww, ours, or 3way")
+	cmd.Flags().StringVar(&opts.markerStyle, "conflict-marker-style", "git", "Conflict markers for 3way merges: git, or diff3 to include the base")
 
 	return cmd
 }
 
-func pullContext(pbURL, projectSlug, output string) error {
+func pullContext(pbURL, projectSlug, output string, opts pullOptions) error {
+	markdown, _, err := buildContext(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+	markdown = projectMarker(projectSlug) + markdown
+
+	chunks := splitContextSections(markdown)
+	if opts.includeHandoffs > 0 {
+		handoffs, err := handoffChunks(pbURL, projectSlug, opts.includeHandoffs, opts.handoffsBudget)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, handoffs...)
+	}
+	trimmed, err := trimContextSections(chunks, opts.trims)
+	if err != nil {
+		return err
+	}
+
+	if opts.splitBy == "section" {
+		if err := writeSplitContext(pbURL, projectSlug, output, opts.splitDir, chunks); err != nil {
+			return err
+		}
+	} else if err := writePulledContext(projectSlug, output, joinContextSections(chunks), opts); err != nil {
+		return err
+	}
+
+	for _, title := range trimmed {
+		fmt.Printf("✂️  Truncated %s to %d tokens\n", title, opts.trims[strings.ToLower(title)])
+	}
+
+	if opts.tokenReport {
+		fmt.Println()
+		printTokenReport(chunks)
+	}
+	return nil
+}
+
+// writePulledContext writes remote to output, reconciling it with any local
+// edits, and records the pull in .ccd-state
+func writePulledContext(projectSlug, output, remote string, opts pullOptions) error {
+	state, err := loadState(output)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(output)
+	var last *pulledFile
+	if pulled, ok := state.Files[name]; ok {
+		last = &pulled
+	}
+
+	content, merge, err := reconcileContext(output, remote, last, opts.mergeStrategy, opts.markerStyle)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	state.Files[name] = pulledFile{
+		Project:  projectSlug,
+		PulledAt: time.Now(),
+		Hash:     snapshotHash(remote),
+		Base:     remote,
+		Merge:    merge,
+	}
+	if err := saveState(output, state); err != nil {
+		return err
+	}
+
+	switch {
+	case merge == nil:
+		fmt.Printf("✓ Context written to %s\n", output)
+	case merge.Strategy == "lww":
+		fmt.Printf("✓ Context written to %s (local edits replaced)\n", output)
+	case merge.Strategy == "ours":
+		fmt.Printf("✓ Kept local edits to %s (remote context not applied)\n", output)
+	case len(merge.Conflicts) > 0:
+		fmt.Printf("⚠️  Merged into %s with conflicts in: %s\n", output, strings.Join(merge.Conflicts, ", "))
+		fmt.Println("   Resolve the <<<<<<< markers, then push the result")
+	default:
+		fmt.Printf("✓ Merged local edits and remote changes into %s\n", output)
+	}
+	return nil
+}
+
+// writeContext renders a project's context to output and returns the number
+// of sections written
+func writeContext(pbURL, projectSlug, output string) (int, error) {
+	markdown, count, err := buildContext(pbURL, projectSlug)
+	if err != nil {
+		return 0, err
+	}
+
+	// Write to file
+	if err := os.WriteFile(output, []byte(projectMarker(projectSlug)+markdown), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return count, nil
+}
+
+// buildContext generates a project's CLAUDE.md and returns it with the number
+// of context sections it includes
+func buildContext(pbURL, projectSlug string) (string, int, error) {
 	// Get project by slug
 	url := fmt.Sprintf("%s/api/collections/projects/records?filter=slug='%s'", pbURL, projectSlug)
-	resp, err := http.Get(url)
+	resp, err := httpGet(url)
 	if err != nil {
-		return fmt.Errorf("failed to fetch project: %w", err)
+		return "", 0, fmt.Errorf("failed to fetch project: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 
 	var result struct {
@@ -56,26 +234,26 @@ func pullContext(pbURL, projectSlug, output string) error {
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return err
+		return "", 0, err
 	}
 
 	if len(result.Items) == 0 {
-		return fmt.Errorf("project not found: %s", projectSlug)
+		return "", 0, fmt.Errorf("project not found: %s", projectSlug)
 	}
 
 	project := result.Items[0]
 
 	// Get context sections
 	url = fmt.Sprintf("%s/api/collections/context_sections/records?filter=project='%s'&sort=order", pbURL, project.ID)
-	resp, err = http.Get(url)
+	resp, err = httpGet(url)
 	if err != nil {
-		return fmt.Errorf("failed to fetch context sections: %w", err)
+		return "", 0, fmt.Errorf("failed to fetch context sections: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 
 	var sections struct {
@@ -86,7 +264,7 @@ func pullContext(pbURL, projectSlug, output string) error {
 	}
 
 	if err := json.Unmarshal(body, &sections); err != nil {
-		return err
+		return "", 0, err
 	}
 
 	// Generate markdown
@@ -111,13 +289,7 @@ func pullContext(pbURL, projectSlug, output string) error {
 		markdown += fmt.Sprintf("## %s\n\n%s\n\n", section.Title, section.Content)
 	}
 
-	// Write to file
-	if err := os.WriteFile(output, []byte(markdown), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	fmt.Printf("✓ Context written to %s\n", output)
-	return nil
+	return markdown, len(sections.Items), nil
 }
 
 func joinStrings(strs []string, sep string) string {
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+)
+
+// handoffTitlePrefix starts the titles of handoff sections added by
+// pull --include-handoffs; context push leaves them out
+const handoffTitlePrefix = "Handoff: "
+
+// handoffChunks renders the latest n handoffs in the project's repo as
+// "## Handoff: <session-id>" sections, newest first. With a budget, the
+// handoffs together use at most about that many tokens; the one that
+// crosses it is truncated and older ones are left out.
+func handoffChunks(pbURL, projectSlug string, n, budget int) ([]contextChunk, error) {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	if project.RepoPath == "" {
+		fmt.Printf("Warning: %s has no repo path; no handoffs included\n", projectSlug)
+		return nil, nil
+	}
+
+	handoffs, err := ledger.ListHandoffsIn(ledger.HandoffDirFor(project.RepoPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list handoffs: %w", err)
+	}
+	if len(handoffs) > n {
+		handoffs = handoffs[:n]
+	}
+
+	var chunks []contextChunk
+	used := 0
+	for _, handoff := range handoffs {
+		body, err := handoffBody(handoff.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read handoff: %w", err)
+		}
+
+		title := handoffTitlePrefix + handoff.SessionID
+		text := fmt.Sprintf("## %s\n\n%s\n\n", title, body)
+
+		if budget > 0 {
+			remaining := budget - used
+			if remaining <= 0 {
+				break
+			}
+			if estimateTokens(text) > remaining {
+				text = truncateToTokens(text, remaining)
+			}
+			used += estimateTokens(text)
+		}
+
+		chunks = append(chunks, contextChunk{Title: title, Text: text})
+	}
+	return chunks, nil
+}
+
+// handoffBody returns a handoff document without its frontmatter and title,
+// with its sections demoted a level so they nest under the handoff's section
+func handoffBody(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	content := string(data)
+	if strings.HasPrefix(content, "---\n") {
+		if end := strings.Index(content[4:], "\n---\n"); end >= 0 {
+			content = content[4+end+5:]
+		}
+	}
+
+	var lines []string
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		switch {
+		case inCode:
+		case strings.HasPrefix(line, "# "):
+			continue
+		case strings.HasPrefix(line, "## "):
+			line = "#" + line
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
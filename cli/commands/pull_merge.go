@@ -0,0 +1,298 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stateFileName is the file, next to each pulled CLAUDE.md, that records
+// what was last pulled so later pulls can tell local edits from remote ones
+const stateFileName = ".ccd-state"
+
+// ccdState is the contents of a .ccd-state file, keyed by the base name of
+// each file pulled into its directory
+type ccdState struct {
+	Files map[string]pulledFile `json:"files"`
+}
+
+// pulledFile is the last pull of one file. Base is the content as pulled,
+// the common ancestor for a three-way merge with the next pull.
+type pulledFile struct {
+	Project  string       `json:"project"`
+	PulledAt time.Time    `json:"pulled_at"`
+	Hash     string       `json:"hash"`
+	Base     string       `json:"base"`
+	Merge    *mergeRecord `json:"merge,omitempty"`
+}
+
+// mergeRecord notes how local and remote changes were reconciled in a pull.
+// Conflicts lists the sections left with conflict markers to resolve.
+type mergeRecord struct {
+	Strategy   string    `json:"strategy"`
+	ResolvedAt time.Time `json:"resolved_at"`
+	Conflicts  []string  `json:"conflicts,omitempty"`
+}
+
+func statePath(output string) string {
+	return filepath.Join(filepath.Dir(output), stateFileName)
+}
+
+// loadState reads the .ccd-state file for output, or an empty state when
+// there isn't one yet
+func loadState(output string) (ccdState, error) {
+	state := ccdState{Files: make(map[string]pulledFile)}
+	data, err := os.ReadFile(statePath(output))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read %s: %w", stateFileName, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse %s: %w", stateFileName, err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]pulledFile)
+	}
+	return state, nil
+}
+
+func saveState(output string, state ccdState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(statePath(output), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", stateFileName, err)
+	}
+	return nil
+}
+
+// reconcileContext decides what to write to output given the freshly pulled
+// remote content. Local edits made since the last pull are kept or merged
+// according to strategy; with "lww", or when the file is untouched, remote
+// is used as is. The returned record is nil when there was nothing to
+// reconcile.
+func reconcileContext(output, remote string, last *pulledFile, strategy, markerStyle string) (string, *mergeRecord, error) {
+	data, err := os.ReadFile(output)
+	if errors.Is(err, os.ErrNotExist) {
+		return remote, nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", output, err)
+	}
+	local := string(data)
+
+	// Without a recorded base, local edits can't be told apart from remote
+	// ones, so only last-write-wins can proceed safely
+	if last == nil {
+		if local == remote || strategy == "lww" {
+			return remote, nil, nil
+		}
+		if strategy == "ours" {
+			return local, &mergeRecord{Strategy: strategy, ResolvedAt: time.Now()}, nil
+		}
+		return "", nil, fmt.Errorf("no merge base recorded for %s in %s: pull once with --merge-strategy lww or ours first", output, stateFileName)
+	}
+
+	localChanged := local != last.Base
+	remoteChanged := remote != last.Base
+	if !localChanged || local == remote {
+		return remote, nil, nil
+	}
+
+	record := &mergeRecord{Strategy: strategy, ResolvedAt: time.Now()}
+	switch strategy {
+	case "lww":
+		return remote, record, nil
+	case "ours":
+		return local, record, nil
+	}
+
+	if !remoteChanged {
+		return local, record, nil
+	}
+	merged, conflicts := mergeContextSections(last.Base, local, remote, markerStyle)
+	record.Conflicts = conflicts
+	return merged, record, nil
+}
+
+// mergeContextSections three-way merges CLAUDE.md by "## " section. Sections
+// changed on one side take that side's version, and sections changed on both
+// are merged line by line. A section deleted on one side but edited on the
+// other is a conflict, left whole between markers. It returns the merged
+// markdown and the titles of sections left with conflict markers.
+func mergeContextSections(base, local, remote, markerStyle string) (string, []string) {
+	index := func(chunks []contextChunk) map[string]string {
+		byTitle := make(map[string]string)
+		for _, chunk := range chunks {
+			byTitle[strings.ToLower(chunk.Title)] = chunk.Text
+		}
+		return byTitle
+	}
+	localChunks := splitContextSections(local)
+	remoteChunks := splitContextSections(remote)
+	baseByTitle := index(splitContextSections(base))
+	localByTitle := index(localChunks)
+	remoteByTitle := index(remoteChunks)
+
+	var merged []contextChunk
+	var conflicts []string
+	conflict := func(title string) {
+		if title == "" {
+			title = "(preamble)"
+		}
+		conflicts = append(conflicts, title)
+	}
+	merge := func(title string) {
+		key := strings.ToLower(title)
+		b, inBase := baseByTitle[key]
+		l, inLocal := localByTitle[key]
+		r, inRemote := remoteByTitle[key]
+
+		switch {
+		case inLocal && inRemote:
+			text, conflicted := mergeLines(b, l, r, markerStyle)
+			if conflicted {
+				conflict(title)
+			}
+			merged = append(merged, contextChunk{Title: title, Text: text})
+		case !inBase:
+			// Added on one side only
+			text := l
+			if !inLocal {
+				text = r
+			}
+			merged = append(merged, contextChunk{Title: title, Text: text})
+		case (!inRemote && l == b) || (!inLocal && r == b):
+			// Deleted on one side and untouched on the other
+		default:
+			// Deleted on one side but edited on the other
+			conflict(title)
+			lines := conflictLines(splitLines(b), splitLines(l), splitLines(r), markerStyle)
+			merged = append(merged, contextChunk{Title: title, Text: strings.Join(lines, "\n") + "\n"})
+		}
+	}
+
+	// Remote's section order wins; sections only in the local file follow
+	seen := make(map[string]bool)
+	for _, chunk := range remoteChunks {
+		seen[strings.ToLower(chunk.Title)] = true
+		merge(chunk.Title)
+	}
+	for _, chunk := range localChunks {
+		if !seen[strings.ToLower(chunk.Title)] {
+			merge(chunk.Title)
+		}
+	}
+
+	return joinContextSections(merged), conflicts
+}
+
+// mergeLines three-way merges text line by line in the manner of diff3.
+// Regions changed on only one side take that change, lines inserted at the
+// same place on both sides are all kept, and regions changed differently on
+// both sides become conflicts, marked git style or, with "diff3", also
+// showing the base.
+func mergeLines(base, local, remote, markerStyle string) (string, bool) {
+	switch {
+	case local == remote || remote == base:
+		return local, false
+	case local == base:
+		return remote, false
+	}
+
+	o, a, b := splitLines(base), splitLines(local), splitLines(remote)
+	matchA, matchB := lineMatches(o, a), lineMatches(o, b)
+
+	var out []string
+	conflicted := false
+	i, ia, ib := 0, 0, 0
+	for i < len(o) || ia < len(a) || ib < len(b) {
+		if i < len(o) && matchA[i] == ia && matchB[i] == ib {
+			out = append(out, o[i])
+			i, ia, ib = i+1, ia+1, ib+1
+			continue
+		}
+
+		// The unstable region runs to the next base line both sides kept
+		j, endA, endB := i, len(a), len(b)
+		for ; j < len(o); j++ {
+			if matchA[j] >= 0 && matchB[j] >= 0 {
+				endA, endB = matchA[j], matchB[j]
+				break
+			}
+		}
+
+		chunkO, chunkA, chunkB := o[i:j], a[ia:endA], b[ib:endB]
+		switch {
+		case equalLines(chunkA, chunkO):
+			out = append(out, chunkB...)
+		case equalLines(chunkB, chunkO), equalLines(chunkA, chunkB):
+			out = append(out, chunkA...)
+		case len(chunkO) == 0:
+			out = append(append(out, chunkA...), chunkB...)
+		default:
+			conflicted = true
+			out = append(out, conflictLines(chunkO, chunkA, chunkB, markerStyle)...)
+		}
+		i, ia, ib = j, endA, endB
+	}
+
+	merged := strings.Join(out, "\n")
+	if len(out) > 0 {
+		merged += "\n"
+	}
+	return merged, conflicted
+}
+
+// conflictLines marks a region changed differently on both sides, git style
+// or, with "diff3", also showing the base
+func conflictLines(base, local, remote []string, markerStyle string) []string {
+	out := append([]string{"<<<<<<< local"}, local...)
+	if markerStyle == "diff3" {
+		out = append(out, "||||||| base")
+		out = append(out, base...)
+	}
+	out = append(out, "=======")
+	out = append(out, remote...)
+	return append(out, ">>>>>>> remote")
+}
+
+// lineMatches maps each line of a to the line of b it's paired with in their
+// longest common subsequence, or -1
+func lineMatches(a, b []string) []int {
+	matches := make([]int, len(a))
+	i, j := 0, 0
+	for _, op := range diffLines(a, b) {
+		switch op.kind {
+		case ' ':
+			matches[i] = j
+			i++
+			j++
+		case '-':
+			matches[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+	return matches
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name                string
+		base, local, remote string
+		markerStyle         string
+		want                string
+		conflicted          bool
+	}{
+		{
+			name: "local change only",
+			base: "a\nb\nc\n", local: "a\nB\nc\n", remote: "a\nb\nc\n",
+			want: "a\nB\nc\n",
+		},
+		{
+			name: "each side changes a different line",
+			base: "a\nb\nc\n", local: "A\nb\nc\n", remote: "a\nb\nC\n",
+			want: "A\nb\nC\n",
+		},
+		{
+			name: "same change on both sides",
+			base: "a\nb\nc\nd\ne\n", local: "a\nB\nc\nD\ne\n", remote: "a\nB\nc\nd\ne\n",
+			want: "a\nB\nc\nD\ne\n",
+		},
+		{
+			name: "insertions at the same place",
+			base: "a\nb\n", local: "a\nx\nb\n", remote: "a\ny\nb\n",
+			want: "a\nx\ny\nb\n",
+		},
+		{
+			name: "conflict, git markers",
+			base: "a\nb\nc\n", local: "a\nL\nc\n", remote: "a\nR\nc\n", markerStyle: "git",
+			want:       "a\n<<<<<<< local\nL\n=======\nR\n>>>>>>> remote\nc\n",
+			conflicted: true,
+		},
+		{
+			name: "conflict, diff3 markers",
+			base: "a\nb\nc\n", local: "a\nL\nc\n", remote: "a\nR\nc\n", markerStyle: "diff3",
+			want:       "a\n<<<<<<< local\nL\n||||||| base\nb\n=======\nR\n>>>>>>> remote\nc\n",
+			conflicted: true,
+		},
+	}
+	for _, tt := range tests {
+		got, conflicted := mergeLines(tt.base, tt.local, tt.remote, tt.markerStyle)
+		if got != tt.want || conflicted != tt.conflicted {
+			t.Errorf("%s: merged %q (conflicted %v), want %q (conflicted %v)", tt.name, got, conflicted, tt.want, tt.conflicted)
+		}
+	}
+}
+
+func TestMergeContextSections(t *testing.T) {
+	base := "# App\n\n## Notes\nold\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n"
+
+	tests := []struct {
+		name          string
+		local, remote string
+		want          string
+		conflicts     []string
+	}{
+		{
+			name:   "deleted remotely, untouched locally",
+			local:  base,
+			remote: "# App\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n- Add a health check\n",
+			want:   "# App\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n- Add a health check\n",
+		},
+		{
+			name:   "deleted remotely, edited locally",
+			local:  "# App\n\n## Notes\nnew\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n",
+			remote: "# App\n\n## Decisions\n- Use Postgres 16\n\n## Todo\n- Add migrations\n",
+			// Sections left only in the local file follow the remote ones
+			want: "# App\n\n## Decisions\n- Use Postgres 16\n\n## Todo\n- Add migrations\n" +
+				"<<<<<<< local\n## Notes\nnew\n\n=======\n>>>>>>> remote\n",
+			conflicts: []string{"Notes"},
+		},
+		{
+			name:   "deleted locally, edited remotely",
+			local:  "# App\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n- Write docs\n",
+			remote: "# App\n\n## Notes\nnewer\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n",
+			want: "# App\n\n<<<<<<< local\n=======\n## Notes\nnewer\n\n>>>>>>> remote\n" +
+				"## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n- Write docs\n",
+			conflicts: []string{"Notes"},
+		},
+		{
+			name:   "sections added on each side",
+			local:  base + "\n## Local\nmine\n",
+			remote: "# App\n\n## Remote\ntheirs\n\n" + strings.TrimPrefix(base, "# App\n\n"),
+			want:   "# App\n\n## Remote\ntheirs\n\n## Notes\nold\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n\n## Local\nmine\n",
+		},
+	}
+	for _, tt := range tests {
+		got, conflicts := mergeContextSections(base, tt.local, tt.remote, "git")
+		if got != tt.want {
+			t.Errorf("%s: merged\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(conflicts, tt.conflicts) {
+			t.Errorf("%s: conflicts %q, want %q", tt.name, conflicts, tt.conflicts)
+		}
+	}
+}
+
+func TestPullMergeStateRoundTrip(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "CLAUDE.md")
+	opts := pullOptions{mergeStrategy: "3way", markerStyle: "git"}
+	pull := func(remote string) {
+		t.Helper()
+		captureStdout(t, func() {
+			if err := writePulledContext("app", output, remote, opts); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	first := "# App\n\n## Decisions\n- Use Postgres\n\n## Todo\n- Add migrations\n"
+	pull(first)
+	state, err := loadState(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Files["CLAUDE.md"]; got.Base != first || got.Project != "app" || got.Merge != nil {
+		t.Fatalf("first pull recorded %+v, want the pulled content as base", got)
+	}
+
+	// Edit locally, then pull a remote change to another section
+	local := strings.Replace(first, "- Add migrations\n", "- Add migrations\n- Write docs\n", 1)
+	if err := os.WriteFile(output, []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	second := strings.Replace(first, "- Use Postgres\n", "- Use Postgres 16\n", 1)
+	pull(second)
+
+	want := "# App\n\n## Decisions\n- Use Postgres 16\n\n## Todo\n- Add migrations\n- Write docs\n"
+	if got := read(); got != want {
+		t.Errorf("merged file:\n%s\nwant:\n%s", got, want)
+	}
+	state, err = loadState(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pulled := state.Files["CLAUDE.md"]
+	if pulled.Base != second {
+		t.Errorf("base after the merge = %q, want the remote content %q", pulled.Base, second)
+	}
+	if pulled.Merge == nil || pulled.Merge.Strategy != "3way" || len(pulled.Merge.Conflicts) != 0 {
+		t.Errorf("merge record = %+v, want a clean 3way merge", pulled.Merge)
+	}
+
+	// A conflicting edit is recorded by section
+	if err := os.WriteFile(output, []byte(strings.Replace(read(), "Postgres 16", "SQLite", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pull(strings.Replace(second, "Postgres 16", "Postgres 17", 1))
+	if got := read(); !strings.Contains(got, "<<<<<<< local\n- Use SQLite\n=======\n- Use Postgres 17\n>>>>>>> remote\n") {
+		t.Errorf("conflicting pull wrote:\n%s", got)
+	}
+	state, err = loadState(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merge := state.Files["CLAUDE.md"].Merge; merge == nil || !reflect.DeepEqual(merge.Conflicts, []string{"Decisions"}) {
+		t.Errorf("merge record = %+v, want a conflict in Decisions", merge)
+	}
+}
+
+func TestReconcileContextWithoutBase(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "CLAUDE.md")
+	if err := os.WriteFile(output, []byte("local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := reconcileContext(output, "remote\n", nil, "3way", "git"); err == nil || !strings.Contains(err.Error(), "no merge base recorded") {
+		t.Errorf("3way without a base returned %v", err)
+	}
+	if got, _, err := reconcileContext(output, "remote\n", nil, "ours", "git"); err != nil || got != "local\n" {
+		t.Errorf("ours without a base = %q, %v; want the local file", got, err)
+	}
+	if got, _, err := reconcileContext(output, "remote\n", nil, "lww", "git"); err != nil || got != "remote\n" {
+		t.Errorf("lww without a base = %q, %v; want the remote content", got, err)
+	}
+}
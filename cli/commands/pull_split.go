@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// factsFileName holds the project's active facts when context is split
+const factsFileName = "facts.md"
+
+// writeSplitContext writes each context section to its own file in splitDir
+// (relative to output) along with the project's active facts, and writes
+// output as an index linking them. Project Info stays in the index.
+func writeSplitContext(pbURL, projectSlug, output, splitDir string, chunks []contextChunk) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(filepath.Dir(output), splitDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var index strings.Builder
+	var links []string
+	used := make(map[string]bool)
+	written := 0
+
+	for _, chunk := range chunks {
+		if chunk.Title == "" || strings.EqualFold(chunk.Title, projectInfoTitle) {
+			index.WriteString(chunk.Text)
+			continue
+		}
+
+		name := sectionFileName(chunk.Title, used)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.TrimRight(chunk.Text, "\n")+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		links = append(links, fmt.Sprintf("- [%s](%s)", chunk.Title, filepath.ToSlash(filepath.Join(splitDir, name))))
+		written++
+	}
+
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s' && stale=false", project.ID), "-verified,-importance")
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+	if len(facts) > 0 {
+		if err := os.WriteFile(filepath.Join(dir, factsFileName), []byte(renderFactsFile(facts)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", factsFileName, err)
+		}
+		links = append(links, fmt.Sprintf("- [Facts](%s)", filepath.ToSlash(filepath.Join(splitDir, factsFileName))))
+		written++
+	}
+
+	index.WriteString("## Context Files\n\n")
+	index.WriteString(strings.Join(links, "\n") + "\n")
+
+	if err := os.WriteFile(output, []byte(index.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	fmt.Printf("✓ Context written to %s with %d file(s) in %s\n", output, written, dir)
+	return nil
+}
+
+// sectionFileName derives a unique markdown file name from a section title
+func sectionFileName(title string, used map[string]bool) string {
+	base := anchorSlug(title)
+	if base == "" {
+		base = "section"
+	}
+	if strings.TrimSuffix(factsFileName, ".md") == base {
+		base += "-section"
+	}
+
+	name := base + ".md"
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s-%d.md", base, n)
+	}
+	used[name] = true
+	return name
+}
+
+// renderFactsFile lists facts grouped by type, verified and then most
+// important first
+func renderFactsFile(facts []factRecord) string {
+	byType := make(map[string][]factRecord)
+	for _, fact := range facts {
+		byType[fact.FactType] = append(byType[fact.FactType], fact)
+	}
+
+	types := make([]string, 0, len(byType))
+	for factType := range byType {
+		types = append(types, factType)
+	}
+	sort.Strings(types)
+
+	var out strings.Builder
+	out.WriteString("## Facts\n")
+	for _, factType := range types {
+		fmt.Fprintf(&out, "\n### %s\n\n", factType)
+		for _, fact := range byType[factType] {
+			if fact.Verified {
+				fmt.Fprintf(&out, "- %s (importance: %d, verified)\n", fact.Content, fact.Importance)
+			} else {
+				fmt.Fprintf(&out, "- %s (importance: %d)\n", fact.Content, fact.Importance)
+			}
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestPullSplitBySection(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "name": "App", "status": "active", "priority": 3.0})
+	pb.add("context_sections",
+		map[string]interface{}{"id": "c1", "project": "p1", "title": "Architecture", "content": "A Go daemon and a CLI.", "order": 1.0},
+		map[string]interface{}{"id": "c2", "project": "p1", "title": "Key Decisions", "content": "Postgres for storage.", "order": 2.0},
+		map[string]interface{}{"id": "c3", "project": "p1", "title": "Facts", "content": "Hand-written facts.", "order": 3.0},
+	)
+	pb.add("extracted_facts",
+		map[string]interface{}{"id": "f1", "project": "p1", "fact_type": "decision", "content": "Use Postgres", "importance": 4.0},
+		map[string]interface{}{"id": "f2", "project": "p1", "fact_type": "blocker", "content": "CI is red on main", "importance": 5.0},
+	)
+
+	output := filepath.Join(t.TempDir(), "CLAUDE.md")
+	captureStdout(t, func() {
+		if err := pullContext(pb.URL, "app", output, pullOptions{splitBy: "section", splitDir: "context", mergeStrategy: "lww"}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	dir := filepath.Join(filepath.Dir(output), "context")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	if want := []string{"architecture.md", "facts-section.md", "facts.md", "key-decisions.md"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("split files = %v, want %v", names, want)
+	}
+
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if got, want := read(filepath.Join(dir, "architecture.md")), "## Architecture\n\nA Go daemon and a CLI.\n"; got != want {
+		t.Errorf("architecture.md = %q, want %q", got, want)
+	}
+	if got, want := read(filepath.Join(dir, "facts.md")), "## Facts\n\n### blocker\n\n- CI is red on main (importance: 5)\n\n### decision\n\n- Use Postgres (importance: 4)\n"; got != want {
+		t.Errorf("facts.md = %q, want %q", got, want)
+	}
+
+	// The index keeps the project info and links every file
+	index := read(output)
+	for _, want := range []string{
+		"<!-- ccd:project app -->",
+		"## Project Info\n",
+		"## Context Files\n\n- [Architecture](context/architecture.md)\n- [Key Decisions](context/key-decisions.md)\n- [Facts](context/facts-section.md)\n- [Facts](context/facts.md)\n",
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index lacks %q:\n%s", want, index)
+		}
+	}
+	if strings.Contains(index, "A Go daemon") {
+		t.Errorf("index holds section content:\n%s", index)
+	}
+}
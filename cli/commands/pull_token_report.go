@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	ansiYellow = "\033[33m"
+	ansiRed    = "\033[31m"
+
+	// Sections over these sizes are flagged in the token report
+	tokenWarnThreshold = 5000
+	tokenOverThreshold = 10000
+)
+
+// contextChunk is a "## " section of generated context, heading included.
+// The chunk before the first section has no title.
+type contextChunk struct {
+	Title string
+	Text  string
+}
+
+// parseTrimSections parses "<title>=<max-tokens>" values into limits keyed
+// by lowercased title
+func parseTrimSections(values []string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, value := range values {
+		i := strings.LastIndex(value, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid --trim-section %q: expected <title>=<max-tokens>", value)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value[i+1:]))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid --trim-section %q: max tokens must be a positive number", value)
+		}
+		limits[strings.ToLower(strings.TrimSpace(value[:i]))] = limit
+	}
+	return limits, nil
+}
+
+// splitContextSections splits markdown at its "## " headings, keeping every
+// line so the chunks join back to the original
+func splitContextSections(markdown string) []contextChunk {
+	chunks := []contextChunk{{}}
+	inCode := false
+
+	for _, line := range strings.SplitAfter(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if m := sectionHeading.FindStringSubmatch(strings.TrimRight(line, "\n")); m != nil && !inCode {
+			chunks = append(chunks, contextChunk{Title: strings.TrimSpace(m[1])})
+		}
+		chunks[len(chunks)-1].Text += line
+	}
+
+	return chunks
+}
+
+func joinContextSections(chunks []contextChunk) string {
+	var out strings.Builder
+	for _, chunk := range chunks {
+		out.WriteString(chunk.Text)
+	}
+	return out.String()
+}
+
+// trimContextSections truncates the sections named in limits in place and
+// returns the titles of those it shortened
+func trimContextSections(chunks []contextChunk, limits map[string]int) ([]string, error) {
+	found := make(map[string]bool)
+	var trimmed []string
+
+	for i, chunk := range chunks {
+		limit, ok := limits[strings.ToLower(chunk.Title)]
+		if chunk.Title == "" || !ok {
+			continue
+		}
+		found[strings.ToLower(chunk.Title)] = true
+
+		if estimateTokens(chunk.Text) <= limit {
+			continue
+		}
+		chunks[i].Text = truncateToTokens(chunk.Text, limit)
+		trimmed = append(trimmed, chunk.Title)
+	}
+
+	for title := range limits {
+		if !found[title] {
+			return nil, fmt.Errorf("section not found: %s", title)
+		}
+	}
+	return trimmed, nil
+}
+
+// truncateToTokens cuts text to about limit tokens at a line or word
+// boundary and marks the cut
+func truncateToTokens(text string, limit int) string {
+	marker := fmt.Sprintf("\n[... truncated to %d tokens]\n\n", limit)
+	budget := limit*charsPerToken - len(marker)
+	if budget < 0 {
+		budget = 0
+	}
+	cut := text[:budget]
+
+	// Prefer ending on a whole line, then a whole word
+	if i := strings.LastIndex(cut, "\n"); i > budget/2 {
+		cut = cut[:i]
+	} else if i := strings.LastIndexAny(cut, " \t"); i > 0 {
+		cut = cut[:i]
+	}
+	// Don't leave a code block open past the marker
+	if strings.Count(cut, "```")%2 == 1 {
+		cut += "\n```"
+	}
+
+	return strings.TrimRight(cut, " \t\n") + "\n" + marker
+}
+
+func estimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+func printTokenReport(chunks []contextChunk) {
+	total := 0
+	for _, chunk := range chunks {
+		total += estimateTokens(chunk.Text)
+	}
+	if total == 0 {
+		return
+	}
+
+	color := isTerminal(os.Stdout)
+
+	fmt.Printf("%-32s %8s %8s  %s\n", "SECTION", "TOKENS", "% TOTAL", "STATUS")
+	for _, chunk := range chunks {
+		tokens := estimateTokens(chunk.Text)
+		title := chunk.Title
+		if title == "" {
+			if tokens == 0 {
+				continue
+			}
+			title = "(header)"
+		}
+
+		status, style := "ok", ""
+		switch {
+		case tokens > tokenOverThreshold:
+			status, style = "over budget", ansiRed
+		case tokens > tokenWarnThreshold:
+			status, style = "large", ansiYellow
+		}
+
+		line := fmt.Sprintf("%-32s %8d %7.1f%%  %s", title, tokens, float64(tokens)*100/float64(total), status)
+		if color && style != "" {
+			line = style + line + ansiReset
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%-32s %8d %7.1f%%\n", "TOTAL", total, 100.0)
+}
@@ -7,10 +7,21 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/angelfreak/ccd/daemon/detect"
 	"github.com/spf13/cobra"
 )
 
+// Summaries this similar to one pushed within the window are duplicates
+const (
+	duplicateSimilarity = 0.8
+	duplicateWindow     = 30 * time.Minute
+	duplicateLookback   = 5
+)
+
 func NewPushCommand(pbURL *string) *cobra.Command {
+	var checkDuplicate, force bool
+	var tag string
+
 	cmd := &cobra.Command{
 		Use:   "push <project-slug> <summary>",
 		Short: "Save session summary",
@@ -18,17 +29,71 @@ func NewPushCommand(pbURL *string) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectSlug := args[0]
 			summary := args[1]
-			return pushSession(*pbURL, projectSlug, summary)
+
+			if checkDuplicate && !force {
+				duplicate, err := findDuplicateSession(*pbURL, projectSlug, summary)
+				if err != nil {
+					return err
+				}
+				if duplicate != nil {
+					fmt.Printf("⚠️  Skipped: near-duplicate of session %s pushed at %s\n", duplicate.ID, formatTime(duplicate.Created))
+					fmt.Printf("   %s\n", duplicate.Summary)
+					fmt.Println("   Use --force to push anyway")
+					cmd.SilenceErrors = true
+					cmd.SilenceUsage = true
+					return &ExitError{Code: 2, Err: fmt.Errorf("duplicate session summary")}
+				}
+			}
+
+			return pushSession(*pbURL, projectSlug, summary, tag)
 		},
 	}
 
+	cmd.Flags().BoolVar(&checkDuplicate, "check-duplicate", false, "Skip (exit 2) if a near-identical summary was pushed in the last 30 minutes")
+	cmd.Flags().BoolVar(&force, "force", false, "Push even if a duplicate is detected")
+	cmd.Flags().StringVar(&tag, "tag", "", "Tag the session with a workstream, such as a ticket, to filter by later")
+
 	return cmd
 }
 
-func pushSession(pbURL, projectSlug, summary string) error {
+// findDuplicateSession returns a recent session whose summary is nearly the
+// same as summary, or nil
+func findDuplicateSession(pbURL, projectSlug, summary string) (*sessionRecord, error) {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/api/collections/session_history/records?filter=project='%s'&sort=-created&perPage=%d",
+		pbURL, project.ID, duplicateLookback)
+	resp, err := httpGet(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	var sessions struct {
+		Items []sessionRecord `json:"items"`
+	}
+	if err := decodeResponse(resp, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	for _, session := range sessions.Items {
+		created, err := parsePBTime(session.Created)
+		if err != nil || time.Since(created) > duplicateWindow {
+			continue
+		}
+		if similarity(session.Summary, summary) > duplicateSimilarity {
+			return &session, nil
+		}
+	}
+	return nil, nil
+}
+
+func pushSession(pbURL, projectSlug, summary, tag string) error {
 	// Get project by slug
 	url := fmt.Sprintf("%s/api/collections/projects/records?filter=slug='%s'", pbURL, projectSlug)
-	resp, err := http.Get(url)
+	resp, err := httpGet(url)
 	if err != nil {
 		return fmt.Errorf("failed to fetch project: %w", err)
 	}
@@ -36,7 +101,8 @@ func pushSession(pbURL, projectSlug, summary string) error {
 
 	var result struct {
 		Items []struct {
-			ID string `json:"id"`
+			ID       string `json:"id"`
+			RepoPath string `json:"repo_path"`
 		} `json:"items"`
 	}
 
@@ -58,13 +124,24 @@ func pushSession(pbURL, projectSlug, summary string) error {
 		"session_start": time.Now().Format(time.RFC3339),
 		"session_end":   time.Now().Format(time.RFC3339),
 	}
+	if tag != "" {
+		data["tag"] = tag
+	}
+	// Without a repo path the push is most likely run from the repo
+	repoPath := result.Items[0].RepoPath
+	if repoPath == "" {
+		repoPath = "."
+	}
+	if branch := detect.GitBranch(repoPath); branch != "" {
+		data["branch"] = branch
+	}
 
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	resp, err = http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err = httpPost(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
 	}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPushStoresTagAndBranch(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/feature/login\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "name": "App", "repo_path": repo})
+
+	captureStdout(t, func() {
+		if err := pushSession(pb.URL, "app", "Added login", "PROJ-12"); err != nil {
+			t.Error(err)
+		}
+	})
+	created := pb.createdRecords("session_history")
+	if len(created) != 1 {
+		t.Fatalf("created %d sessions, want 1", len(created))
+	}
+	if created[0]["tag"] != "PROJ-12" || created[0]["branch"] != "feature/login" {
+		t.Errorf("session tag %v, branch %v; want PROJ-12 and feature/login", created[0]["tag"], created[0]["branch"])
+	}
+
+	// Listing by tag asks for sessions with that tag or branch
+	captureStdout(t, func() {
+		if err := listSessions(pb.URL, "app", "PROJ-12", 0, true); err != nil {
+			t.Error(err)
+		}
+	})
+	filters := pb.listFilters("session_history")
+	if want := "project='p1' && (tag='PROJ-12' || branch='PROJ-12')"; len(filters) != 1 || filters[0] != want {
+		t.Errorf("session filters = %q, want %q", filters, want)
+	}
+}
+
+func TestSessionTagFilterEscapesQuotes(t *testing.T) {
+	if got, want := sessionTagFilter("it's"), `(tag='it\'s' || branch='it\'s')`; got != want {
+		t.Errorf("sessionTagFilter = %s, want %s", got, want)
+	}
+}
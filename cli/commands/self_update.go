@@ -0,0 +1,328 @@
+package commands
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/instance"
+	"github.com/spf13/cobra"
+)
+
+const (
+	latestReleaseURL  = "https://api.github.com/repos/AngelFreak/CCD/releases/latest"
+	checksumsAsset    = "checksums.txt"
+	daemonStopTimeout = 10 * time.Second
+)
+
+type selfUpdateOptions struct {
+	checkOnly bool
+	yes       bool
+	binary    string
+	runDir    string
+}
+
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+func NewSelfUpdateCommand() *cobra.Command {
+	var opts selfUpdateOptions
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update the daemon binary to the latest GitHub release",
+		Long: `Update the daemon binary to the latest GitHub release. The download is
+verified against the release's checksums, and every running daemon registered
+for that binary (see cct daemon list) is stopped before it is replaced.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return selfUpdate(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.checkOnly, "check-only", false, "Only report whether an update is available")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Update without asking for confirmation")
+	cmd.Flags().StringVar(&opts.binary, "binary", "", "Path to the daemon binary (default: cct-daemon on PATH)")
+	cmd.Flags().StringVar(&opts.runDir, "run-dir", defaultRunDir(), "Run directory the daemons register in (the daemon's -run-dir)")
+
+	return cmd
+}
+
+func selfUpdate(opts selfUpdateOptions) error {
+	binary := opts.binary
+	if binary == "" {
+		path, err := exec.LookPath("cct-daemon")
+		if err != nil {
+			return fmt.Errorf("cct-daemon not found on PATH, use --binary to locate it")
+		}
+		binary = path
+	}
+	binary, err := filepath.EvalSymlinks(binary)
+	if err != nil {
+		return fmt.Errorf("failed to resolve daemon binary: %w", err)
+	}
+
+	release, err := fetchLatestRelease()
+	if err != nil {
+		return err
+	}
+
+	current := daemonVersion(binary)
+	fmt.Printf("📦 Current version: %s\n", current)
+	fmt.Printf("🆕 Latest version:  %s\n", release.TagName)
+
+	if strings.TrimPrefix(current, "v") == strings.TrimPrefix(release.TagName, "v") {
+		fmt.Println("✓ Daemon is up to date")
+		return nil
+	}
+	if opts.checkOnly {
+		fmt.Println("⬆️  An update is available, run without --check-only to install it")
+		return nil
+	}
+
+	assetName := daemonAssetName(runtime.GOOS, runtime.GOARCH)
+	asset := release.asset(assetName)
+	if asset == nil {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	checksums := release.asset(checksumsAsset)
+	if checksums == nil {
+		return fmt.Errorf("release %s has no %s", release.TagName, checksumsAsset)
+	}
+
+	if !opts.yes {
+		p := &linePrompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+		answer, err := p.Ask(fmt.Sprintf("Replace %s with %s? (y/N)", binary, release.TagName), "n")
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Println("Update cancelled")
+			return nil
+		}
+	}
+
+	expected, err := fetchChecksum(checksums.BrowserDownloadURL, assetName)
+	if err != nil {
+		return err
+	}
+
+	// Download next to the binary so the final rename stays on one filesystem
+	tmpPath, err := downloadAsset(asset.BrowserDownloadURL, filepath.Dir(binary), expected)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	stopped, err := stopDaemons(opts.runDir, binary)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, binary); err != nil {
+		return fmt.Errorf("failed to replace daemon binary: %w", err)
+	}
+
+	fmt.Printf("✓ Updated %s to %s\n", binary, release.TagName)
+	if stopped > 0 {
+		fmt.Printf("⚠️  %d daemon(s) were stopped for the update, restart them to resume tracking\n", stopped)
+	}
+	return nil
+}
+
+func fetchLatestRelease() (*githubRelease, error) {
+	req, err := http.NewRequest("GET", latestReleaseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpDo(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+
+	var release githubRelease
+	if err := decodeResponse(resp, &release); err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	return &release, nil
+}
+
+// daemonAssetName is the name of the release asset holding the daemon for
+// an OS and architecture
+func daemonAssetName(goos, goarch string) string {
+	return fmt.Sprintf("cct-daemon_%s_%s", goos, goarch)
+}
+
+func (r *githubRelease) asset(name string) *githubAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// daemonVersion asks the binary for its version, or "unknown" if it can't say
+func daemonVersion(binary string) string {
+	out, err := exec.Command(binary, "-version").Output()
+	if err != nil {
+		return "unknown"
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return fields[len(fields)-1]
+}
+
+// fetchChecksum finds the SHA256 for assetName in a sha256sum-style listing
+func fetchChecksum(checksumsURL, assetName string) (string, error) {
+	resp, err := httpGet(checksumsURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch checksums: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch checksums: status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == assetName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksums: %w", err)
+	}
+	return "", fmt.Errorf("no checksum listed for %s", assetName)
+}
+
+// downloadAsset saves url to a temp file in dir and checks its SHA256,
+// returning the temp file's path
+func downloadAsset(url, dir, expected string) (string, error) {
+	resp, err := httpDownload(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download update: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download update: status %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".cct-daemon-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to download update: %w", err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// stopDaemons stops every running daemon registered in runDir for binary:
+// each is sent SIGTERM, then waited on until it exits. A registration whose
+// PID now belongs to some other process, as happens when a daemon crashes
+// and the PID is reused, is never signalled. It returns how many daemons were
+// stopped.
+func stopDaemons(runDir, binary string) (int, error) {
+	if runDir == "" {
+		return 0, nil
+	}
+	instances, err := instance.List(runDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read run directory: %w", err)
+	}
+
+	var stopping []instance.Instance
+	for _, inst := range instances {
+		if !inst.Running {
+			continue
+		}
+		name := inst.ProjectSlug
+		if name == "" {
+			name = inst.ProjectID
+		}
+		if inst.Binary == "" {
+			// Registered by a daemon too old to record its binary
+			fmt.Printf("⚠️  Can't tell which binary the daemon for %s (PID %d) runs; stop it yourself if it's %s\n", name, inst.PID, binary)
+			continue
+		}
+		if inst.Binary != binary {
+			continue
+		}
+		if !inst.IsDaemon() {
+			fmt.Printf("⚠️  Skipping PID %d: it no longer belongs to the daemon registered for %s\n", inst.PID, name)
+			continue
+		}
+
+		process, err := os.FindProcess(inst.PID)
+		if err != nil {
+			continue
+		}
+		if err := process.Signal(syscall.SIGTERM); err != nil {
+			// Exited since it was listed
+			continue
+		}
+		fmt.Printf("⏹️  Stopping daemon for %s (PID %d)...\n", name, inst.PID)
+		stopping = append(stopping, inst)
+	}
+
+	stopped := len(stopping)
+	deadline := time.Now().Add(daemonStopTimeout)
+	for {
+		running := stopping[:0]
+		for _, inst := range stopping {
+			if instance.Alive(inst.PID) {
+				running = append(running, inst)
+			}
+		}
+		stopping = running
+		if len(stopping) == 0 {
+			return stopped, nil
+		}
+		if !time.Now().Before(deadline) {
+			return stopped - len(stopping), fmt.Errorf("daemon (PID %d) did not stop within %s", stopping[0].PID, daemonStopTimeout)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
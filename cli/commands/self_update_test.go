@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/instance"
+)
+
+func TestReleaseAssetSelection(t *testing.T) {
+	release := githubRelease{
+		TagName: "v1.4.0",
+		Assets: []githubAsset{
+			{Name: "cct-daemon_darwin_arm64", BrowserDownloadURL: "https://example.com/darwin_arm64"},
+			{Name: "cct-daemon_linux_amd64", BrowserDownloadURL: "https://example.com/linux_amd64"},
+			{Name: "cct-daemon_linux_arm64", BrowserDownloadURL: "https://example.com/linux_arm64"},
+			{Name: checksumsAsset, BrowserDownloadURL: "https://example.com/checksums"},
+		},
+	}
+
+	asset := release.asset(daemonAssetName("linux", "arm64"))
+	if asset == nil || asset.BrowserDownloadURL != "https://example.com/linux_arm64" {
+		t.Errorf("asset for linux/arm64 = %+v, want the linux_arm64 binary", asset)
+	}
+	if asset := release.asset(daemonAssetName("windows", "amd64")); asset != nil {
+		t.Errorf("asset for windows/amd64 = %+v, want none", asset)
+	}
+}
+
+// releaseServer serves a daemon binary and a checksums listing for it
+func releaseServer(t *testing.T, binary []byte, checksums string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/checksums.txt":
+			fmt.Fprint(w, checksums)
+		case "/cct-daemon_linux_amd64":
+			w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSelfUpdateVerifiesChecksum(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho cct-daemon v1.4.0\n")
+	sum := sha256.Sum256(binary)
+	checksums := fmt.Sprintf("%s  cct-daemon_darwin_arm64\n%s *cct-daemon_linux_amd64\n",
+		strings.Repeat("0", 64), strings.ToUpper(hex.EncodeToString(sum[:])))
+	srv := releaseServer(t, binary, checksums)
+
+	expected, err := fetchChecksum(srv.URL+"/checksums.txt", "cct-daemon_linux_amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected != hex.EncodeToString(sum[:]) {
+		t.Errorf("checksum = %s, want %x", expected, sum)
+	}
+	if _, err := fetchChecksum(srv.URL+"/checksums.txt", "cct-daemon_windows_amd64"); err == nil {
+		t.Error("fetchChecksum found a checksum for an unlisted asset")
+	}
+
+	dir := t.TempDir()
+	path, err := downloadAsset(srv.URL+"/cct-daemon_linux_amd64", dir, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(binary) {
+		t.Errorf("downloaded %q, want the release binary", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("downloaded binary mode = %v (%v), want 0755", info.Mode().Perm(), err)
+	}
+	os.Remove(path)
+
+	// A download that doesn't match its checksum is rejected and removed
+	_, err = downloadAsset(srv.URL+"/cct-daemon_linux_amd64", dir, strings.Repeat("0", 64))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("download with the wrong checksum: err = %v, want a checksum mismatch", err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, ".cct-daemon-*")); len(left) != 0 {
+		t.Errorf("rejected download left %v behind", left)
+	}
+}
+
+// startProcess runs a long sleep standing in for a daemon, returning a
+// channel closed once it exits and has been reaped
+func startProcess(t *testing.T) (*exec.Cmd, <-chan struct{}) {
+	t.Helper()
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("can't start a process: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		<-exited
+	})
+	return cmd, exited
+}
+
+func TestStopDaemons(t *testing.T) {
+	runDir := t.TempDir()
+	binary := "/opt/ccd/cct-daemon"
+	register := func(projectID string, pid int, binary string, started time.Time) {
+		t.Helper()
+		info := instance.Info{PID: pid, ProjectID: projectID, Binary: binary, StartedAt: started}
+		if _, err := instance.Register(runDir, info); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, firstExited := startProcess(t)
+	second, secondExited := startProcess(t)
+	other, otherExited := startProcess(t)
+	reused, reusedExited := startProcess(t)
+	now := time.Now()
+	register("first", first.Process.Pid, binary, now)
+	register("second", second.Process.Pid, binary, now)
+	register("other", other.Process.Pid, "/usr/local/bin/cct-daemon", now)
+	// A daemon that crashed an hour ago, whose PID went to another process
+	register("reused", reused.Process.Pid, binary, now.Add(-time.Hour))
+
+	var stopped int
+	var err error
+	out := captureStdout(t, func() { stopped, err = stopDaemons(runDir, binary) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped != 2 {
+		t.Errorf("stopped %d daemons, want 2:\n%s", stopped, out)
+	}
+	for name, exited := range map[string]<-chan struct{}{"first": firstExited, "second": secondExited} {
+		select {
+		case <-exited:
+		case <-time.After(time.Second):
+			t.Errorf("daemon %s still running", name)
+		}
+	}
+	for name, exited := range map[string]<-chan struct{}{"other binary": otherExited, "reused PID": reusedExited} {
+		select {
+		case <-exited:
+			t.Errorf("%s was signalled", name)
+		default:
+		}
+	}
+	if !strings.Contains(out, fmt.Sprintf("Skipping PID %d: it no longer belongs to the daemon registered for reused", reused.Process.Pid)) {
+		t.Errorf("reused PID not reported:\n%s", out)
+	}
+}
+
+func TestStopDaemonsWithoutRegistrations(t *testing.T) {
+	stopped, err := stopDaemons(filepath.Join(t.TempDir(), "missing"), "/opt/ccd/cct-daemon")
+	if err != nil || stopped != 0 {
+		t.Errorf("stopDaemons with no run directory = %v, %v; want nothing stopped", stopped, err)
+	}
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func NewSessionsCommand(pbURL *string) *cobra.Command {
+	var limit int
+	var asJSON bool
+	var tag string
+
+	cmd := &cobra.Command{
+		Use:   "sessions [project-slug]",
+		Short: "List a project's sessions, or review them with a subcommand",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return cmd.Help()
+			}
+			projectSlug := args[0]
+			return listSessions(*pbURL, projectSlug, tag, limit, asJSON)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of sessions to list (0 for all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print sessions as JSON")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only list sessions with this tag or git branch")
+
+	cmd.AddCommand(NewSessionsDigestCommand(pbURL))
+	cmd.AddCommand(NewSessionsCostEstimateCommand(pbURL))
+	cmd.AddCommand(NewSessionsDurationStatsCommand(pbURL))
+	cmd.AddCommand(NewSessionsExportToObsidianCommand(pbURL))
+
+	return cmd
+}
+
+type sessionListing struct {
+	ID              string `json:"id"`
+	Created         string `json:"created"`
+	Summary         string `json:"summary"`
+	TokenCount      int    `json:"token_count"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+	Model           string `json:"model,omitempty"`
+	Cwd             string `json:"cwd,omitempty"`
+	Branch          string `json:"branch,omitempty"`
+	Tag             string `json:"tag,omitempty"`
+}
+
+func listSessions(pbURL, projectSlug, tag string, limit int, asJSON bool) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := getSessions(pbURL, project.ID, tag, limit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	listings := make([]sessionListing, 0, len(sessions))
+	for _, session := range sessions {
+		listings = append(listings, sessionListing{
+			ID:              session.ID,
+			Created:         session.Created,
+			Summary:         session.Summary,
+			TokenCount:      session.TokenCount,
+			DurationMinutes: int(sessionLength(session).Minutes()),
+			Model:           session.Model,
+			Cwd:             session.Cwd,
+			Branch:          session.Branch,
+			Tag:             session.Tag,
+		})
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listings)
+	}
+
+	if len(listings) == 0 {
+		fmt.Println("No session history found")
+		return nil
+	}
+
+	fmt.Printf("📚 Sessions for %s\n\n", project.Name)
+	for _, listing := range listings {
+		duration := "-"
+		if listing.DurationMinutes > 0 {
+			duration = formatMinutes(float64(listing.DurationMinutes))
+		}
+		fmt.Printf("%-22s %-15s %8d tokens %8s\n", formatTime(listing.Created), listing.ID, listing.TokenCount, duration)
+		if details := sessionDetails(listing); details != "" {
+			fmt.Printf("  %s\n", details)
+		}
+		fmt.Printf("  %s\n\n", listing.Summary)
+	}
+	return nil
+}
+
+// sessionDetails describes where a session ran and what it was tagged, or ""
+// if nothing was recorded
+func sessionDetails(listing sessionListing) string {
+	var details []string
+	if listing.Tag != "" {
+		details = append(details, "tag: "+listing.Tag)
+	}
+	if listing.Branch != "" {
+		details = append(details, "branch: "+listing.Branch)
+	}
+	if listing.Model != "" {
+		details = append(details, "model: "+listing.Model)
+	}
+	if listing.Cwd != "" {
+		details = append(details, "cwd: "+listing.Cwd)
+	}
+	return strings.Join(details, " · ")
+}
+
+// sessionLength is how long a session ran, or zero if its start or end
+// wasn't recorded
+func sessionLength(session sessionRecord) time.Duration {
+	start, err := parsePBTime(session.SessionStart)
+	if err != nil {
+		return 0
+	}
+	end, err := parsePBTime(session.SessionEnd)
+	if err != nil || !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
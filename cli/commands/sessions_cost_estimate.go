@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultCostModel is priced when neither --model nor
+// --price-per-million-tokens is given
+const defaultCostModel = "claude-3-sonnet"
+
+// modelPrices are input prices in US dollars per million tokens. Session
+// token counts measure the conversation sent to the model, so input pricing
+// is what applies.
+var modelPrices = map[string]float64{
+	"claude-3-opus":     15.00,
+	"claude-3-sonnet":   3.00,
+	"claude-3-haiku":    0.25,
+	"claude-3-5-sonnet": 3.00,
+	"claude-3-5-haiku":  0.80,
+	"opus":              15.00,
+	"sonnet":            3.00,
+	"haiku":             0.25,
+}
+
+type costEstimateOptions struct {
+	model         string
+	pricePerMTok  float64
+	since         string
+	until         string
+	priceExplicit bool
+	// location is the time zone for dates, in --since/--until and the
+	// output; nil means local time
+	location *time.Location
+}
+
+func NewSessionsCostEstimateCommand(pbURL *string) *cobra.Command {
+	var opts costEstimateOptions
+
+	cmd := &cobra.Command{
+		Use:   "cost-estimate [project-slug]",
+		Short: "Estimate the API cost of each session from its token count",
+		Long: `Estimate what each session would cost at API prices, from the token count
+recorded with it, with a running total. Prices come from --model's entry in
+the built-in price table (Claude 3 Sonnet by default) unless
+--price-per-million-tokens is given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.priceExplicit = cmd.Flags().Changed("price-per-million-tokens")
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return estimateSessionCosts(*pbURL, projectSlug, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.model, "model", defaultCostModel, "Model whose price to use: "+strings.Join(pricedModels(), ", "))
+	cmd.Flags().Float64Var(&opts.pricePerMTok, "price-per-million-tokens", 0, "Price in dollars per million tokens (overrides --model)")
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only include sessions starting on or after this date (2024-01-31) or day count (30d)")
+	cmd.Flags().StringVar(&opts.until, "until", "", "Only include sessions starting on or before this date (2024-01-31)")
+
+	return cmd
+}
+
+func estimateSessionCosts(pbURL, projectSlug string, opts costEstimateOptions) error {
+	price := opts.pricePerMTok
+	priceSource := "custom price"
+	if !opts.priceExplicit {
+		var ok bool
+		price, ok = modelPrices[strings.ToLower(opts.model)]
+		if !ok {
+			return fmt.Errorf("no price for model %q: use one of %s, or --price-per-million-tokens",
+				opts.model, strings.Join(pricedModels(), ", "))
+		}
+		priceSource = opts.model
+	}
+	if price < 0 {
+		return fmt.Errorf("--price-per-million-tokens can't be negative")
+	}
+
+	loc := opts.location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var since, until time.Time
+	var err error
+	if opts.since != "" {
+		if since, err = parseSince(opts.since, loc); err != nil {
+			return err
+		}
+	}
+	if opts.until != "" {
+		if until, err = parseUntil(opts.until, loc); err != nil {
+			return err
+		}
+	}
+
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := listRecords[sessionRecord](pbURL, "session_history",
+		fmt.Sprintf("project='%s'", project.ID), "created")
+	if err != nil {
+		return fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	fmt.Printf("💰 Estimated cost for %s at $%.2f per million tokens (%s)\n\n", project.Name, price, priceSource)
+	fmt.Printf("%-15s  %-17s  %10s  %10s  %11s\n", "SESSION", "DATE", "TOKENS", "COST", "CUMULATIVE")
+
+	count, tokens, total := 0, 0, 0.0
+	for _, session := range sessions {
+		started := sessionStartTime(session)
+		if (!since.IsZero() && started.Before(since)) || (!until.IsZero() && !started.Before(until)) {
+			continue
+		}
+
+		cost := float64(session.TokenCount) / 1e6 * price
+		count++
+		tokens += session.TokenCount
+		total += cost
+
+		fmt.Printf("%-15s  %-17s  %10d  %10s  %11s\n", session.ID, started.In(loc).Format("2006-01-02 15:04"),
+			session.TokenCount, formatDollars(cost), formatDollars(total))
+	}
+
+	if count == 0 {
+		fmt.Println("\nNo sessions found")
+		return nil
+	}
+
+	fmt.Printf("\nTotal: %s for %d tokens across %d sessions\n", formatDollars(total), tokens, count)
+	return nil
+}
+
+// sessionStartTime is when a session started, falling back to when it was
+// recorded
+func sessionStartTime(session sessionRecord) time.Time {
+	if start, err := parsePBTime(session.SessionStart); err == nil {
+		return start
+	}
+	created, _ := parsePBTime(session.Created)
+	return created
+}
+
+// formatDollars shows cents, or more precision for amounts under a cent
+func formatDollars(amount float64) string {
+	if amount > 0 && amount < 0.01 {
+		return fmt.Sprintf("$%.4f", amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+func pricedModels() []string {
+	models := make([]string, 0, len(modelPrices))
+	for model := range modelPrices {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateSessionCosts(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "name": "App"})
+	pb.add("session_history",
+		map[string]interface{}{"id": "s1", "project": "p1", "created": "2026-03-01 10:00:00.000Z", "token_count": 100000.0},
+		map[string]interface{}{"id": "s2", "project": "p1", "created": "2026-03-02 10:00:00.000Z", "session_start": "2026-03-02 09:00:00.000Z", "token_count": 500000.0},
+		map[string]interface{}{"id": "s3", "project": "p1", "created": "2026-03-03 10:00:00.000Z", "token_count": 2000.0},
+		map[string]interface{}{"id": "s4", "project": "p1", "created": "2026-03-04 10:00:00.000Z", "token_count": 900000.0},
+	)
+
+	run := func(opts costEstimateOptions) string {
+		opts.location = time.UTC
+		return captureStdout(t, func() {
+			if err := estimateSessionCosts(pb.URL, "app", opts); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	// Claude 3 Sonnet by default, from the 2nd to the 3rd inclusive
+	out := run(costEstimateOptions{model: defaultCostModel, since: "2026-03-02", until: "2026-03-03"})
+	for _, want := range []string{
+		"at $3.00 per million tokens (claude-3-sonnet)",
+		"s2               2026-03-02 09:00       500000       $1.50        $1.50\n",
+		"s3               2026-03-03 10:00         2000     $0.0060        $1.51\n",
+		"Total: $1.51 for 502000 tokens across 2 sessions\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "s1 ") || strings.Contains(out, "s4 ") {
+		t.Errorf("output includes sessions outside --since/--until:\n%s", out)
+	}
+
+	// A model from the table, and an explicit price overriding it
+	if out := run(costEstimateOptions{model: "opus"}); !strings.Contains(out, "Total: $22.53 for 1502000 tokens across 4 sessions") {
+		t.Errorf("opus estimate:\n%s", out)
+	}
+	if out := run(costEstimateOptions{model: "opus", pricePerMTok: 1, priceExplicit: true}); !strings.Contains(out, "Total: $1.50 for 1502000 tokens") {
+		t.Errorf("custom price estimate:\n%s", out)
+	}
+
+	if err := estimateSessionCosts(pb.URL, "app", costEstimateOptions{model: "gpt-4"}); err == nil {
+		t.Error("an unpriced model was accepted")
+	}
+}
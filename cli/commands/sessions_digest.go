@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// digestMinImportance is the lowest importance a fact needs to appear in a digest
+const digestMinImportance = 3
+
+// charsPerToken approximates tokens the same way the daemon does for prose
+const charsPerToken = 4
+
+func NewSessionsDigestCommand(pbURL *string) *cobra.Command {
+	var (
+		output      string
+		tokenBudget int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "digest <project-slug> <n>",
+		Short: "Summarize the last N sessions in one Markdown document",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug := args[0]
+			count, err := strconv.Atoi(args[1])
+			if err != nil || count < 1 {
+				return fmt.Errorf("invalid session count: %s", args[1])
+			}
+			return sessionsDigest(*pbURL, projectSlug, count, output, tokenBudget)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default: stdout)")
+	cmd.Flags().IntVar(&tokenBudget, "token-budget", 0, "Drop the oldest sessions until the digest fits this many tokens (0 = no limit)")
+
+	return cmd
+}
+
+func sessionsDigest(pbURL, projectSlug string, count int, output string, tokenBudget int) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := listRecords[sessionRecord](pbURL, "session_history",
+		fmt.Sprintf("project='%s'", project.ID), "-created")
+	if err != nil {
+		return fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	if len(sessions) > count {
+		sessions = sessions[:count]
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("No session history found")
+		return nil
+	}
+
+	header := fmt.Sprintf("# %s: last %d sessions\n\n", project.Name, len(sessions))
+	digest := header
+	tokens := len(header) / charsPerToken
+	omitted := 0
+
+	// Newest first, so the oldest sessions are the ones cut to fit the budget
+	for i, session := range sessions {
+		facts, err := fetchSessionFacts(pbURL, project.ID, session)
+		if err != nil {
+			return fmt.Errorf("failed to fetch facts: %w", err)
+		}
+
+		block := renderDigestSession(session, facts)
+		blockTokens := len(block) / charsPerToken
+		if tokenBudget > 0 && tokens+blockTokens > tokenBudget {
+			omitted = len(sessions) - i
+			break
+		}
+
+		digest += block
+		tokens += blockTokens
+	}
+
+	if omitted > 0 {
+		digest += fmt.Sprintf("_%d older session(s) omitted to fit the %d token budget._\n", omitted, tokenBudget)
+	}
+
+	if output == "" {
+		fmt.Print(digest)
+		return nil
+	}
+
+	if err := os.WriteFile(output, []byte(digest), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	fmt.Printf("✓ Digest written to %s\n", output)
+	return nil
+}
+
+func renderDigestSession(session sessionRecord, facts []factRecord) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## %s\n\n", formatTime(session.Created))
+	if session.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", session.Summary)
+	}
+
+	written := 0
+	for _, fact := range facts {
+		if fact.Importance < digestMinImportance {
+			continue
+		}
+		fmt.Fprintf(&b, "- [%s] %s\n", fact.FactType, fact.Content)
+		written++
+	}
+	if written > 0 {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// histogramWidth is the length of the longest bar in the hour histogram
+const histogramWidth = 30
+
+type durationStatsOptions struct {
+	since     string
+	until     string
+	dayOfWeek bool
+	format    string
+}
+
+type durationStats struct {
+	Sessions       int              `json:"sessions"`
+	TotalHours     float64          `json:"total_hours"`
+	AverageMinutes float64          `json:"average_minutes"`
+	Longest        *sessionDuration `json:"longest,omitempty"`
+	Shortest       *sessionDuration `json:"shortest,omitempty"`
+	TokensPerHour  float64          `json:"tokens_per_hour"`
+	SessionsByHour [24]int          `json:"sessions_by_hour"`
+	DayOfWeek      []dayStats       `json:"day_of_week,omitempty"`
+}
+
+type sessionDuration struct {
+	ID      string    `json:"id"`
+	Start   time.Time `json:"start"`
+	Minutes float64   `json:"minutes"`
+	Summary string    `json:"summary"`
+}
+
+type dayStats struct {
+	Day            string  `json:"day"`
+	Sessions       int     `json:"sessions"`
+	TotalHours     float64 `json:"total_hours"`
+	AverageMinutes float64 `json:"average_minutes"`
+}
+
+func NewSessionsDurationStatsCommand(pbURL *string) *cobra.Command {
+	var opts durationStatsOptions
+
+	cmd := &cobra.Command{
+		Use:   "duration-stats [project-slug]",
+		Short: "Show how long sessions run and when they happen",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return showDurationStats(*pbURL, projectSlug, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only include sessions starting on or after this date (2024-01-31) or day count (30d)")
+	cmd.Flags().StringVar(&opts.until, "until", "", "Only include sessions starting on or before this date (2024-01-31)")
+	cmd.Flags().BoolVar(&opts.dayOfWeek, "day-of-week", false, "Break durations down by day of the week")
+	cmd.Flags().StringVar(&opts.format, "format", "table", "Output format: table or json")
+
+	return cmd
+}
+
+func showDurationStats(pbURL, projectSlug string, opts durationStatsOptions) error {
+	if opts.format != "table" && opts.format != "json" {
+		return fmt.Errorf("invalid --format %q: use table or json", opts.format)
+	}
+
+	var since, until time.Time
+	var err error
+	if opts.since != "" {
+		if since, err = parseSince(opts.since, time.Local); err != nil {
+			return err
+		}
+	}
+	if opts.until != "" {
+		if until, err = parseUntil(opts.until, time.Local); err != nil {
+			return err
+		}
+	}
+
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := listRecords[sessionRecord](pbURL, "session_history",
+		fmt.Sprintf("project='%s' && session_start!='' && session_end!=''", project.ID), "session_start")
+	if err != nil {
+		return fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	stats := computeDurationStats(sessions, since, until, opts.dayOfWeek)
+
+	if opts.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	printDurationStats(project.Name, stats)
+	return nil
+}
+
+// computeDurationStats summarizes sessions starting in [since, until); zero
+// times leave that end open. Sessions without a positive duration are skipped.
+func computeDurationStats(sessions []sessionRecord, since, until time.Time, byDay bool) durationStats {
+	var stats durationStats
+	var total time.Duration
+	tokens := 0
+
+	var days [7]dayStats
+	var dayTotals [7]time.Duration
+
+	for _, session := range sessions {
+		start, err := parsePBTime(session.SessionStart)
+		if err != nil {
+			continue
+		}
+		end, err := parsePBTime(session.SessionEnd)
+		if err != nil || !end.After(start) {
+			continue
+		}
+		if !since.IsZero() && start.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !start.Before(until) {
+			continue
+		}
+
+		start = start.Local()
+		duration := end.Sub(start)
+		current := &sessionDuration{
+			ID:      session.ID,
+			Start:   start,
+			Minutes: duration.Minutes(),
+			Summary: session.Summary,
+		}
+
+		stats.Sessions++
+		total += duration
+		tokens += session.TokenCount
+		stats.SessionsByHour[start.Hour()]++
+
+		if stats.Longest == nil || current.Minutes > stats.Longest.Minutes {
+			stats.Longest = current
+		}
+		if stats.Shortest == nil || current.Minutes < stats.Shortest.Minutes {
+			stats.Shortest = current
+		}
+
+		day := start.Weekday()
+		days[day].Sessions++
+		dayTotals[day] += duration
+	}
+
+	if stats.Sessions == 0 {
+		return stats
+	}
+
+	stats.TotalHours = total.Hours()
+	stats.AverageMinutes = total.Minutes() / float64(stats.Sessions)
+	stats.TokensPerHour = float64(tokens) / total.Hours()
+
+	if byDay {
+		for day := range days {
+			if days[day].Sessions == 0 {
+				continue
+			}
+			days[day].Day = time.Weekday(day).String()
+			days[day].TotalHours = dayTotals[day].Hours()
+			days[day].AverageMinutes = dayTotals[day].Minutes() / float64(days[day].Sessions)
+			stats.DayOfWeek = append(stats.DayOfWeek, days[day])
+		}
+		// Longest average sessions first
+		sort.SliceStable(stats.DayOfWeek, func(i, j int) bool {
+			return stats.DayOfWeek[i].AverageMinutes > stats.DayOfWeek[j].AverageMinutes
+		})
+	}
+
+	return stats
+}
+
+func printDurationStats(projectName string, stats durationStats) {
+	fmt.Printf("⏱️  Session durations for %s\n\n", projectName)
+
+	if stats.Sessions == 0 {
+		fmt.Println("No sessions with recorded start and end times")
+		return
+	}
+
+	fmt.Printf("Sessions:        %d\n", stats.Sessions)
+	fmt.Printf("Total time:      %.1fh\n", stats.TotalHours)
+	fmt.Printf("Average session: %s\n", formatMinutes(stats.AverageMinutes))
+	fmt.Printf("Longest:         %s (%s)\n", formatMinutes(stats.Longest.Minutes), stats.Longest.Start.Format("2006-01-02 15:04"))
+	fmt.Printf("Shortest:        %s (%s)\n", formatMinutes(stats.Shortest.Minutes), stats.Shortest.Start.Format("2006-01-02 15:04"))
+	fmt.Printf("Tokens per hour: %.0f\n", stats.TokensPerHour)
+
+	peak := 0
+	for _, count := range stats.SessionsByHour {
+		if count > peak {
+			peak = count
+		}
+	}
+
+	fmt.Println("\n🕐 Sessions by start hour:")
+	for hour, count := range stats.SessionsByHour {
+		bar := strings.Repeat("█", (count*histogramWidth+peak-1)/peak)
+		fmt.Printf("  %02d:00 %-*s %d\n", hour, histogramWidth, bar, count)
+	}
+
+	if len(stats.DayOfWeek) > 0 {
+		fmt.Println("\n📅 By day of week:")
+		fmt.Printf("  %-10s %8s %8s %10s\n", "DAY", "SESSIONS", "HOURS", "AVERAGE")
+		for _, day := range stats.DayOfWeek {
+			fmt.Printf("  %-10s %8d %8.1f %10s\n", day.Day, day.Sessions, day.TotalHours, formatMinutes(day.AverageMinutes))
+		}
+	}
+}
+
+// formatMinutes renders a duration in minutes as e.g. "1h 25m" or "40m"
+func formatMinutes(minutes float64) string {
+	m := int(minutes + 0.5)
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh %dm", m/60, m%60)
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeDurationStats(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	sessions := []sessionRecord{
+		{ID: "mon-morning", SessionStart: "2026-03-02 09:00:00.000Z", SessionEnd: "2026-03-02 10:30:00.000Z", TokenCount: 3000},
+		{ID: "mon-afternoon", SessionStart: "2026-03-02 14:00:00.000Z", SessionEnd: "2026-03-02 14:30:00.000Z", TokenCount: 1000},
+		{ID: "wed", SessionStart: "2026-03-04 09:00:00.000Z", SessionEnd: "2026-03-04 13:00:00.000Z", TokenCount: 6000},
+		{ID: "open", SessionStart: "2026-03-05 09:00:00.000Z", TokenCount: 500},
+		{ID: "backwards", SessionStart: "2026-03-05 11:00:00.000Z", SessionEnd: "2026-03-05 10:00:00.000Z"},
+		{ID: "before-since", SessionStart: "2026-02-20 09:00:00.000Z", SessionEnd: "2026-02-20 17:00:00.000Z"},
+	}
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	stats := computeDurationStats(sessions, since, time.Time{}, true)
+
+	if stats.Sessions != 3 {
+		t.Fatalf("Sessions = %d, want 3", stats.Sessions)
+	}
+	if stats.TotalHours != 6 {
+		t.Errorf("TotalHours = %v, want 6", stats.TotalHours)
+	}
+	if stats.AverageMinutes != 120 {
+		t.Errorf("AverageMinutes = %v, want 120", stats.AverageMinutes)
+	}
+	if want := 10000.0 / 6; math.Abs(stats.TokensPerHour-want) > 1e-9 {
+		t.Errorf("TokensPerHour = %v, want %v", stats.TokensPerHour, want)
+	}
+	if stats.Longest.ID != "wed" || stats.Longest.Minutes != 240 {
+		t.Errorf("Longest = %+v, want the 4h Wednesday session", stats.Longest)
+	}
+	if stats.Shortest.ID != "mon-afternoon" || stats.Shortest.Minutes != 30 {
+		t.Errorf("Shortest = %+v, want the 30m Monday afternoon session", stats.Shortest)
+	}
+	if stats.SessionsByHour[9] != 2 || stats.SessionsByHour[14] != 1 {
+		t.Errorf("SessionsByHour = %v, want 2 at 09:00 and 1 at 14:00", stats.SessionsByHour)
+	}
+
+	want := []dayStats{
+		{Day: "Wednesday", Sessions: 1, TotalHours: 4, AverageMinutes: 240},
+		{Day: "Monday", Sessions: 2, TotalHours: 2, AverageMinutes: 60},
+	}
+	if len(stats.DayOfWeek) != len(want) {
+		t.Fatalf("DayOfWeek = %+v, want %+v", stats.DayOfWeek, want)
+	}
+	for i := range want {
+		if stats.DayOfWeek[i] != want[i] {
+			t.Errorf("DayOfWeek[%d] = %+v, want %+v", i, stats.DayOfWeek[i], want[i])
+		}
+	}
+
+	// until excludes sessions starting at or after it
+	stats = computeDurationStats(sessions, since, time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC), false)
+	if stats.Sessions != 2 || stats.DayOfWeek != nil {
+		t.Errorf("until Wednesday: %d sessions, day breakdown %v; want 2 and none", stats.Sessions, stats.DayOfWeek)
+	}
+}
+
+func TestComputeDurationStatsWithoutSessions(t *testing.T) {
+	stats := computeDurationStats(nil, time.Time{}, time.Time{}, true)
+	if stats.Sessions != 0 || stats.Longest != nil || stats.TokensPerHour != 0 {
+		t.Errorf("stats with no sessions = %+v, want zero", stats)
+	}
+}
+
+func TestFormatMinutes(t *testing.T) {
+	for minutes, want := range map[float64]string{0: "0m", 40.4: "40m", 59.6: "1h 0m", 85: "1h 25m", 1440: "24h 0m"} {
+		if got := formatMinutes(minutes); got != want {
+			t.Errorf("formatMinutes(%v) = %q, want %q", minutes, got, want)
+		}
+	}
+}
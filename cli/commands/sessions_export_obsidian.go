@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// obsidianDateTokens converts Obsidian (moment.js) date tokens to Go layout
+// elements. Longer tokens come first so they win over their prefixes.
+var obsidianDateTokens = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MMMM", "January",
+	"MMM", "Jan",
+	"MM", "01",
+	"dddd", "Monday",
+	"ddd", "Mon",
+	"DD", "02",
+)
+
+func NewSessionsExportToObsidianCommand(pbURL *string) *cobra.Command {
+	var (
+		vaultDir          string
+		dailyNoteFormat   string
+		overwriteExisting bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export-to-obsidian <project-slug> [session-id]",
+		Short: "Append a session to its Obsidian daily note",
+		Long: `Append a session's summary and top facts to the Obsidian daily note for
+the day it started. Exports the latest session unless a session ID is given.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug := args[0]
+			sessionID := ""
+			if len(args) == 2 {
+				sessionID = args[1]
+			}
+			if vaultDir == "" {
+				return fmt.Errorf("--vault-dir is required")
+			}
+			return exportToObsidian(*pbURL, projectSlug, sessionID, vaultDir, dailyNoteFormat, overwriteExisting)
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultDir, "vault-dir", "", "Obsidian vault directory")
+	cmd.Flags().StringVar(&dailyNoteFormat, "daily-note-format", "YYYY-MM-DD", "Daily note filename format, using Obsidian date tokens")
+	cmd.Flags().BoolVar(&overwriteExisting, "overwrite-existing", false, "Replace this project's CCD block in the note instead of appending")
+
+	return cmd
+}
+
+func exportToObsidian(pbURL, projectSlug, sessionID, vaultDir, dailyNoteFormat string, overwriteExisting bool) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	filter := fmt.Sprintf("project='%s'", project.ID)
+	if sessionID != "" {
+		filter += fmt.Sprintf(" && id='%s'", sessionID)
+	}
+	sessions, err := firstRecords[sessionRecord](pbURL, "session_history", filter, "-created", 1)
+	if err != nil {
+		return fmt.Errorf("failed to fetch session: %w", err)
+	}
+	if len(sessions) == 0 {
+		return fmt.Errorf("no session found")
+	}
+	session := sessions[0]
+
+	facts, err := fetchSessionFacts(pbURL, project.ID, session)
+	if err != nil {
+		return fmt.Errorf("failed to fetch facts: %w", err)
+	}
+
+	started, err := parsePBTime(session.SessionStart)
+	if err != nil {
+		if started, err = parsePBTime(session.Created); err != nil {
+			return fmt.Errorf("session has no usable start date")
+		}
+	}
+
+	notePath := filepath.Join(vaultDir, "Daily Notes",
+		started.Local().Format(obsidianDateTokens.Replace(dailyNoteFormat))+".md")
+	if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+		return fmt.Errorf("failed to create daily note directory: %w", err)
+	}
+
+	existing, err := os.ReadFile(notePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read daily note: %w", err)
+	}
+
+	heading := fmt.Sprintf("## CCD Session: %s", project.Name)
+	block := renderObsidianBlock(heading, session, facts, started)
+
+	note := string(existing)
+	if overwriteExisting && strings.Contains(note, heading) {
+		note = replaceMarkdownSection(note, heading, block)
+	} else {
+		if note != "" && !strings.HasSuffix(note, "\n\n") {
+			note = strings.TrimRight(note, "\n") + "\n\n"
+		}
+		note += block
+	}
+
+	if err := os.WriteFile(notePath, []byte(note), 0644); err != nil {
+		return fmt.Errorf("failed to write daily note: %w", err)
+	}
+
+	fmt.Printf("✓ Session exported to %s\n", notePath)
+	return nil
+}
+
+func renderObsidianBlock(heading string, session sessionRecord, facts []factRecord, started time.Time) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s\n\n", heading)
+	fmt.Fprintf(&b, "*Started %s*\n\n", started.Local().Format("15:04"))
+	if session.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", session.Summary)
+	}
+
+	// fetchSessionFacts returns facts by descending importance
+	for i, fact := range facts {
+		if i == 3 {
+			break
+		}
+		fmt.Fprintf(&b, "- **%s**: %s\n", fact.FactType, fact.Content)
+	}
+	if len(facts) > 0 {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// replaceMarkdownSection swaps the section starting at heading, up to the next
+// heading of the same or higher level, for replacement
+func replaceMarkdownSection(note, heading, replacement string) string {
+	start := strings.Index(note, heading)
+	rest := note[start+len(heading):]
+
+	end := len(note)
+	for _, marker := range []string{"\n## ", "\n# "} {
+		if i := strings.Index(rest, marker); i >= 0 && start+len(heading)+i+1 < end {
+			end = start + len(heading) + i + 1
+		}
+	}
+
+	return note[:start] + replacement + note[end:]
+}
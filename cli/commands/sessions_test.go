@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestListSessionsNewestFirst(t *testing.T) {
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "slug": "app", "name": "App"})
+	pb.add("session_history",
+		map[string]interface{}{"id": "s1", "project": "p1", "created": "2026-03-01 09:00:00.000Z", "summary": "Set up the repo", "token_count": 1200.0},
+		map[string]interface{}{"id": "s3", "project": "p1", "created": "2026-03-03 09:00:00.000Z", "summary": "Added caching", "token_count": 5400.0,
+			"session_start": "2026-03-03 09:00:00.000Z", "session_end": "2026-03-03 10:30:00.000Z"},
+		map[string]interface{}{"id": "s2", "project": "p1", "created": "2026-03-02 09:00:00.000Z", "summary": "Chose Postgres", "token_count": 3100.0},
+	)
+
+	out := captureStdout(t, func() {
+		if err := listSessions(pb.URL, "app", "", 2, true); err != nil {
+			t.Error(err)
+		}
+	})
+	var listings []sessionListing
+	if err := json.Unmarshal([]byte(out), &listings); err != nil {
+		t.Fatalf("%v in %q", err, out)
+	}
+	if len(listings) != 2 || listings[0].ID != "s3" || listings[1].ID != "s2" {
+		t.Fatalf("listed %+v, want s3 then s2", listings)
+	}
+	if listings[0].DurationMinutes != 90 || listings[0].TokenCount != 5400 || listings[0].Summary != "Added caching" {
+		t.Errorf("first listing = %+v, want its summary, tokens, and 90 minutes", listings[0])
+	}
+	if filters := pb.listFilters("session_history"); len(filters) != 1 || filters[0] != "project='p1'" {
+		t.Errorf("session filters = %q, want the project's sessions", filters)
+	}
+
+	// Without a limit every session is listed, still newest first
+	out = captureStdout(t, func() {
+		if err := listSessions(pb.URL, "app", "", 0, false); err != nil {
+			t.Error(err)
+		}
+	})
+	s3, s2, s1 := strings.Index(out, "Added caching"), strings.Index(out, "Chose Postgres"), strings.Index(out, "Set up the repo")
+	if s3 < 0 || s3 > s2 || s2 > s1 {
+		t.Errorf("sessions not listed newest first:\n%s", out)
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slackNotifier posts Block Kit messages to a Slack incoming webhook
+type slackNotifier struct {
+	webhookURL string
+	// quietStart and quietEnd are hours on a 24h clock; notifications are
+	// suppressed from quietStart up to quietEnd. Equal values disable quiet hours.
+	quietStart int
+	quietEnd   int
+}
+
+// parseQuietHours parses a "<start>-<end>" range of 24h clock hours, e.g. "22-7"
+func parseQuietHours(value string) (int, int, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid quiet hours %q: use <start>-<end>, e.g. 22-7", value)
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || start < 0 || start > 23 {
+		return 0, 0, fmt.Errorf("invalid quiet hours start %q", parts[0])
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || end < 0 || end > 23 {
+		return 0, 0, fmt.Errorf("invalid quiet hours end %q", parts[1])
+	}
+
+	return start, end, nil
+}
+
+// quiet reports whether hour falls in the quiet period, which may wrap past
+// midnight
+func (s *slackNotifier) quiet(hour int) bool {
+	if s.quietStart == s.quietEnd {
+		return false
+	}
+	if s.quietStart < s.quietEnd {
+		return hour >= s.quietStart && hour < s.quietEnd
+	}
+	return hour >= s.quietStart || hour < s.quietEnd
+}
+
+// notify posts a message with a header and Markdown body, unless it's quiet hours
+func (s *slackNotifier) notify(header, body string) error {
+	if s.quiet(time.Now().Hour()) {
+		return nil
+	}
+
+	message := map[string]interface{}{
+		"text": header,
+		"blocks": []map[string]interface{}{
+			{
+				"type": "header",
+				"text": map[string]string{"type": "plain_text", "text": header},
+			},
+			{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": body},
+			},
+		},
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpPost(s.webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to post to Slack: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// tokenBar renders usage as a ten-segment emoji bar
+func tokenBar(fraction float64) string {
+	filled := int(fraction*10 + 0.5)
+	if filled > 10 {
+		filled = 10
+	}
+
+	segment := "🟩"
+	if fraction >= 0.85 {
+		segment = "🟥"
+	} else if fraction >= 0.6 {
+		segment = "🟨"
+	}
+
+	return strings.Repeat(segment, filled) + strings.Repeat("⬜", 10-filled)
+}
@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewStaleCommand(pbURL *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stale",
+		Short: "Teach the daemon which facts are stale",
+		Long: `Label facts as stale or fresh and train the model the daemon uses with
+-adaptive-stale. Labels and the model are kept in ~/.config/ccd.`,
+	}
+
+	cmd.AddCommand(NewStaleLabelCommand(pbURL))
+	cmd.AddCommand(NewStaleTrainCommand())
+
+	return cmd
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/smart"
+	"github.com/spf13/cobra"
+)
+
+func NewStaleLabelCommand(pbURL *string) *cobra.Command {
+	var stale, fresh bool
+
+	cmd := &cobra.Command{
+		Use:   "label <fact-id> --stale|--fresh",
+		Short: "Record whether a fact is stale, for training",
+		Long: `Record whether a fact is stale as training data for cct stale train.
+The fact's stale flag is updated to match, and the daemon's stale model
+leaves labeled facts alone.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if stale == fresh {
+				return fmt.Errorf("specify exactly one of --stale or --fresh")
+			}
+			return labelFact(*pbURL, args[0], stale)
+		},
+	}
+
+	cmd.Flags().BoolVar(&stale, "stale", false, "The fact is no longer relevant")
+	cmd.Flags().BoolVar(&fresh, "fresh", false, "The fact is still relevant")
+
+	return cmd
+}
+
+func labelFact(pbURL, factID string, stale bool) error {
+	facts, err := listRecords[factRecord](pbURL, "extracted_facts", fmt.Sprintf("id='%s'", factID), "")
+	if err != nil {
+		return fmt.Errorf("failed to fetch fact: %w", err)
+	}
+	if len(facts) == 0 {
+		return fmt.Errorf("fact not found: %s", factID)
+	}
+	fact := facts[0]
+
+	created, err := parsePBTime(fact.Created)
+	if err != nil {
+		return fmt.Errorf("failed to parse fact creation time: %w", err)
+	}
+
+	path, err := smart.DefaultStaleLabelsPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate labels file: %w", err)
+	}
+
+	label := smart.StaleLabel{
+		FactID: fact.ID,
+		Fact: smart.CompressibleFact{
+			Type:       fact.FactType,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+			Created:    created,
+		},
+		WasStale:  stale,
+		LabeledAt: time.Now(),
+	}
+	if err := smart.AppendStaleLabel(path, label); err != nil {
+		return fmt.Errorf("failed to save label: %w", err)
+	}
+
+	if fact.Stale != stale {
+		if err := updateRecord(pbURL, "extracted_facts", fact.ID, map[string]interface{}{"stale": stale}); err != nil {
+			return fmt.Errorf("failed to update fact: %w", err)
+		}
+	}
+
+	labels, err := smart.LoadStaleLabels(path)
+	if err != nil {
+		return fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	verdict := "fresh"
+	if stale {
+		verdict = "stale"
+	}
+	fmt.Printf("✓ Labeled [%s] %s as %s (%d labels collected)\n", fact.FactType, fact.Content, verdict, len(labels))
+	return nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/angelfreak/ccd/daemon/smart"
+	"github.com/spf13/cobra"
+)
+
+func NewStaleTrainCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "train",
+		Short: "Train the stale model from collected labels",
+		Long: `Train a logistic regression on the labels collected with cct stale label
+and save it for the daemon's -adaptive-stale mode. Some labels are held out
+of training to report the model's accuracy. Restart the daemon to pick up a
+new model.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return trainStaleModel()
+		},
+	}
+
+	return cmd
+}
+
+func trainStaleModel() error {
+	labelsPath, err := smart.DefaultStaleLabelsPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate labels file: %w", err)
+	}
+	modelPath, err := smart.DefaultStaleModelPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate model file: %w", err)
+	}
+
+	labels, err := smart.LoadStaleLabels(labelsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	detector := smart.NewStaleDetector()
+	if err := detector.TrainFromFeedback(labels); err != nil {
+		return fmt.Errorf("failed to train: %w", err)
+	}
+
+	model := detector.Model()
+	if err := smart.SaveStaleModel(modelPath, model); err != nil {
+		return fmt.Errorf("failed to save model: %w", err)
+	}
+
+	fmt.Printf("✓ Trained on %d labels\n", model.Samples)
+	fmt.Printf("  Validation accuracy: %.1f%%\n", model.Accuracy*100)
+	fmt.Println("  Coefficients:")
+	for i, name := range smart.StaleFeatures {
+		fmt.Printf("    %-20s %+.3f\n", name, model.Coefficients[i])
+	}
+	fmt.Printf("    %-20s %+.3f\n", "intercept", model.Intercept)
+	fmt.Printf("\nSaved to %s; run the daemon with -adaptive-stale to use it\n", modelPath)
+	return nil
+}
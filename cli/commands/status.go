@@ -3,118 +3,154 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 )
 
+// statusReport is what cct status shows. Project and LastSession are null in
+// JSON when the current directory matches no active project.
+type statusReport struct {
+	Project        *statusProject  `json:"project"`
+	LastSession    *statusSession  `json:"last_session"`
+	OpenBlockers   int             `json:"open_blockers"`
+	OpenTodos      int             `json:"open_todos"`
+	ActiveProjects []statusProject `json:"active_projects"`
+}
+
+type statusProject struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	RepoPath string `json:"repo_path"`
+	Status   string `json:"status"`
+}
+
+type statusSession struct {
+	Summary    string `json:"summary"`
+	TokenCount int    `json:"token_count"`
+	Created    string `json:"created"`
+}
+
 func NewStatusCommand(pbURL *string) *cobra.Command {
+	var asJSON bool
+
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show active project and session info",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return showStatus(*pbURL)
+			return showStatus(*pbURL, asJSON)
 		},
 	}
 
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output the status as JSON")
+
 	return cmd
 }
 
-func showStatus(pbURL string) error {
-	// Try to determine current project from git repo
-	cwd, err := os.Getwd()
+func showStatus(pbURL string, asJSON bool) error {
+	report, err := buildStatus(pbURL)
 	if err != nil {
 		return err
 	}
 
-	// Get all active projects
-	url := fmt.Sprintf("%s/api/collections/projects/records?filter=status='active'&sort=-updated", pbURL)
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to fetch projects: %w", err)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	if len(report.ActiveProjects) == 0 {
+		fmt.Println("No active projects")
+		return nil
 	}
 
-	var result struct {
-		Items []struct {
-			ID       string `json:"id"`
-			Name     string `json:"name"`
-			Slug     string `json:"slug"`
-			RepoPath string `json:"repo_path"`
-			Status   string `json:"status"`
-		} `json:"items"`
+	if project := report.Project; project != nil {
+		fmt.Printf("📂 Current Project: %s (%s)\n", project.Name, project.Slug)
+		fmt.Printf("📍 Path: %s\n", project.RepoPath)
+		fmt.Printf("🟢 Status: %s\n", project.Status)
+		if report.OpenBlockers > 0 || report.OpenTodos > 0 {
+			fmt.Printf("🚧 Open: %d blocker(s), %d todo(s)\n", report.OpenBlockers, report.OpenTodos)
+		}
+
+		if session := report.LastSession; session != nil {
+			fmt.Printf("\n📝 Last Session:\n")
+			fmt.Printf("   Summary: %s\n", session.Summary)
+			if session.TokenCount > 0 {
+				fmt.Printf("   Tokens: %d\n", session.TokenCount)
+			}
+		}
+	} else {
+		fmt.Println("📂 No project matching current directory")
+		fmt.Printf("\nActive Projects:\n")
+		for _, project := range report.ActiveProjects {
+			fmt.Printf("  • %s (%s)\n", project.Name, project.Slug)
+		}
 	}
 
-	if err := json.Unmarshal(body, &result); err != nil {
-		return err
+	return nil
+}
+
+// buildStatus finds the active project for the current directory, its last
+// session, and its open blocker and todo counts
+func buildStatus(pbURL string) (statusReport, error) {
+	report := statusReport{ActiveProjects: []statusProject{}}
+
+	// Try to determine current project from git repo
+	cwd, err := os.Getwd()
+	if err != nil {
+		return report, err
 	}
 
-	if len(result.Items) == 0 {
-		fmt.Println("No active projects")
-		return nil
+	projects, err := listRecords[projectRecord](pbURL, "projects", "status='active'", "-updated")
+	if err != nil {
+		return report, fmt.Errorf("failed to fetch projects: %w", err)
 	}
 
 	// Find project matching current directory
-	var currentProject *struct {
-		ID       string `json:"id"`
-		Name     string `json:"name"`
-		Slug     string `json:"slug"`
-		RepoPath string `json:"repo_path"`
-		Status   string `json:"status"`
-	}
+	for _, project := range projects {
+		summary := statusProject{
+			ID:       project.ID,
+			Name:     project.Name,
+			Slug:     project.Slug,
+			RepoPath: project.RepoPath,
+			Status:   project.Status,
+		}
+		report.ActiveProjects = append(report.ActiveProjects, summary)
 
-	for _, project := range result.Items {
+		if report.Project != nil {
+			continue
+		}
 		absPath, err := filepath.Abs(project.RepoPath)
 		if err != nil {
 			continue
 		}
 		if absPath == cwd || filepath.Dir(cwd) == absPath {
-			currentProject = &project
-			break
+			report.Project = &summary
 		}
 	}
+	if report.Project == nil {
+		return report, nil
+	}
 
-	if currentProject != nil {
-		fmt.Printf("📂 Current Project: %s (%s)\n", currentProject.Name, currentProject.Slug)
-		fmt.Printf("📍 Path: %s\n", currentProject.RepoPath)
-		fmt.Printf("🟢 Status: %s\n", currentProject.Status)
-
-		// Get latest session
-		url = fmt.Sprintf("%s/api/collections/session_history/records?filter=project='%s'&sort=-created&limit=1", pbURL, currentProject.ID)
-		resp, err = http.Get(url)
-		if err == nil {
-			defer resp.Body.Close()
-			var sessions struct {
-				Items []struct {
-					Summary    string `json:"summary"`
-					TokenCount int    `json:"token_count"`
-					Created    string `json:"created"`
-				} `json:"items"`
-			}
-
-			if err := json.NewDecoder(resp.Body).Decode(&sessions); err == nil && len(sessions.Items) > 0 {
-				fmt.Printf("\n📝 Last Session:\n")
-				fmt.Printf("   Summary: %s\n", sessions.Items[0].Summary)
-				if sessions.Items[0].TokenCount > 0 {
-					fmt.Printf("   Tokens: %d\n", sessions.Items[0].TokenCount)
-				}
-			}
-		}
-	} else {
-		fmt.Println("📂 No project matching current directory")
-		fmt.Printf("\nActive Projects:\n")
-		for _, project := range result.Items {
-			fmt.Printf("  • %s (%s)\n", project.Name, project.Slug)
+	// The session and fact counts are best effort; the project is reported
+	// even when they can't be fetched
+	projectID := report.Project.ID
+	sessions, err := firstRecords[sessionRecord](pbURL, "session_history", fmt.Sprintf("project='%s'", projectID), "-created", 1)
+	if err == nil && len(sessions) > 0 {
+		report.LastSession = &statusSession{
+			Summary:    sessions[0].Summary,
+			TokenCount: sessions[0].TokenCount,
+			Created:    sessions[0].Created,
 		}
 	}
+	if n, err := countRecords(pbURL, "extracted_facts", fmt.Sprintf("project='%s' && fact_type='blocker' && stale=false", projectID)); err == nil {
+		report.OpenBlockers = n
+	}
+	if n, err := countRecords(pbURL, "extracted_facts", fmt.Sprintf("project='%s' && fact_type='todo' && stale=false", projectID)); err == nil {
+		report.OpenTodos = n
+	}
 
-	return nil
+	return report, nil
 }
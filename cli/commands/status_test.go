@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// chdir changes into dir for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+}
+
+// statusJSON runs cct status --json, decoding its output generically so the
+// test sees exactly the JSON shape scripts do
+func statusJSON(t *testing.T, pbURL string) map[string]interface{} {
+	t.Helper()
+	var err error
+	out := captureStdout(t, func() { err = showStatus(pbURL, true) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("status printed invalid JSON: %v\n%s", err, out)
+	}
+	return status
+}
+
+func TestStatusJSONMatchedProject(t *testing.T) {
+	repo, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, repo)
+
+	pb := newFakePocketBase(t)
+	pb.add("projects",
+		map[string]interface{}{"id": "p1", "name": "Other", "slug": "other", "repo_path": "/elsewhere", "status": "active", "updated": "2026-03-02"},
+		map[string]interface{}{"id": "p2", "name": "App", "slug": "app", "repo_path": repo, "status": "active", "updated": "2026-03-01"},
+	)
+	pb.add("session_history", map[string]interface{}{
+		"id": "s1", "summary": "Wired up auth", "token_count": 1200, "created": "2026-03-01 10:00:00.000Z",
+	})
+	pb.add("extracted_facts",
+		map[string]interface{}{"id": "f1", "fact_type": "blocker"},
+		map[string]interface{}{"id": "f2", "fact_type": "todo"},
+	)
+
+	status := statusJSON(t, pb.URL)
+
+	wantProject := map[string]interface{}{"id": "p2", "name": "App", "slug": "app", "repo_path": repo, "status": "active"}
+	if !reflect.DeepEqual(status["project"], wantProject) {
+		t.Errorf("project = %v, want %v", status["project"], wantProject)
+	}
+	wantSession := map[string]interface{}{"summary": "Wired up auth", "token_count": 1200.0, "created": "2026-03-01 10:00:00.000Z"}
+	if !reflect.DeepEqual(status["last_session"], wantSession) {
+		t.Errorf("last_session = %v, want %v", status["last_session"], wantSession)
+	}
+	// The fake ignores filters, so each count is every fact; the filters
+	// show which were asked for
+	if status["open_blockers"] != 2.0 || status["open_todos"] != 2.0 {
+		t.Errorf("open counts = %v blockers, %v todos; want both counted", status["open_blockers"], status["open_todos"])
+	}
+	wantFilters := []string{
+		"project='p2' && fact_type='blocker' && stale=false",
+		"project='p2' && fact_type='todo' && stale=false",
+	}
+	if got := pb.listFilters("extracted_facts"); !reflect.DeepEqual(got, wantFilters) {
+		t.Errorf("fact filters = %q, want %q", got, wantFilters)
+	}
+	if projects, _ := status["active_projects"].([]interface{}); len(projects) != 2 {
+		t.Errorf("active_projects = %v, want both projects", status["active_projects"])
+	}
+}
+
+func TestStatusJSONUnmatched(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	pb := newFakePocketBase(t)
+	pb.add("projects", map[string]interface{}{"id": "p1", "name": "Other", "slug": "other", "repo_path": "/elsewhere", "status": "active"})
+
+	status := statusJSON(t, pb.URL)
+
+	for _, field := range []string{"project", "last_session"} {
+		value, ok := status[field]
+		if !ok || value != nil {
+			t.Errorf("%s = %v (present %v), want null", field, value, ok)
+		}
+	}
+	if status["open_blockers"] != 0.0 || status["open_todos"] != 0.0 {
+		t.Errorf("open counts = %v, %v; want 0", status["open_blockers"], status["open_todos"])
+	}
+	if projects, _ := status["active_projects"].([]interface{}); len(projects) != 1 {
+		t.Errorf("active_projects = %v, want the one active project", status["active_projects"])
+	}
+	if filters := pb.listFilters("extracted_facts"); len(filters) != 0 {
+		t.Errorf("counted facts %q without a matched project", filters)
+	}
+
+	// With no projects at all the list is empty, not null
+	pb = newFakePocketBase(t)
+	status = statusJSON(t, pb.URL)
+	if projects, ok := status["active_projects"].([]interface{}); !ok || len(projects) != 0 {
+		t.Errorf("active_projects = %v, want []", status["active_projects"])
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -27,7 +26,7 @@ func NewSwitchCommand(pbURL *string) *cobra.Command {
 func switchProject(pbURL, projectSlug string) error {
 	// Get project by slug
 	url := fmt.Sprintf("%s/api/collections/projects/records?filter=slug='%s'", pbURL, projectSlug)
-	resp, err := http.Get(url)
+	resp, err := httpGet(url)
 	if err != nil {
 		return fmt.Errorf("failed to fetch project: %w", err)
 	}
@@ -63,7 +62,7 @@ func switchProject(pbURL, projectSlug string) error {
 	}
 
 	// Pull context automatically
-	if err := pullContext(pbURL, projectSlug, "CLAUDE.md"); err != nil {
+	if err := pullContext(pbURL, projectSlug, "CLAUDE.md", pullOptions{}); err != nil {
 		fmt.Printf("Warning: failed to pull context: %v\n", err)
 	}
 
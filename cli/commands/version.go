@@ -0,0 +1,5 @@
+package commands
+
+// Version is the cct version, set by main. Commands that stamp their output,
+// such as context export, include it.
+var Version = "dev"
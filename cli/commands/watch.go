@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// compactWarningFraction is the share of the compact threshold at which a
+// warning is raised
+const compactWarningFraction = 0.85
+
+type watchOptions struct {
+	interval         time.Duration
+	compactThreshold int
+	daemonURL        string
+	slackWebhook     string
+	quietHours       string
+	notifyOnce       bool
+}
+
+func NewWatchCommand(pbURL *string) *cobra.Command {
+	var opts watchOptions
+
+	cmd := &cobra.Command{
+		Use:   "watch [project-slug]",
+		Short: "Follow new facts, handoffs, and token usage for a project",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectSlug, err := projectSlugArg(args)
+			if err != nil {
+				return err
+			}
+			return watchProject(*pbURL, projectSlug, opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.interval, "interval", 30*time.Second, "Polling interval")
+	cmd.Flags().IntVar(&opts.compactThreshold, "compact-threshold", 170000, "Token count at which Claude compacts context")
+	cmd.Flags().StringVar(&opts.daemonURL, "daemon-url", "", "Daemon HTTP address for live token counts, e.g. http://localhost:8091 (default: latest session)")
+	cmd.Flags().StringVar(&opts.slackWebhook, "notify-slack", "", "Slack incoming webhook URL to notify on compact warnings, blockers, and handoffs")
+	cmd.Flags().StringVar(&opts.quietHours, "slack-quiet-hours", "", "Suppress Slack notifications during these hours, e.g. 22-7")
+	cmd.Flags().BoolVar(&opts.notifyOnce, "notify-once", false, "Send the compact warning once instead of on every poll")
+
+	return cmd
+}
+
+type projectWatcher struct {
+	pbURL    string
+	project  *projectRecord
+	opts     watchOptions
+	slack    *slackNotifier
+	lastFact string
+	lastSess string
+	warned   bool
+}
+
+func watchProject(pbURL, projectSlug string, opts watchOptions) error {
+	project, err := getProject(pbURL, projectSlug)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(pbTimeLayout)
+	w := &projectWatcher{
+		pbURL:    pbURL,
+		project:  project,
+		opts:     opts,
+		lastFact: now,
+		lastSess: now,
+	}
+
+	if opts.slackWebhook != "" {
+		w.slack = &slackNotifier{webhookURL: opts.slackWebhook}
+		if opts.quietHours != "" {
+			start, end, err := parseQuietHours(opts.quietHours)
+			if err != nil {
+				return err
+			}
+			w.slack.quietStart, w.slack.quietEnd = start, end
+		}
+	}
+
+	fmt.Printf("👀 Watching %s (every %s, Ctrl+C to stop)\n", project.Name, opts.interval)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	w.poll()
+	for {
+		select {
+		case <-ticker.C:
+			w.poll()
+		case <-sigChan:
+			return nil
+		}
+	}
+}
+
+func (w *projectWatcher) poll() {
+	if err := w.checkFacts(); err != nil {
+		fmt.Printf("Warning: failed to fetch facts: %v\n", err)
+	}
+	if err := w.checkHandoffs(); err != nil {
+		fmt.Printf("Warning: failed to fetch sessions: %v\n", err)
+	}
+	if err := w.checkTokens(); err != nil {
+		fmt.Printf("Warning: failed to read token usage: %v\n", err)
+	}
+}
+
+func (w *projectWatcher) checkFacts() error {
+	facts, err := listRecords[factRecord](w.pbURL, "extracted_facts",
+		fmt.Sprintf("project='%s' && created>'%s'", w.project.ID, w.lastFact), "created")
+	if err != nil {
+		return err
+	}
+
+	for _, fact := range facts {
+		w.lastFact = fact.Created
+		fmt.Printf("• [%s] %s\n", fact.FactType, fact.Content)
+
+		if fact.FactType == "blocker" {
+			w.notify(fmt.Sprintf("Blocker in %s", w.project.Name),
+				fmt.Sprintf("⚠️ %s\n*Importance:* %d", fact.Content, fact.Importance))
+		}
+	}
+	return nil
+}
+
+func (w *projectWatcher) checkHandoffs() error {
+	sessions, err := listRecords[sessionRecord](w.pbURL, "session_history",
+		fmt.Sprintf("project='%s' && created>'%s'", w.project.ID, w.lastSess), "created")
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		w.lastSess = session.Created
+		fmt.Printf("📝 Handoff: %s (tokens: %d)\n", session.Summary, session.TokenCount)
+		w.notify(fmt.Sprintf("Handoff for %s", w.project.Name),
+			fmt.Sprintf("%s\n*Tokens:* %d", session.Summary, session.TokenCount))
+	}
+	return nil
+}
+
+func (w *projectWatcher) checkTokens() error {
+	tokens, err := w.tokenCount()
+	if err != nil {
+		return err
+	}
+
+	fraction := float64(tokens) / float64(w.opts.compactThreshold)
+	if fraction < compactWarningFraction {
+		w.warned = false
+		return nil
+	}
+
+	fmt.Printf("🔥 Token usage at %.0f%% (%d/%d)\n", fraction*100, tokens, w.opts.compactThreshold)
+	if w.opts.notifyOnce && w.warned {
+		return nil
+	}
+	w.warned = true
+
+	w.notify(fmt.Sprintf("%s is nearing compaction", w.project.Name),
+		fmt.Sprintf("%s %.0f%%\n*Tokens:* %d of %d", tokenBar(fraction), fraction*100, tokens, w.opts.compactThreshold))
+	return nil
+}
+
+// tokenCount reads the live count from the daemon when configured, otherwise
+// the count recorded with the latest session
+func (w *projectWatcher) tokenCount() (int, error) {
+	if w.opts.daemonURL != "" {
+		resp, err := httpGet(w.opts.daemonURL + "/status")
+		if err != nil {
+			return 0, err
+		}
+
+		var status struct {
+			TokenCount int `json:"token_count"`
+		}
+		if err := decodeResponse(resp, &status); err != nil {
+			return 0, err
+		}
+		return status.TokenCount, nil
+	}
+
+	url := fmt.Sprintf("%s/api/collections/session_history/records?filter=project='%s'&sort=-created&perPage=1", w.pbURL, w.project.ID)
+	resp, err := httpGet(url)
+	if err != nil {
+		return 0, err
+	}
+
+	var sessions struct {
+		Items []sessionRecord `json:"items"`
+	}
+	if err := decodeResponse(resp, &sessions); err != nil {
+		return 0, err
+	}
+
+	if len(sessions.Items) == 0 {
+		return 0, nil
+	}
+	return sessions.Items[0].TokenCount, nil
+}
+
+func (w *projectWatcher) notify(header, body string) {
+	if w.slack == nil {
+		return
+	}
+	if err := w.slack.notify(header, body); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
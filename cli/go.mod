@@ -2,9 +2,18 @@ module github.com/angelfreak/ccd/cli
 
 go 1.21
 
-require github.com/spf13/cobra v1.8.0
+require (
+	github.com/angelfreak/ccd/daemon v0.0.0
+	github.com/spf13/cobra v1.8.0
+	golang.org/x/term v0.15.0
+)
 
 require (
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/angelfreak/ccd/daemon => ../daemon
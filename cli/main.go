@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/angelfreak/ccd/cli/commands"
 	"github.com/spf13/cobra"
@@ -11,24 +13,43 @@ import (
 var (
 	version = "0.1.0"
 	pbURL   string
+	timeout time.Duration
 )
 
 func main() {
+	commands.Version = version
+
 	rootCmd := &cobra.Command{
 		Use:   "cct",
 		Short: "Claude Context Tracker CLI",
 		Long:  `A command-line tool for managing Claude Code project contexts`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			commands.SetTimeout(timeout)
+		},
 	}
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&pbURL, "pb-url", "http://localhost:8090", "PocketBase URL")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", commands.DefaultTimeout, "Give up on a request that takes longer than this (0 waits indefinitely)")
 
 	// Add commands
+	rootCmd.AddCommand(commands.NewInitCommand(&pbURL))
 	rootCmd.AddCommand(commands.NewPullCommand(&pbURL))
 	rootCmd.AddCommand(commands.NewPushCommand(&pbURL))
 	rootCmd.AddCommand(commands.NewStatusCommand(&pbURL))
 	rootCmd.AddCommand(commands.NewSwitchCommand(&pbURL))
 	rootCmd.AddCommand(commands.NewDiffCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewOpenCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewLedgerCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewVerifyLedgerCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewHandoffCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewFactsCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewContextCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewProjectsCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewSessionsCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewWatchCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewDaemonCommand(&pbURL))
+	rootCmd.AddCommand(commands.NewStaleCommand(&pbURL))
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
@@ -38,6 +59,10 @@ func main() {
 	})
 
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
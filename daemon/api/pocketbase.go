@@ -5,28 +5,184 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/angelfreak/ccd/daemon/extractor"
 )
 
 type Client struct {
-	baseURL string
-	client  *http.Client
+	baseURL    string
+	client     *http.Client
+	factFields FactFields
+}
+
+// FactFields names the fields of the extracted_facts collection that facts
+// are written to, read from, and filtered on, for schemas that don't use
+// CCD's names
+type FactFields struct {
+	Project    string
+	Type       string
+	Content    string
+	Importance string
+	Stale      string
+	Pinned     string
+	Verified   string
+	Embedding  string
+	Created    string
+}
+
+// DefaultFactFields are the field names of CCD's own schema
+var DefaultFactFields = FactFields{
+	Project:    "project",
+	Type:       "fact_type",
+	Content:    "content",
+	Importance: "importance",
+	Stale:      "stale",
+	Pinned:     "pinned",
+	Verified:   "verified",
+	Embedding:  "embedding",
+	Created:    "created",
+}
+
+// Set renames the field that CCD calls name, one of the DefaultFactFields
+// names, to field
+func (f *FactFields) Set(name, field string) error {
+	if field == "" {
+		return fmt.Errorf("empty field name for %q", name)
+	}
+	switch name {
+	case DefaultFactFields.Project:
+		f.Project = field
+	case DefaultFactFields.Type:
+		f.Type = field
+	case DefaultFactFields.Content:
+		f.Content = field
+	case DefaultFactFields.Importance:
+		f.Importance = field
+	case DefaultFactFields.Stale:
+		f.Stale = field
+	case DefaultFactFields.Pinned:
+		f.Pinned = field
+	case DefaultFactFields.Verified:
+		f.Verified = field
+	case DefaultFactFields.Embedding:
+		f.Embedding = field
+	case DefaultFactFields.Created:
+		f.Created = field
+	default:
+		return fmt.Errorf("unknown fact field %q: expected project, fact_type, content, importance, stale, pinned, verified, embedding, or created", name)
+	}
+	return nil
+}
+
+// pairs lists each default field name with the name it maps to
+func (f FactFields) pairs() [][2]string {
+	d := DefaultFactFields
+	return [][2]string{
+		{d.Project, f.Project},
+		{d.Type, f.Type},
+		{d.Content, f.Content},
+		{d.Importance, f.Importance},
+		{d.Stale, f.Stale},
+		{d.Pinned, f.Pinned},
+		{d.Verified, f.Verified},
+		{d.Embedding, f.Embedding},
+		{d.Created, f.Created},
+	}
+}
+
+// toDefault renames a record's mapped fields back to the default names that
+// FactRecord decodes. A field that only shares a default name, while that
+// name is mapped elsewhere, is dropped.
+func (f FactFields) toDefault(record map[string]json.RawMessage) map[string]json.RawMessage {
+	names := make(map[string]string)
+	for _, pair := range f.pairs() {
+		if pair[0] != pair[1] {
+			names[pair[0]] = ""
+		}
+	}
+	for _, pair := range f.pairs() {
+		names[pair[1]] = pair[0]
+	}
+
+	renamed := make(map[string]json.RawMessage, len(record))
+	for field, value := range record {
+		name, mapped := names[field]
+		switch {
+		case !mapped:
+			renamed[field] = value
+		case name != "":
+			renamed[name] = value
+		}
+	}
+	return renamed
 }
 
 type Project struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	RepoPath string `json:"repo_path"`
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Slug      string   `json:"slug"`
+	RepoPath  string   `json:"repo_path"`
+	Status    string   `json:"status"`
+	Priority  int      `json:"priority"`
+	TechStack []string `json:"tech_stack"`
+}
+
+// FactRecord is an extracted fact as stored in PocketBase
+type FactRecord struct {
+	ID         string `json:"id"`
+	Project    string `json:"project"`
+	FactType   string `json:"fact_type"`
+	Content    string `json:"content"`
+	Importance int    `json:"importance"`
+	Stale      bool   `json:"stale"`
+	Pinned     bool   `json:"pinned"`
+	Created    string `json:"created"`
+	// Verified is set on facts a human has confirmed or corrected
+	Verified bool `json:"verified"`
+	// Embedding is the fact's content as a vector, empty until computed
+	Embedding []float64 `json:"embedding"`
+}
+
+// CreatedAt parses the PocketBase created timestamp
+func (f FactRecord) CreatedAt() (time.Time, error) {
+	return time.Parse(pbTimeLayout, f.Created)
+}
+
+// pbTimeLayout is the datetime format PocketBase uses in records and filters
+const pbTimeLayout = "2006-01-02 15:04:05.000Z"
+
+// FormatTime formats t for use in a PocketBase filter expression
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(pbTimeLayout)
+}
+
+type listResponse struct {
+	Page       int             `json:"page"`
+	TotalPages int             `json:"totalPages"`
+	Items      json.RawMessage `json:"items"`
 }
 
 func NewClient(baseURL string) *Client {
 	return &Client{
-		baseURL: baseURL,
-		client:  &http.Client{},
+		baseURL:    baseURL,
+		client:     &http.Client{},
+		factFields: DefaultFactFields,
 	}
 }
 
+// SetFactFields changes the field names facts are written, read, and
+// filtered by
+func (c *Client) SetFactFields(fields FactFields) {
+	c.factFields = fields
+}
+
+// FactFields returns the field names in use, for building ListFacts filters
+func (c *Client) FactFields() FactFields {
+	return c.factFields
+}
+
 func (c *Client) VerifyProject(projectID string) error {
 	_, err := c.GetProject(projectID)
 	return err
@@ -52,36 +208,126 @@ func (c *Client) GetProject(projectID string) (*Project, error) {
 	return &project, nil
 }
 
-func (c *Client) CreateFact(projectID string, fact extractor.Fact) error {
+// ListProjects returns every project matching a PocketBase filter expression,
+// or all projects when filter is empty
+func (c *Client) ListProjects(filter string) ([]Project, error) {
+	var projects []Project
+	for page := 1; ; page++ {
+		query := url.Values{}
+		if filter != "" {
+			query.Set("filter", filter)
+		}
+		query.Set("page", fmt.Sprint(page))
+		query.Set("perPage", "200")
+
+		endpoint := fmt.Sprintf("%s/api/collections/projects/records?%s", c.baseURL, query.Encode())
+		resp, err := c.client.Get(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		var list listResponse
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to list projects: status %d", resp.StatusCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var items []Project
+		if err := json.Unmarshal(list.Items, &items); err != nil {
+			return nil, err
+		}
+		projects = append(projects, items...)
+
+		if page >= list.TotalPages {
+			break
+		}
+	}
+
+	return projects, nil
+}
+
+func (c *Client) UpdateProjectTechStack(projectID string, techStack []string) error {
+	return c.updateProject(projectID, map[string]interface{}{
+		"tech_stack": techStack,
+	})
+}
+
+func (c *Client) UpdateProjectPriority(projectID string, priority int) error {
+	return c.updateProject(projectID, map[string]interface{}{
+		"priority": priority,
+	})
+}
+
+func (c *Client) updateProject(projectID string, data map[string]interface{}) error {
+	url := fmt.Sprintf("%s/api/collections/projects/records/%s", c.baseURL, projectID)
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update project: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// CreateFact posts a fact and returns the ID of the created record
+func (c *Client) CreateFact(projectID string, fact extractor.Fact) (string, error) {
 	url := fmt.Sprintf("%s/api/collections/extracted_facts/records", c.baseURL)
 
+	fields := c.factFields
 	data := map[string]interface{}{
-		"project":    projectID,
-		"fact_type":  fact.Type,
-		"content":    fact.Content,
-		"importance": fact.Importance,
-		"stale":      false,
+		fields.Project:    projectID,
+		fields.Type:       fact.Type,
+		fields.Content:    fact.Content,
+		fields.Importance: fact.Importance,
+		fields.Stale:      false,
 	}
 
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	resp, err := c.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to create fact: status %d", resp.StatusCode)
+		return "", fmt.Errorf("failed to create fact: status %d", resp.StatusCode)
 	}
 
-	return nil
+	var record FactRecord
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return "", err
+	}
+	return record.ID, nil
 }
 
-func (c *Client) CreateSession(projectID, summary string, tokenCount int) error {
+// CreateSession records a session checkpoint. Whatever of meta the
+// transcript provided is stored with it; without a start time the session
+// is recorded as starting now.
+func (c *Client) CreateSession(projectID, summary string, tokenCount int, sourceFiles []string, meta extractor.Metadata) error {
 	url := fmt.Sprintf("%s/api/collections/session_history/records", c.baseURL)
 
 	data := map[string]interface{}{
@@ -90,6 +336,21 @@ func (c *Client) CreateSession(projectID, summary string, tokenCount int) error
 		"token_count":   tokenCount,
 		"session_start": "now",
 	}
+	if len(sourceFiles) > 0 {
+		data["source_files"] = sourceFiles
+	}
+	if !meta.StartTime.IsZero() {
+		data["session_start"] = FormatTime(meta.StartTime)
+	}
+	if meta.Model != "" {
+		data["model"] = meta.Model
+	}
+	if meta.Cwd != "" {
+		data["cwd"] = meta.Cwd
+	}
+	if meta.Branch != "" {
+		data["branch"] = meta.Branch
+	}
 
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -113,7 +374,7 @@ func (c *Client) UpdateFactStale(factID string, stale bool) error {
 	url := fmt.Sprintf("%s/api/collections/extracted_facts/records/%s", c.baseURL, factID)
 
 	data := map[string]interface{}{
-		"stale": stale,
+		c.factFields.Stale: stale,
 	}
 
 	jsonData, err := json.Marshal(data)
@@ -139,3 +400,147 @@ func (c *Client) UpdateFactStale(factID string, stale bool) error {
 
 	return nil
 }
+
+// UpdateFactContent replaces a fact's content and importance, as when a
+// later message adds detail to it. PocketBase bumps the record's updated
+// time.
+func (c *Client) UpdateFactContent(factID, content string, importance int) error {
+	url := fmt.Sprintf("%s/api/collections/extracted_facts/records/%s", c.baseURL, factID)
+
+	data := map[string]interface{}{
+		c.factFields.Content:    content,
+		c.factFields.Importance: importance,
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update fact content: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// UpdateFactEmbedding stores the embedding vector computed for a fact
+func (c *Client) UpdateFactEmbedding(factID string, embedding []float64) error {
+	url := fmt.Sprintf("%s/api/collections/extracted_facts/records/%s", c.baseURL, factID)
+
+	data := map[string]interface{}{
+		c.factFields.Embedding: embedding,
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update fact embedding: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ListFacts returns all facts for a project matching an optional PocketBase
+// filter expression, following pagination. The filter must use the mapped
+// field names from FactFields; the records returned use the default ones.
+func (c *Client) ListFacts(projectID, filter string) ([]FactRecord, error) {
+	expr := fmt.Sprintf("%s = %q", c.factFields.Project, projectID)
+	if filter != "" {
+		expr = fmt.Sprintf("%s && (%s)", expr, filter)
+	}
+
+	var facts []FactRecord
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("filter", expr)
+		query.Set("page", fmt.Sprint(page))
+		query.Set("perPage", "200")
+
+		endpoint := fmt.Sprintf("%s/api/collections/extracted_facts/records?%s", c.baseURL, query.Encode())
+		resp, err := c.client.Get(endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		var list listResponse
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to list facts: status %d", resp.StatusCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(list.Items, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			data, err := json.Marshal(c.factFields.toDefault(item))
+			if err != nil {
+				return nil, err
+			}
+			var fact FactRecord
+			if err := json.Unmarshal(data, &fact); err != nil {
+				return nil, err
+			}
+			facts = append(facts, fact)
+		}
+
+		if page >= list.TotalPages {
+			break
+		}
+	}
+
+	return facts, nil
+}
+
+func (c *Client) DeleteFact(factID string) error {
+	url := fmt.Sprintf("%s/api/collections/extracted_facts/records/%s", c.baseURL, factID)
+
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete fact: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
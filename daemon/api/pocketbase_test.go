@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+)
+
+// remappedFields is a schema that calls the type "kind", the content
+// "body", and the project "repo"
+func remappedFields(t *testing.T) FactFields {
+	t.Helper()
+	fields := DefaultFactFields
+	for name, field := range map[string]string{"fact_type": "kind", "content": "body", "project": "repo"} {
+		if err := fields.Set(name, field); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fields
+}
+
+func TestCreateFactUsesMappedFields(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/collections/extracted_facts/records" {
+			http.NotFound(w, r)
+			return
+		}
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]string{"id": "fact1"})
+	}))
+	defer server.Close()
+
+	fact := extractor.Fact{Type: "decision", Content: "Use Postgres", Importance: 4}
+	tests := []struct {
+		fields FactFields
+		want   map[string]interface{}
+	}{
+		{DefaultFactFields, map[string]interface{}{
+			"project": "proj1", "fact_type": "decision", "content": "Use Postgres", "importance": 4.0, "stale": false,
+		}},
+		{remappedFields(t), map[string]interface{}{
+			"repo": "proj1", "kind": "decision", "body": "Use Postgres", "importance": 4.0, "stale": false,
+		}},
+	}
+	for _, tt := range tests {
+		c := NewClient(server.URL)
+		c.SetFactFields(tt.fields)
+		id, err := c.CreateFact("proj1", fact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != "fact1" {
+			t.Errorf("CreateFact returned ID %q, want fact1", id)
+		}
+		if len(body) != len(tt.want) {
+			t.Errorf("posted %v, want %v", body, tt.want)
+		}
+		for field, want := range tt.want {
+			if body[field] != want {
+				t.Errorf("posted %s = %v, want %v (body %v)", field, body[field], want, body)
+			}
+		}
+	}
+}
+
+func TestListFactsDecodesMappedFields(t *testing.T) {
+	var filter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter = r.URL.Query().Get("filter")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"page":       1,
+			"totalPages": 1,
+			"items": []map[string]interface{}{{
+				"id":         "fact1",
+				"repo":       "proj1",
+				"kind":       "blocker",
+				"body":       "CI is red on main",
+				"importance": 5,
+				// An unrelated field that happens to share a default name
+				"content": "rendered HTML",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL)
+	c.SetFactFields(remappedFields(t))
+	facts, err := c.ListFacts("proj1", `kind = "blocker"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `repo = "proj1" && (kind = "blocker")`; filter != want {
+		t.Errorf("filter = %q, want %q", filter, want)
+	}
+	want := FactRecord{ID: "fact1", Project: "proj1", FactType: "blocker", Content: "CI is red on main", Importance: 5}
+	if len(facts) != 1 || facts[0].ID != want.ID || facts[0].Project != want.Project ||
+		facts[0].FactType != want.FactType || facts[0].Content != want.Content || facts[0].Importance != want.Importance {
+		t.Errorf("ListFacts = %+v, want [%+v]", facts, want)
+	}
+}
+
+func TestFactFieldsSetRejectsBadNames(t *testing.T) {
+	fields := DefaultFactFields
+	if err := fields.Set("title", "name"); err == nil {
+		t.Error("Set accepted an unknown field")
+	}
+	if err := fields.Set("content", ""); err == nil {
+		t.Error("Set accepted an empty field name")
+	}
+	if fields != DefaultFactFields {
+		t.Errorf("rejected renames changed the fields: %+v", fields)
+	}
+}
@@ -0,0 +1,39 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitBranch(t *testing.T) {
+	repo := t.TempDir()
+	if got := GitBranch(repo); got != "" {
+		t.Errorf("GitBranch outside a repo = %q, want none", got)
+	}
+
+	gitDir := filepath.Join(repo, ".git")
+	if err := os.Mkdir(gitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/feature/login\n"), 0644)
+	if got := GitBranch(repo); got != "feature/login" {
+		t.Errorf("GitBranch = %q, want feature/login", got)
+	}
+
+	// A worktree's .git file points at its git directory
+	worktree := t.TempDir()
+	worktreeGit := filepath.Join(gitDir, "worktrees", "hotfix")
+	os.MkdirAll(worktreeGit, 0755)
+	os.WriteFile(filepath.Join(worktreeGit, "HEAD"), []byte("ref: refs/heads/hotfix\n"), 0644)
+	os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+worktreeGit+"\n"), 0644)
+	if got := GitBranch(worktree); got != "hotfix" {
+		t.Errorf("GitBranch in a worktree = %q, want hotfix", got)
+	}
+
+	// A detached HEAD isn't on a branch
+	os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("3f1c2e9a0b7d4c5e6f708192a3b4c5d6e7f80912\n"), 0644)
+	if got := GitBranch(repo); got != "" {
+		t.Errorf("GitBranch with a detached HEAD = %q, want none", got)
+	}
+}
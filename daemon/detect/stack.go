@@ -0,0 +1,94 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// marker maps a file (or dependency name) to the stack it implies
+type marker struct {
+	file  string
+	stack string
+}
+
+// markers are checked in order; the first match for a stack wins
+var markers = []marker{
+	{"go.mod", "Go"},
+	{"package.json", "Node"},
+	{"tsconfig.json", "TypeScript"},
+	{"requirements.txt", "Python"},
+	{"pyproject.toml", "Python"},
+	{"setup.py", "Python"},
+	{"Pipfile", "Python"},
+	{"Cargo.toml", "Rust"},
+	{"pom.xml", "Java"},
+	{"build.gradle", "Java"},
+	{"build.gradle.kts", "Kotlin"},
+	{"Gemfile", "Ruby"},
+	{"composer.json", "PHP"},
+	{"mix.exs", "Elixir"},
+	{"Package.swift", "Swift"},
+	{"pubspec.yaml", "Dart"},
+	{"CMakeLists.txt", "C/C++"},
+	{"Dockerfile", "Docker"},
+}
+
+// nodeFrameworks are detected from package.json dependency names
+var nodeFrameworks = []marker{
+	{"react", "React"},
+	{"vue", "Vue"},
+	{"svelte", "Svelte"},
+	{"next", "Next.js"},
+	{"express", "Express"},
+	{"vite", "Vite"},
+}
+
+// TechStack inspects marker files in repoPath and returns the detected
+// languages and frameworks in a stable order
+func TechStack(repoPath string) []string {
+	var stack []string
+	seen := make(map[string]bool)
+
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			stack = append(stack, name)
+		}
+	}
+
+	for _, m := range markers {
+		if fileExists(filepath.Join(repoPath, m.file)) {
+			add(m.stack)
+		}
+	}
+
+	// .csproj / .sln files don't have a fixed name
+	if matches, _ := filepath.Glob(filepath.Join(repoPath, "*.csproj")); len(matches) > 0 {
+		add("C#")
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "package.json")); err == nil {
+		for _, framework := range detectNodeFrameworks(string(data)) {
+			add(framework)
+		}
+	}
+
+	return stack
+}
+
+// detectNodeFrameworks looks for well-known dependency names in package.json
+func detectNodeFrameworks(packageJSON string) []string {
+	var found []string
+	for _, framework := range nodeFrameworks {
+		if strings.Contains(packageJSON, `"`+framework.file+`"`) {
+			found = append(found, framework.stack)
+		}
+	}
+	return found
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,35 @@
+package detect
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTechStack(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+	}{
+		{"go", []string{"Go"}},
+		{"node-react", []string{"Node", "TypeScript", "React", "Vite"}},
+		{"python", []string{"Python"}},
+		{"rust", []string{"Rust"}},
+		{"java", []string{"Java"}},
+		{"dotnet", []string{"C#"}},
+		{"polyglot", []string{"Go", "Node", "Docker", "Express"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			if got := TechStack(filepath.Join("testdata", tt.fixture)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TechStack = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTechStackEmptyRepo(t *testing.T) {
+	if got := TechStack(t.TempDir()); len(got) != 0 {
+		t.Errorf("TechStack of an empty repo = %v, want nothing", got)
+	}
+}
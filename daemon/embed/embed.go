@@ -0,0 +1,129 @@
+package embed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The Claude API has no embeddings endpoint; Anthropic points to Voyage AI,
+// whose API (like other OpenAI-compatible ones) takes {input, model} and
+// returns {data: [{embedding, index}]}.
+const (
+	DefaultURL   = "https://api.voyageai.com/v1/embeddings"
+	DefaultModel = "voyage-3"
+	// APIKeyEnv names the environment variable holding the API key
+	APIKeyEnv = "VOYAGE_API_KEY"
+)
+
+// Client requests embedding vectors from an embeddings API
+type Client struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func NewClient(url, apiKey, model string) *Client {
+	if url == "" {
+		url = DefaultURL
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	return &Client{
+		url:    url,
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Model returns the embedding model requested by the client
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Embed returns one vector per input text, in input order
+func (c *Client) Embed(texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"input": texts,
+		"model": c.model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to compute embeddings: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Data []struct {
+			Embedding []float64 `json:"embedding"`
+			Index     int       `json:"index"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+
+	return vectors, nil
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 when they differ
+// in length or either is all zeros
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
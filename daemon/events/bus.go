@@ -0,0 +1,148 @@
+package events
+
+import (
+	"log"
+	"sync"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+)
+
+// DefaultQueueSize is the per-subscriber buffer used when none is configured
+const DefaultQueueSize = 100
+
+// FactEvent carries the facts extracted from one pass over a log file
+type FactEvent struct {
+	ProjectID  string
+	SessionID  string
+	Facts      []extractor.Fact
+	TokenCount int
+	// SessionEnded is set when the transcript signalled the end of the session
+	SessionEnded bool
+	// SessionStarted is set on the first event of a session begun after a
+	// gap in activity, so processors can reset per-session state
+	SessionStarted bool
+	// Metadata is the transcript's model, start time, and working directory
+	Metadata extractor.Metadata
+}
+
+// Handler processes a fact event
+type Handler func(FactEvent)
+
+// Bus delivers fact events from the watcher to processors. Each subscriber
+// gets its own buffered queue and goroutine, so a slow processor never blocks
+// log parsing.
+type Bus struct {
+	mu          sync.RWMutex
+	queueSize   int
+	subscribers []*subscriber
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// subscriber is one processor's queue. When an event is dropped from a full
+// queue its session start and end flags are kept in pending, to be merged
+// into the next event the processor takes, so they are never lost.
+type subscriber struct {
+	queue chan FactEvent
+
+	// mu guards pending. Publish holds it from a failed send until the flags
+	// are recorded, so a processor taking an event from the full queue
+	// always sees them.
+	mu      sync.Mutex
+	pending lifecycle
+}
+
+// lifecycle holds the session flags of dropped events
+type lifecycle struct {
+	started bool
+	ended   bool
+}
+
+// take merges the pending flags into event and clears them
+func (s *subscriber) take(event FactEvent) FactEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.SessionStarted = event.SessionStarted || s.pending.started
+	event.SessionEnded = event.SessionEnded || s.pending.ended
+	s.pending = lifecycle{}
+	return event
+}
+
+// offer queues event without blocking, reporting whether it was queued.
+// The session flags of an event that doesn't fit are kept for the next one.
+func (s *subscriber) offer(event FactEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case s.queue <- event:
+		return true
+	default:
+		s.pending.started = s.pending.started || event.SessionStarted
+		s.pending.ended = s.pending.ended || event.SessionEnded
+		return false
+	}
+}
+
+func NewBus(queueSize int) *Bus {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	return &Bus{
+		queueSize: queueSize,
+	}
+}
+
+// Subscribe registers a handler that runs in its own goroutine
+func (b *Bus) Subscribe(handler Handler) {
+	sub := &subscriber{queue: make(chan FactEvent, b.queueSize)}
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, sub)
+	b.mu.Unlock()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for event := range sub.queue {
+			handler(sub.take(event))
+		}
+	}()
+}
+
+// Publish queues an event for every subscriber without blocking. Events are
+// dropped, with a warning, for subscribers whose queue is full, but their
+// session start and end flags are carried over to the next event the
+// subscriber handles.
+func (b *Bus) Publish(event FactEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, sub := range b.subscribers {
+		if !sub.offer(event) {
+			log.Printf("Warning: event queue full, dropping %d facts for session %s",
+				len(event.Facts), event.SessionID)
+		}
+	}
+}
+
+// Close stops accepting events and waits for queued events to be handled
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, sub := range b.subscribers {
+		close(sub.queue)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockedHandler returns a handler that records each event it handles and
+// blocks inside its first call until release is closed. entered is closed
+// once that first call starts.
+func blockedHandler() (handler Handler, handled chan FactEvent, entered, release chan struct{}) {
+	handled = make(chan FactEvent, 100)
+	entered, release = make(chan struct{}), make(chan struct{})
+	first := true
+	handler = func(event FactEvent) {
+		if first {
+			first = false
+			close(entered)
+			<-release
+		}
+		handled <- event
+	}
+	return handler, handled, entered, release
+}
+
+func TestFullQueueDropsEventsButKeepsSessionFlags(t *testing.T) {
+	var logs bytes.Buffer
+	output := log.Writer()
+	defer log.SetOutput(output)
+	log.SetOutput(&logs)
+
+	bus := NewBus(1)
+	handler, handled, entered, release := blockedHandler()
+	bus.Subscribe(handler)
+
+	bus.Publish(FactEvent{SessionID: "one"})
+	<-entered
+	// The handler is stuck on "one", so "two" fills the queue and the rest
+	// are dropped
+	bus.Publish(FactEvent{SessionID: "two"})
+	bus.Publish(FactEvent{SessionID: "three", SessionEnded: true})
+	bus.Publish(FactEvent{SessionID: "four", SessionStarted: true})
+	close(release)
+
+	for _, want := range []FactEvent{
+		{SessionID: "one"},
+		{SessionID: "two", SessionEnded: true, SessionStarted: true},
+	} {
+		select {
+		case got := <-handled:
+			if got.SessionID != want.SessionID || got.SessionEnded != want.SessionEnded || got.SessionStarted != want.SessionStarted {
+				t.Errorf("handled %+v, want %+v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %s never handled", want.SessionID)
+		}
+	}
+
+	// The carried flags were used up
+	bus.Publish(FactEvent{SessionID: "five"})
+	bus.Close()
+	if got := <-handled; got.SessionID != "five" || got.SessionEnded || got.SessionStarted {
+		t.Errorf("handled %+v, want five without session flags", got)
+	}
+	if len(handled) != 0 {
+		t.Errorf("dropped events were handled: %+v", <-handled)
+	}
+	if n := strings.Count(logs.String(), "Warning: event queue full"); n != 2 {
+		t.Errorf("warned %d times, want once per dropped event:\n%s", n, logs.String())
+	}
+}
+
+func TestCloseDrainsQueuedEvents(t *testing.T) {
+	bus := NewBus(10)
+	handler, handled, entered, release := blockedHandler()
+	bus.Subscribe(handler)
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		bus.Publish(FactEvent{SessionID: id})
+	}
+	<-entered
+
+	closed := make(chan struct{})
+	go func() {
+		bus.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned while events were still queued")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-closed
+	if len(handled) != 4 {
+		t.Fatalf("handled %d events before Close returned, want 4", len(handled))
+	}
+	for _, want := range []string{"a", "b", "c", "d"} {
+		if got := <-handled; got.SessionID != want {
+			t.Errorf("handled %s, want %s", got.SessionID, want)
+		}
+	}
+
+	// Events published after Close are ignored
+	bus.Publish(FactEvent{SessionID: "late"})
+	bus.Close()
+	if len(handled) != 0 {
+		t.Errorf("handled an event published after Close")
+	}
+}
@@ -1,6 +1,8 @@
 package extractor
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/angelfreak/ccd/daemon/types"
@@ -10,9 +12,21 @@ type Fact struct {
 	Type       string
 	Content    string
 	Importance int
+	// Score is the unrounded importance behind Importance, set alongside
+	// it in smart mode
+	Score float64
+	// Keyword is what the extraction rule matched, for explaining why the
+	// fact was created
+	Keyword string
 }
 
 func ExtractFacts(conv *types.Conversation) []Fact {
+	return ExtractFactsWithRules(conv, DefaultRules())
+}
+
+// ExtractFactsWithRules extracts facts from the assistant's messages, each
+// rule producing at most one fact per message
+func ExtractFactsWithRules(conv *types.Conversation, rules []Rule) []Fact {
 	var facts []Fact
 
 	for _, msg := range conv.Messages {
@@ -20,65 +34,44 @@ func ExtractFacts(conv *types.Conversation) []Fact {
 			continue
 		}
 
-		content := msg.Content
-
-		// Extract decisions
-		if containsAny(content, []string{"decided to", "chose to", "going with", "will use"}) {
-			facts = append(facts, Fact{
-				Type:       "decision",
-				Content:    extractSentence(content, []string{"decided to", "chose to", "going with", "will use"}),
-				Importance: 4,
-			})
+		for _, rule := range rules {
+			if fact, ok := rule.extract(msg.Content); ok {
+				facts = append(facts, fact)
+			}
 		}
+	}
 
-		// Extract blockers
-		if containsAny(content, []string{"blocked by", "can't proceed", "error:", "failed to"}) {
-			facts = append(facts, Fact{
-				Type:       "blocker",
-				Content:    extractSentence(content, []string{"blocked by", "can't proceed", "error:", "failed to"}),
-				Importance: 5,
-			})
-		}
+	return facts
+}
 
-		// Extract todos
-		if containsAny(content, []string{"TODO:", "need to", "should", "must"}) {
-			facts = append(facts, Fact{
-				Type:       "todo",
-				Content:    extractSentence(content, []string{"TODO:", "need to", "should", "must"}),
-				Importance: 3,
-			})
-		}
+var configKeywords = []string{"env var", "set PORT", ".env", "config", "secret", "credential"}
 
-		// Extract file changes
-		if containsAny(content, []string{"created", "modified", "updated", "deleted"}) &&
-			containsAny(content, []string{".ts", ".tsx", ".js", ".jsx", ".go", ".py", ".java"}) {
-			facts = append(facts, Fact{
-				Type:       "file_change",
-				Content:    extractSentence(content, []string{"created", "modified", "updated", "deleted"}),
-				Importance: 2,
-			})
-		}
+var (
+	// testStatusPattern matches mentions of test runs and CI. Short terms
+	// are matched as whole words so "ci" isn't found inside "decide".
+	testStatusPattern = regexp.MustCompile(`(?i)\b(?:tests? (?:pass|fail)\w*|ci|build (?:broke|fail)\w*|coverage|flaky)\b`)
+	// testFailurePattern marks a test or CI mention as a failure
+	testFailurePattern = regexp.MustCompile(`(?i)\b(?:fail\w*|broken?|broke|red|flak(?:e?y))\b`)
+)
 
-		// Extract dependencies
-		if containsAny(content, []string{"installed", "added dependency", "npm install", "go get"}) {
-			facts = append(facts, Fact{
-				Type:       "dependency",
-				Content:    extractSentence(content, []string{"installed", "added dependency", "npm install", "go get"}),
-				Importance: 3,
-			})
-		}
+// IsTestFailure reports whether a test or CI mention describes failing
+// tests or a broken build
+func IsTestFailure(content string) bool {
+	return testFailurePattern.MatchString(content)
+}
 
-		// Extract insights
-		if containsAny(content, []string{"discovered", "found that", "interesting", "note that"}) {
-			facts = append(facts, Fact{
-				Type:       "insight",
-				Content:    extractSentence(content, []string{"discovered", "found that", "interesting", "note that"}),
-				Importance: 3,
-			})
-		}
-	}
+var (
+	// KEY=value / key: value assignments whose name suggests a secret
+	secretAssignment = regexp.MustCompile(`(?i)\b([a-z0-9_]*(?:secret|token|password|passwd|api_?key|credential)[a-z0-9_]*)(\s*[=:]\s*)("[^"]*"|'[^']*'|\S+)`)
+	// Long opaque strings that look like generated keys or tokens
+	tokenLike = regexp.MustCompile(`\b[A-Za-z0-9_\-]{32,}\b`)
+)
 
-	return facts
+// redactSecretValues masks values that look like secrets so config facts can
+// be stored without leaking credentials
+func redactSecretValues(text string) string {
+	text = secretAssignment.ReplaceAllString(text, "${1}${2}[REDACTED]")
+	return tokenLike.ReplaceAllString(text, "[REDACTED]")
 }
 
 func containsAny(text string, keywords []string) bool {
@@ -91,6 +84,25 @@ func containsAny(text string, keywords []string) bool {
 	return false
 }
 
+// matchedKeyword returns the first of keywords found in text, or ""
+func matchedKeyword(text string, keywords []string) string {
+	lowerText := strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(lowerText, strings.ToLower(keyword)) {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// Explain describes which rule created the fact and what it matched
+func (f Fact) Explain() string {
+	if f.Keyword == "" {
+		return fmt.Sprintf("rule %s", f.Type)
+	}
+	return fmt.Sprintf("rule %s matched %q", f.Type, f.Keyword)
+}
+
 func extractSentence(text string, keywords []string) string {
 	sentences := strings.Split(text, ".")
 	for _, sentence := range sentences {
@@ -100,3 +112,13 @@ func extractSentence(text string, keywords []string) string {
 	}
 	return ""
 }
+
+// extractMatchingSentence returns the first sentence pattern matches
+func extractMatchingSentence(text string, pattern *regexp.Regexp) string {
+	for _, sentence := range strings.Split(text, ".") {
+		if pattern.MatchString(sentence) {
+			return strings.TrimSpace(sentence)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/types"
+)
+
+// factsOfType returns the facts of one type
+func factsOfType(facts []Fact, factType string) []Fact {
+	var result []Fact
+	for _, fact := range facts {
+		if fact.Type == factType {
+			result = append(result, fact)
+		}
+	}
+	return result
+}
+
+func TestConfigChangeCapturedAndRedacted(t *testing.T) {
+	conv := &types.Conversation{Messages: []types.Message{
+		{Role: "user", Content: "Set up the webhook config, API_TOKEN=hunter2hunter2"},
+		{Role: "assistant", Content: "Updated the config so API_TOKEN=hunter2hunter2 and the webhook signs with ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789abcd. It's live."},
+		{Role: "assistant", Content: "Renamed the handler for clarity"},
+	}}
+
+	configs := factsOfType(ExtractFacts(conv), "config_change")
+	if len(configs) != 1 {
+		t.Fatalf("got %d config_change facts, want 1: %+v", len(configs), configs)
+	}
+	fact := configs[0]
+	if want := "Updated the config so API_TOKEN=[REDACTED] and the webhook signs with [REDACTED]"; fact.Content != want {
+		t.Errorf("content = %q, want %q", fact.Content, want)
+	}
+	if fact.Importance != 4 {
+		t.Errorf("importance = %d, want 4", fact.Importance)
+	}
+	if fact.Keyword != "config" {
+		t.Errorf("keyword = %q, want config", fact.Keyword)
+	}
+}
+
+func TestConfigChangeKeywords(t *testing.T) {
+	for _, content := range []string{
+		"Added the DATABASE_URL env var for staging",
+		"I'll set PORT to 3000 in the compose file",
+		"Rotated the credential used by the deploy job",
+	} {
+		conv := &types.Conversation{Messages: []types.Message{{Role: "assistant", Content: content}}}
+		if configs := factsOfType(ExtractFacts(conv), "config_change"); len(configs) != 1 {
+			t.Errorf("%q: got %d config_change facts, want 1", content, len(configs))
+		}
+	}
+}
+
+func TestTestStatusPassingAndFailing(t *testing.T) {
+	tests := []struct {
+		content    string
+		wantFacts  int
+		importance int
+	}{
+		{"All tests pass after the refactor", 1, 3},
+		{"Coverage is up to 82% on the parser", 1, 3},
+		{"CI is green on main again", 1, 3},
+		{"Two tests failed in the auth package", 1, 5},
+		{"CI is red on main", 1, 5},
+		{"The build broke when I bumped the SDK", 1, 5},
+		{"TestUpload is flaky under -race", 1, 5},
+		// "ci" inside a word is not a CI mention
+		{"We should decide on the specification", 0, 0},
+	}
+	for _, tt := range tests {
+		conv := &types.Conversation{Messages: []types.Message{{Role: "assistant", Content: tt.content}}}
+		statuses := factsOfType(ExtractFacts(conv), "test_status")
+		if len(statuses) != tt.wantFacts {
+			t.Errorf("%q: got %d test_status facts, want %d", tt.content, len(statuses), tt.wantFacts)
+			continue
+		}
+		if tt.wantFacts == 1 && statuses[0].Importance != tt.importance {
+			t.Errorf("%q: importance %d, want %d", tt.content, statuses[0].Importance, tt.importance)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package extractor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/types"
+)
+
+// Metadata describes the session a transcript was recorded in
+type Metadata struct {
+	Model     string    `json:"model,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	Cwd       string    `json:"cwd,omitempty"`
+	// Branch is the git branch the session worked on
+	Branch string `json:"branch,omitempty"`
+}
+
+// Header names each field is read from, in order of preference
+var (
+	modelKeys  = []string{"model"}
+	startKeys  = []string{"start_time", "started_at", "session_start", "timestamp"}
+	cwdKeys    = []string{"cwd", "working_directory", "workdir"}
+	branchKeys = []string{"git_branch", "gitbranch", "branch"}
+)
+
+// ExtractMetadata reads a transcript's model, start time, working directory,
+// and git branch from its header. Without a header the model falls back to the
+// latest assistant message's and the start time to the first message's.
+// Fields that can't be found are left zero.
+func ExtractMetadata(conv *types.Conversation) Metadata {
+	var meta Metadata
+	if conv == nil {
+		return meta
+	}
+
+	meta.Model = firstMetadata(conv.Metadata, modelKeys)
+	meta.Cwd = firstMetadata(conv.Metadata, cwdKeys)
+	meta.Branch = firstMetadata(conv.Metadata, branchKeys)
+	if start := firstMetadata(conv.Metadata, startKeys); start != "" {
+		meta.StartTime, _ = time.Parse(time.RFC3339, start)
+	}
+
+	if meta.Model == "" {
+		for i := len(conv.Messages) - 1; i >= 0; i-- {
+			if conv.Messages[i].Model != "" {
+				meta.Model = conv.Messages[i].Model
+				break
+			}
+		}
+	}
+	if meta.StartTime.IsZero() {
+		for _, msg := range conv.Messages {
+			if !msg.Timestamp.IsZero() {
+				meta.StartTime = msg.Timestamp
+				break
+			}
+		}
+	}
+
+	return meta
+}
+
+// IsZero reports whether no metadata was found
+func (m Metadata) IsZero() bool {
+	return m.Model == "" && m.Cwd == "" && m.Branch == "" && m.StartTime.IsZero()
+}
+
+func firstMetadata(values map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(values[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
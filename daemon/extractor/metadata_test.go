@@ -0,0 +1,58 @@
+package extractor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/types"
+)
+
+func TestExtractMetadataFromHeader(t *testing.T) {
+	conv := &types.Conversation{
+		Metadata: map[string]string{
+			"model":      "claude-sonnet-4-5",
+			"started_at": "2026-03-01T09:00:00Z",
+			"cwd":        " /work/app ",
+			"git_branch": "main",
+		},
+		Messages: []types.Message{
+			{Role: "assistant", Content: "Hi", Model: "claude-haiku-4-5", Timestamp: time.Date(2026, 3, 1, 9, 5, 0, 0, time.UTC)},
+		},
+	}
+
+	want := Metadata{
+		Model:     "claude-sonnet-4-5",
+		StartTime: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Cwd:       "/work/app",
+		Branch:    "main",
+	}
+	if got := ExtractMetadata(conv); got != want {
+		t.Errorf("ExtractMetadata = %+v, want %+v from the header", got, want)
+	}
+}
+
+func TestExtractMetadataFallsBackToMessages(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 5, 0, 0, time.UTC)
+	conv := &types.Conversation{Messages: []types.Message{
+		{Role: "user", Content: "Hi"},
+		{Role: "assistant", Content: "Hello", Model: "claude-opus-4-1", Timestamp: start},
+		{Role: "assistant", Content: "Done", Model: "claude-sonnet-4-5", Timestamp: start.Add(time.Minute)},
+	}}
+
+	got := ExtractMetadata(conv)
+	if got.Model != "claude-sonnet-4-5" || !got.StartTime.Equal(start) || got.Cwd != "" {
+		t.Errorf("ExtractMetadata = %+v, want the latest model and the first timestamp", got)
+	}
+}
+
+func TestExtractMetadataWithoutAny(t *testing.T) {
+	for _, conv := range []*types.Conversation{
+		nil,
+		{Messages: []types.Message{{Role: "user", Content: "Hi"}}},
+		{Metadata: map[string]string{"started_at": "yesterday"}},
+	} {
+		if got := ExtractMetadata(conv); !got.IsZero() {
+			t.Errorf("ExtractMetadata(%+v) = %+v, want nothing", conv, got)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package extractor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectRulesFile is where a repo keeps its own extraction rules, relative
+// to the repo root
+var ProjectRulesFile = filepath.Join(".ccd", "rules.yaml")
+
+// DefaultRulesPath is the global rules file, applied to every project
+func DefaultRulesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "ccd", "rules.yaml"), nil
+}
+
+// Rule extracts a fact of one type from a message that matches its pattern
+// or mentions any of its keywords
+type Rule struct {
+	Type     string
+	Keywords []string
+	// Require, when set, must also be mentioned, as file extensions are
+	// for file changes
+	Require []string
+	// Pattern, when set, is tried before the keywords
+	Pattern    *regexp.Regexp
+	Importance int
+}
+
+// DefaultRules are the built-in rules, in the order their facts are
+// extracted from a message
+func DefaultRules() []Rule {
+	return []Rule{
+		{Type: "decision", Keywords: []string{"decided to", "chose to", "going with", "will use"}, Importance: 4},
+		{Type: "blocker", Keywords: []string{"blocked by", "can't proceed", "error:", "failed to"}, Importance: 5},
+		{Type: "todo", Keywords: []string{"TODO:", "need to", "should", "must"}, Importance: 3},
+		{
+			Type:       "file_change",
+			Keywords:   []string{"created", "modified", "updated", "deleted"},
+			Require:    []string{".ts", ".tsx", ".js", ".jsx", ".go", ".py", ".java"},
+			Importance: 2,
+		},
+		{Type: "dependency", Keywords: []string{"installed", "added dependency", "npm install", "go get"}, Importance: 3},
+		{Type: "config_change", Keywords: configKeywords, Importance: 4},
+		// Failures rank like blockers; see extract
+		{Type: "test_status", Pattern: testStatusPattern, Importance: 3},
+		{Type: "insight", Keywords: []string{"discovered", "found that", "interesting", "note that"}, Importance: 3},
+	}
+}
+
+// extract applies the rule to one message
+func (r Rule) extract(content string) (Fact, bool) {
+	fact := Fact{Type: r.Type, Importance: r.Importance}
+
+	switch {
+	case r.Pattern != nil && r.Pattern.MatchString(content):
+		fact.Content = extractMatchingSentence(content, r.Pattern)
+		fact.Keyword = strings.ToLower(r.Pattern.FindString(content))
+	case containsAny(content, r.Keywords):
+		fact.Content = extractSentence(content, r.Keywords)
+		fact.Keyword = matchedKeyword(content, r.Keywords)
+	default:
+		return fact, false
+	}
+
+	if len(r.Require) > 0 {
+		if !containsAny(content, r.Require) {
+			return fact, false
+		}
+		fact.Keyword += " + " + matchedKeyword(content, r.Require)
+	}
+
+	switch r.Type {
+	case "config_change":
+		fact.Content = redactSecretValues(fact.Content)
+	case "test_status":
+		if IsTestFailure(content) {
+			fact.Importance = 5
+		}
+	}
+	return fact, true
+}
+
+// RulesFile is a rules.yaml file. Each entry changes the rule of the same
+// type, or adds a rule for a new type after the others.
+type RulesFile struct {
+	Rules []RuleOverride `yaml:"rules"`
+}
+
+// RuleOverride changes one type's rule. Keywords and Require extend the
+// rule's lists and Pattern overrides its pattern; with Replace, all three
+// replace the rule's matching outright. A non-zero Importance overrides
+// the rule's, and Disabled removes the rule altogether.
+type RuleOverride struct {
+	Type       string   `yaml:"type"`
+	Keywords   []string `yaml:"keywords"`
+	Require    []string `yaml:"require"`
+	Pattern    string   `yaml:"pattern"`
+	Importance int      `yaml:"importance"`
+	Replace    bool     `yaml:"replace"`
+	Disabled   bool     `yaml:"disabled"`
+}
+
+// LoadRulesFile applies the rules file at path on top of rules. A missing
+// file leaves rules as they are and reports false.
+func LoadRulesFile(rules []Rule, path string) ([]Rule, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var file RulesFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	merged, err := MergeRules(rules, file.Rules)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", path, err)
+	}
+	return merged, true, nil
+}
+
+// MergeRules applies overrides to a copy of rules, in order
+func MergeRules(rules []Rule, overrides []RuleOverride) ([]Rule, error) {
+	merged := make([]Rule, 0, len(rules)+len(overrides))
+	for _, rule := range rules {
+		rule.Keywords = append([]string(nil), rule.Keywords...)
+		rule.Require = append([]string(nil), rule.Require...)
+		merged = append(merged, rule)
+	}
+
+	for _, o := range overrides {
+		if o.Type == "" {
+			return nil, fmt.Errorf("rule without a type")
+		}
+		if o.Importance < 0 || o.Importance > 5 {
+			return nil, fmt.Errorf("rule %s: importance must be 0-5", o.Type)
+		}
+		var pattern *regexp.Regexp
+		if o.Pattern != "" {
+			re, err := regexp.Compile(o.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid pattern: %w", o.Type, err)
+			}
+			pattern = re
+		}
+
+		i := ruleIndex(merged, o.Type)
+		if o.Disabled {
+			if i >= 0 {
+				merged = append(merged[:i], merged[i+1:]...)
+			}
+			continue
+		}
+		if i < 0 {
+			if len(o.Keywords) == 0 && pattern == nil {
+				return nil, fmt.Errorf("new rule %s needs keywords or a pattern", o.Type)
+			}
+			merged = append(merged, Rule{Type: o.Type, Importance: 3})
+			i = len(merged) - 1
+		}
+
+		rule := &merged[i]
+		if o.Replace {
+			rule.Keywords = o.Keywords
+			rule.Require = o.Require
+			rule.Pattern = pattern
+		} else {
+			rule.Keywords = appendNew(rule.Keywords, o.Keywords)
+			rule.Require = appendNew(rule.Require, o.Require)
+			if pattern != nil {
+				rule.Pattern = pattern
+			}
+		}
+		if o.Importance > 0 {
+			rule.Importance = o.Importance
+		}
+		if len(rule.Keywords) == 0 && rule.Pattern == nil {
+			return nil, fmt.Errorf("rule %s has no keywords or pattern left", o.Type)
+		}
+	}
+	return merged, nil
+}
+
+func ruleIndex(rules []Rule, factType string) int {
+	for i, rule := range rules {
+		if rule.Type == factType {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendNew appends the values not already in list
+func appendNew(list, values []string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,112 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/types"
+)
+
+// writeRules writes a rules file into dir, returning its path
+func writeRules(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func ruleOf(t *testing.T, rules []Rule, factType string) Rule {
+	t.Helper()
+	i := ruleIndex(rules, factType)
+	if i < 0 {
+		t.Fatalf("no %s rule in %+v", factType, rules)
+	}
+	return rules[i]
+}
+
+func TestMergeRules(t *testing.T) {
+	defaults := DefaultRules()
+	merged, err := MergeRules(defaults, []RuleOverride{
+		// Extends the list, skipping a keyword it already has
+		{Type: "decision", Keywords: []string{"settled on", "going with"}, Importance: 5},
+		// Replaces the matching outright
+		{Type: "todo", Keywords: []string{"FIXME"}, Replace: true},
+		{Type: "insight", Disabled: true},
+		{Type: "runbook", Keywords: []string{"runbook"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decision := ruleOf(t, merged, "decision")
+	if want := []string{"decided to", "chose to", "going with", "will use", "settled on"}; !reflect.DeepEqual(decision.Keywords, want) {
+		t.Errorf("decision keywords = %q, want %q", decision.Keywords, want)
+	}
+	if decision.Importance != 5 {
+		t.Errorf("decision importance = %d, want 5", decision.Importance)
+	}
+	if todo := ruleOf(t, merged, "todo"); !reflect.DeepEqual(todo.Keywords, []string{"FIXME"}) || todo.Importance != 3 {
+		t.Errorf("todo = %+v, want only FIXME at the default importance", todo)
+	}
+	if ruleIndex(merged, "insight") >= 0 {
+		t.Error("disabled insight rule kept")
+	}
+	// New types come after the built-in ones, at importance 3
+	if last := merged[len(merged)-1]; last.Type != "runbook" || last.Importance != 3 {
+		t.Errorf("last rule = %+v, want the new runbook rule", last)
+	}
+
+	// The input rules are unchanged
+	if !reflect.DeepEqual(defaults, DefaultRules()) {
+		t.Error("MergeRules changed the rules it was given")
+	}
+}
+
+func TestMergeRulesRejectsBadOverrides(t *testing.T) {
+	tests := map[string]RuleOverride{
+		"rule without a type":         {Keywords: []string{"x"}},
+		"importance must be 0-5":      {Type: "todo", Importance: 6},
+		"invalid pattern":             {Type: "todo", Pattern: "("},
+		"needs keywords or a pattern": {Type: "runbook"},
+		"no keywords or pattern left": {Type: "todo", Replace: true},
+	}
+	for want, override := range tests {
+		_, err := MergeRules(DefaultRules(), []RuleOverride{override})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("override %+v: error %v, want %q", override, err, want)
+		}
+	}
+}
+
+func TestLoadRulesFile(t *testing.T) {
+	path := writeRules(t, t.TempDir(), `
+rules:
+  - type: blocker
+    keywords: ["waiting on"]
+`)
+	rules, loaded, err := LoadRulesFile(DefaultRules(), path)
+	if err != nil || !loaded {
+		t.Fatalf("LoadRulesFile = %v, %v", loaded, err)
+	}
+	conv := &types.Conversation{Messages: []types.Message{{Role: "assistant", Content: "We're waiting on the vendor"}}}
+	if blockers := factsOfType(ExtractFactsWithRules(conv, rules), "blocker"); len(blockers) != 1 {
+		t.Errorf("got %d blockers from the added keyword, want 1", len(blockers))
+	}
+
+	// A missing file changes nothing
+	rules, loaded, err = LoadRulesFile(DefaultRules(), filepath.Join(t.TempDir(), "rules.yaml"))
+	if err != nil || loaded || !reflect.DeepEqual(rules, DefaultRules()) {
+		t.Errorf("missing file: loaded %v, error %v, rules changed %v", loaded, err, !reflect.DeepEqual(rules, DefaultRules()))
+	}
+
+	// Unknown fields are mistakes, not silently ignored
+	path = writeRules(t, t.TempDir(), "rules:\n  - type: todo\n    keyword: [x]\n")
+	if _, _, err := LoadRulesFile(DefaultRules(), path); err == nil {
+		t.Error("LoadRulesFile accepted an unknown field")
+	}
+}
@@ -2,6 +2,9 @@ module github.com/angelfreak/ccd/daemon
 
 go 1.21
 
-require github.com/fsnotify/fsnotify v1.7.0
+require (
+	github.com/fsnotify/fsnotify v1.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/sys v0.15.0 // indirect
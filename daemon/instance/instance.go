@@ -0,0 +1,212 @@
+// Package instance records running daemons so they can be listed.
+//
+// Each daemon writes <project-id>.pid and <project-id>.json to the run
+// directory while it runs and removes them on exit. PID files left behind by
+// a daemon that didn't shut down cleanly are reported as dead.
+package instance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Info describes a daemon instance
+type Info struct {
+	PID         int       `json:"pid"`
+	ProjectID   string    `json:"project_id"`
+	ProjectSlug string    `json:"project_slug"`
+	LogPath     string    `json:"log_path"`
+	RepoPath    string    `json:"repo_path"`
+	HTTPAddr    string    `json:"http_addr,omitempty"`
+	Version     string    `json:"version"`
+	StartedAt   time.Time `json:"started_at"`
+	// Binary is the resolved path of the daemon's executable, so an update
+	// of that binary knows which daemons to stop
+	Binary string `json:"binary,omitempty"`
+}
+
+// Instance is a daemon found in the run directory
+type Instance struct {
+	Info
+	// PIDFile is the instance's PID file; its config sits beside it
+	PIDFile string
+	Running bool
+}
+
+// startTolerance is how far a process's start time may be from a daemon's
+// StartedAt for the process to count as that daemon
+const startTolerance = 5 * time.Second
+
+// DefaultDir is the run directory daemons register in
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "run", "ccd"), nil
+}
+
+// Register writes info's PID file and config to dir. The returned function
+// removes them.
+func Register(dir string, info Info) (func(), error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	pidFile := filepath.Join(dir, info.ProjectID+".pid")
+	configFile := configPath(pidFile)
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(info.PID)), 0644); err != nil {
+		os.Remove(configFile)
+		return nil, err
+	}
+
+	return func() {
+		os.Remove(pidFile)
+		os.Remove(configFile)
+	}, nil
+}
+
+// List returns the instances registered in dir, ordered by project. A missing
+// directory has no instances.
+func List(dir string) ([]Instance, error) {
+	pidFiles, err := filepath.Glob(filepath.Join(dir, "*.pid"))
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []Instance
+	for _, pidFile := range pidFiles {
+		data, err := os.ReadFile(pidFile)
+		if err != nil {
+			continue
+		}
+		pid, err := strconv.Atoi(string(bytes.TrimSpace(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PID file %s: %w", pidFile, err)
+		}
+
+		inst := Instance{PIDFile: pidFile}
+		// A daemon that died mid-registration may have left no config; the
+		// PID file alone still identifies it
+		if config, err := os.ReadFile(configPath(pidFile)); err == nil {
+			if err := json.Unmarshal(config, &inst.Info); err != nil {
+				return nil, fmt.Errorf("invalid config %s: %w", configPath(pidFile), err)
+			}
+		}
+		if inst.ProjectID == "" {
+			inst.ProjectID = strings.TrimSuffix(filepath.Base(pidFile), ".pid")
+		}
+		inst.PID = pid
+		inst.Running = Alive(pid)
+
+		instances = append(instances, inst)
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].ProjectID < instances[j].ProjectID
+	})
+	return instances, nil
+}
+
+// Remove deletes a dead instance's PID file and config
+func Remove(inst Instance) error {
+	if err := os.Remove(inst.PIDFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(configPath(inst.PIDFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Alive reports whether a process with this PID is running
+func Alive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal 0 checks for the process without affecting it; EPERM means it
+	// exists but belongs to another user
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// IsDaemon reports whether inst's PID still belongs to the daemon that
+// registered it: the process is running and started when the daemon did.
+// After a crash the OS may hand the PID to an unrelated process, which fails
+// this. Instances registered without a start time can't be confirmed.
+func (inst Instance) IsDaemon() bool {
+	if !inst.Running || inst.StartedAt.IsZero() {
+		return false
+	}
+	started, err := StartTime(inst.PID)
+	if err != nil {
+		return false
+	}
+	offset := started.Sub(inst.StartedAt)
+	return offset > -startTolerance && offset < startTolerance
+}
+
+// StartTime returns when the process with this PID started, to the second
+func StartTime(pid int) (time.Time, error) {
+	out, err := exec.Command("ps", "-o", "etime=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("no process %d: %w", pid, err)
+	}
+	elapsed, err := parseElapsed(strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-elapsed), nil
+}
+
+// parseElapsed parses a process's elapsed time as ps prints it,
+// [[dd-]hh:]mm:ss
+func parseElapsed(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid elapsed time %q", value)
+
+	clock, days := value, 0
+	if i := strings.IndexByte(value, '-'); i >= 0 {
+		var err error
+		if days, err = strconv.Atoi(value[:i]); err != nil {
+			return 0, invalid
+		}
+		clock = value[i+1:]
+	}
+
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, invalid
+	}
+	seconds := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, invalid
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds)*time.Second, nil
+}
+
+func configPath(pidFile string) string {
+	return strings.TrimSuffix(pidFile, ".pid") + ".json"
+}
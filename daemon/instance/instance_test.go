@@ -0,0 +1,153 @@
+package instance
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// deadPID returns the PID of a process that has exited
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("can't run a process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestRegisterListUnregister(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	info := Info{
+		PID:         os.Getpid(),
+		ProjectID:   "proj1",
+		ProjectSlug: "app",
+		LogPath:     "/logs",
+		RepoPath:    "/work/app",
+		HTTPAddr:    "localhost:8090",
+		Version:     "1.2.0",
+		StartedAt:   time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Binary:      "/opt/ccd/cct-daemon",
+	}
+
+	unregister, err := Register(dir, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instances, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Instance{{Info: info, PIDFile: filepath.Join(dir, "proj1.pid"), Running: true}}
+	if !reflect.DeepEqual(instances, want) {
+		t.Errorf("List = %+v, want %+v", instances, want)
+	}
+
+	unregister()
+	if instances, err := List(dir); err != nil || len(instances) != 0 {
+		t.Errorf("List after unregistering = %+v, %v; want none", instances, err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "*")); len(left) != 0 {
+		t.Errorf("unregistering left %v behind", left)
+	}
+}
+
+func TestListReportsDeadInstances(t *testing.T) {
+	dir := t.TempDir()
+	pid := deadPID(t)
+
+	// A daemon that crashed leaves its files behind
+	if _, err := Register(dir, Info{PID: pid, ProjectID: "crashed", ProjectSlug: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	// One that died mid-registration leaves only its PID file
+	if err := os.WriteFile(filepath.Join(dir, "partial.pid"), []byte("999999999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Register(dir, Info{PID: os.Getpid(), ProjectID: "live"}); err != nil {
+		t.Fatal(err)
+	}
+
+	instances, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, inst := range instances {
+		got = append(got, inst.ProjectID)
+		if running := inst.ProjectID == "live"; inst.Running != running {
+			t.Errorf("%s running = %v, want %v", inst.ProjectID, inst.Running, running)
+		}
+	}
+	if want := []string{"crashed", "live", "partial"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %q, want %q", got, want)
+	}
+	if instances[0].PID != pid || instances[0].ProjectSlug != "old" {
+		t.Errorf("dead instance = %+v, want its registered details", instances[0])
+	}
+
+	if err := Remove(instances[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"crashed.pid", "crashed.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s still there after Remove (stat: %v)", name, err)
+		}
+	}
+	if err := Remove(instances[2]); err != nil {
+		t.Errorf("removing an instance without a config: %v", err)
+	}
+}
+
+func TestParseElapsed(t *testing.T) {
+	tests := map[string]time.Duration{
+		"00:05":       5 * time.Second,
+		"12:34":       12*time.Minute + 34*time.Second,
+		"01:02:03":    time.Hour + 2*time.Minute + 3*time.Second,
+		"2-03:04:05":  51*time.Hour + 4*time.Minute + 5*time.Second,
+		"10-00:00:00": 240 * time.Hour,
+	}
+	for value, want := range tests {
+		if got, err := parseElapsed(value); err != nil || got != want {
+			t.Errorf("parseElapsed(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "5", "1:2:3:4", "a:00", "x-01:00"} {
+		if _, err := parseElapsed(value); err == nil {
+			t.Errorf("parseElapsed(%q) succeeded", value)
+		}
+	}
+}
+
+func TestIsDaemonChecksStartTime(t *testing.T) {
+	// This test process stands in for a daemon that registered when it started
+	started, err := StartTime(os.Getpid())
+	if err != nil {
+		t.Skipf("can't read process start times: %v", err)
+	}
+	self := Instance{Info: Info{PID: os.Getpid(), StartedAt: started}, Running: true}
+	if !self.IsDaemon() {
+		t.Error("a running process that started at StartedAt isn't recognized")
+	}
+
+	reused := self
+	reused.StartedAt = started.Add(-time.Hour)
+	if reused.IsDaemon() {
+		t.Error("a process that started an hour after registration passed as the daemon")
+	}
+
+	unknown := self
+	unknown.StartedAt = time.Time{}
+	if unknown.IsDaemon() {
+		t.Error("an instance without a start time was confirmed")
+	}
+
+	dead := self
+	dead.Running = false
+	if dead.IsDaemon() {
+		t.Error("a dead instance was confirmed")
+	}
+}
@@ -0,0 +1,80 @@
+package ledger
+
+import (
+	"log"
+	"time"
+)
+
+// pendingEntry is an encoded entry waiting to be appended to its day's file
+type pendingEntry struct {
+	path string
+	data []byte
+}
+
+// batching reports whether appended entries are buffered before writing
+func (l *Ledger) batching() bool {
+	return l.batchSize > 1
+}
+
+// Flush writes any batched entries to disk
+func (l *Ledger) Flush() error {
+	l.batchMu.Lock()
+	defer l.batchMu.Unlock()
+
+	return l.flushLocked()
+}
+
+// flushLocked writes pending entries, one append per continuity file. Entries
+// that couldn't be written stay pending for the next flush. Callers must hold
+// batchMu.
+func (l *Ledger) flushLocked() error {
+	for len(l.pending) > 0 {
+		path := l.pending[0].path
+		n := 1
+		for n < len(l.pending) && l.pending[n].path == path {
+			n++
+		}
+
+		lines := make([][]byte, n)
+		for i := range lines {
+			lines[i] = l.pending[i].data
+		}
+		if err := appendLines(path, lines); err != nil {
+			return err
+		}
+		l.pending = l.pending[n:]
+	}
+	l.pending = nil
+	return nil
+}
+
+// flushPeriodically writes batched entries every flushInterval until Close
+func (l *Ledger) flushPeriodically() {
+	defer close(l.flushDone)
+
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.Flush(); err != nil {
+				log.Printf("Warning: failed to flush ledger entries: %v", err)
+			}
+		case <-l.stopFlush:
+			return
+		}
+	}
+}
+
+// Close stops periodic flushing and writes any batched entries. The ledger
+// stays usable; later appends are written as their batches fill.
+func (l *Ledger) Close() error {
+	l.closeOnce.Do(func() {
+		if l.stopFlush != nil {
+			close(l.stopFlush)
+			<-l.flushDone
+		}
+	})
+	return l.Flush()
+}
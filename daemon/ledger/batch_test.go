@@ -0,0 +1,87 @@
+package ledger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// diskLines returns the lines of today's continuity file as written to disk,
+// without flushing
+func diskLines(t *testing.T, l *Ledger) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(l.Dir(), "CONTINUITY_"+time.Now().Format("2006-01-02")+".jsonl"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestBatchedEntriesDurableAfterFlush(t *testing.T) {
+	repo := t.TempDir()
+	l := NewLedgerWithConfig(LedgerConfig{ProjectID: "proj1", RepoPath: repo, BatchSize: 3})
+
+	for _, session := range []string{"s1", "s2"} {
+		if err := l.AppendEntry(LedgerEntry{Timestamp: time.Now(), SessionID: session, ProjectID: "proj1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines := diskLines(t, l); len(lines) != 0 {
+		t.Fatalf("%d entries written before the batch filled, want 0", len(lines))
+	}
+
+	// Reads see batched entries, flushing them first
+	latest, err := l.GetLatestEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.SessionID != "s2" {
+		t.Errorf("latest entry is %s, want s2", latest.SessionID)
+	}
+	if lines := diskLines(t, l); len(lines) != 2 {
+		t.Errorf("%d entries on disk after a read, want 2", len(lines))
+	}
+
+	// A full batch is written at once, and Close writes the rest
+	for _, session := range []string{"s3", "s4", "s5", "s6"} {
+		if err := l.AppendEntry(LedgerEntry{Timestamp: time.Now(), SessionID: session, ProjectID: "proj1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines := diskLines(t, l); len(lines) != 5 {
+		t.Errorf("%d entries on disk after a full batch, want 5", len(lines))
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh ledger reads every entry back from disk
+	entries, err := NewLedgerWithConfig(LedgerConfig{ProjectID: "proj1", RepoPath: repo}).ReadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 6 || entries[5].SessionID != "s6" {
+		t.Errorf("read back %d entries, want all 6 in order", len(entries))
+	}
+}
+
+func TestBatchFlushedPeriodically(t *testing.T) {
+	l := NewLedgerWithConfig(LedgerConfig{ProjectID: "proj1", RepoPath: t.TempDir(), BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer l.Close()
+
+	if err := l.AppendEntry(LedgerEntry{Timestamp: time.Now(), SessionID: "s1", ProjectID: "proj1"}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(diskLines(t, l)) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("batched entry never flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -2,149 +2,450 @@ package ledger
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
+// CurrentSchemaVersion is written on every new ledger entry. Version 1 entries
+// predate the field and are upgraded when read.
+const CurrentSchemaVersion = 2
+
 // LedgerEntry represents a snapshot of project state
 type LedgerEntry struct {
-	Timestamp   time.Time              `json:"timestamp"`
-	SessionID   string                 `json:"session_id"`
-	ProjectID   string                 `json:"project_id"`
-	TokenCount  int                    `json:"token_count"`
-	Facts       []Fact                 `json:"facts"`
-	Context     map[string]interface{} `json:"context"`
-	Decisions   []string               `json:"decisions"`
-	NextSteps   []string               `json:"next_steps"`
-	Blockers    []string               `json:"blockers"`
-	FileChanges []string               `json:"file_changes"`
+	SchemaVersion int                    `json:"schema_version"`
+	Timestamp     time.Time              `json:"timestamp"`
+	SessionID     string                 `json:"session_id"`
+	ProjectID     string                 `json:"project_id"`
+	TokenCount    int                    `json:"token_count"`
+	Facts         []Fact                 `json:"facts"`
+	Context       map[string]interface{} `json:"context"`
+	Decisions     []string               `json:"decisions"`
+	NextSteps     []string               `json:"next_steps"`
+	Blockers      []string               `json:"blockers"`
+	FileChanges   []string               `json:"file_changes"`
+	// ResolvedBlockers lists blockers resolved during this pass
+	ResolvedBlockers []ResolvedBlocker `json:"resolved_blockers,omitempty"`
+	// Model, Cwd, and SessionStart come from the transcript's metadata,
+	// when it has any
+	Model        string     `json:"model,omitempty"`
+	Cwd          string     `json:"cwd,omitempty"`
+	SessionStart *time.Time `json:"session_start,omitempty"`
+	// Branch is the git branch worked on, from the transcript or the repo
+	Branch string `json:"branch,omitempty"`
+	// DroppedFacts counts the facts this pass extracted beyond the
+	// per-pass cap, which were neither posted nor ledgered
+	DroppedFacts int `json:"dropped_facts,omitempty"`
+}
+
+// ResolvedBlocker records how long a blocker stayed open
+type ResolvedBlocker struct {
+	Content        string    `json:"content"`
+	OpenedAt       time.Time `json:"opened_at"`
+	ResolvedAt     time.Time `json:"resolved_at"`
+	BlockedSeconds int64     `json:"blocked_seconds"`
 }
 
 type Fact struct {
-	Type       string    `json:"type"`
-	Content    string    `json:"content"`
-	Importance int       `json:"importance"`
-	Timestamp  time.Time `json:"timestamp"`
+	Type       string `json:"type"`
+	Content    string `json:"content"`
+	Importance int    `json:"importance"`
+	// Score is the unrounded 0-5 importance, for ranking facts that share
+	// an Importance. Entries written before it was recorded have none.
+	Score     float64   `json:"score,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Rank is the fact's importance for ordering: its unrounded score when
+// recorded, otherwise the 1-5 importance
+func (f Fact) Rank() float64 {
+	if f.Score > 0 {
+		return f.Score
+	}
+	return float64(f.Importance)
 }
 
 type Ledger struct {
 	ledgerPath string
 	projectID  string
+	noEmoji    bool
+	// topImportanceFraction limits importance 5 in handoffs
+	topImportanceFraction float64
+	// defaultFactLineLength and factLineLengths clip facts in handoffs
+	defaultFactLineLength int
+	factLineLengths       map[string]int
+
+	// batchMu guards pending, the entries appended but not yet written
+	batchMu       sync.Mutex
+	pending       []pendingEntry
+	batchSize     int
+	flushInterval time.Duration
+	stopFlush     chan struct{}
+	flushDone     chan struct{}
+	closeOnce     sync.Once
+}
+
+type LedgerConfig struct {
+	ProjectID string
+	RepoPath  string
+	// NoEmoji renders plain-text markers instead of emoji in handoffs, for
+	// tools that mangle them
+	NoEmoji bool
+	// BatchSize buffers appended entries in memory and writes them once
+	// this many are waiting. Zero or one writes each entry as it's appended.
+	BatchSize int
+	// FlushInterval, when batching, also writes waiting entries at least
+	// this often. Zero leaves them until the batch fills, a read, or Close.
+	FlushInterval time.Duration
+	// TopImportanceFraction, when between 0 and 1, shows only that fraction
+	// of a handoff's facts, the highest ranked, with importance 5; the rest
+	// that scored 5 are shown as 4. The ledger keeps the scored importances.
+	TopImportanceFraction float64
+	// FactLineLength clips each fact to this many characters in a handoff's
+	// lines, ending it with "…", and FactLineLengths sets the length for
+	// particular types. Zero leaves facts whole. Clipped facts are kept
+	// whole in the handoff's frontmatter.
+	FactLineLength  int
+	FactLineLengths map[string]int
+}
+
+// LedgerMetrics summarizes everything recorded in the continuity ledger
+type LedgerMetrics struct {
+	TotalEntries      int            `json:"total_entries"`
+	TotalFacts        int            `json:"total_facts"`
+	FactsByType       map[string]int `json:"facts_by_type"`
+	PeakTokenCount    int            `json:"peak_token_count"`
+	PeakTokenSession  string         `json:"peak_token_session"`
+	TotalSessions     int            `json:"total_sessions"`
+	FileSizeBytes     int64          `json:"file_size_bytes"`
+	ResolvedBlockers  int            `json:"resolved_blockers"`
+	AvgBlockedSeconds int64          `json:"avg_blocked_seconds"`
+	MaxBlockedSeconds int64          `json:"max_blocked_seconds"`
 }
 
 func NewLedger(projectID, repoPath string) *Ledger {
-	ledgerPath := filepath.Join(repoPath, "thoughts", "ledgers")
+	return NewLedgerWithConfig(LedgerConfig{
+		ProjectID: projectID,
+		RepoPath:  repoPath,
+	})
+}
+
+func NewLedgerWithConfig(config LedgerConfig) *Ledger {
+	ledgerPath := LedgerDirFor(config.RepoPath)
 	os.MkdirAll(ledgerPath, 0755)
 
-	return &Ledger{
-		ledgerPath: ledgerPath,
-		projectID:  projectID,
+	l := &Ledger{
+		ledgerPath:            ledgerPath,
+		projectID:             config.ProjectID,
+		noEmoji:               config.NoEmoji,
+		batchSize:             config.BatchSize,
+		flushInterval:         config.FlushInterval,
+		topImportanceFraction: config.TopImportanceFraction,
+		defaultFactLineLength: config.FactLineLength,
+		factLineLengths:       config.FactLineLengths,
 	}
+	if l.batching() && l.flushInterval > 0 {
+		l.stopFlush = make(chan struct{})
+		l.flushDone = make(chan struct{})
+		go l.flushPeriodically()
+	}
+	return l
 }
 
-// AppendEntry adds a new entry to the continuity ledger
+// AppendEntry adds a new entry to the continuity ledger. When batching, the
+// entry is written with the rest of its batch; reads flush it first, so it
+// is always visible to this Ledger.
 func (l *Ledger) AppendEntry(entry LedgerEntry) error {
+	entry.SchemaVersion = CurrentSchemaVersion
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
 	filename := fmt.Sprintf("CONTINUITY_%s.jsonl", time.Now().Format("2006-01-02"))
-	path := filepath.Join(l.ledgerPath, filename)
 
-	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	l.batchMu.Lock()
+	defer l.batchMu.Unlock()
+
+	l.pending = append(l.pending, pendingEntry{path: filepath.Join(l.ledgerPath, filename), data: data})
+	if l.batching() && len(l.pending) < l.batchSize {
+		return nil
+	}
+	return l.flushLocked()
+}
+
+// appendLines writes entry lines to the end of a continuity file and syncs it
+func appendLines(path string, lines [][]byte) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	data, err := json.Marshal(entry)
-	if err != nil {
+	var data []byte
+	// A crash mid-append leaves a partial line; start a fresh one so these
+	// entries aren't joined onto it
+	if partial, err := endsMidLine(file); err != nil {
 		return err
+	} else if partial {
+		data = append(data, '\n')
+	}
+	for _, line := range lines {
+		data = append(append(data, line...), '\n')
 	}
 
-	_, err = file.Write(append(data, '\n'))
-	return err
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// endsMidLine reports whether a file's last byte isn't a newline
+func endsMidLine(file *os.File) (bool, error) {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
 }
 
-// GetLatestEntry retrieves the most recent ledger entry
+// GetLatestEntry retrieves the most recent ledger entry, writing any batched
+// entries first. Corrupt lines, such as one cut short by a crash, are skipped
+// in favour of the last valid entry.
 func (l *Ledger) GetLatestEntry() (*LedgerEntry, error) {
-	files, err := filepath.Glob(filepath.Join(l.ledgerPath, "CONTINUITY_*.jsonl"))
-	if err != nil || len(files) == 0 {
+	if err := l.Flush(); err != nil {
 		return nil, err
 	}
 
-	// Read the most recent file
-	latestFile := files[len(files)-1]
-	data, err := os.ReadFile(latestFile)
-	if err != nil {
+	files, err := l.ledgerFiles()
+	if err != nil || len(files) == 0 {
 		return nil, err
 	}
+	sort.Strings(files)
 
-	// Parse last line
-	lines := splitLines(string(data))
-	if len(lines) == 0 {
-		return nil, fmt.Errorf("empty ledger file")
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			return nil, err
+		}
+
+		lines := strings.Split(string(data), "\n")
+		for n := len(lines) - 1; n >= 0; n-- {
+			if strings.TrimSpace(lines[n]) == "" {
+				continue
+			}
+			entry, err := parseEntry(lines[n])
+			if errors.Is(err, errUnsupportedSchema) {
+				return nil, err
+			}
+			if err != nil {
+				log.Printf("Warning: skipping corrupt ledger line %s:%d: %v", filepath.Base(files[i]), n+1, err)
+				continue
+			}
+			return entry, nil
+		}
 	}
 
-	var entry LedgerEntry
-	err = json.Unmarshal([]byte(lines[len(lines)-1]), &entry)
-	return &entry, err
+	return nil, fmt.Errorf("no valid ledger entries")
 }
 
-// CreateHandoff generates a handoff document before context clearing
-func (l *Ledger) CreateHandoff(sessionID string, summary string, facts []Fact) error {
-	handoffPath := filepath.Join(filepath.Dir(l.ledgerPath), "shared", "handoffs")
-	os.MkdirAll(handoffPath, 0755)
+// errUnsupportedSchema marks entries written by a newer version, which
+// aren't skipped as corrupt
+var errUnsupportedSchema = errors.New("unsupported ledger schema version")
 
-	filename := fmt.Sprintf("handoff_%s_%s.md", sessionID, time.Now().Format("20060102_150405"))
-	path := filepath.Join(handoffPath, filename)
+// parseEntry decodes a ledger line and upgrades it to the current schema
+func parseEntry(line string) (*LedgerEntry, error) {
+	var entry LedgerEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return nil, err
+	}
 
-	content := fmt.Sprintf(`# Session Handoff
+	if entry.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w %d", errUnsupportedSchema, entry.SchemaVersion)
+	}
 
-**Session ID**: %s
-**Timestamp**: %s
-**Project**: %s
+	upgradeEntry(&entry)
+	return &entry, nil
+}
 
-## Summary
-%s
+// readEntryFile parses every entry of a continuity file, logging and
+// skipping corrupt lines
+func readEntryFile(path string) ([]LedgerEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 
-## Key Facts
-`, sessionID, time.Now().Format(time.RFC3339), l.projectID, summary)
+	var entries []LedgerEntry
+	for n, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry, err := parseEntry(line)
+		if errors.Is(err, errUnsupportedSchema) {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if err != nil {
+			log.Printf("Warning: skipping corrupt ledger line %s:%d: %v", filepath.Base(path), n+1, err)
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
 
-	for _, fact := range facts {
-		content += fmt.Sprintf("- [%s] %s (importance: %d)\n", fact.Type, fact.Content, fact.Importance)
+// upgradeEntry migrates an entry in place to CurrentSchemaVersion
+func upgradeEntry(entry *LedgerEntry) {
+	if entry.SchemaVersion == 0 {
+		entry.SchemaVersion = 1
 	}
 
-	content += "\n## Next Steps\n"
-	for _, fact := range facts {
-		if fact.Type == "todo" {
-			content += fmt.Sprintf("- [ ] %s\n", fact.Content)
+	// v1 -> v2: context and the per-type summaries are always populated
+	if entry.SchemaVersion == 1 {
+		if entry.Context == nil {
+			entry.Context = make(map[string]interface{})
+		}
+		if entry.Decisions == nil {
+			entry.Decisions = factContents(entry.Facts, "decision")
 		}
+		if entry.NextSteps == nil {
+			entry.NextSteps = factContents(entry.Facts, "todo")
+		}
+		if entry.Blockers == nil {
+			entry.Blockers = factContents(entry.Facts, "blocker")
+		}
+		if entry.FileChanges == nil {
+			entry.FileChanges = factContents(entry.Facts, "file_change")
+		}
+		entry.SchemaVersion = 2
 	}
+}
 
-	content += "\n## Blockers\n"
+func factContents(facts []Fact, factType string) []string {
+	var contents []string
 	for _, fact := range facts {
-		if fact.Type == "blocker" {
-			content += fmt.Sprintf("- ⚠️ %s\n", fact.Content)
+		if fact.Type == factType {
+			contents = append(contents, fact.Content)
+		}
+	}
+	return contents
+}
+
+// ReadEntries returns every ledger entry in chronological order, including
+// batched ones, skipping corrupt lines
+func (l *Ledger) ReadEntries() ([]LedgerEntry, error) {
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+
+	files, err := l.ledgerFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LedgerEntry
+	for _, file := range files {
+		fileEntries, err := readEntryFile(file)
+		if err != nil {
+			return nil, err
 		}
+		entries = append(entries, fileEntries...)
 	}
 
-	return os.WriteFile(path, []byte(content), 0644)
+	return entries, nil
 }
 
-func splitLines(s string) []string {
-	var lines []string
-	var line string
-	for _, c := range s {
-		if c == '\n' {
-			if line != "" {
-				lines = append(lines, line)
+// GetMetrics scans all continuity files and aggregates their statistics
+func (l *Ledger) GetMetrics() (*LedgerMetrics, error) {
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+
+	files, err := l.ledgerFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &LedgerMetrics{
+		FactsByType: make(map[string]int),
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		metrics.FileSizeBytes += info.Size()
+	}
+
+	entries, err := l.ReadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string]bool)
+	var totalBlocked int64
+	for _, entry := range entries {
+		for _, blocker := range entry.ResolvedBlockers {
+			metrics.ResolvedBlockers++
+			totalBlocked += blocker.BlockedSeconds
+			if blocker.BlockedSeconds > metrics.MaxBlockedSeconds {
+				metrics.MaxBlockedSeconds = blocker.BlockedSeconds
 			}
-			line = ""
-		} else {
-			line += string(c)
 		}
+		metrics.TotalEntries++
+		metrics.TotalFacts += len(entry.Facts)
+		for _, fact := range entry.Facts {
+			metrics.FactsByType[fact.Type]++
+		}
+		if entry.TokenCount > metrics.PeakTokenCount {
+			metrics.PeakTokenCount = entry.TokenCount
+			metrics.PeakTokenSession = entry.SessionID
+		}
+		sessions[entry.SessionID] = true
+	}
+	metrics.TotalSessions = len(sessions)
+	if metrics.ResolvedBlockers > 0 {
+		metrics.AvgBlockedSeconds = totalBlocked / int64(metrics.ResolvedBlockers)
 	}
-	if line != "" {
-		lines = append(lines, line)
+
+	return metrics, nil
+}
+
+// Dir returns the directory continuity files are written to
+func (l *Ledger) Dir() string {
+	return l.ledgerPath
+}
+
+func (l *Ledger) ledgerFiles() ([]string, error) {
+	return filepath.Glob(filepath.Join(l.ledgerPath, "CONTINUITY_*.jsonl"))
+}
+
+// LedgerDirFor returns the directory a repo's continuity files are written to
+func LedgerDirFor(repoPath string) string {
+	return filepath.Join(repoPath, "thoughts", "ledgers")
+}
+
+// LatestLedgerFileIn returns the path of the most recent day's continuity
+// file in dir, or "" if there are none. Unlike NewLedger it creates nothing
+// on disk.
+func LatestLedgerFileIn(dir string) (string, error) {
+	// Files are named by date, so the last in sorted order is the latest
+	files, err := filepath.Glob(filepath.Join(dir, "CONTINUITY_*.jsonl"))
+	if err != nil || len(files) == 0 {
+		return "", err
 	}
-	return lines
+	sort.Strings(files)
+	return files[len(files)-1], nil
 }
@@ -0,0 +1,128 @@
+package ledger
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadEntriesUpgradesOldSchema(t *testing.T) {
+	l := newFixtureLedger(t, "ledger", LedgerConfig{})
+
+	entries, err := l.ReadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	// The first line predates schema versions; it reads as the current shape
+	v1 := entries[0]
+	if v1.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("v1 entry schema version = %d, want %d", v1.SchemaVersion, CurrentSchemaVersion)
+	}
+	if v1.Context == nil {
+		t.Error("v1 entry has no context map")
+	}
+	if want := []string{"Use Postgres"}; !reflect.DeepEqual(v1.Decisions, want) {
+		t.Errorf("v1 entry decisions = %v, want %v derived from its facts", v1.Decisions, want)
+	}
+
+	v2 := entries[1]
+	if v2.SchemaVersion != 2 || !reflect.DeepEqual(v2.Blockers, []string{"CI is red on main"}) {
+		t.Errorf("v2 entry = %+v, want it read as written", v2)
+	}
+
+	latest, err := l.GetLatestEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.SessionID != "s2" {
+		t.Errorf("latest entry is from session %s, want s2", latest.SessionID)
+	}
+}
+
+func TestReadEntriesRejectsNewerSchema(t *testing.T) {
+	l := newFixtureLedger(t, "ledger", LedgerConfig{})
+	future := []byte(`{"schema_version":99,"timestamp":"2026-03-02T10:00:00Z","session_id":"s3"}` + "\n")
+	if err := os.WriteFile(filepath.Join(l.Dir(), "CONTINUITY_2026-03-02.jsonl"), future, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.ReadEntries(); !errors.Is(err, errUnsupportedSchema) {
+		t.Errorf("ReadEntries error = %v, want an unsupported schema error", err)
+	}
+	if _, err := l.GetLatestEntry(); !errors.Is(err, errUnsupportedSchema) {
+		t.Errorf("GetLatestEntry error = %v, want an unsupported schema error", err)
+	}
+}
+
+func TestCorruptLinesSkipped(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	// A garbage line mid-file, and a last line cut off by a crash mid-append
+	l := newFixtureLedger(t, "truncated", LedgerConfig{})
+
+	latest, err := l.GetLatestEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.SessionID != "s2" || latest.TokenCount != 800 {
+		t.Errorf("latest entry = %s with %d tokens, want the last valid one, s2 with 800", latest.SessionID, latest.TokenCount)
+	}
+
+	entries, err := l.ReadEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sessions []string
+	for _, entry := range entries {
+		sessions = append(sessions, entry.SessionID)
+	}
+	if want := []string{"s1", "s1", "s2"}; !reflect.DeepEqual(sessions, want) {
+		t.Errorf("read sessions %v, want %v", sessions, want)
+	}
+
+	for _, want := range []string{"CONTINUITY_2026-03-01.jsonl:3", "CONTINUITY_2026-03-01.jsonl:5"} {
+		if !bytes.Contains(logs.Bytes(), []byte("skipping corrupt ledger line "+want)) {
+			t.Errorf("no warning for line %s:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestMetricsBlockedTime(t *testing.T) {
+	l := NewLedger("proj1", t.TempDir())
+	opened := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, hours := range []int{2, 4} {
+		err := l.AppendEntry(LedgerEntry{
+			Timestamp: opened.Add(time.Duration(hours) * time.Hour),
+			SessionID: "s1",
+			ResolvedBlockers: []ResolvedBlocker{{
+				Content:        "CI is red",
+				OpenedAt:       opened,
+				ResolvedAt:     opened.Add(time.Duration(hours) * time.Hour),
+				BlockedSeconds: int64(hours * 3600),
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	metrics, err := l.GetMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.ResolvedBlockers != 2 || metrics.AvgBlockedSeconds != 3*3600 || metrics.MaxBlockedSeconds != 4*3600 {
+		t.Errorf("metrics = %d resolved, %ds average, %ds max; want 2, 3h, 4h",
+			metrics.ResolvedBlockers, metrics.AvgBlockedSeconds, metrics.MaxBlockedSeconds)
+	}
+}
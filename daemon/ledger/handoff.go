@@ -0,0 +1,415 @@
+package ledger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handoff is a handoff document read back from disk
+type Handoff struct {
+	Path        string
+	SessionID   string
+	Timestamp   time.Time
+	Summary     string
+	SourceFiles []string
+	Facts       []Fact
+}
+
+// handoffFactLine matches "- [type] content (importance: N)" under Key Facts
+var handoffFactLine = regexp.MustCompile(`^- \[([a-z_]+)\] (.*) \(importance: (\d+)\)$`)
+
+// CarryForward is the subset of a handoff's facts recommended for the next
+// session, chosen to fit a token budget
+type CarryForward struct {
+	Budget int
+	Tokens int
+	Facts  []Fact
+}
+
+// CreateHandoff generates a handoff document before context clearing.
+// sourceFiles lists the log files that contributed since the previous handoff
+// and is recorded in the document's frontmatter. carry, when not nil, is
+// rendered as a Carry Forward section.
+func (l *Ledger) CreateHandoff(sessionID string, summary string, facts []Fact, sourceFiles []string, carry *CarryForward) error {
+	return l.writeHandoff(sessionID, summary, facts, sourceFiles, carry, time.Now())
+}
+
+// RebuildHandoffs regenerates one handoff per session from ledger entries
+// recorded on or after since, using each session's latest entry and the
+// current handoff format. Sessions that already have a handoff are skipped
+// unless force is set. Returns the number of handoffs written.
+func (l *Ledger) RebuildHandoffs(since time.Time, force bool, summarize func(*LedgerEntry) string) (int, error) {
+	entries, err := l.ReadEntries()
+	if err != nil {
+		return 0, err
+	}
+
+	// Keep the latest entry for each session
+	latest := make(map[string]LedgerEntry)
+	for _, entry := range entries {
+		if entry.Timestamp.Before(since) {
+			continue
+		}
+		if prev, ok := latest[entry.SessionID]; !ok || !entry.Timestamp.Before(prev.Timestamp) {
+			latest[entry.SessionID] = entry
+		}
+	}
+
+	sessions := make([]string, 0, len(latest))
+	for sessionID := range latest {
+		sessions = append(sessions, sessionID)
+	}
+	sort.Strings(sessions)
+
+	written := 0
+	for _, sessionID := range sessions {
+		entry := latest[sessionID]
+
+		if !force {
+			existing, err := filepath.Glob(filepath.Join(l.handoffPath(), fmt.Sprintf("handoff_%s_*.md", sessionID)))
+			if err != nil {
+				return written, err
+			}
+			if len(existing) > 0 {
+				continue
+			}
+		}
+
+		if err := l.writeHandoff(sessionID, summarize(&entry), entry.Facts, nil, nil, entry.Timestamp); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// HandoffDir returns the directory handoff documents are written to
+func (l *Ledger) HandoffDir() string {
+	return l.handoffPath()
+}
+
+func (l *Ledger) handoffPath() string {
+	return filepath.Join(filepath.Dir(l.ledgerPath), "shared", "handoffs")
+}
+
+func (l *Ledger) writeHandoff(sessionID, summary string, facts []Fact, sourceFiles []string, carry *CarryForward, at time.Time) error {
+	handoffPath := l.handoffPath()
+	os.MkdirAll(handoffPath, 0755)
+
+	facts = CapTopImportance(facts, l.topImportanceFraction)
+
+	filename := fmt.Sprintf("handoff_%s_%s.md", sessionID, at.Format("20060102_150405"))
+	path := filepath.Join(handoffPath, filename)
+
+	// Long facts are clipped in the document's lines; the frontmatter then
+	// keeps them whole for ParseHandoff
+	clipped := false
+	line := func(fact Fact) string {
+		text := ClipFact(fact.Content, l.factLineLength(fact.Type))
+		clipped = clipped || text != fact.Content
+		return text
+	}
+
+	body := ""
+	for _, fact := range facts {
+		body += fmt.Sprintf("- [%s] %s (importance: %d)\n", fact.Type, line(fact), fact.Importance)
+	}
+
+	body += "\n## Next Steps\n"
+	for _, fact := range facts {
+		if fact.Type == "todo" {
+			body += fmt.Sprintf("- [ ] %s\n", line(fact))
+		}
+	}
+
+	blockerMarker := "⚠️"
+	if l.noEmoji {
+		blockerMarker = "[BLOCKER]"
+	}
+
+	body += "\n## Blockers\n"
+	for _, fact := range facts {
+		if fact.Type == "blocker" {
+			body += fmt.Sprintf("- %s %s\n", blockerMarker, line(fact))
+		}
+	}
+
+	if carry != nil {
+		body += "\n## Carry Forward\n"
+		body += fmt.Sprintf("Close to compacting: carry these %d of %d facts (~%d of %d tokens) into the next session.\n",
+			len(carry.Facts), len(facts), carry.Tokens, carry.Budget)
+		for _, fact := range carry.Facts {
+			body += fmt.Sprintf("- [%s] %s\n", fact.Type, line(fact))
+		}
+	}
+
+	var fullFacts []Fact
+	if clipped {
+		fullFacts = facts
+	}
+	content := handoffFrontmatter(sessionID, l.projectID, sourceFiles, fullFacts, at)
+	content += fmt.Sprintf(`# Session Handoff
+
+**Session ID**: %s
+**Timestamp**: %s
+**Project**: %s
+
+## Summary
+%s
+
+## Key Facts
+`, sessionID, at.Format(time.RFC3339), l.projectID, summary)
+	content += body
+
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// factLineLength is how many characters of a fact's content its handoff
+// lines show
+func (l *Ledger) factLineLength(factType string) int {
+	if limit, ok := l.factLineLengths[factType]; ok {
+		return limit
+	}
+	return l.defaultFactLineLength
+}
+
+// ClipFact shortens content to at most limit characters, at a word boundary
+// when there's one in the second half, ending it with "…". A limit of zero
+// leaves it whole.
+func ClipFact(content string, limit int) string {
+	runes := []rune(content)
+	if limit <= 0 || len(runes) <= limit {
+		return content
+	}
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
+
+// CapTopImportance keeps importance 5 for only the top fraction of facts by
+// rank, rounded up, lowering the rest that have it to 4, so that a session
+// with many high-scoring facts still singles out its most important. The
+// facts are returned as a copy in their original order. A fraction outside
+// (0, 1) returns them unchanged.
+func CapTopImportance(facts []Fact, fraction float64) []Fact {
+	if fraction <= 0 || fraction >= 1 || len(facts) == 0 {
+		return facts
+	}
+
+	order := make([]int, len(facts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return facts[order[a]].Rank() > facts[order[b]].Rank()
+	})
+	top := int(math.Ceil(fraction * float64(len(facts))))
+
+	capped := append([]Fact(nil), facts...)
+	for _, i := range order[top:] {
+		if capped[i].Importance > 4 {
+			capped[i].Importance = 4
+		}
+	}
+	return capped
+}
+
+// frontmatterFact is a fact as listed, one JSON object per line, under the
+// frontmatter's facts key
+type frontmatterFact struct {
+	Type       string  `json:"type"`
+	Content    string  `json:"content"`
+	Importance int     `json:"importance"`
+	Score      float64 `json:"score,omitempty"`
+}
+
+// handoffFrontmatter renders a handoff's frontmatter. facts, when given, are
+// listed in full, for documents whose fact lines were clipped.
+func handoffFrontmatter(sessionID, projectID string, sourceFiles []string, facts []Fact, at time.Time) string {
+	fm := "---\n"
+	fm += fmt.Sprintf("session_id: %s\n", sessionID)
+	fm += fmt.Sprintf("timestamp: %s\n", at.Format(time.RFC3339))
+	fm += fmt.Sprintf("project: %s\n", projectID)
+	if len(sourceFiles) > 0 {
+		fm += "source_files:\n"
+		for _, file := range sourceFiles {
+			fm += fmt.Sprintf("  - %q\n", file)
+		}
+	}
+	if len(facts) > 0 {
+		fm += "facts:\n"
+		for _, fact := range facts {
+			data, _ := json.Marshal(frontmatterFact{
+				Type:       fact.Type,
+				Content:    fact.Content,
+				Importance: fact.Importance,
+				Score:      fact.Score,
+			})
+			fm += fmt.Sprintf("  - %s\n", data)
+		}
+	}
+	fm += "---\n\n"
+	return fm
+}
+
+// LatestHandoff returns the most recently written handoff, or nil if none exist
+func (l *Ledger) LatestHandoff() (*Handoff, error) {
+	return LatestHandoffIn(l.handoffPath())
+}
+
+// HandoffDirFor returns the directory a repo's handoffs are written to
+func HandoffDirFor(repoPath string) string {
+	return filepath.Join(repoPath, "thoughts", "shared", "handoffs")
+}
+
+// LatestHandoffIn returns the most recent handoff in dir, or nil if none
+// exist. Unlike NewLedger it creates nothing on disk.
+func LatestHandoffIn(dir string) (*Handoff, error) {
+	handoffs, err := ListHandoffsIn(dir)
+	if err != nil || len(handoffs) == 0 {
+		return nil, err
+	}
+	return handoffs[0], nil
+}
+
+// ListHandoffsIn returns the handoffs in dir, newest first. Files that
+// aren't valid handoffs are skipped.
+func ListHandoffsIn(dir string) ([]*Handoff, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "handoff_*.md"))
+	if err != nil {
+		return nil, err
+	}
+
+	var handoffs []*Handoff
+	for _, file := range files {
+		handoff, err := ParseHandoff(file)
+		if err != nil {
+			continue
+		}
+		handoffs = append(handoffs, handoff)
+	}
+
+	sort.SliceStable(handoffs, func(i, j int) bool {
+		return handoffs[i].Timestamp.After(handoffs[j].Timestamp)
+	})
+	return handoffs, nil
+}
+
+// ParseHandoff reads a handoff document's frontmatter, summary, and key facts
+func ParseHandoff(path string) (*Handoff, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	handoff := &Handoff{Path: path}
+	section := ""
+	inFrontmatter := false
+	// list is the frontmatter list being read. Facts listed there are whole,
+	// so the Key Facts lines, which may be clipped, are then skipped.
+	list := ""
+	fullFacts := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for lineNo := 0; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+
+		if line == "---" && (lineNo == 0 || inFrontmatter) {
+			inFrontmatter = !inFrontmatter
+			fullFacts = len(handoff.Facts) > 0
+			continue
+		}
+
+		if inFrontmatter {
+			list = parseFrontmatterLine(handoff, line, list)
+			continue
+		}
+
+		if strings.HasPrefix(line, "## ") {
+			section = strings.TrimPrefix(line, "## ")
+			continue
+		}
+
+		switch section {
+		case "Summary":
+			if strings.TrimSpace(line) != "" {
+				handoff.Summary = strings.TrimSpace(strings.TrimSpace(handoff.Summary + " " + line))
+			}
+		case "Key Facts":
+			if fullFacts {
+				continue
+			}
+			if match := handoffFactLine.FindStringSubmatch(line); match != nil {
+				importance, _ := strconv.Atoi(match[3])
+				handoff.Facts = append(handoff.Facts, Fact{
+					Type:       match[1],
+					Content:    match[2],
+					Importance: importance,
+					Timestamp:  handoff.Timestamp,
+				})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if handoff.SessionID == "" {
+		return nil, fmt.Errorf("%s: missing handoff frontmatter", filepath.Base(path))
+	}
+	return handoff, nil
+}
+
+// parseFrontmatterLine reads one frontmatter line into handoff. list is the
+// key of the list the line may belong to; the key of the list being read
+// after the line is returned.
+func parseFrontmatterLine(handoff *Handoff, line, list string) string {
+	if item, ok := strings.CutPrefix(line, "  - "); ok {
+		switch list {
+		case "facts":
+			var fact frontmatterFact
+			if err := json.Unmarshal([]byte(item), &fact); err == nil {
+				handoff.Facts = append(handoff.Facts, Fact{
+					Type:       fact.Type,
+					Content:    fact.Content,
+					Importance: fact.Importance,
+					Score:      fact.Score,
+					Timestamp:  handoff.Timestamp,
+				})
+			}
+		case "source_files":
+			if file, err := strconv.Unquote(item); err == nil {
+				handoff.SourceFiles = append(handoff.SourceFiles, file)
+			}
+		}
+		return list
+	}
+
+	if key, ok := strings.CutSuffix(line, ":"); ok {
+		return key
+	}
+	key, value, ok := strings.Cut(line, ": ")
+	if !ok {
+		return ""
+	}
+	switch key {
+	case "session_id":
+		handoff.SessionID = value
+	case "timestamp":
+		handoff.Timestamp, _ = time.Parse(time.RFC3339, value)
+	}
+	return ""
+}
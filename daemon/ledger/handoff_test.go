@@ -0,0 +1,305 @@
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFixtureLedger copies the continuity files in testdata/fixture into a
+// temporary repo and returns a ledger over them
+func newFixtureLedger(t *testing.T, fixture string, config LedgerConfig) *Ledger {
+	t.Helper()
+	config.RepoPath = t.TempDir()
+	if config.ProjectID == "" {
+		config.ProjectID = "proj1"
+	}
+	l := NewLedgerWithConfig(config)
+
+	files, err := filepath.Glob(filepath.Join("testdata", fixture, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(l.Dir(), filepath.Base(file)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return l
+}
+
+// readGolden returns a file from testdata/golden
+func readGolden(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "golden", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func summarizeForTest(entry *LedgerEntry) string {
+	return fmt.Sprintf("Session %s: %d facts, %d tokens", entry.SessionID, len(entry.Facts), entry.TokenCount)
+}
+
+func TestRebuildHandoffs(t *testing.T) {
+	l := newFixtureLedger(t, "ledger", LedgerConfig{})
+
+	// s1 already has a handoff, in an older format without frontmatter
+	old := filepath.Join(l.HandoffDir(), "handoff_s1_20260301_090000.md")
+	os.MkdirAll(l.HandoffDir(), 0755)
+	if err := os.WriteFile(old, []byte("# Session Handoff\n\n**Session ID**: s1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := l.RebuildHandoffs(time.Time{}, false, summarizeForTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 1 {
+		t.Errorf("rebuilt %d handoffs, want 1 for the session without one", written)
+	}
+
+	// The rebuilt handoff uses the current format, from the session's latest entry
+	got, err := os.ReadFile(filepath.Join(l.HandoffDir(), "handoff_s2_20260301_120000.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := readGolden(t, "handoff_s2.md"); string(got) != want {
+		t.Errorf("rebuilt handoff:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Forcing rebuilds s1 too, from its latest entry
+	written, err = l.RebuildHandoffs(time.Time{}, true, summarizeForTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 2 {
+		t.Errorf("forced rebuild wrote %d handoffs, want 2", written)
+	}
+	handoff, err := ParseHandoff(filepath.Join(l.HandoffDir(), "handoff_s1_20260301_110000.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Session s1: 2 facts, 5400 tokens"; handoff.Summary != want {
+		t.Errorf("s1 summary = %q, want %q", handoff.Summary, want)
+	}
+	if len(handoff.Facts) != 2 || handoff.Facts[1].Content != "CI is red on main" {
+		t.Errorf("s1 facts = %+v, want the decision and the blocker", handoff.Facts)
+	}
+
+	// since leaves out sessions whose entries are all earlier
+	l = newFixtureLedger(t, "ledger", LedgerConfig{})
+	written, err = l.RebuildHandoffs(time.Date(2026, 3, 1, 11, 30, 0, 0, time.UTC), false, summarizeForTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 1 {
+		t.Errorf("rebuild since 11:30 wrote %d handoffs, want 1", written)
+	}
+}
+
+func TestHandoffMarkers(t *testing.T) {
+	facts := []Fact{
+		{Type: "blocker", Content: "CI is red on main", Importance: 5},
+		{Type: "todo", Content: "Add migrations", Importance: 3},
+	}
+
+	tests := []struct {
+		noEmoji  bool
+		blocker  string
+		unwanted string
+	}{
+		{false, "- ⚠️ CI is red on main\n", "[BLOCKER]"},
+		{true, "- [BLOCKER] CI is red on main\n", "⚠️"},
+	}
+	for _, tt := range tests {
+		l := NewLedgerWithConfig(LedgerConfig{ProjectID: "proj1", RepoPath: t.TempDir(), NoEmoji: tt.noEmoji})
+		if err := l.CreateHandoff("s1", "Summary", facts, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		handoff, err := l.LatestHandoff()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(handoff.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content := string(data)
+		if !strings.Contains(content, tt.blocker) {
+			t.Errorf("no-emoji %v: handoff lacks %q:\n%s", tt.noEmoji, tt.blocker, content)
+		}
+		if strings.Contains(content, tt.unwanted) {
+			t.Errorf("no-emoji %v: handoff has %q:\n%s", tt.noEmoji, tt.unwanted, content)
+		}
+		if !strings.Contains(content, "- [ ] Add migrations\n") {
+			t.Errorf("no-emoji %v: handoff lacks the todo checkbox:\n%s", tt.noEmoji, content)
+		}
+	}
+}
+
+func TestHandoffCapsTopImportance(t *testing.T) {
+	// Ten facts scoring 5, and one lower that the cap leaves alone
+	var facts []Fact
+	for i := 0; i < 10; i++ {
+		facts = append(facts, Fact{Type: "decision", Content: fmt.Sprintf("Decision %d", i), Importance: 5, Score: 4.5 + float64(i)/100})
+	}
+	facts = append(facts, Fact{Type: "todo", Content: "Add migrations", Importance: 3})
+
+	l := NewLedgerWithConfig(LedgerConfig{ProjectID: "proj1", RepoPath: t.TempDir(), TopImportanceFraction: 0.2})
+	if err := l.CreateHandoff("s1", "Summary", facts, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	handoff, err := l.LatestHandoff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(handoff.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 20% of 11 facts, rounded up, is 3: the three highest scored
+	var top []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasSuffix(line, "(importance: 5)") {
+			top = append(top, line)
+		}
+	}
+	want := []string{
+		"- [decision] Decision 7 (importance: 5)",
+		"- [decision] Decision 8 (importance: 5)",
+		"- [decision] Decision 9 (importance: 5)",
+	}
+	if strings.Join(top, "\n") != strings.Join(want, "\n") {
+		t.Errorf("facts at importance 5:\n%s\nwant:\n%s", strings.Join(top, "\n"), strings.Join(want, "\n"))
+	}
+	for _, line := range []string{"- [decision] Decision 0 (importance: 4)", "- [todo] Add migrations (importance: 3)"} {
+		if !strings.Contains(string(data), line+"\n") {
+			t.Errorf("handoff lacks %q:\n%s", line, data)
+		}
+	}
+
+	// The facts given are left as scored
+	if facts[0].Importance != 5 {
+		t.Errorf("capping changed the caller's facts: %+v", facts[0])
+	}
+}
+
+func TestCapTopImportanceOff(t *testing.T) {
+	facts := []Fact{{Content: "a", Importance: 5}, {Content: "b", Importance: 5}}
+	for _, fraction := range []float64{0, 1, -0.5} {
+		capped := CapTopImportance(facts, fraction)
+		if capped[0].Importance != 5 || capped[1].Importance != 5 {
+			t.Errorf("fraction %v capped %+v, want unchanged", fraction, capped)
+		}
+	}
+}
+
+func TestClipFact(t *testing.T) {
+	tests := []struct {
+		content string
+		limit   int
+		want    string
+	}{
+		{"Use Postgres", 0, "Use Postgres"},
+		{"Use Postgres", 12, "Use Postgres"},
+		// Cut at the last word boundary in the second half
+		{"Use Postgres for the session store", 20, "Use Postgres for…"},
+		// Without one the word itself is cut
+		{"Supercalifragilistic", 10, "Supercali…"},
+		{"Done. Then more", 7, "Done…"},
+		{"Café au lait au bar", 9, "Café au…"},
+	}
+	for _, tt := range tests {
+		got := ClipFact(tt.content, tt.limit)
+		if got != tt.want {
+			t.Errorf("ClipFact(%q, %d) = %q, want %q", tt.content, tt.limit, got, tt.want)
+		}
+		if tt.limit > 0 && len([]rune(got)) > tt.limit {
+			t.Errorf("ClipFact(%q, %d) = %q, longer than the limit", tt.content, tt.limit, got)
+		}
+	}
+}
+
+func TestHandoffClipsLongFactsInLinesOnly(t *testing.T) {
+	decision := "Use Postgres for the session store, with a read replica per region and nightly logical backups"
+	blocker := "Deploys are blocked by the expired certificate on the staging load balancer"
+	facts := []Fact{
+		{Type: "decision", Content: decision, Importance: 4},
+		{Type: "blocker", Content: blocker, Importance: 5},
+		{Type: "todo", Content: "Add migrations", Importance: 3},
+	}
+
+	l := NewLedgerWithConfig(LedgerConfig{
+		ProjectID:       "proj1",
+		RepoPath:        t.TempDir(),
+		FactLineLength:  40,
+		FactLineLengths: map[string]int{"blocker": 100},
+	})
+	if err := l.CreateHandoff("s1", "Summary", facts, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	latest, err := l.LatestHandoff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(latest.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frontmatter, body, _ := strings.Cut(strings.TrimPrefix(string(data), "---\n"), "\n---\n")
+
+	// The decision is clipped in the lines; the blocker, under its own
+	// higher limit, is not
+	for _, line := range []string{
+		"- [decision] Use Postgres for the session store… (importance: 4)\n",
+		"- [blocker] " + blocker + " (importance: 5)\n",
+		"- ⚠️ " + blocker + "\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("handoff lacks %q:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, decision) {
+		t.Errorf("handoff lines hold the whole decision:\n%s", body)
+	}
+
+	// The frontmatter keeps every fact whole, and parsing reads it from there
+	if !strings.Contains(frontmatter, decision) {
+		t.Errorf("frontmatter lacks the whole decision:\n%s", frontmatter)
+	}
+	handoff, err := ParseHandoff(latest.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(handoff.Facts) != 3 || handoff.Facts[0].Content != decision || handoff.Facts[1].Content != blocker {
+		t.Errorf("parsed facts = %+v, want them whole", handoff.Facts)
+	}
+
+	// With nothing clipped the facts aren't repeated in the frontmatter
+	l = NewLedgerWithConfig(LedgerConfig{ProjectID: "proj1", RepoPath: t.TempDir()})
+	if err := l.CreateHandoff("s1", "Summary", facts, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	latest, err = l.LatestHandoff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(latest.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frontmatter, _, _ := strings.Cut(strings.TrimPrefix(string(data), "---\n"), "\n---\n"); strings.Contains(frontmatter, "facts:") {
+		t.Errorf("unclipped handoff repeats its facts in the frontmatter:\n%s", frontmatter)
+	}
+}
@@ -0,0 +1,139 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MergeResult summarizes a Merge
+type MergeResult struct {
+	// Added is how many entries were copied from the other ledger
+	Added int
+	// Duplicates is how many of its entries were already recorded
+	Duplicates int
+	// Files is how many day files were written
+	Files int
+}
+
+// Merge adds the entries of the continuity files in otherDir to this
+// ledger, for example to combine ledgers kept on two machines. Entries stay
+// in the day file they were written to; day files present in both ledgers
+// are combined, ordered by timestamp and session, with identical entries
+// kept once. Each day file is replaced atomically.
+func (l *Ledger) Merge(otherDir string) (*MergeResult, error) {
+	otherFiles, err := filepath.Glob(filepath.Join(otherDir, "CONTINUITY_*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(otherFiles) == 0 {
+		return nil, fmt.Errorf("no continuity files in %s", otherDir)
+	}
+
+	if err := os.MkdirAll(l.ledgerPath, 0755); err != nil {
+		return nil, err
+	}
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+
+	result := &MergeResult{}
+	for _, otherFile := range otherFiles {
+		name := filepath.Base(otherFile)
+		path := filepath.Join(l.ledgerPath, name)
+
+		ours, err := readEntryFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		theirs, err := readEntryFile(otherFile)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		merged := make([]LedgerEntry, 0, len(ours)+len(theirs))
+		for _, entry := range ours {
+			key, err := entryKey(entry)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[key] {
+				seen[key] = true
+				merged = append(merged, entry)
+			}
+		}
+
+		added := 0
+		for _, entry := range theirs {
+			key, err := entryKey(entry)
+			if err != nil {
+				return nil, err
+			}
+			if seen[key] {
+				result.Duplicates++
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, entry)
+			added++
+		}
+		if added == 0 {
+			continue
+		}
+
+		sort.SliceStable(merged, func(i, j int) bool {
+			if !merged[i].Timestamp.Equal(merged[j].Timestamp) {
+				return merged[i].Timestamp.Before(merged[j].Timestamp)
+			}
+			return merged[i].SessionID < merged[j].SessionID
+		})
+
+		if err := writeEntryFile(path, merged); err != nil {
+			return nil, err
+		}
+		result.Added += added
+		result.Files++
+	}
+
+	return result, nil
+}
+
+// writeEntryFile replaces a continuity file with entries via a temporary
+// file, so a failed write leaves the original intact
+func writeEntryFile(path string, entries []LedgerEntry) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err == nil {
+			_, err = file.Write(append(data, '\n'))
+		}
+		if err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// entryKey identifies identical entries. Entries are upgraded when read, so
+// the same pass recorded under different schema versions still matches.
+func entryKey(entry LedgerEntry) (string, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,59 @@
+package ledger
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// entrySessions returns the session of each entry in a continuity file
+func entrySessions(t *testing.T, path string) []string {
+	t.Helper()
+	entries, err := readEntryFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sessions []string
+	for _, entry := range entries {
+		sessions = append(sessions, entry.SessionID+"@"+entry.Timestamp.Format("15:04"))
+	}
+	return sessions
+}
+
+func TestMergeLedgers(t *testing.T) {
+	l := newFixtureLedger(t, "ledger", LedgerConfig{})
+
+	result, err := l.Merge(filepath.Join("testdata", "ledger-desktop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The desktop repeats both s1 entries, one in the old schema
+	if want := (MergeResult{Added: 3, Duplicates: 2, Files: 2}); *result != want {
+		t.Errorf("merge result = %+v, want %+v", *result, want)
+	}
+
+	got := entrySessions(t, filepath.Join(l.Dir(), "CONTINUITY_2026-03-01.jsonl"))
+	if want := []string{"s1@10:00", "s3@10:30", "s1@11:00", "s0@12:00", "s2@12:00"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merged day = %v, want %v", got, want)
+	}
+	got = entrySessions(t, filepath.Join(l.Dir(), "CONTINUITY_2026-03-02.jsonl"))
+	if want := []string{"s4@09:00"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("copied day = %v, want %v", got, want)
+	}
+
+	// Merging again changes nothing
+	result, err = l.Merge(filepath.Join("testdata", "ledger-desktop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (MergeResult{Duplicates: 5}); *result != want {
+		t.Errorf("second merge result = %+v, want %+v", *result, want)
+	}
+}
+
+func TestMergeWithoutContinuityFiles(t *testing.T) {
+	l := newFixtureLedger(t, "ledger", LedgerConfig{})
+	if _, err := l.Merge(t.TempDir()); err == nil {
+		t.Error("merging an empty directory succeeded")
+	}
+}
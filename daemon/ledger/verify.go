@@ -0,0 +1,143 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Problem is an integrity issue found by Verify, located by day file and
+// line number
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+}
+
+// VerifyResult summarizes a Verify
+type VerifyResult struct {
+	Files    int
+	Entries  int
+	Problems []Problem
+}
+
+// entryLocation is where a verified entry was read from
+type entryLocation struct {
+	file      string
+	line      int
+	projectID string
+}
+
+// Verify checks every continuity file without changing it: each line must
+// parse, timestamps must not go backwards within a file, every entry needs
+// a session ID and must belong to the same project as the rest, and no
+// entry may be recorded twice. Entries written by a newer schema version
+// are reported rather than failing the check.
+func (l *Ledger) Verify() (*VerifyResult, error) {
+	if err := l.Flush(); err != nil {
+		return nil, err
+	}
+
+	files, err := l.ledgerFiles()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	result := &VerifyResult{Files: len(files)}
+	seen := make(map[string]entryLocation)
+	var located []entryLocation
+	projects := make(map[string]int)
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(path)
+		problem := func(line int, format string, args ...interface{}) {
+			result.Problems = append(result.Problems, Problem{File: name, Line: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		var lastTime time.Time
+		lastLine := 0
+		for i, line := range strings.Split(string(data), "\n") {
+			n := i + 1
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			entry, err := parseEntry(line)
+			if errors.Is(err, errUnsupportedSchema) {
+				problem(n, "%v", err)
+				continue
+			}
+			if err != nil {
+				problem(n, "unparseable entry: %v", err)
+				continue
+			}
+			result.Entries++
+
+			if entry.Timestamp.IsZero() {
+				problem(n, "missing timestamp")
+			} else {
+				if entry.Timestamp.Before(lastTime) {
+					problem(n, "timestamp %s is earlier than line %d's %s",
+						entry.Timestamp.Format(time.RFC3339), lastLine, lastTime.Format(time.RFC3339))
+				}
+				lastTime, lastLine = entry.Timestamp, n
+			}
+
+			if entry.SessionID == "" {
+				problem(n, "missing session ID")
+			}
+
+			key, err := entryKey(*entry)
+			if err != nil {
+				return nil, err
+			}
+			if first, ok := seen[key]; ok {
+				problem(n, "duplicate of %s:%d", first.file, first.line)
+				continue
+			}
+			location := entryLocation{file: name, line: n, projectID: entry.ProjectID}
+			seen[key] = location
+			located = append(located, location)
+			projects[entry.ProjectID]++
+		}
+	}
+
+	// A ledger belongs to one repo, so entries from another project were
+	// most likely merged in from the wrong place
+	project := ""
+	for id, count := range projects {
+		if count > projects[project] || (count == projects[project] && id < project) {
+			project = id
+		}
+	}
+	for _, location := range located {
+		if location.projectID != project {
+			result.Problems = append(result.Problems, Problem{
+				File:    location.file,
+				Line:    location.line,
+				Message: fmt.Sprintf("project %q differs from the ledger's %q", location.projectID, project),
+			})
+		}
+	}
+
+	sort.SliceStable(result.Problems, func(i, j int) bool {
+		if result.Problems[i].File != result.Problems[j].File {
+			return result.Problems[i].File < result.Problems[j].File
+		}
+		return result.Problems[i].Line < result.Problems[j].Line
+	})
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package ledger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyCorruptedLedger(t *testing.T) {
+	l := newFixtureLedger(t, "corrupted", LedgerConfig{})
+
+	result, err := l.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 2 || result.Entries != 7 {
+		t.Errorf("verified %d entries in %d files, want 7 in 2", result.Entries, result.Files)
+	}
+
+	const day = "CONTINUITY_2026-03-01.jsonl"
+	want := []struct {
+		line    int
+		message string
+	}{
+		{3, "unparseable entry"},
+		{4, "timestamp 2026-03-01T09:00:00Z is earlier than line 2's 2026-03-01T11:00:00Z"},
+		{5, "duplicate of CONTINUITY_2026-03-01.jsonl:1"},
+		{6, "missing session ID"},
+		{7, `project "proj2" differs from the ledger's "proj1"`},
+		{8, "unsupported ledger schema version"},
+	}
+	if len(result.Problems) != len(want) {
+		t.Fatalf("found %d problems, want %d:\n%v", len(result.Problems), len(want), result.Problems)
+	}
+	for i, w := range want {
+		got := result.Problems[i]
+		if got.File != day || got.Line != w.line || !strings.Contains(got.Message, w.message) {
+			t.Errorf("problem %d = %s, want %s:%d: %s", i, got, day, w.line, w.message)
+		}
+	}
+}
+
+func TestVerifyCleanLedger(t *testing.T) {
+	l := newFixtureLedger(t, "ledger", LedgerConfig{})
+
+	result, err := l.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Problems) != 0 || result.Entries != 3 {
+		t.Errorf("clean ledger: %d entries, problems %v", result.Entries, result.Problems)
+	}
+}
@@ -2,35 +2,124 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
+	"text/template"
+	"time"
 
 	"github.com/angelfreak/ccd/daemon/api"
+	"github.com/angelfreak/ccd/daemon/detect"
+	"github.com/angelfreak/ccd/daemon/embed"
+	"github.com/angelfreak/ccd/daemon/extractor"
+	"github.com/angelfreak/ccd/daemon/instance"
+	"github.com/angelfreak/ccd/daemon/ledger"
 	"github.com/angelfreak/ccd/daemon/monitor"
+	"github.com/angelfreak/ccd/daemon/redact"
+	"github.com/angelfreak/ccd/daemon/server"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// version is stamped at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+var (
+	pbURL            = flag.String("pb-url", "http://localhost:8090", "PocketBase URL")
+	projectID        = flag.String("project", "", "Project ID to track")
+	repoPath         = flag.String("repo", "", "Repository path for ledger storage")
+	logPath          = flag.String("logs", getDefaultLogPath(), "Claude Code logs directory")
+	verbose          = flag.Bool("v", false, "Verbose logging")
+	smartMode        = flag.Bool("smart", true, "Enable smart context features (importance scoring, compression)")
+	compactThreshold = flag.Int("compact-threshold", 170000, "Token threshold for pre-compact handoff (overrides -model)")
+	model            = flag.String("model", "", "Set the compact threshold from this model's context window (opus, sonnet, haiku, sonnet-1m, or a full model ID)")
+	compactFraction  = flag.Float64("compact-fraction", 0.85, "With -model, the share of the context window at which to compact")
+	waitForLogs      = flag.Duration("wait-for-logs", 0, "Wait this long for a missing log directory to be created before giving up (negative waits indefinitely, 0 fails at once)")
+	recordSources    = flag.Bool("record-sources", true, "Record contributing log filenames in handoffs and session records")
+	recordSessions   = flag.Bool("record-sessions", false, "Also record each handoff as a session in PocketBase (always on with -no-ledger)")
+	idleHandoff      = flag.Duration("idle-handoff", 0, "Create a handoff after this long without log activity (0 disables)")
+	sessionGap       = flag.Duration("session-gap", 0, "Start a new session on activity after this long without any (0 disables)")
+	redactSecrets    = flag.Bool("redact", true, "Redact API keys, tokens, and passwords before storing facts and handoffs")
+	factRetention    = flag.String("fact-retention", "", "Delete stale, unpinned facts older than this (e.g. 90d, 720h); empty keeps facts forever")
+	eventQueueSize   = flag.Int("event-queue-size", 100, "Processing passes to buffer before dropping when PocketBase is slow")
+	detectStack      = flag.Bool("detect-stack", false, "Detect the repo's tech stack at startup and update the project record")
+	rebuildHandoffs  = flag.Bool("rebuild-handoffs", false, "Regenerate handoffs from the ledger and exit")
+	rebuildSince     = flag.String("rebuild-since", "", "With -rebuild-handoffs, only use ledger entries from this date (YYYY-MM-DD)")
+	rebuildForce     = flag.Bool("rebuild-force", false, "With -rebuild-handoffs, overwrite sessions that already have a handoff")
+	proseRatio       = flag.Float64("prose-chars-per-token", monitor.DefaultProseCharsPerToken, "Characters per token when estimating prose")
+	codeRatio        = flag.Float64("code-chars-per-token", monitor.DefaultCodeCharsPerToken, "Characters per token when estimating fenced code")
+	summaryTemplate  = flag.String("summary-template", "", "Path to a Go text/template used to render handoff summaries")
+	rulesFile        = flag.String("rules", defaultRulesPath(), "Extraction rules file applied to every project, before the repo's own "+extractor.ProjectRulesFile+" (ignored if missing)")
+	asyncPublish     = flag.Bool("async-publish", false, "Post facts to PocketBase in the background instead of inline")
+	factsPerMinute   = flag.Int("facts-per-minute", 60, "Maximum facts posted to PocketBase per minute (0 = unlimited)")
+	factsPerSession  = flag.Int("facts-per-session", 1000, "Stop posting facts after this many in one session (0 = unlimited)")
+	carryForward     = flag.Int("carry-forward-tokens", 0, "Recommend the most valuable facts fitting in this many tokens in pre-compact handoffs (0 = off)")
+	topImportance    = flag.Float64("top-importance-fraction", 0, "In handoffs, show only this fraction of facts, the highest ranked, with importance 5 (0 = off)")
+	maxFactsPerPass  = flag.Int("max-facts-per-pass", 0, "Keep only this many of the most important facts from each processing pass (0 = no cap)")
+	factDetailCap    = flag.Int("fact-detail-cap", 0, "Append a fact to an earlier one on the same topic this session while the result fits in this many bytes (0 = always post separately)")
+	factRateWarning  = flag.Int("fact-rate-warning", 200, "Warn when more facts than this are extracted in a minute, a sign of reprocessed logs (0 = off)")
+	noEmoji          = flag.Bool("no-emoji", false, "Use plain-text markers instead of emoji in handoffs")
+	computeEmbed     = flag.Bool("compute-embeddings", false, "Periodically compute embedding vectors for facts that lack one (needs $"+embed.APIKeyEnv+")")
+	embeddingModel   = flag.String("embedding-model", embed.DefaultModel, "Model used by -compute-embeddings")
+	embeddingURL     = flag.String("embedding-url", embed.DefaultURL, "Embeddings API endpoint used by -compute-embeddings")
+	normalize        = flag.String("normalize", "basic", "How facts are matched for dedup and diffs: exact, basic (case, whitespace, trailing punctuation), or stem")
+	adaptiveStale    = flag.Bool("adaptive-stale", false, "Judge staleness with the model trained by cct stale train, when one exists")
+	autoPrioritize   = flag.Bool("auto-prioritize", false, "Re-rank active project priorities by open blocker count daily at midnight")
+	httpAddr         = flag.String("http-addr", "", "Address for the optional health/metrics/events HTTP server (e.g. 127.0.0.1:8091)")
+	runDir           = flag.String("run-dir", defaultRunDir(), "Register the running daemon here for cct daemon list (empty to disable)")
+	explain          = flag.Bool("explain", false, "Log why each fact was created: the rule and keyword matched, and the importance score's breakdown")
+	ledgerBatchSize  = flag.Int("ledger-batch-size", 1, "Write ledger entries in batches of this many to reduce disk writes on busy repos (1 = write each entry)")
+	ledgerFlush      = flag.Duration("ledger-flush-interval", 5*time.Second, "With -ledger-batch-size, write a partial batch at least this often (0 = only when full or on shutdown)")
+	noLedger         = flag.Bool("no-ledger", false, "Keep smart mode's ledger in memory and record handoffs only as PocketBase sessions, writing nothing under thoughts/")
+	showVersion      = flag.Bool("version", false, "Print the version and exit")
 )
 
 var (
-	pbURL      = flag.String("pb-url", "http://localhost:8090", "PocketBase URL")
-	projectID  = flag.String("project", "", "Project ID to track")
-	repoPath   = flag.String("repo", "", "Repository path for ledger storage")
-	logPath    = flag.String("logs", getDefaultLogPath(), "Claude Code logs directory")
-	verbose    = flag.Bool("v", false, "Verbose logging")
-	smartMode  = flag.Bool("smart", true, "Enable smart context features (importance scoring, compression)")
-	compactThreshold = flag.Int("compact-threshold", 170000, "Token threshold for pre-compact handoff")
+	redactPatterns   stringList
+	importanceFloors stringList
+	ledgerMinimums   stringList
+	includeGlobs     stringList
+	excludeGlobs     stringList
+	factFields       stringList
+	factLengths      stringList
 )
 
+func init() {
+	flag.Var(&redactPatterns, "redact-pattern", "Additional regular expression to redact (repeatable)")
+	flag.Var(&includeGlobs, "include-glob", "Only process log files whose name matches this glob (repeatable; default *.log, which also covers rotated and gzipped copies)")
+	flag.Var(&excludeGlobs, "exclude-glob", "Skip log files whose name matches this glob (repeatable)")
+	flag.Var(&importanceFloors, "importance-floor", "Lowest importance for a fact type, as type=N (repeatable; defaults blocker=4, decision=3; N=0 removes a floor)")
+	flag.Var(&ledgerMinimums, "ledger-min-importance", "Only write facts of a type to the ledger and handoffs at this importance or above, as type=N (repeatable; facts are still posted)")
+	flag.Var(&factLengths, "handoff-fact-length", "Clip facts in handoff lines to this many characters, as N or type=N (repeatable; facts are kept whole by default)")
+	flag.Var(&factFields, "fact-field", "Read and write a fact field under another name for a custom PocketBase schema, as name=field (repeatable; e.g. fact_type=kind)")
+}
+
 func main() {
+	// Registered as the start time, so cct can tell this process from one
+	// that later reuses its PID
+	started := time.Now()
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Printf("cct-daemon version %s\n", version)
+		return
+	}
+
 	if *projectID == "" {
 		log.Fatal("Project ID is required. Use -project flag.")
 	}
 
 	// Initialize PocketBase client
 	client := api.NewClient(*pbURL)
+	fields, err := parseFactFields(factFields)
+	if err != nil {
+		log.Fatalf("Invalid -fact-field: %v", err)
+	}
+	client.SetFactFields(fields)
 
 	// Verify project exists and get repo path
 	project, err := client.GetProject(*projectID)
@@ -43,23 +132,151 @@ func main() {
 		*repoPath = project.RepoPath
 	}
 
+	tmpl, err := loadSummaryTemplate(*summaryTemplate)
+	if err != nil {
+		log.Fatalf("Invalid -summary-template: %v", err)
+	}
+
+	rules, err := loadExtractionRules(*rulesFile, *repoPath)
+	if err != nil {
+		log.Fatalf("Invalid extraction rules: %v", err)
+	}
+
+	if *topImportance < 0 || *topImportance >= 1 {
+		log.Fatalf("Invalid -top-importance-fraction: must be at least 0 and less than 1")
+	}
+
+	factLength, typeFactLengths, err := parseFactLengths(factLengths)
+	if err != nil {
+		log.Fatalf("Invalid -handoff-fact-length: %v", err)
+	}
+
+	if *rebuildHandoffs {
+		if *noLedger {
+			log.Fatalf("-rebuild-handoffs can't be used with -no-ledger")
+		}
+		runRebuildHandoffs(*projectID, *repoPath, tmpl, factLength, typeFactLengths)
+		return
+	}
+
+	if *detectStack {
+		refreshTechStack(client, project, *repoPath)
+	}
+
+	if *autoPrioritize {
+		go runAtMidnight(func() { prioritizeByBlockers(client) })
+	}
+
+	if err := resolveCompactThreshold(); err != nil {
+		log.Fatalf("Invalid -model or -compact-fraction: %v", err)
+	}
+
 	log.Printf("Starting Claude Context Tracker daemon")
 	log.Printf("PocketBase URL: %s", *pbURL)
 	log.Printf("Project ID: %s", *projectID)
 	log.Printf("Repo Path: %s", *repoPath)
 	log.Printf("Logs path: %s", *logPath)
 	log.Printf("Smart mode: %v", *smartMode)
+	if *smartMode && *noLedger {
+		log.Printf("Ledger: disabled, handoffs are recorded in PocketBase only")
+	}
 	log.Printf("Compact threshold: %d tokens", *compactThreshold)
 
+	retention, err := parseRetention(*factRetention)
+	if err != nil {
+		log.Fatalf("Invalid -fact-retention: %v", err)
+	}
+
+	var redactor *redact.Redactor
+	if *redactSecrets {
+		redactor, err = redact.NewRedactor(redactPatterns)
+		if err != nil {
+			log.Fatalf("Failed to configure redaction: %v", err)
+		}
+	}
+
+	var embedder *embed.Client
+	if *computeEmbed {
+		apiKey := os.Getenv(embed.APIKeyEnv)
+		if apiKey == "" {
+			log.Fatalf("-compute-embeddings requires $%s", embed.APIKeyEnv)
+		}
+		embedder = embed.NewClient(*embeddingURL, apiKey, *embeddingModel)
+	}
+
+	level, err := smart.ParseNormalizeLevel(*normalize)
+	if err != nil {
+		log.Fatalf("Invalid -normalize: %v", err)
+	}
+	smart.SetNormalization(level)
+
+	floors, err := parseTypeImportances(importanceFloors)
+	if err != nil {
+		log.Fatalf("Invalid -importance-floor: %v", err)
+	}
+	ledgerMinImportance, err := parseTypeImportances(ledgerMinimums)
+	if err != nil {
+		log.Fatalf("Invalid -ledger-min-importance: %v", err)
+	}
+
+	var staleModel *smart.StaleModel
+	if *adaptiveStale {
+		staleModel = loadStaleModel()
+	}
+
+	// Live events are only needed when the HTTP server is enabled. The
+	// watcher gets them as an interface, which must stay nil without them.
+	var events *server.Broadcaster
+	var eventSink monitor.EventSink
+	if *httpAddr != "" {
+		events = server.NewBroadcaster()
+		eventSink = events
+	}
+
 	// Create watcher with enhanced features
 	config := monitor.WatcherConfig{
-		LogPath:          *logPath,
-		ProjectID:        *projectID,
-		RepoPath:         *repoPath,
-		Client:           client,
-		Verbose:          *verbose,
-		SmartMode:        *smartMode,
-		CompactThreshold: *compactThreshold,
+		LogPath:             *logPath,
+		ProjectID:           *projectID,
+		RepoPath:            *repoPath,
+		Client:              client,
+		Verbose:             *verbose,
+		SmartMode:           *smartMode,
+		CompactThreshold:    *compactThreshold,
+		RecordSourceFiles:   *recordSources,
+		RecordSessions:      *recordSessions,
+		IdleHandoffAfter:    *idleHandoff,
+		SessionGap:          *sessionGap,
+		Redactor:            redactor,
+		Events:              eventSink,
+		FactRetention:       retention,
+		EventQueueSize:      *eventQueueSize,
+		ProseCharsPerToken:  *proseRatio,
+		CodeCharsPerToken:   *codeRatio,
+		SummaryTemplate:     tmpl,
+		AsyncPublish:        *asyncPublish,
+		NoEmoji:             *noEmoji,
+		FactsPerMinute:      *factsPerMinute,
+		FactsPerSession:     *factsPerSession,
+		Embedder:            embedder,
+		StaleModel:          staleModel,
+		ImportanceFloors:    floors,
+		LedgerMinImportance: ledgerMinImportance,
+		FactRateWarning:     *factRateWarning,
+		IncludeGlobs:        includeGlobs,
+		ExcludeGlobs:        excludeGlobs,
+		Explain:             *explain,
+		LedgerBatchSize:     *ledgerBatchSize,
+		LedgerFlushInterval: *ledgerFlush,
+		FactDetailCap:       *factDetailCap,
+		MaxFactsPerPass:     *maxFactsPerPass,
+		ExtractionRules:     rules,
+		CarryForwardTokens:  *carryForward,
+		NoLedger:            *noLedger,
+		LogPathWait:         *waitForLogs,
+
+		TopImportanceFraction: *topImportance,
+		HandoffFactLength:     factLength,
+		HandoffFactLengths:    typeFactLengths,
 	}
 
 	watcher, err := monitor.NewWatcherWithConfig(config)
@@ -72,17 +289,369 @@ func main() {
 		log.Fatalf("Failed to start watcher: %v", err)
 	}
 
+	// Start optional HTTP server
+	var httpServer *server.Server
+	if *httpAddr != "" {
+		httpServer = server.NewServer(*httpAddr, watcher.Ledger(), events, watcher)
+		if err := httpServer.Start(); err != nil {
+			log.Fatalf("Failed to start HTTP server: %v", err)
+		}
+		log.Printf("HTTP server listening on %s", *httpAddr)
+	}
+
+	if *runDir != "" {
+		unregister, err := instance.Register(*runDir, instance.Info{
+			PID:         os.Getpid(),
+			ProjectID:   *projectID,
+			ProjectSlug: project.Slug,
+			LogPath:     *logPath,
+			RepoPath:    *repoPath,
+			HTTPAddr:    *httpAddr,
+			Version:     version,
+			StartedAt:   started,
+			Binary:      executablePath(),
+		})
+		if err != nil {
+			log.Printf("Warning: failed to register in %s: %v", *runDir, err)
+		} else {
+			defer unregister()
+		}
+	}
+
 	log.Println("Daemon started successfully. Press Ctrl+C to stop.")
 
+	// SIGUSR1 logs what's being watched, for diagnosing missed logs without
+	// the HTTP server
+	inventoryChan := make(chan os.Signal, 1)
+	signal.Notify(inventoryChan, syscall.SIGUSR1)
+	go func() {
+		for range inventoryChan {
+			logInventory(watcher.Inventory())
+		}
+	}()
+
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	log.Println("Shutting down...")
+	if httpServer != nil {
+		httpServer.Stop()
+	}
 	watcher.Stop()
 }
 
+// logInventory writes the watched-file inventory to the log
+func logInventory(inventory monitor.Inventory) {
+	log.Printf("Watching directories: %s", strings.Join(inventory.Directories, ", "))
+	log.Printf("Include globs: %s; exclude globs: %s", strings.Join(inventory.IncludeGlobs, ", "), strings.Join(inventory.ExcludeGlobs, ", "))
+	for _, file := range inventory.Files {
+		status := "never processed"
+		switch {
+		case !file.Included:
+			status = "not matched by globs"
+		case file.LastProcessed != nil:
+			status = fmt.Sprintf("%d messages extracted, last processed %s", file.Messages, file.LastProcessed.Format(time.RFC3339))
+		}
+		log.Printf("  %s (%d bytes, modified %s): %s", file.Path, file.Size, file.Modified.Format(time.RFC3339), status)
+	}
+}
+
+// runRebuildHandoffs regenerates handoff documents from the continuity ledger
+func runRebuildHandoffs(projectID, repoPath string, tmpl *template.Template, factLength int, typeFactLengths map[string]int) {
+	var since time.Time
+	if *rebuildSince != "" {
+		var err error
+		since, err = time.ParseInLocation("2006-01-02", *rebuildSince, time.Local)
+		if err != nil {
+			log.Fatalf("Invalid -rebuild-since date: %v", err)
+		}
+	}
+
+	l := ledger.NewLedgerWithConfig(ledger.LedgerConfig{
+		ProjectID:             projectID,
+		RepoPath:              repoPath,
+		NoEmoji:               *noEmoji,
+		TopImportanceFraction: *topImportance,
+		FactLineLength:        factLength,
+		FactLineLengths:       typeFactLengths,
+	})
+	summarize := func(entry *ledger.LedgerEntry) string {
+		return monitor.RenderSummary(tmpl, entry)
+	}
+	count, err := l.RebuildHandoffs(since, *rebuildForce, summarize)
+	if err != nil {
+		log.Fatalf("Failed to rebuild handoffs: %v", err)
+	}
+	log.Printf("Rebuilt %d handoffs", count)
+}
+
+// loadSummaryTemplate reads and validates a handoff summary template file
+func loadSummaryTemplate(path string) (*template.Template, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return monitor.ParseSummaryTemplate(string(data))
+}
+
+// loadExtractionRules layers the global rules file, then the repo's own, over
+// the built-in extraction rules
+func loadExtractionRules(globalPath, repoPath string) ([]extractor.Rule, error) {
+	rules := extractor.DefaultRules()
+	paths := []string{globalPath}
+	if repoPath != "" {
+		paths = append(paths, filepath.Join(repoPath, extractor.ProjectRulesFile))
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		var loaded bool
+		var err error
+		rules, loaded, err = extractor.LoadRulesFile(rules, path)
+		if err != nil {
+			return nil, err
+		}
+		if loaded {
+			log.Printf("Loaded extraction rules from %s", path)
+		}
+	}
+	return rules, nil
+}
+
+func defaultRulesPath() string {
+	path, err := extractor.DefaultRulesPath()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// refreshTechStack updates the project's tech stack when detection finds
+// something different from what's recorded
+func refreshTechStack(client *api.Client, project *api.Project, repoPath string) {
+	stack := detect.TechStack(repoPath)
+	if len(stack) == 0 || strings.Join(stack, ",") == strings.Join(project.TechStack, ",") {
+		return
+	}
+
+	if err := client.UpdateProjectTechStack(project.ID, stack); err != nil {
+		log.Printf("Failed to update tech stack: %v", err)
+		return
+	}
+	log.Printf("Tech stack: %s", strings.Join(stack, ", "))
+}
+
+// runAtMidnight calls fn at every local midnight, forever
+func runAtMidnight(fn func()) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+		time.Sleep(time.Until(next))
+		fn()
+	}
+}
+
+// prioritizeByBlockers re-ranks active projects so those with the most open
+// blockers get the highest priority
+func prioritizeByBlockers(client *api.Client) {
+	projects, err := client.ListProjects(`status = "active"`)
+	if err != nil {
+		log.Printf("Failed to list projects for prioritizing: %v", err)
+		return
+	}
+
+	counts := make([]smart.ProjectBlockers, 0, len(projects))
+	for _, project := range projects {
+		fields := client.FactFields()
+		blockers, err := client.ListFacts(project.ID, fmt.Sprintf(`%s = "blocker" && %s = false`, fields.Type, fields.Stale))
+		if err != nil {
+			log.Printf("Failed to count blockers for %s: %v", project.Name, err)
+			return
+		}
+		counts = append(counts, smart.ProjectBlockers{
+			ProjectID: project.ID,
+			Blockers:  len(blockers),
+			Priority:  project.Priority,
+		})
+	}
+
+	priorities := smart.PrioritizeByBlockers(counts, false)
+	for _, project := range projects {
+		priority := priorities[project.ID]
+		if priority == project.Priority {
+			continue
+		}
+		if err := client.UpdateProjectPriority(project.ID, priority); err != nil {
+			log.Printf("Failed to update priority for %s: %v", project.Name, err)
+			continue
+		}
+		log.Printf("Priority for %s: %d -> %d", project.Name, project.Priority, priority)
+	}
+}
+
+// resolveCompactThreshold derives -compact-threshold from -model and
+// -compact-fraction, unless the threshold was given explicitly
+func resolveCompactThreshold() error {
+	if *model == "" {
+		return nil
+	}
+
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "compact-threshold" {
+			explicit = true
+		}
+	})
+	if explicit {
+		return nil
+	}
+
+	threshold, err := smart.CompactThresholdFor(*model, *compactFraction)
+	if err != nil {
+		return err
+	}
+	*compactThreshold = threshold
+	return nil
+}
+
+func defaultRunDir() string {
+	dir, err := instance.DefaultDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// executablePath is the daemon binary's path with symlinks resolved, or empty
+// when it can't be found
+func executablePath() string {
+	path, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// loadStaleModel reads the trained stale model, returning nil so the
+// built-in thresholds are used when there isn't one
+func loadStaleModel() *smart.StaleModel {
+	path, err := smart.DefaultStaleModelPath()
+	if err != nil {
+		log.Printf("Adaptive stale: %v; using built-in thresholds", err)
+		return nil
+	}
+
+	model, err := smart.LoadStaleModel(path)
+	if os.IsNotExist(err) {
+		log.Printf("Adaptive stale: no model at %s yet; using built-in thresholds", path)
+		return nil
+	}
+	if err != nil {
+		log.Printf("Adaptive stale: %v; using built-in thresholds", err)
+		return nil
+	}
+
+	log.Printf("Adaptive stale: using model trained %s on %d labels (%.0f%% validation accuracy)",
+		model.TrainedAt.Format("2006-01-02"), model.Samples, model.Accuracy*100)
+	return model
+}
+
+// parseRetention accepts a Go duration or a whole number of days ("90d")
+func parseRetention(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// parseTypeImportances parses type=N pairs into per-type importances
+func parseTypeImportances(values []string) (map[string]int, error) {
+	importances := make(map[string]int)
+	for _, value := range values {
+		factType, n, ok := strings.Cut(value, "=")
+		if !ok || factType == "" {
+			return nil, fmt.Errorf("%q is not type=N", value)
+		}
+		importance, err := strconv.Atoi(n)
+		if err != nil || importance < 0 || importance > 5 {
+			return nil, fmt.Errorf("%q: importance must be 0-5", value)
+		}
+		importances[factType] = importance
+	}
+	return importances, nil
+}
+
+// parseFactLengths parses N and type=N values into an overall fact length
+// and per-type lengths
+func parseFactLengths(values []string) (int, map[string]int, error) {
+	overall := 0
+	byType := make(map[string]int)
+	for _, value := range values {
+		factType, n, ok := strings.Cut(value, "=")
+		if !ok {
+			factType, n = "", value
+		}
+		length, err := strconv.Atoi(n)
+		if err != nil || length < 0 {
+			return 0, nil, fmt.Errorf("%q is not N or type=N with N 0 or more", value)
+		}
+		if ok && factType == "" {
+			return 0, nil, fmt.Errorf("%q has no type", value)
+		}
+		if factType == "" {
+			overall = length
+		} else {
+			byType[factType] = length
+		}
+	}
+	return overall, byType, nil
+}
+
+// parseFactFields parses name=field pairs into a mapping of the default
+// fact field names
+func parseFactFields(values []string) (api.FactFields, error) {
+	fields := api.DefaultFactFields
+	for _, value := range values {
+		name, field, ok := strings.Cut(value, "=")
+		if !ok {
+			return fields, fmt.Errorf("%q is not name=field", value)
+		}
+		if err := fields.Set(name, field); err != nil {
+			return fields, err
+		}
+	}
+	return fields, nil
+}
+
+// stringList collects the values of a repeatable flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func getDefaultLogPath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+	"github.com/angelfreak/ccd/daemon/types"
+)
+
+func TestProjectRulesOverrideGlobalRules(t *testing.T) {
+	global := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(global, []byte(`
+rules:
+  - type: todo
+    importance: 2
+  - type: runbook
+    keywords: ["runbook"]
+    importance: 4
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := t.TempDir()
+	project := filepath.Join(repo, extractor.ProjectRulesFile)
+	if err := os.MkdirAll(filepath.Dir(project), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(project, []byte(`
+rules:
+  - type: todo
+    importance: 4
+  - type: runbook
+    disabled: true
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv := &types.Conversation{Messages: []types.Message{
+		{Role: "assistant", Content: "We need to add migrations"},
+		{Role: "assistant", Content: "Updated the runbook for failover"},
+	}}
+	importances := func(rules []extractor.Rule) map[string]int {
+		found := make(map[string]int)
+		for _, fact := range extractor.ExtractFactsWithRules(conv, rules) {
+			found[fact.Type] = fact.Importance
+		}
+		return found
+	}
+
+	// The global rules apply to a repo without its own
+	rules, err := loadExtractionRules(global, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := importances(rules); got["todo"] != 2 || got["runbook"] != 4 {
+		t.Errorf("global rules extracted %v, want todo at 2 and runbook at 4", got)
+	}
+
+	// The project's rules are layered over them
+	rules, err = loadExtractionRules(global, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := importances(rules)
+	if got["todo"] != 4 {
+		t.Errorf("project rules left todo at %d, want 4", got["todo"])
+	}
+	if _, ok := got["runbook"]; ok {
+		t.Error("project rules didn't disable the global runbook rule")
+	}
+}
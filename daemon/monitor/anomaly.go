@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"log"
+	"time"
+)
+
+// factRateWindow is the span fact creation rates are measured over
+const factRateWindow = time.Minute
+
+// FactRateMonitor spots bursts of fact extraction, which usually mean logs
+// are being reprocessed rather than a genuinely busy session
+type FactRateMonitor struct {
+	threshold int
+	batches   []factBatch
+	// warned is set while a burst is in progress so it's reported once
+	warned bool
+}
+
+type factBatch struct {
+	at    time.Time
+	count int
+}
+
+// NewFactRateMonitor flags rates above threshold facts per minute
+func NewFactRateMonitor(threshold int) *FactRateMonitor {
+	return &FactRateMonitor{threshold: threshold}
+}
+
+// Observe records count facts extracted at now and returns the rate over
+// the last minute. burst is true only when the rate first exceeds the
+// threshold; it rearms once the rate falls back below it.
+func (m *FactRateMonitor) Observe(count int, now time.Time) (rate int, burst bool) {
+	if count > 0 {
+		m.batches = append(m.batches, factBatch{at: now, count: count})
+	}
+
+	cutoff := now.Add(-factRateWindow)
+	kept := m.batches[:0]
+	for _, batch := range m.batches {
+		if batch.at.After(cutoff) {
+			kept = append(kept, batch)
+			rate += batch.count
+		}
+	}
+	m.batches = kept
+
+	if rate <= m.threshold {
+		m.warned = false
+		return rate, false
+	}
+	if m.warned {
+		return rate, false
+	}
+	m.warned = true
+	return rate, true
+}
+
+// checkFactRate warns when fact extraction bursts past the configured rate.
+// Processing carries on; the warning only points at a likely cause. Only
+// the event processor calls it.
+func (w *Watcher) checkFactRate(count int) {
+	if w.factRate == nil {
+		return
+	}
+	if rate, burst := w.factRate.Observe(count, time.Now()); burst {
+		log.Printf("Warning: %d facts extracted in the last minute (threshold %d); "+
+			"logs may be being reprocessed - check for a reset log offset or duplicate facts",
+			rate, w.factRate.threshold)
+	}
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFactRateMonitorWarnsOncePerBurst(t *testing.T) {
+	m := NewFactRateMonitor(10)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if rate, burst := m.Observe(6, start); rate != 6 || burst {
+		t.Errorf("first batch: rate %d, burst %v; want 6 and no burst", rate, burst)
+	}
+	if rate, burst := m.Observe(6, start.Add(10*time.Second)); rate != 12 || !burst {
+		t.Errorf("second batch: rate %d, burst %v; want 12 and a burst", rate, burst)
+	}
+	if _, burst := m.Observe(6, start.Add(20*time.Second)); burst {
+		t.Error("a burst in progress was reported again")
+	}
+
+	// Once the earlier batches age out the rate falls and the warning rearms
+	if rate, burst := m.Observe(0, start.Add(85*time.Second)); rate != 0 || burst {
+		t.Errorf("after a quiet minute: rate %d, burst %v; want 0 and no burst", rate, burst)
+	}
+	m.Observe(8, start.Add(90*time.Second))
+	if _, burst := m.Observe(8, start.Add(95*time.Second)); !burst {
+		t.Error("a second burst wasn't reported")
+	}
+}
+
+func TestFactBurstWarns(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	w, pb := newTestWatcher(t, WatcherConfig{FactRateWarning: 5})
+	defer w.Stop()
+
+	// A pathological transcript: many facts at once
+	var lines []string
+	for i := 0; i < 8; i++ {
+		lines = append(lines, "User: next?", fmt.Sprintf("Assistant: We decided to use option %d", i))
+	}
+	w.processLogFile(writeLog(t, w.logPath, "session.log", lines...))
+	settle(w)
+
+	if !strings.Contains(logs.String(), "Warning: 8 facts extracted in the last minute (threshold 5)") {
+		t.Errorf("no burst warning logged:\n%s", logs.String())
+	}
+	// Processing carries on regardless
+	if got := len(pb.postedFacts()); got != 8 {
+		t.Errorf("posted %d facts, want all 8", got)
+	}
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	// embeddingSweepInterval is how often facts missing an embedding are
+	// sent to the embeddings API
+	embeddingSweepInterval = 10 * time.Minute
+	// embeddingBatchSize caps how many facts are embedded per request
+	embeddingBatchSize = 64
+)
+
+// computeEmbeddings embeds every fact that doesn't have a vector yet
+func (w *Watcher) computeEmbeddings() {
+	facts, err := w.client.ListFacts(w.projectID, "")
+	if err != nil {
+		log.Printf("Failed to list facts for embedding: %v", err)
+		return
+	}
+
+	var ids, contents []string
+	for _, fact := range facts {
+		if len(fact.Embedding) == 0 {
+			ids = append(ids, fact.ID)
+			contents = append(contents, fact.Content)
+		}
+	}
+
+	embedded := 0
+	for start := 0; start < len(ids); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		vectors, err := w.embedder.Embed(contents[start:end])
+		if err != nil {
+			// Leave the rest for the next sweep rather than hammering a failing API
+			log.Printf("Failed to compute embeddings: %v", err)
+			break
+		}
+
+		for i, vector := range vectors {
+			if err := w.client.UpdateFactEmbedding(ids[start+i], vector); err != nil {
+				log.Printf("Failed to store embedding for fact %s: %v", ids[start+i], err)
+				continue
+			}
+			embedded++
+		}
+	}
+
+	if embedded > 0 || w.verbose {
+		log.Printf("Embeddings: computed %d of %d missing (%s)", embedded, len(ids), w.embedder.Model())
+	}
+}
@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePocketBase records what the watcher writes to PocketBase and serves
+// a fixed set of facts to its list requests
+type fakePocketBase struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	facts    []map[string]interface{}
+	sessions []map[string]interface{}
+	patches  map[string][]map[string]interface{}
+	deleted  []string
+	filters  []string
+	// stored is returned, unfiltered, by fact list requests
+	stored []map[string]interface{}
+	// delay holds up every fact post, for a slow server
+	delay time.Duration
+}
+
+func newFakePocketBase(t *testing.T) *fakePocketBase {
+	t.Helper()
+	pb := &fakePocketBase{patches: make(map[string][]map[string]interface{})}
+	pb.Server = httptest.NewServer(http.HandlerFunc(pb.serve))
+	t.Cleanup(pb.Close)
+	return pb
+}
+
+func (pb *fakePocketBase) serve(w http.ResponseWriter, r *http.Request) {
+	const facts = "/api/collections/extracted_facts/records"
+	const sessions = "/api/collections/session_history/records"
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == facts:
+		pb.mu.Lock()
+		delay := pb.delay
+		pb.mu.Unlock()
+		time.Sleep(delay)
+
+		pb.mu.Lock()
+		pb.facts = append(pb.facts, body)
+		id := fmt.Sprintf("fact%d", len(pb.facts))
+		pb.mu.Unlock()
+		writeTestJSON(w, map[string]string{"id": id})
+
+	case r.Method == http.MethodGet && r.URL.Path == facts:
+		pb.mu.Lock()
+		pb.filters = append(pb.filters, r.URL.Query().Get("filter"))
+		items := pb.stored
+		pb.mu.Unlock()
+		if items == nil {
+			items = []map[string]interface{}{}
+		}
+		writeTestJSON(w, map[string]interface{}{"page": 1, "totalPages": 1, "items": items})
+
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, facts+"/"):
+		pb.mu.Lock()
+		id := strings.TrimPrefix(r.URL.Path, facts+"/")
+		pb.patches[id] = append(pb.patches[id], body)
+		pb.mu.Unlock()
+		writeTestJSON(w, body)
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, facts+"/"):
+		pb.mu.Lock()
+		pb.deleted = append(pb.deleted, strings.TrimPrefix(r.URL.Path, facts+"/"))
+		pb.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPost && r.URL.Path == sessions:
+		pb.mu.Lock()
+		pb.sessions = append(pb.sessions, body)
+		pb.mu.Unlock()
+		writeTestJSON(w, body)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeTestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// postedFacts returns the facts posted so far
+func (pb *fakePocketBase) postedFacts() []map[string]interface{} {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]map[string]interface{}(nil), pb.facts...)
+}
+
+// postedContents returns the content of each fact posted so far
+func (pb *fakePocketBase) postedContents() []string {
+	var contents []string
+	for _, fact := range pb.postedFacts() {
+		content, _ := fact["content"].(string)
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+// postedSessions returns the session records created so far
+func (pb *fakePocketBase) postedSessions() []map[string]interface{} {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]map[string]interface{}(nil), pb.sessions...)
+}
+
+// deletedFacts returns the IDs of the facts deleted so far
+func (pb *fakePocketBase) deletedFacts() []string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]string(nil), pb.deleted...)
+}
+
+// listFilters returns the filter of each fact list request so far
+func (pb *fakePocketBase) listFilters() []string {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]string(nil), pb.filters...)
+}
+
+// store sets the facts served to list requests
+func (pb *fakePocketBase) store(facts ...map[string]interface{}) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.stored = facts
+}
+
+// patchesTo returns the bodies of the updates sent to a fact so far
+func (pb *fakePocketBase) patchesTo(id string) []map[string]interface{} {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return append([]map[string]interface{}(nil), pb.patches[id]...)
+}
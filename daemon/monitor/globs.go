@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// DefaultIncludeGlobs selects transcript logs when no include globs are
+// configured
+var DefaultIncludeGlobs = []string{"*.log"}
+
+// validateGlobs reports the first malformed pattern
+func validateGlobs(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesLogGlobs reports whether a log file's name is selected by the
+// include globs and not by the exclude globs. Rotated and compressed copies
+// (session.log.1.gz) are also matched by their original name (session.log),
+// so "*.log" covers them.
+func (w *Watcher) matchesLogGlobs(path string) bool {
+	name := filepath.Base(path)
+	names := []string{name}
+	if original := rotationSuffix.ReplaceAllString(name, ""); original != name {
+		names = append(names, original)
+	}
+
+	return matchesAny(w.includeGlobs, names) && !matchesAny(w.excludeGlobs, names)
+}
+
+func matchesAny(patterns, names []string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			// Patterns are validated when the watcher is created
+			if ok, _ := filepath.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWatcherGlobFilter(t *testing.T) {
+	w, pb := newTestWatcher(t, WatcherConfig{ExcludeGlobs: []string{"npm-*"}})
+	defer w.Stop()
+
+	writeLog(t, w.logPath, "session.log", "User: db?", "Assistant: We decided to use Postgres.")
+	writeLog(t, w.logPath, "session.log.1", "User: cache?", "Assistant: Going with Redis for now.")
+	writeLog(t, w.logPath, "npm-debug.log", "User: npm?", "Assistant: We decided to pin npm.")
+	writeLog(t, w.logPath, "notes.txt", "User: notes?", "Assistant: We decided to keep notes.")
+	if err := w.processExistingLogs(); err != nil {
+		t.Fatal(err)
+	}
+	settle(w)
+
+	got := pb.postedContents()
+	sort.Strings(got)
+	if want := []string{"Going with Redis for now", "We decided to use Postgres"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("posted facts = %q, want only those from the included logs: %q", got, want)
+	}
+
+	tests := map[string]bool{
+		"session.log":        true,
+		"session.log.1.gz":   true,
+		"/logs/other.log":    true,
+		"npm-debug.log":      false,
+		"npm-debug.log.2":    false,
+		"notes.txt":          false,
+		"session.log.backup": false,
+	}
+	for name, want := range tests {
+		if got := w.matchesLogGlobs(name); got != want {
+			t.Errorf("matchesLogGlobs(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestWatcherCustomIncludeGlobs(t *testing.T) {
+	w, _ := newTestWatcher(t, WatcherConfig{IncludeGlobs: []string{"*.jsonl", "claude-*"}})
+	defer w.Stop()
+
+	tests := map[string]bool{
+		"session.jsonl": true,
+		"claude-01.txt": true,
+		"session.log":   false,
+	}
+	for name, want := range tests {
+		if got := w.matchesLogGlobs(name); got != want {
+			t.Errorf("matchesLogGlobs(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestWatcherRejectsInvalidGlobs(t *testing.T) {
+	for _, config := range []WatcherConfig{
+		{IncludeGlobs: []string{"[*.log"}},
+		{ExcludeGlobs: []string{"npm-["}},
+	} {
+		config.LogPath, config.RepoPath, config.ProjectID = t.TempDir(), t.TempDir(), testProjectID
+		if _, err := NewWatcherWithConfig(config); err == nil {
+			t.Errorf("config with globs %q %q was accepted", config.IncludeGlobs, config.ExcludeGlobs)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Inventory lists what the watcher is watching, for diagnosing logs that
+// aren't being picked up
+type Inventory struct {
+	// Directories are the paths registered with the file watcher
+	Directories  []string      `json:"directories"`
+	IncludeGlobs []string      `json:"include_globs"`
+	ExcludeGlobs []string      `json:"exclude_globs,omitempty"`
+	Files        []WatchedFile `json:"files"`
+}
+
+// WatchedFile is a file in a watched directory. Files that don't match the
+// globs are listed too, with Included false, since they're usually the
+// answer to "why isn't my log processed?"
+type WatchedFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Included bool      `json:"included"`
+	// Messages is how many of the log's messages have been extracted,
+	// counted across renamed and rotated copies of the same content
+	Messages int `json:"messages"`
+	// LastProcessed is when the file was last parsed, or nil if never
+	LastProcessed *time.Time `json:"last_processed,omitempty"`
+}
+
+// processedLog records when a path was last parsed and how far extraction
+// had got
+type processedLog struct {
+	at       time.Time
+	messages int
+}
+
+// recordProcessed notes that path was parsed, with messages extracted from
+// its content so far
+func (w *Watcher) recordProcessed(path string, messages int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.processed[path] = processedLog{at: time.Now(), messages: messages}
+}
+
+// Inventory reports the watched directories and every file in them, with
+// the extraction progress of those processed so far
+func (w *Watcher) Inventory() Inventory {
+	inventory := Inventory{
+		Directories:  w.watcher.WatchList(),
+		IncludeGlobs: w.includeGlobs,
+		ExcludeGlobs: w.excludeGlobs,
+		Files:        []WatchedFile{},
+	}
+	sort.Strings(inventory.Directories)
+
+	w.mu.Lock()
+	processed := make(map[string]processedLog, len(w.processed))
+	for path, p := range w.processed {
+		processed[path] = p
+	}
+	w.mu.Unlock()
+
+	for _, dir := range inventory.Directories {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			file := WatchedFile{
+				Path:     path,
+				Size:     info.Size(),
+				Modified: info.ModTime(),
+				Included: w.matchesLogGlobs(entry.Name()) && !w.isOwnOutput(path),
+			}
+			if p, ok := processed[path]; ok {
+				at := p.at
+				file.Messages = p.messages
+				file.LastProcessed = &at
+			}
+			inventory.Files = append(inventory.Files, file)
+		}
+	}
+	return inventory
+}
@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestInventoryReflectsWatchedPaths(t *testing.T) {
+	w, _ := newTestWatcher(t, WatcherConfig{ExcludeGlobs: []string{"npm-*"}})
+	session := writeLog(t, w.logPath, "session.log", "User: db?", "Assistant: We decided to use Postgres.")
+	writeLog(t, w.logPath, "npm-debug.log", "npm output")
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// Another directory added later is listed too
+	extra := t.TempDir()
+	archived := writeLog(t, extra, "archived.log", "User: hi")
+	if err := w.watcher.Add(extra); err != nil {
+		t.Fatal(err)
+	}
+
+	inventory := w.Inventory()
+	wantDirs := []string{w.logPath, extra}
+	sort.Strings(wantDirs)
+	if !reflect.DeepEqual(inventory.Directories, wantDirs) {
+		t.Errorf("directories = %v, want %v", inventory.Directories, wantDirs)
+	}
+	if !reflect.DeepEqual(inventory.IncludeGlobs, DefaultIncludeGlobs) || !reflect.DeepEqual(inventory.ExcludeGlobs, []string{"npm-*"}) {
+		t.Errorf("globs = %v excluding %v", inventory.IncludeGlobs, inventory.ExcludeGlobs)
+	}
+
+	files := make(map[string]WatchedFile)
+	for _, file := range inventory.Files {
+		files[file.Path] = file
+	}
+	if len(files) != 3 {
+		t.Errorf("listed %d files, want 3: %+v", len(files), inventory.Files)
+	}
+
+	if file := files[session]; !file.Included || file.Messages != 2 || file.LastProcessed == nil || file.Size == 0 {
+		t.Errorf("session.log = %+v, want it included and processed with 2 messages", file)
+	}
+	if file := files[filepath.Join(w.logPath, "npm-debug.log")]; file.Included || file.LastProcessed != nil {
+		t.Errorf("npm-debug.log = %+v, want it excluded and unprocessed", file)
+	}
+	if file, ok := files[archived]; !ok || !file.Included || file.LastProcessed != nil {
+		t.Errorf("archived.log = %+v, want it included but not yet processed", file)
+	}
+}
@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartWaitsForLogDirectory(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "projects")
+	w, _ := newTestWatcher(t, WatcherConfig{LogPath: logPath, LogPathWait: time.Minute})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.Mkdir(logPath, 0755)
+	}()
+
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer w.Stop()
+
+	if dirs := w.Inventory().Directories; !reflect.DeepEqual(dirs, []string{logPath}) {
+		t.Errorf("watching %v, want %v", dirs, []string{logPath})
+	}
+}
+
+func TestStartGivesUpWaitingForLogDirectory(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "projects")
+
+	// Without a wait a missing directory fails at once
+	w, _ := newTestWatcher(t, WatcherConfig{LogPath: logPath})
+	if err := w.Start(); err == nil {
+		w.Stop()
+		t.Fatal("Start succeeded without the log directory")
+	}
+
+	w, _ = newTestWatcher(t, WatcherConfig{LogPath: logPath, LogPathWait: 200 * time.Millisecond})
+	start := time.Now()
+	err := w.Start()
+	if err == nil {
+		w.Stop()
+		t.Fatal("Start succeeded though the log directory never appeared")
+	}
+	if !strings.Contains(err.Error(), "was not created within 200ms") {
+		t.Errorf("Start error = %v, want a timeout", err)
+	}
+	if waited := time.Since(start); waited < 200*time.Millisecond || waited > 2*time.Second {
+		t.Errorf("waited %s, want about the 200ms allowed", waited)
+	}
+}
@@ -2,30 +2,112 @@ package monitor
 
 import (
 	"encoding/json"
+	"math"
+	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/angelfreak/ccd/daemon/types"
 )
 
-type Parser struct{}
+// Default characters-per-token ratios for the heuristic token estimate. Code
+// tokenizes more densely than prose, so it gets fewer characters per token.
+const (
+	DefaultProseCharsPerToken = 4.0
+	DefaultCodeCharsPerToken  = 3.0
+)
+
+type Parser struct {
+	proseCharsPerToken float64
+	codeCharsPerToken  float64
+}
 
 func NewParser() *Parser {
-	return &Parser{}
+	return NewParserWithTokenRatios(DefaultProseCharsPerToken, DefaultCodeCharsPerToken)
+}
+
+// NewParserWithTokenRatios creates a parser with custom characters-per-token
+// ratios for prose and fenced code. Non-positive values fall back to defaults.
+func NewParserWithTokenRatios(prose, code float64) *Parser {
+	if prose <= 0 {
+		prose = DefaultProseCharsPerToken
+	}
+	if code <= 0 {
+		code = DefaultCodeCharsPerToken
+	}
+	return &Parser{
+		proseCharsPerToken: prose,
+		codeCharsPerToken:  code,
+	}
+}
+
+// ParseResult is a parsed transcript with statistics for diagnosing logs in
+// a format the parser doesn't understand, which otherwise fall through to
+// the text parser and quietly yield little or nothing
+type ParseResult struct {
+	Conversation *types.Conversation
+	// Format is "json" or "text", whichever parsed the transcript
+	Format string
+	// Messages is how many messages were found
+	Messages int
+	// Lines counts a text transcript's non-blank lines, and SkippedLines
+	// those that were neither header metadata nor part of a message. Both
+	// are zero for JSON.
+	Lines        int
+	SkippedLines int
+	// Bytes is the size of the input consumed, and DroppedBytes how much
+	// of it was dropped as invalid UTF-8 or control characters
+	Bytes        int
+	DroppedBytes int
+}
+
+// Poor reports whether most of a text transcript was skipped, or it held
+// no messages at all
+func (r *ParseResult) Poor() bool {
+	return r.Format == "text" && r.Lines > 0 && (r.Messages == 0 || r.SkippedLines*2 > r.Lines)
 }
 
-func (p *Parser) Parse(data string) (*types.Conversation, error) {
+func (p *Parser) Parse(data string) (*ParseResult, error) {
 	var conv types.Conversation
+	result := &ParseResult{Format: "json", Bytes: len(data)}
+	data = sanitizeText(data)
+	result.DroppedBytes = result.Bytes - len(data)
 
 	// Try to parse as JSON first
 	if err := json.Unmarshal([]byte(data), &conv); err != nil {
 		// If JSON parsing fails, parse as plain text
-		conv = p.parseText(data)
+		conv = p.parseText(data, result)
+		result.Format = "text"
 	}
 
-	return &conv, nil
+	result.Conversation = &conv
+	result.Messages = len(conv.Messages)
+	return result, nil
+}
+
+// sanitizeText drops what can't be transcript text: invalid UTF-8, left by
+// partial writes or mixed encodings, and control characters
+func sanitizeText(data string) string {
+	if utf8.ValidString(data) && !strings.ContainsFunc(data, isControlNoise) {
+		return data
+	}
+	return strings.Map(func(r rune) rune {
+		if isControlNoise(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(data, ""))
 }
 
-func (p *Parser) parseText(data string) types.Conversation {
+// isControlNoise reports whether r is a control character other than the
+// whitespace a transcript uses
+func isControlNoise(r rune) bool {
+	return r < ' ' && r != '\n' && r != '\r' && r != '\t'
+}
+
+// parseText parses a plain text transcript, counting its lines in stats
+func (p *Parser) parseText(data string, stats *ParseResult) types.Conversation {
 	conv := types.Conversation{
 		Messages: []types.Message{},
 	}
@@ -39,23 +121,29 @@ func (p *Parser) parseText(data string) types.Conversation {
 		if line == "" {
 			continue
 		}
+		stats.Lines++
 
-		// Detect role markers
-		if strings.HasPrefix(line, "User:") || strings.HasPrefix(line, "user:") {
-			if currentMessage != nil {
-				conv.Messages = append(conv.Messages, *currentMessage)
-			}
-			currentMessage = &types.Message{
-				Role:    "user",
-				Content: strings.TrimPrefix(strings.TrimPrefix(line, "User:"), "user:"),
+		// "Key: value" lines before the first message are header metadata
+		if _, _, isRole := roleMarker(line); currentMessage == nil && !isRole {
+			if key, value, ok := strings.Cut(line, ":"); ok && isHeaderKey(key) {
+				if conv.Metadata == nil {
+					conv.Metadata = make(map[string]string)
+				}
+				conv.Metadata[strings.ToLower(strings.ReplaceAll(strings.TrimSpace(key), " ", "_"))] = strings.TrimSpace(value)
+			} else {
+				stats.SkippedLines++
 			}
-		} else if strings.HasPrefix(line, "Assistant:") || strings.HasPrefix(line, "assistant:") {
+			continue
+		}
+
+		// Detect role markers
+		if role, content, ok := roleMarker(line); ok {
 			if currentMessage != nil {
 				conv.Messages = append(conv.Messages, *currentMessage)
 			}
 			currentMessage = &types.Message{
-				Role:    "assistant",
-				Content: strings.TrimPrefix(strings.TrimPrefix(line, "Assistant:"), "assistant:"),
+				Role:    role,
+				Content: content,
 			}
 		} else if currentMessage != nil {
 			currentMessage.Content += "\n" + line
@@ -69,11 +157,67 @@ func (p *Parser) parseText(data string) types.Conversation {
 	return conv
 }
 
+// roleLine matches a role marker such as "User:", allowing the markdown
+// decoration transcripts exported from chat UIs add: blockquotes ("> User:"),
+// headings, and bold or italics around the role ("**Assistant:**",
+// "**User**:")
+var roleLine = regexp.MustCompile(`^(?:>\s*)*(?:#{1,6}\s+)?[*_]{0,3}(?i:(user|assistant))[*_]{0,3}\s*:[*_]{0,3}(.*)$`)
+
+// roleMarker reports whether a trimmed line starts a message, returning the
+// role and the rest of the line as the start of its content
+func roleMarker(line string) (role, content string, ok bool) {
+	m := roleLine.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	return strings.ToLower(m[1]), m[2], true
+}
+
+// isHeaderKey reports whether s looks like a header name: a few words of
+// letters, digits, spaces, underscores, or hyphens
+func isHeaderKey(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || len(s) > 32 {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *Parser) CountTokens(conv *types.Conversation) int {
-	// Simple token estimation: ~4 characters per token
-	total := 0
+	// Heuristic estimation: fenced code and prose use different ratios
+	total := 0.0
 	for _, msg := range conv.Messages {
-		total += len(msg.Content) / 4
+		prose, code := splitCodeAndProse(msg.Content)
+		total += float64(prose)/p.proseCharsPerToken + float64(code)/p.codeCharsPerToken
+	}
+	return int(total)
+}
+
+// TextTokens estimates the tokens in a piece of text, rounding up
+func (p *Parser) TextTokens(text string) int {
+	prose, code := splitCodeAndProse(text)
+	return int(math.Ceil(float64(prose)/p.proseCharsPerToken + float64(code)/p.codeCharsPerToken))
+}
+
+// splitCodeAndProse returns the number of characters outside and inside
+// fenced code blocks. An unterminated fence counts as code to the end.
+func splitCodeAndProse(content string) (prose, code int) {
+	inCode := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			code += len(line)
+		} else {
+			prose += len(line)
+		}
 	}
-	return total
+	return prose, code
 }
@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/angelfreak/ccd/daemon/types"
+)
+
+func TestCountTokensCodeVersusProse(t *testing.T) {
+	// 400 characters of prose and 300 of fenced code, newlines included
+	prose := strings.Repeat(strings.Repeat("a", 39)+"\n", 10)
+	code := "```go\n" + strings.Repeat(strings.Repeat("x", 29)+"\n", 10) + "```\n"
+
+	tests := []struct {
+		name    string
+		content string
+		parser  *Parser
+		want    int
+	}{
+		{"prose", prose, NewParser(), 100},
+		{"code", code, NewParser(), 100},
+		{"mixed", prose + code, NewParser(), 200},
+		{"tuned ratios", prose + code, NewParserWithTokenRatios(5, 2), 80 + 150},
+		{"invalid ratios use defaults", prose + code, NewParserWithTokenRatios(0, -1), 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := &types.Conversation{Messages: []types.Message{{Role: "assistant", Content: tt.content}}}
+			if got := tt.parser.CountTokens(conv); got != tt.want {
+				t.Errorf("CountTokens = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// Code counts more tokens than prose of the same length
+	codeOnly := "```\n" + prose + "```\n"
+	parser := NewParser()
+	if p, c := parser.TextTokens(prose), parser.TextTokens(codeOnly); c <= p {
+		t.Errorf("code estimate %d isn't above the prose estimate %d for the same text", c, p)
+	}
+}
+
+func TestSplitCodeAndProseUnterminatedFence(t *testing.T) {
+	prose, code := splitCodeAndProse("intro\n```\nfunc main() {}\n")
+	if prose != len("intro\n") || code != len("func main() {}\n") {
+		t.Errorf("splitCodeAndProse = %d prose, %d code; want an unterminated fence to run to the end", prose, code)
+	}
+}
+
+func TestParseTextDecoratedRoleMarkers(t *testing.T) {
+	transcript := strings.Join([]string{
+		"> User: which database?",
+		">   **Assistant:** We decided to use Postgres.",
+		"    It scales well.",
+		"**User**: and the cache?",
+		"## Assistant: Going with Redis.",
+		"_user_: thanks",
+		"> > *ASSISTANT*: You're welcome.",
+		"Username: not a role",
+	}, "\n")
+
+	result, err := NewParser().Parse(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.Message{
+		{Role: "user", Content: " which database?"},
+		{Role: "assistant", Content: " We decided to use Postgres.\nIt scales well."},
+		{Role: "user", Content: " and the cache?"},
+		{Role: "assistant", Content: " Going with Redis."},
+		{Role: "user", Content: " thanks"},
+		{Role: "assistant", Content: " You're welcome.\nUsername: not a role"},
+	}
+	got := result.Conversation.Messages
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Role != want[i].Role || got[i].Content != want[i].Content {
+			t.Errorf("message %d = %s %q, want %s %q", i, got[i].Role, got[i].Content, want[i].Role, want[i].Content)
+		}
+	}
+}
+
+func TestParseCountsSkippedLines(t *testing.T) {
+	transcript := strings.Join([]string{
+		"Model: claude-sonnet-4",
+		"<<<< binary junk >>>>",
+		"=====",
+		"",
+		"User: which database?",
+		"Assistant: We decided to use Postgres.",
+		"It scales well.",
+	}, "\n")
+
+	result, err := NewParser().Parse(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header is metadata and the continuation belongs to the reply;
+	// only the two lines of junk are skipped
+	if result.Format != "text" || result.Messages != 2 || result.Lines != 6 || result.SkippedLines != 2 {
+		t.Errorf("parsed as %s: %d messages, %d of %d lines skipped; want text, 2 messages, 2 of 6 skipped",
+			result.Format, result.Messages, result.SkippedLines, result.Lines)
+	}
+	if result.Bytes != len(transcript) || result.DroppedBytes != 0 {
+		t.Errorf("consumed %d bytes, dropped %d; want %d and 0", result.Bytes, result.DroppedBytes, len(transcript))
+	}
+	if result.Poor() {
+		t.Error("a transcript with a third of its lines skipped is reported as poorly parsed")
+	}
+
+	// A log that's mostly junk is poor
+	result, err = NewParser().Parse("garbage one\ngarbage two\n!!!\nUser: hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SkippedLines != 3 || !result.Poor() {
+		t.Errorf("mostly junk: %d skipped, poor %v; want 3 and poor", result.SkippedLines, result.Poor())
+	}
+
+	// JSON transcripts have no line statistics
+	result, err = NewParser().Parse(`{"messages":[{"role":"user","content":"hi"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Format != "json" || result.Messages != 1 || result.Lines != 0 || result.Poor() {
+		t.Errorf("JSON parsed as %+v, want one message and no line statistics", result)
+	}
+}
+
+func TestParseDropsInvalidUTF8(t *testing.T) {
+	transcript := "User: which db?\xff\xfe\nAssistant: We decided to use Post\xc3gres\x00 for sessions."
+
+	result, err := NewParser().Parse(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DroppedBytes != 4 || result.Bytes != len(transcript) {
+		t.Errorf("dropped %d of %d bytes, want 4 of %d", result.DroppedBytes, result.Bytes, len(transcript))
+	}
+	if len(result.Conversation.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(result.Conversation.Messages))
+	}
+	for _, message := range result.Conversation.Messages {
+		if !utf8.ValidString(message.Content) {
+			t.Errorf("message %q isn't valid UTF-8", message.Content)
+		}
+	}
+	if got, want := result.Conversation.Messages[1].Content, " We decided to use Postgres for sessions."; got != want {
+		t.Errorf("assistant message = %q, want %q", got, want)
+	}
+
+	// Valid text, including non-ASCII and tabs, is left alone
+	clean := "User: café\tau lait ☕"
+	if result, _ := NewParser().Parse(clean); result.DroppedBytes != 0 || result.Conversation.Messages[0].Content != " café\tau lait ☕" {
+		t.Errorf("clean transcript parsed as %+v", result.Conversation.Messages)
+	}
+}
+
+func TestLooksBinary(t *testing.T) {
+	tests := map[string]struct {
+		data []byte
+		want bool
+	}{
+		"text":                 {[]byte("User: hi\nAssistant: hello\n"), false},
+		"text with stray byte": {[]byte("User: hi\xff there, how are things going today?\n"), false},
+		"gzip header":          {[]byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 'a', 'b'}, true},
+		"nul padded":           {append([]byte("User: hi"), make([]byte, 64)...), true},
+		"empty":                {nil, false},
+	}
+	for name, tt := range tests {
+		if got := looksBinary(tt.data); got != tt.want {
+			t.Errorf("%s: looksBinary = %v, want %v", name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package monitor
+
+import (
+	"log"
+	"sync"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// publishBacklogWarning is how many facts may wait for the background
+// publisher before a warning is logged. The queue itself is unbounded.
+const publishBacklogWarning = 1000
+
+// factQueue holds facts for the background publisher. It grows as needed,
+// so adding a fact never blocks event processing, however far the rate
+// limit has fallen behind.
+type factQueue struct {
+	mu     sync.Mutex
+	facts  []extractor.Fact
+	closed bool
+	// ready is signalled when facts are added or the queue is closed
+	ready chan struct{}
+}
+
+func newFactQueue() *factQueue {
+	return &factQueue{ready: make(chan struct{}, 1)}
+}
+
+// push adds a fact, returning how many are now waiting. Facts pushed after
+// close are dropped.
+func (q *factQueue) push(fact extractor.Fact) int {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return 0
+	}
+	q.facts = append(q.facts, fact)
+	waiting := len(q.facts)
+	q.mu.Unlock()
+
+	q.signal()
+	return waiting
+}
+
+// pop waits for the oldest fact, reporting false once the queue is closed
+// and empty
+func (q *factQueue) pop() (extractor.Fact, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.facts) > 0 {
+			fact := q.facts[0]
+			q.facts[0] = extractor.Fact{}
+			q.facts = q.facts[1:]
+			q.mu.Unlock()
+			return fact, true
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return extractor.Fact{}, false
+		}
+		q.mu.Unlock()
+
+		<-q.ready
+	}
+}
+
+// close stops accepting facts; those already queued are still popped
+func (q *factQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	q.signal()
+}
+
+// signal wakes a waiting pop without blocking when one is already pending
+func (q *factQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// postFact sends a fact to PocketBase, in the background when async
+// publishing or rate limiting is enabled. Facts past the per-session limit
+// are dropped.
+func (w *Watcher) postFact(fact extractor.Fact) {
+	if w.factsPerSession > 0 {
+		if w.sessionFacts >= w.factsPerSession {
+			return
+		}
+		w.sessionFacts++
+		if w.sessionFacts == w.factsPerSession {
+			w.mu.Lock()
+			sessionID := w.sessionID
+			w.mu.Unlock()
+			log.Printf("Warning: session %s reached %d facts; no more will be posted this session",
+				sessionID, w.factsPerSession)
+		}
+	}
+
+	if w.publishQueue != nil {
+		if w.publishQueue.push(fact) == publishBacklogWarning {
+			log.Printf("Warning: %d facts are waiting to be posted; PocketBase or -facts-per-minute is holding them up",
+				publishBacklogWarning)
+		}
+		return
+	}
+	w.createFact(fact)
+}
+
+// createFact posts a fact synchronously and notifies listeners on success.
+// A fact on the same topic as one posted earlier this session is appended
+// to that one instead.
+func (w *Watcher) createFact(fact extractor.Fact) {
+	if w.topics != nil && w.enrichFact(fact) {
+		return
+	}
+
+	id, err := w.client.CreateFact(w.projectID, fact)
+	if err != nil {
+		log.Printf("Failed to create fact: %v", err)
+		return
+	}
+	if w.topics != nil {
+		w.mu.Lock()
+		w.topics.Track(smart.TopicFact{ID: id, Type: fact.Type, Content: fact.Content, Importance: fact.Importance})
+		w.mu.Unlock()
+	}
+
+	w.notifyFact(fact)
+	if w.verbose {
+		log.Printf("Created fact (importance: %d): %s (%s)", fact.Importance, fact.Content, fact.Type)
+	}
+}
+
+// enrichFact adds fact's detail to the earlier fact on its topic, reporting
+// whether it did. If the update fails the fact is posted on its own.
+func (w *Watcher) enrichFact(fact extractor.Fact) bool {
+	w.mu.Lock()
+	update, ok := w.topics.Enrich(fact.Type, fact.Content, fact.Importance)
+	w.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if err := w.client.UpdateFactContent(update.ID, update.Content, update.Importance); err != nil {
+		log.Printf("Failed to update fact %s: %v", update.ID, err)
+		return false
+	}
+
+	w.mu.Lock()
+	w.topics.Track(update)
+	w.mu.Unlock()
+	if w.explain {
+		log.Printf("Explain: %s fact %q added to earlier fact %s on the same topic", fact.Type, fact.Content, update.ID)
+	}
+	if w.verbose {
+		log.Printf("Updated fact (importance: %d): %s (%s)", update.Importance, update.Content, update.Type)
+	}
+	return true
+}
+
+// publishFacts posts queued facts, as fast as the rate limit allows, until
+// the queue is closed and empty
+func (w *Watcher) publishFacts() {
+	defer close(w.publishDone)
+	for {
+		fact, ok := w.publishQueue.pop()
+		if !ok {
+			return
+		}
+		if w.rateLimiter != nil {
+			w.rateLimiter.Wait()
+		}
+		w.createFact(fact)
+	}
+}
+
+// drainPublisher stops accepting facts and waits for queued ones to post.
+// Pacing is lifted so shutdown doesn't wait on the rate limit.
+func (w *Watcher) drainPublisher() {
+	if w.publishQueue == nil {
+		return
+	}
+	if w.rateLimiter != nil {
+		w.rateLimiter.Close()
+	}
+	w.publishQueue.close()
+	<-w.publishDone
+}
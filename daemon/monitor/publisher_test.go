@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+)
+
+func TestAsyncPublishDoesNotWaitOnSlowAPI(t *testing.T) {
+	const postDelay = 200 * time.Millisecond
+	w, pb := newTestWatcher(t, WatcherConfig{SmartMode: true, AsyncPublish: true})
+	pb.delay = postDelay
+
+	lines := []string{"User: plan?"}
+	var want []string
+	for i := 1; i <= 4; i++ {
+		lines = append(lines, fmt.Sprintf("Assistant: We decided to use option %d.", i))
+		want = append(want, fmt.Sprintf("We decided to use option %d", i))
+	}
+
+	start := time.Now()
+	w.processLogFile(writeLog(t, w.logPath, "session.log", lines...))
+	settle(w)
+	if elapsed := time.Since(start); elapsed >= 2*postDelay {
+		t.Errorf("parsing and processing took %s, waiting on posts that take %s each", elapsed, postDelay)
+	}
+
+	// Stop waits for every queued fact to post
+	w.Stop()
+	if got := pb.postedContents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("posted facts = %q, want %q", got, want)
+	}
+}
+
+func TestFactQueueDrainsAfterClose(t *testing.T) {
+	q := newFactQueue()
+	for i := 1; i <= 3; i++ {
+		if waiting := q.push(extractor.Fact{Content: fmt.Sprint(i)}); waiting != i {
+			t.Errorf("push %d: %d waiting, want %d", i, waiting, i)
+		}
+	}
+	q.close()
+	if waiting := q.push(extractor.Fact{Content: "late"}); waiting != 0 {
+		t.Errorf("push after close: %d waiting, want the fact dropped", waiting)
+	}
+
+	var got []string
+	for {
+		fact, ok := q.pop()
+		if !ok {
+			break
+		}
+		got = append(got, fact.Content)
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("popped %v, want %v", got, want)
+	}
+}
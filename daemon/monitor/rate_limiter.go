@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that paces fact creation so busy sessions
+// don't flood PocketBase. The bucket holds up to a minute's worth of tokens,
+// so short bursts go through immediately.
+type RateLimiter struct {
+	mu       sync.Mutex
+	tokens   float64
+	capacity float64
+	perSec   float64
+	last     time.Time
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewRateLimiter(factsPerMinute int) *RateLimiter {
+	capacity := float64(factsPerMinute)
+	return &RateLimiter{
+		tokens:   capacity,
+		capacity: capacity,
+		perSec:   capacity / 60,
+		last:     time.Now(),
+		closed:   make(chan struct{}),
+	}
+}
+
+// Wait blocks until a token is available. Once the limiter is closed it
+// returns immediately so shutdown isn't held up by pacing.
+func (r *RateLimiter) Wait() {
+	for {
+		r.mu.Lock()
+		r.refill(time.Now())
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return
+		}
+		wait := time.Duration((1 - r.tokens) / r.perSec * float64(time.Second))
+		r.mu.Unlock()
+
+		select {
+		case <-time.After(wait):
+		case <-r.closed:
+			return
+		}
+	}
+}
+
+// Close releases any waiters and disables pacing
+func (r *RateLimiter) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+}
+
+// refill adds the tokens accrued since the last refill. Callers must hold mu.
+func (r *RateLimiter) refill(now time.Time) {
+	r.tokens += now.Sub(r.last).Seconds() * r.perSec
+	if r.tokens > r.capacity {
+		r.tokens = r.capacity
+	}
+	r.last = now
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/api"
+)
+
+// retentionSweepInterval is how often expired facts are purged
+const retentionSweepInterval = time.Hour
+
+// sweepExpiredFacts deletes stale facts older than the retention period.
+// Pinned facts are always kept.
+func (w *Watcher) sweepExpiredFacts() {
+	cutoff := time.Now().Add(-w.factRetention)
+	fields := w.client.FactFields()
+	filter := fmt.Sprintf("%s = true && %s != true && %s < %q",
+		fields.Stale, fields.Pinned, fields.Created, api.FormatTime(cutoff))
+
+	facts, err := w.client.ListFacts(w.projectID, filter)
+	if err != nil {
+		log.Printf("Failed to list expired facts: %v", err)
+		return
+	}
+
+	deleted := 0
+	for _, fact := range facts {
+		// Re-check locally in case the server ignored part of the filter
+		if !fact.Stale || fact.Pinned {
+			continue
+		}
+		if created, err := fact.CreatedAt(); err != nil || created.After(cutoff) {
+			continue
+		}
+
+		if err := w.client.DeleteFact(fact.ID); err != nil {
+			log.Printf("Failed to delete expired fact %s: %v", fact.ID, err)
+			continue
+		}
+		deleted++
+	}
+
+	if deleted > 0 || w.verbose {
+		log.Printf("Fact retention: deleted %d stale facts older than %s", deleted, cutoff.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/api"
+)
+
+func TestSweepExpiredFactsDeletesOnlyOldStaleUnpinned(t *testing.T) {
+	w, pb := newTestWatcher(t, WatcherConfig{FactRetention: 90 * 24 * time.Hour})
+	defer w.Stop()
+
+	old := api.FormatTime(time.Now().Add(-100 * 24 * time.Hour))
+	recent := api.FormatTime(time.Now().Add(-10 * 24 * time.Hour))
+	pb.store(
+		map[string]interface{}{"id": "old-stale", "stale": true, "pinned": false, "created": old},
+		map[string]interface{}{"id": "old-stale-pinned", "stale": true, "pinned": true, "created": old},
+		map[string]interface{}{"id": "old-fresh", "stale": false, "pinned": false, "created": old},
+		map[string]interface{}{"id": "recent-stale", "stale": true, "pinned": false, "created": recent},
+	)
+
+	w.sweepExpiredFacts()
+
+	if got, want := pb.deletedFacts(), []string{"old-stale"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deleted %v, want %v", got, want)
+	}
+	filters := pb.listFilters()
+	if len(filters) != 1 {
+		t.Fatalf("got %d list requests, want 1", len(filters))
+	}
+	for _, want := range []string{`project = "proj1"`, "stale = true", "pinned != true", "created < "} {
+		if !strings.Contains(filters[0], want) {
+			t.Errorf("filter %q lacks %q", filters[0], want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package monitor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/angelfreak/ccd/daemon/types"
+)
+
+// rotationSuffix matches the suffix log rotation adds: .1, .gz, or .1.gz
+var rotationSuffix = regexp.MustCompile(`(\.\d+)?(\.gz)?$`)
+
+// logPrefixLen is how much of the start of a log identifies its content
+const logPrefixLen = 4096
+
+// logProgress records how much of a log's content has been extracted. Logs
+// are keyed by content identity rather than path, so a rotated or compressed
+// copy of a log isn't extracted again under its new name.
+type logProgress struct {
+	messages int
+	// lastHash identifies the last extracted message, which may still have
+	// been growing when it was read
+	lastHash string
+}
+
+// readLogFile reads a log, decompressing gzipped rotations
+func readLogFile(path string) ([]byte, error) {
+	if !strings.HasSuffix(path, ".gz") {
+		return os.ReadFile(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// binarySniffLen is how much of the start of a log looksBinary checks
+const binarySniffLen = 8000
+
+// looksBinary reports whether a log is obviously not a transcript: more than
+// 30% of its start is invalid UTF-8 or control characters. The odd stray
+// byte from a partial write or a mixed encoding doesn't count; the parser
+// drops those.
+func looksBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+
+	runes, noise := 0, 0
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if (r == utf8.RuneError && size == 1) || isControlNoise(r) {
+			noise++
+		}
+		runes++
+		data = data[size:]
+	}
+	return noise*10 > runes*3
+}
+
+// logContent is one log's content as last seen, under whichever name
+type logContent struct {
+	id string
+	// file is the file it was last read from, compared with os.SameFile so
+	// a renamed log is recognised. Nil once the file holds other content.
+	file os.FileInfo
+	// prefix holds the first logPrefixLen bytes, or all of a shorter log
+	prefix []byte
+	size   int
+}
+
+// continuedBy reports whether data is this content, possibly appended to
+func (c *logContent) continuedBy(data []byte) bool {
+	return len(data) >= c.size && bytes.HasPrefix(data, c.prefix)
+}
+
+// copiedTo reports whether data, from another file, is a copy of this
+// content, as log rotation makes when it compresses a log. A short log's
+// copy must match it exactly, so that a new log which merely starts the
+// same way isn't mistaken for it.
+func (c *logContent) copiedTo(data []byte) bool {
+	if len(c.prefix) < logPrefixLen {
+		return bytes.Equal(data, c.prefix)
+	}
+	return c.continuedBy(data)
+}
+
+// update records data as the content's latest state
+func (c *logContent) update(data []byte) {
+	if len(c.prefix) < logPrefixLen {
+		c.prefix = append([]byte(nil), data[:min(len(data), logPrefixLen)]...)
+	}
+	c.size = len(data)
+}
+
+// logIdentity identifies a log's content, which keeps its identity as the
+// log is appended to, renamed, or compressed. A file already seen is the
+// same log while its earlier content is unchanged; another file is the
+// same log only if it holds a copy of it. info is the file's stat, or nil
+// when unavailable. Only the watch loop calls it.
+func (w *Watcher) logIdentity(info os.FileInfo, data []byte) string {
+	for _, content := range w.logContents {
+		if content.file == nil || info == nil || !os.SameFile(content.file, info) {
+			continue
+		}
+		if content.continuedBy(data) {
+			content.file = info
+			content.update(data)
+			return content.id
+		}
+		// Rewritten in place: whatever it held before is gone
+		content.file = nil
+		break
+	}
+
+	for _, content := range w.logContents {
+		if content.copiedTo(data) {
+			content.update(data)
+			return content.id
+		}
+	}
+
+	content := &logContent{id: strconv.Itoa(len(w.logContents)), file: info}
+	content.update(data)
+	w.logContents = append(w.logContents, content)
+	return content.id
+}
+
+func messageHash(msg types.Message) string {
+	sum := sha256.Sum256([]byte(msg.Role + "\x00" + msg.Content))
+	return hex.EncodeToString(sum[:])
+}
+
+// unprocessedMessages returns the messages of the log identified by id that
+// haven't been extracted yet and records them as extracted. Only the watch
+// loop calls it.
+func (w *Watcher) unprocessedMessages(id string, messages []types.Message) []types.Message {
+	start := 0
+	if progress, ok := w.logProgress[id]; ok && progress.messages <= len(messages) {
+		start = progress.messages
+		// A last message that has grown since is extracted again
+		if start > 0 && messageHash(messages[start-1]) != progress.lastHash {
+			start--
+		}
+	}
+
+	if len(messages) > 0 {
+		w.logProgress[id] = logProgress{
+			messages: len(messages),
+			lastHash: messageHash(messages[len(messages)-1]),
+		}
+	}
+	return messages[start:]
+}
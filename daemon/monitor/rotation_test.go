@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRotatedLogNotReprocessed(t *testing.T) {
+	w, pb := newTestWatcher(t, WatcherConfig{})
+	defer w.Stop()
+
+	path := writeLog(t, w.logPath, "session.log", "User: which database?", "Assistant: We decided to use Postgres.")
+	w.processLogFile(path)
+
+	// Rotation renames the log and starts a fresh one under the old name
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	w.processLogFile(rotated)
+	w.processLogFile(writeLog(t, w.logPath, "session.log", "User: and the cache?", "Assistant: Going with Redis for now."))
+
+	// then compresses the rotated copy
+	data, err := os.ReadFile(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(rotated + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	gz.Write(data)
+	gz.Close()
+	file.Close()
+	os.Remove(rotated)
+	w.processLogFile(rotated + ".gz")
+	settle(w)
+
+	want := []string{"We decided to use Postgres", "Going with Redis for now"}
+	if got := pb.postedContents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("posted facts = %q, want each once: %q", got, want)
+	}
+}
+
+func TestLogIdentity(t *testing.T) {
+	w, _ := newTestWatcher(t, WatcherConfig{})
+	defer w.Stop()
+
+	stat := func(path string) os.FileInfo {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+
+	path := filepath.Join(w.logPath, "session.log")
+	os.WriteFile(path, []byte("User: hi\n"), 0644)
+	first := w.logIdentity(stat(path), []byte("User: hi\n"))
+
+	// Appending keeps the identity
+	os.WriteFile(path, []byte("User: hi\nAssistant: hello\n"), 0644)
+	if id := w.logIdentity(stat(path), []byte("User: hi\nAssistant: hello\n")); id != first {
+		t.Errorf("appended log has identity %s, want %s", id, first)
+	}
+
+	// A new log that merely starts the same way is different content
+	other := filepath.Join(w.logPath, "other.log")
+	os.WriteFile(other, []byte("User: hi\nAssistant: bye\n"), 0644)
+	if id := w.logIdentity(stat(other), []byte("User: hi\nAssistant: bye\n")); id == first {
+		t.Errorf("different log shares identity %s", id)
+	}
+
+	// Rewriting the log in place gives it a new identity
+	os.WriteFile(path, []byte("User: new\n"), 0644)
+	if id := w.logIdentity(stat(path), []byte("User: new\n")); id == first {
+		t.Errorf("rewritten log kept identity %s", id)
+	}
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// recordSnapshot folds one processing pass into the current session snapshot.
+// Facts are deduplicated by type and normalized content, keeping the highest importance
+// seen, so the snapshot reflects the session as a whole rather than the last
+// pass.
+func (w *Watcher) recordSnapshot(facts []extractor.Fact, tokenCount int) {
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.snapshotFacts == nil {
+		w.snapshotFacts = make(map[string]int)
+	}
+
+	for _, fact := range facts {
+		key := smart.FactKey(fact.Type, fact.Content)
+		if i, ok := w.snapshotFacts[key]; ok {
+			kept := &w.snapshot.Facts[i]
+			kept.Importance = max(kept.Importance, fact.Importance)
+			kept.Score = max(kept.Score, fact.Score)
+			continue
+		}
+
+		w.snapshotFacts[key] = len(w.snapshot.Facts)
+		w.snapshot.Facts = append(w.snapshot.Facts, smart.CompressibleFact{
+			Type:       fact.Type,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+			Score:      fact.Score,
+			Created:    now,
+		})
+		if fact.Type == "file_change" {
+			w.snapshot.FileChanges = append(w.snapshot.FileChanges, fact.Content)
+		}
+	}
+
+	w.snapshot.SessionID = w.sessionID
+	w.snapshot.Timestamp = now
+	w.snapshot.TokenCount = tokenCount
+}
+
+// Snapshot returns a copy of the current session's cumulative state: every
+// fact seen so far, the latest token count, and the files changed
+func (w *Watcher) Snapshot() smart.SessionSnapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	snapshot := w.snapshot
+	snapshot.SessionID = w.sessionID
+	snapshot.Facts = append([]smart.CompressibleFact(nil), w.snapshot.Facts...)
+	snapshot.FileChanges = append([]string(nil), w.snapshot.FileChanges...)
+	return snapshot
+}
@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+)
+
+func TestSnapshotIsCumulative(t *testing.T) {
+	w, _ := newTestWatcher(t, WatcherConfig{})
+	defer w.Stop()
+
+	w.recordSnapshot([]extractor.Fact{
+		{Type: "decision", Content: "Use Postgres", Importance: 3},
+		{Type: "file_change", Content: "Created server.go", Importance: 2},
+	}, 1000)
+	w.recordSnapshot([]extractor.Fact{
+		{Type: "decision", Content: "use postgres.", Importance: 4},
+		{Type: "blocker", Content: "CI is red", Importance: 5},
+	}, 1800)
+
+	snapshot := w.Snapshot()
+	if snapshot.SessionID != w.sessionID {
+		t.Errorf("SessionID = %q, want %q", snapshot.SessionID, w.sessionID)
+	}
+	if snapshot.TokenCount != 1800 {
+		t.Errorf("TokenCount = %d, want the latest pass's 1800", snapshot.TokenCount)
+	}
+	var got []string
+	for _, fact := range snapshot.Facts {
+		got = append(got, fact.Type+": "+fact.Content)
+	}
+	want := []string{"decision: Use Postgres", "file_change: Created server.go", "blocker: CI is red"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("facts = %q, want %q", got, want)
+	}
+	if snapshot.Facts[0].Importance != 4 {
+		t.Errorf("repeated decision has importance %d, want the highest seen, 4", snapshot.Facts[0].Importance)
+	}
+	if want := []string{"Created server.go"}; !reflect.DeepEqual(snapshot.FileChanges, want) {
+		t.Errorf("FileChanges = %v, want %v", snapshot.FileChanges, want)
+	}
+
+	// The returned snapshot is a copy
+	snapshot.Facts[0].Content = "changed"
+	if w.Snapshot().Facts[0].Content != "Use Postgres" {
+		t.Error("changing a returned snapshot changed the watcher's")
+	}
+
+	// A new session starts from nothing
+	w.resetSessionState()
+	if facts := w.Snapshot().Facts; len(facts) != 0 {
+		t.Errorf("after a new session the snapshot has %d facts, want 0", len(facts))
+	}
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// staleSweepInterval is how often the trained stale model reviews facts
+const staleSweepInterval = time.Hour
+
+// adaptiveStale reports whether a trained stale model is in use
+func (w *Watcher) adaptiveStale() bool {
+	return w.staleDetector != nil && w.staleDetector.Model() != nil
+}
+
+// sweepStaleFacts marks the facts the stale model judges outdated. Pinned
+// and verified facts and facts a user has labeled are left alone.
+func (w *Watcher) sweepStaleFacts() {
+	fields := w.client.FactFields()
+	filter := fmt.Sprintf("%s = false && %s != true && %s != true", fields.Stale, fields.Pinned, fields.Verified)
+	facts, err := w.client.ListFacts(w.projectID, filter)
+	if err != nil {
+		log.Printf("Failed to list facts for stale detection: %v", err)
+		return
+	}
+
+	labeled := make(map[string]bool)
+	if path, err := smart.DefaultStaleLabelsPath(); err == nil {
+		labels, err := smart.LoadStaleLabels(path)
+		if err != nil {
+			log.Printf("Failed to read stale labels: %v", err)
+		}
+		for _, label := range labels {
+			labeled[label.FactID] = true
+		}
+	}
+
+	marked := 0
+	for _, fact := range facts {
+		if labeled[fact.ID] {
+			continue
+		}
+		created, err := fact.CreatedAt()
+		if err != nil {
+			continue
+		}
+
+		if !w.staleDetector.IsStaleFact(smart.CompressibleFact{
+			Type:       fact.FactType,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+			Created:    created,
+		}) {
+			continue
+		}
+
+		if err := w.client.UpdateFactStale(fact.ID, true); err != nil {
+			log.Printf("Failed to mark fact %s stale: %v", fact.ID, err)
+			continue
+		}
+		marked++
+	}
+
+	if marked > 0 || w.verbose {
+		log.Printf("Stale model: marked %d of %d facts stale", marked, len(facts))
+	}
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// topFactCount is how many facts are exposed to summary templates as TopFacts
+const topFactCount = 5
+
+// SummaryData is the data available to a custom handoff summary template
+type SummaryData struct {
+	SessionID       string
+	ProjectID       string
+	Timestamp       time.Time
+	TokenCount      int
+	FactCount       int
+	DecisionCount   int
+	BlockerCount    int
+	TodoCount       int
+	FileChangeCount int
+	Decisions       []string
+	Blockers        []string
+	NextSteps       []string
+	FileChanges     []string
+	// TopFacts holds the highest-importance facts, most important first
+	TopFacts []ledger.Fact
+	// Summary is the built-in summary, from smart.SummarizeSession
+	Summary string
+}
+
+// ParseSummaryTemplate parses a text/template for handoff summaries and
+// validates it by rendering against sample data, so mistakes surface at
+// startup rather than at the first handoff
+func ParseSummaryTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("summary").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &ledger.LedgerEntry{
+		Timestamp:  time.Now(),
+		SessionID:  "sample",
+		TokenCount: 1000,
+		Facts: []ledger.Fact{
+			{Type: "decision", Content: "Use PocketBase", Importance: 4},
+			{Type: "blocker", Content: "Missing credentials", Importance: 5},
+		},
+		Decisions: []string{"Use PocketBase"},
+		Blockers:  []string{"Missing credentials"},
+	}
+	if err := tmpl.Execute(&strings.Builder{}, newSummaryData(sample)); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// RenderSummary renders the handoff summary for entry with tmpl, falling back
+// to the built-in summary of its top facts when tmpl is nil or fails
+func RenderSummary(tmpl *template.Template, entry *ledger.LedgerEntry) string {
+	if tmpl == nil {
+		return smart.SummarizeSession(entry)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, newSummaryData(entry)); err != nil {
+		log.Printf("Summary template failed, using built-in summary: %v", err)
+		return smart.SummarizeSession(entry)
+	}
+	return strings.TrimSpace(out.String())
+}
+
+func newSummaryData(entry *ledger.LedgerEntry) SummaryData {
+	top := make([]ledger.Fact, len(entry.Facts))
+	copy(top, entry.Facts)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Rank() > top[j].Rank()
+	})
+	if len(top) > topFactCount {
+		top = top[:topFactCount]
+	}
+
+	return SummaryData{
+		SessionID:       entry.SessionID,
+		ProjectID:       entry.ProjectID,
+		Timestamp:       entry.Timestamp,
+		TokenCount:      entry.TokenCount,
+		FactCount:       len(entry.Facts),
+		DecisionCount:   len(entry.Decisions),
+		BlockerCount:    len(entry.Blockers),
+		TodoCount:       len(entry.NextSteps),
+		FileChangeCount: len(entry.FileChanges),
+		Decisions:       entry.Decisions,
+		Blockers:        entry.Blockers,
+		NextSteps:       entry.NextSteps,
+		FileChanges:     entry.FileChanges,
+		TopFacts:        top,
+		Summary:         smart.SummarizeSession(entry),
+	}
+}
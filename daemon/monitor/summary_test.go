@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+func sampleSummaryEntry() *ledger.LedgerEntry {
+	return &ledger.LedgerEntry{
+		Timestamp:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		SessionID:  "s1",
+		ProjectID:  "proj1",
+		TokenCount: 5400,
+		Facts: []ledger.Fact{
+			{Type: "todo", Content: "Add migrations", Importance: 3},
+			{Type: "blocker", Content: "CI is red", Importance: 5},
+			{Type: "decision", Content: "Use Postgres", Importance: 4},
+		},
+		Decisions: []string{"Use Postgres"},
+		Blockers:  []string{"CI is red"},
+		NextSteps: []string{"Add migrations"},
+	}
+}
+
+func TestRenderSummaryTemplate(t *testing.T) {
+	tmpl, err := ParseSummaryTemplate(`{{.SessionID}}: {{.FactCount}} facts, {{.BlockerCount}} blocker(s).
+Top: {{range $i, $f := .TopFacts}}{{if $i}}; {{end}}{{$f.Content}}{{end}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := RenderSummary(tmpl, sampleSummaryEntry())
+	if want := "s1: 3 facts, 1 blocker(s).\nTop: CI is red; Use Postgres; Add migrations"; got != want {
+		t.Errorf("RenderSummary = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSummaryFallsBackToBuiltIn(t *testing.T) {
+	entry := sampleSummaryEntry()
+	if got, want := RenderSummary(nil, entry), smart.SummarizeSession(entry); got != want {
+		t.Errorf("RenderSummary without a template = %q, want the built-in %q", got, want)
+	}
+}
+
+func TestParseSummaryTemplateValidates(t *testing.T) {
+	for _, text := range []string{
+		"{{.SessionID",
+		"{{.NoSuchField}}",
+		"{{index .Decisions 5}}",
+	} {
+		if _, err := ParseSummaryTemplate(text); err == nil {
+			t.Errorf("ParseSummaryTemplate(%q) accepted an invalid template", text)
+		}
+	}
+}
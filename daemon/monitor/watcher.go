@@ -1,15 +1,26 @@
 package monitor
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/angelfreak/ccd/daemon/api"
+	"github.com/angelfreak/ccd/daemon/detect"
+	"github.com/angelfreak/ccd/daemon/embed"
+	"github.com/angelfreak/ccd/daemon/events"
 	"github.com/angelfreak/ccd/daemon/extractor"
 	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/angelfreak/ccd/daemon/redact"
 	"github.com/angelfreak/ccd/daemon/smart"
+	"github.com/angelfreak/ccd/daemon/types"
 	"github.com/fsnotify/fsnotify"
 )
 
@@ -21,8 +32,126 @@ type WatcherConfig struct {
 	Verbose          bool
 	SmartMode        bool
 	CompactThreshold int
+	// RecordSourceFiles keeps track of which log files contributed to each
+	// handoff so they can be listed in the handoff and session record.
+	RecordSourceFiles bool
+	// RecordSessions also records each handoff as a session in PocketBase.
+	// Under NoLedger handoffs are always recorded, having nowhere else to go.
+	RecordSessions bool
+	// IdleHandoffAfter creates a handoff once no log writes have been seen
+	// for this long. Zero disables idle detection.
+	IdleHandoffAfter time.Duration
+	// SessionGap starts a new session, with a new ID, on the first activity
+	// after this long without any. The old session gets a closing handoff
+	// if idle detection hasn't already written one. Zero disables it.
+	SessionGap time.Duration
+	// Redactor masks sensitive content before facts and handoffs are
+	// persisted. Nil disables redaction.
+	Redactor *redact.Redactor
+	// Events is notified as facts and handoffs are persisted. Optional.
+	Events EventSink
+	// FactRetention deletes stale, unpinned facts older than this. Zero
+	// keeps facts forever.
+	FactRetention time.Duration
+	// EventQueueSize bounds how many parsed passes may wait for processing
+	// before new ones are dropped. Zero uses events.DefaultQueueSize.
+	EventQueueSize int
+	// ProseCharsPerToken and CodeCharsPerToken tune the token estimate for
+	// prose and fenced code. Zero uses the parser defaults.
+	ProseCharsPerToken float64
+	CodeCharsPerToken  float64
+	// SummaryTemplate renders handoff summaries. Nil uses the built-in summary.
+	SummaryTemplate *template.Template
+	// AsyncPublish posts facts from a background goroutine so ledger
+	// updates don't wait on PocketBase
+	AsyncPublish bool
+	// NoEmoji renders plain-text markers in handoffs
+	NoEmoji bool
+	// FactsPerMinute paces fact creation. Facts over the rate wait in the
+	// background publish queue. Zero disables pacing.
+	FactsPerMinute int
+	// FactsPerSession stops posting facts once a session has created this
+	// many. Zero means no limit.
+	FactsPerSession int
+	// Embedder computes embedding vectors for facts that lack one. Nil
+	// disables embedding.
+	Embedder *embed.Client
+	// StaleModel, in smart mode, replaces the per-type stale thresholds and
+	// periodically marks facts it judges stale. Nil keeps the thresholds.
+	StaleModel *smart.StaleModel
+	// ImportanceFloors override the lowest importance each fact type can
+	// score. A zero floor removes the type's default.
+	ImportanceFloors map[string]int
+	// LedgerMinImportance, in smart mode, is the lowest importance a fact of
+	// each type needs to be written to the ledger and so appear in
+	// handoffs. Facts below it are still posted. Types without a minimum
+	// are always written.
+	LedgerMinImportance map[string]int
+	// FactRateWarning logs a warning when more than this many facts are
+	// extracted in a minute. Zero disables the check.
+	FactRateWarning int
+	// IncludeGlobs select which files in the logs directory are processed,
+	// matched against the file name. Empty uses DefaultIncludeGlobs.
+	IncludeGlobs []string
+	// ExcludeGlobs skip files otherwise selected by IncludeGlobs
+	ExcludeGlobs []string
+	// Explain logs why each fact was created: the extraction rule and
+	// keyword, and in smart mode the importance score's breakdown
+	Explain bool
+	// LedgerBatchSize, in smart mode, writes ledger entries in batches of
+	// this size instead of one at a time. Zero or one disables batching.
+	LedgerBatchSize int
+	// LedgerFlushInterval writes a partial ledger batch at least this often
+	LedgerFlushInterval time.Duration
+	// NoLedger, in smart mode, writes nothing under thoughts/: each pass's
+	// entry is kept in memory instead of the ledger, and handoffs are only
+	// recorded as sessions in PocketBase
+	NoLedger bool
+	// MaxFactsPerPass keeps only this many facts from each processing pass,
+	// the most important after scoring. Zero keeps them all.
+	MaxFactsPerPass int
+	// ExtractionRules replace the built-in extraction rules when set
+	ExtractionRules []extractor.Rule
+	// FactDetailCap lets a fact on the same topic as one posted earlier in
+	// the session be appended to it, rather than posted separately, while
+	// the combined content stays within this many bytes. Zero disables it.
+	FactDetailCap int
+	// CarryForwardTokens, in smart mode, adds a Carry Forward section to
+	// handoffs created near the compact threshold: the most valuable facts
+	// that fit in this many tokens. Zero disables it.
+	CarryForwardTokens int
+	// TopImportanceFraction, in smart mode, limits importance 5 in handoffs
+	// to this fraction of their facts. Zero leaves importances as scored.
+	TopImportanceFraction float64
+	// HandoffFactLength and HandoffFactLengths, in smart mode, clip long
+	// facts in handoff lines, overall and per type. Zero leaves them whole.
+	HandoffFactLength  int
+	HandoffFactLengths map[string]int
+	// LogPathWait makes Start wait up to this long for a missing log
+	// directory to be created, retrying with backoff. Negative waits
+	// indefinitely; zero fails at once.
+	LogPathWait time.Duration
 }
 
+// EventSink receives notifications about facts and handoffs as they are written
+type EventSink interface {
+	FactCreated(projectID string, fact extractor.Fact)
+	HandoffCreated(sessionID, summary string)
+}
+
+// Backoff bounds for waiting on a log directory that doesn't exist yet
+const (
+	logWaitInitialDelay = time.Second
+	logWaitMaxDelay     = 30 * time.Second
+)
+
+// carryForwardPerType caps the facts of one type recommended for the next
+// session, so one type can't crowd out the rest of the budget
+const carryForwardPerType = 10
+
+// sessionEndMarkers are transcript lines that signal the user ended the session
+var sessionEndMarkers = []string{"/exit", "/quit", "[session ended]"}
+
 type Watcher struct {
 	logPath          string
 	projectID        string
@@ -36,9 +165,91 @@ type Watcher struct {
 	importanceScorer *smart.ImportanceScorer
 	staleDetector    *smart.StaleDetector
 	compactDetector  *smart.PreCompactDetector
+	blockers         *smart.BlockerTracker
 	currentTokens    int
 	sessionID        string
 	lastHandoff      time.Time
+	recordSources    bool
+	recordSessions   bool
+	sourceFiles      map[string]bool
+	idleHandoffAfter time.Duration
+	sessionGap       time.Duration
+	lastActivity     time.Time
+	pendingActivity  bool
+	endMarkersSeen   map[string]int
+	redactor         *redact.Redactor
+	events           EventSink
+	factRetention    time.Duration
+	bus              *events.Bus
+	summaryTemplate  *template.Template
+	// outputDirs are the daemon's own ledger/handoff directories, which
+	// must never be parsed as transcripts
+	outputDirs []string
+	// publishQueue feeds the background fact publisher when AsyncPublish
+	// is enabled
+	publishQueue *factQueue
+	publishDone  chan struct{}
+	rateLimiter  *RateLimiter
+	// sessionFacts counts facts posted this session against factsPerSession.
+	// Only the event processor touches it.
+	factsPerSession int
+	sessionFacts    int
+	embedder        *embed.Client
+	// factRate watches for bursts of extracted facts. Only the event
+	// processor touches it.
+	factRate *FactRateMonitor
+	// includeGlobs and excludeGlobs select the log files to process
+	includeGlobs []string
+	excludeGlobs []string
+	explain      bool
+	// ledgerMinImportance filters which facts of each type are ledgered
+	ledgerMinImportance map[string]int
+	// latestEntry stands in for the ledger under NoLedger, holding the last
+	// pass's entry. handoffMu guards it.
+	latestEntry *ledger.LedgerEntry
+	// maxFactsPerPass caps the facts kept from one pass
+	maxFactsPerPass int
+	// rules extract facts from messages
+	rules []extractor.Rule
+	// carryForwardTokens budgets the Carry Forward section of pre-compact
+	// handoffs
+	carryForwardTokens int
+	// logPathWait is how long Start waits for the log directory to exist
+	logPathWait time.Duration
+
+	// snapshot accumulates the current session's facts; snapshotFacts
+	// indexes them by type and content
+	snapshot      smart.SessionSnapshot
+	snapshotFacts map[string]int
+
+	// processed records when each log path was last parsed, for Inventory
+	processed map[string]processedLog
+
+	// topics matches facts to earlier ones on the same topic this session,
+	// when FactDetailCap is set
+	topics *smart.TopicTracker
+
+	// activitySeen is set once any log has been processed, so the time
+	// before the first activity isn't mistaken for a session gap
+	activitySeen bool
+
+	// logProgress tracks extracted messages per log content identity, and
+	// logContents the contents seen so far. Only the watch loop touches them.
+	logProgress map[string]logProgress
+	logContents []*logContent
+	// poorlyParsed holds the logs already warned about as poorly parsed.
+	// Only the watch loop touches it.
+	poorlyParsed map[string]bool
+	// binaryLogs holds the logs already warned about as binary. Only the
+	// watch loop touches it.
+	binaryLogs map[string]bool
+
+	// mu guards the activity, source-file, processed-log, topic, and
+	// snapshot tracking shared between the watch loop, the event processor,
+	// the publisher, and diagnostics
+	mu sync.Mutex
+	// handoffMu serializes ledger updates and handoff creation
+	handoffMu sync.Mutex
 }
 
 func NewWatcher(logPath, projectID string, client *api.Client, verbose bool) (*Watcher, error) {
@@ -53,37 +264,123 @@ func NewWatcher(logPath, projectID string, client *api.Client, verbose bool) (*W
 }
 
 func NewWatcherWithConfig(config WatcherConfig) (*Watcher, error) {
+	includeGlobs := config.IncludeGlobs
+	if len(includeGlobs) == 0 {
+		includeGlobs = DefaultIncludeGlobs
+	}
+	if err := validateGlobs(includeGlobs); err != nil {
+		return nil, err
+	}
+	if err := validateGlobs(config.ExcludeGlobs); err != nil {
+		return nil, err
+	}
+	rules := config.ExtractionRules
+	if rules == nil {
+		rules = extractor.DefaultRules()
+	}
+
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
 	}
 
 	w := &Watcher{
-		logPath:      config.LogPath,
-		projectID:    config.ProjectID,
-		repoPath:     config.RepoPath,
-		client:       config.Client,
-		watcher:      watcher,
-		verbose:      config.Verbose,
-		smartMode:    config.SmartMode,
-		parser:       NewParser(),
-		sessionID:    time.Now().Format("20060102_150405"),
-		lastHandoff:  time.Now(),
+		logPath:          config.LogPath,
+		projectID:        config.ProjectID,
+		repoPath:         config.RepoPath,
+		client:           config.Client,
+		watcher:          watcher,
+		verbose:          config.Verbose,
+		smartMode:        config.SmartMode,
+		parser:           NewParserWithTokenRatios(config.ProseCharsPerToken, config.CodeCharsPerToken),
+		sessionID:        time.Now().Format("20060102_150405"),
+		lastHandoff:      time.Now(),
+		recordSources:    config.RecordSourceFiles,
+		recordSessions:   config.RecordSessions || (config.SmartMode && config.NoLedger),
+		sourceFiles:      make(map[string]bool),
+		idleHandoffAfter: config.IdleHandoffAfter,
+		sessionGap:       config.SessionGap,
+		lastActivity:     time.Now(),
+		endMarkersSeen:   make(map[string]int),
+		logProgress:      make(map[string]logProgress),
+		processed:        make(map[string]processedLog),
+		redactor:         config.Redactor,
+		events:           config.Events,
+		factRetention:    config.FactRetention,
+		bus:              events.NewBus(config.EventQueueSize),
+		summaryTemplate:  config.SummaryTemplate,
+		factsPerSession:  config.FactsPerSession,
+		embedder:         config.Embedder,
+		includeGlobs:     includeGlobs,
+		excludeGlobs:     config.ExcludeGlobs,
+		explain:          config.Explain,
+		maxFactsPerPass:  config.MaxFactsPerPass,
+		rules:            rules,
+		logPathWait:      config.LogPathWait,
+		poorlyParsed:     make(map[string]bool),
+		binaryLogs:       make(map[string]bool),
+
+		ledgerMinImportance: config.LedgerMinImportance,
+		carryForwardTokens:  config.CarryForwardTokens,
+	}
+
+	// The ledger keeps smart mode's entries and handoffs under thoughts/
+	if config.SmartMode && !config.NoLedger {
+		w.ledger = ledger.NewLedgerWithConfig(ledger.LedgerConfig{
+			ProjectID:             config.ProjectID,
+			RepoPath:              config.RepoPath,
+			NoEmoji:               config.NoEmoji,
+			BatchSize:             config.LedgerBatchSize,
+			FlushInterval:         config.LedgerFlushInterval,
+			TopImportanceFraction: config.TopImportanceFraction,
+			FactLineLength:        config.HandoffFactLength,
+			FactLineLengths:       config.HandoffFactLengths,
+		})
+		w.outputDirs = absPaths(w.ledger.Dir(), w.ledger.HandoffDir())
 	}
 
 	// Initialize smart features if enabled
 	if config.SmartMode {
-		w.ledger = ledger.NewLedger(config.ProjectID, config.RepoPath)
 		w.importanceScorer = smart.NewImportanceScorer()
+		for factType, floor := range config.ImportanceFloors {
+			w.importanceScorer.SetFloor(factType, floor)
+		}
 		w.staleDetector = smart.NewStaleDetector()
+		w.staleDetector.UseModel(config.StaleModel)
 		w.compactDetector = smart.NewPreCompactDetector(config.CompactThreshold)
+		w.blockers = smart.NewBlockerTracker()
 	}
 
+	if config.FactDetailCap > 0 {
+		w.topics = smart.NewTopicTracker(config.FactDetailCap)
+	}
+
+	if config.FactRateWarning > 0 {
+		w.factRate = NewFactRateMonitor(config.FactRateWarning)
+	}
+
+	if config.FactsPerMinute > 0 {
+		w.rateLimiter = NewRateLimiter(config.FactsPerMinute)
+	}
+
+	// Rate-limited facts queue up for the background publisher rather than
+	// stalling processing
+	if config.AsyncPublish || w.rateLimiter != nil {
+		w.publishQueue = newFactQueue()
+		w.publishDone = make(chan struct{})
+		go w.publishFacts()
+	}
+
+	w.bus.Subscribe(w.handleFactEvent)
+
 	return w, nil
 }
 
 func (w *Watcher) Start() error {
-	// Watch the logs directory
+	// Watch the logs directory, once it exists
+	if err := w.waitForLogPath(); err != nil {
+		return err
+	}
 	if err := w.watcher.Add(w.logPath); err != nil {
 		return err
 	}
@@ -93,21 +390,121 @@ func (w *Watcher) Start() error {
 		log.Printf("Warning: failed to process existing logs: %v", err)
 	}
 
+	if w.factRetention > 0 {
+		w.sweepExpiredFacts()
+	}
+	if w.embedder != nil {
+		w.computeEmbeddings()
+	}
+	if w.adaptiveStale() {
+		w.sweepStaleFacts()
+	}
+
 	// Start watching for new events
 	go w.watch()
 
 	return nil
 }
 
+// waitForLogPath blocks until the log directory exists, for a daemon started
+// before Claude Code has created it, such as by systemd at boot. It checks
+// again with backoff for up to logPathWait, or indefinitely when that's
+// negative. Errors other than the directory being missing are left for
+// Start to report.
+func (w *Watcher) waitForLogPath() error {
+	if w.logPathWait == 0 {
+		return nil
+	}
+	if _, err := os.Stat(w.logPath); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if w.logPathWait > 0 {
+		log.Printf("Waiting up to %s for log directory %s to be created", w.logPathWait, w.logPath)
+	} else {
+		log.Printf("Waiting for log directory %s to be created", w.logPath)
+	}
+
+	start := time.Now()
+	delay := logWaitInitialDelay
+	for {
+		if w.logPathWait > 0 {
+			remaining := w.logPathWait - time.Since(start)
+			if remaining <= 0 {
+				return fmt.Errorf("log directory %s was not created within %s", w.logPath, w.logPathWait)
+			}
+			delay = min(delay, remaining)
+		}
+		time.Sleep(delay)
+
+		if _, err := os.Stat(w.logPath); !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Log directory %s appeared after %s", w.logPath, time.Since(start).Round(time.Second))
+			return nil
+		}
+		delay = min(delay*2, logWaitMaxDelay)
+	}
+}
+
 func (w *Watcher) Stop() {
-	// Create final handoff if smart mode enabled
+	w.watcher.Close()
+
+	// Let queued passes finish before the final handoff reads the ledger
+	w.bus.Close()
+
+	// Create final handoff if smart mode enabled, then write any entries
+	// still batched
 	if w.smartMode {
 		w.createHandoffIfNeeded(true)
 	}
-	w.watcher.Close()
+	if w.ledger != nil {
+		if err := w.ledger.Close(); err != nil {
+			log.Printf("Warning: failed to flush ledger: %v", err)
+		}
+	}
+
+	// Wait for background posts to finish
+	w.drainPublisher()
+}
+
+// Ledger returns the continuity ledger, or nil when smart mode or the ledger
+// is disabled
+func (w *Watcher) Ledger() *ledger.Ledger {
+	return w.ledger
 }
 
 func (w *Watcher) watch() {
+	var idleCheck <-chan time.Time
+	if w.smartMode && w.idleHandoffAfter > 0 {
+		interval := w.idleHandoffAfter / 4
+		if interval > time.Minute {
+			interval = time.Minute
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		idleCheck = ticker.C
+	}
+
+	var retentionSweep <-chan time.Time
+	if w.factRetention > 0 {
+		ticker := time.NewTicker(retentionSweepInterval)
+		defer ticker.Stop()
+		retentionSweep = ticker.C
+	}
+
+	var embeddingSweep <-chan time.Time
+	if w.embedder != nil {
+		ticker := time.NewTicker(embeddingSweepInterval)
+		defer ticker.Stop()
+		embeddingSweep = ticker.C
+	}
+
+	var staleSweep <-chan time.Time
+	if w.adaptiveStale() {
+		ticker := time.NewTicker(staleSweepInterval)
+		defer ticker.Stop()
+		staleSweep = ticker.C
+	}
+
 	for {
 		select {
 		case event, ok := <-w.watcher.Events:
@@ -115,11 +512,22 @@ func (w *Watcher) watch() {
 				return
 			}
 
+			if !w.matchesLogGlobs(event.Name) {
+				continue
+			}
+
 			if event.Op&fsnotify.Write == fsnotify.Write {
 				if w.verbose {
 					log.Printf("Modified file: %s", event.Name)
 				}
 				w.processLogFile(event.Name)
+			} else if event.Op&fsnotify.Create == fsnotify.Create {
+				// A rotated log appears under its new name; content identity
+				// keeps its already processed messages from being extracted again
+				if w.verbose {
+					log.Printf("New log file: %s", event.Name)
+				}
+				w.processLogFile(event.Name)
 			}
 
 		case err, ok := <-w.watcher.Errors:
@@ -127,10 +535,36 @@ func (w *Watcher) watch() {
 				return
 			}
 			log.Printf("Watcher error: %v", err)
+
+		case <-idleCheck:
+			w.checkIdle()
+
+		case <-retentionSweep:
+			w.sweepExpiredFacts()
+
+		case <-embeddingSweep:
+			w.computeEmbeddings()
+
+		case <-staleSweep:
+			w.sweepStaleFacts()
 		}
 	}
 }
 
+// checkIdle creates a handoff when the session has gone quiet for longer than
+// the configured idle threshold
+func (w *Watcher) checkIdle() {
+	w.mu.Lock()
+	idle := w.pendingActivity && time.Since(w.lastActivity) >= w.idleHandoffAfter
+	w.mu.Unlock()
+	if !idle {
+		return
+	}
+
+	log.Printf("No activity for %s, treating session as ended", w.idleHandoffAfter)
+	w.createHandoffIfNeeded(true)
+}
+
 func (w *Watcher) processExistingLogs() error {
 	entries, err := os.ReadDir(w.logPath)
 	if err != nil {
@@ -138,7 +572,7 @@ func (w *Watcher) processExistingLogs() error {
 	}
 
 	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".log" {
+		if !entry.IsDir() && w.matchesLogGlobs(entry.Name()) {
 			w.processLogFile(filepath.Join(w.logPath, entry.Name()))
 		}
 	}
@@ -147,97 +581,330 @@ func (w *Watcher) processExistingLogs() error {
 }
 
 func (w *Watcher) processLogFile(path string) {
-	data, err := os.ReadFile(path)
+	if w.isOwnOutput(path) {
+		if w.verbose {
+			log.Printf("Skipping daemon output file: %s", path)
+		}
+		return
+	}
+
+	data, err := readLogFile(path)
 	if err != nil {
 		if w.verbose {
 			log.Printf("Failed to read log file: %v", err)
 		}
 		return
 	}
+	if w.skipBinary(path, data) {
+		return
+	}
 
 	// Parse conversation
-	conversation, err := w.parser.Parse(string(data))
+	parsed, err := w.parser.Parse(string(data))
 	if err != nil {
 		if w.verbose {
 			log.Printf("Failed to parse conversation: %v", err)
 		}
 		return
 	}
+	if parsed.DroppedBytes > 0 && w.verbose {
+		log.Printf("Dropped %d byte(s) of invalid UTF-8 or control characters from %s", parsed.DroppedBytes, filepath.Base(path))
+	}
+	conversation := parsed.Conversation
+	w.checkParseQuality(path, parsed)
+
+	// Coming back after a long break starts a new session, closed off
+	// before this log's activity is recorded against it
+	w.mu.Lock()
+	gap := time.Since(w.lastActivity)
+	newSession := w.sessionGap > 0 && w.activitySeen && gap >= w.sessionGap
+	w.mu.Unlock()
+	if newSession {
+		w.startNewSession(gap)
+	}
+
+	w.mu.Lock()
+	if w.recordSources {
+		w.sourceFiles[w.relativeLogPath(path)] = true
+	}
+	w.lastActivity = time.Now()
+	w.activitySeen = true
+	w.pendingActivity = true
+	w.mu.Unlock()
+
+	// Only extract messages not already seen, under this name or another
+	info, _ := os.Stat(path)
+	id := w.logIdentity(info, data)
+	messages := w.unprocessedMessages(id, conversation.Messages)
+	w.recordProcessed(path, w.logProgress[id].messages)
+	if w.verbose && len(messages) < len(conversation.Messages) {
+		log.Printf("Skipping %d already processed messages in %s", len(conversation.Messages)-len(messages), filepath.Base(path))
+	}
 
 	// Extract facts
-	facts := extractor.ExtractFacts(conversation)
+	facts := extractor.ExtractFactsWithRules(&types.Conversation{Messages: messages}, w.rules)
+	for i := range facts {
+		facts[i].Content = w.redactText(facts[i].Content)
+	}
 
 	// Update token count
 	tokenCount := w.parser.CountTokens(conversation)
 	w.currentTokens = tokenCount
 
+	if w.verbose {
+		log.Printf("Token count: %d", tokenCount)
+	}
+
+	// An explicit end marker closes the session without waiting for idle
+	sessionEnded := w.smartMode && w.endsWithSessionEndMarker(path, conversation)
+	if sessionEnded {
+		log.Printf("Session end marker found in %s", filepath.Base(path))
+	}
+
+	// Hand off to processors so slow API calls don't hold up parsing
+	w.bus.Publish(events.FactEvent{
+		ProjectID:      w.projectID,
+		SessionID:      w.sessionID,
+		Facts:          facts,
+		TokenCount:     tokenCount,
+		SessionEnded:   sessionEnded,
+		SessionStarted: newSession,
+		Metadata:       extractor.ExtractMetadata(conversation),
+	})
+}
+
+// checkParseQuality warns, once per file, when a log parsed poorly: a sign
+// its format isn't one the parser understands
+func (w *Watcher) checkParseQuality(path string, parsed *ParseResult) {
+	if !parsed.Poor() {
+		delete(w.poorlyParsed, path)
+		return
+	}
+	if w.poorlyParsed[path] {
+		return
+	}
+	w.poorlyParsed[path] = true
+	log.Printf("Warning: %s parsed poorly as text: %d message(s), %d of %d line(s) skipped; is it in a supported format?",
+		filepath.Base(path), parsed.Messages, parsed.SkippedLines, parsed.Lines)
+}
+
+// skipBinary reports whether a log looks binary and so isn't parsed, warning
+// once per log
+func (w *Watcher) skipBinary(path string, data []byte) bool {
+	if !looksBinary(data) {
+		delete(w.binaryLogs, path)
+		return false
+	}
+	if !w.binaryLogs[path] {
+		w.binaryLogs[path] = true
+		log.Printf("Warning: skipping %s, which looks like a binary file rather than a transcript", filepath.Base(path))
+	}
+	return true
+}
+
+// startNewSession ends the current session after a gap of inactivity and
+// switches to a new session ID. Per-session counters are reset when the
+// processor sees the first event of the new session.
+func (w *Watcher) startNewSession(gap time.Duration) {
+	log.Printf("No activity for %s, starting a new session", gap.Round(time.Second))
+
+	w.mu.Lock()
+	pending := w.pendingActivity
+	w.mu.Unlock()
+	if w.smartMode && pending {
+		w.createHandoffIfNeeded(true)
+	}
+
+	w.handoffMu.Lock()
+	w.mu.Lock()
+	w.sessionID = time.Now().Format("20060102_150405")
+	w.mu.Unlock()
+	w.handoffMu.Unlock()
+}
+
+// resetSessionState clears the per-session fact count, topics, and snapshot
+// when a new session starts. Only the event processor calls it.
+func (w *Watcher) resetSessionState() {
+	w.sessionFacts = 0
+
+	w.mu.Lock()
+	w.snapshot = smart.SessionSnapshot{}
+	w.snapshotFacts = nil
+	if w.topics != nil {
+		w.topics.Reset()
+	}
+	w.mu.Unlock()
+}
+
+// handleFactEvent persists the facts from one processing pass
+func (w *Watcher) handleFactEvent(event events.FactEvent) {
+	if event.SessionStarted {
+		w.resetSessionState()
+	}
+	w.checkFactRate(len(event.Facts))
+
 	// Process with smart features if enabled
 	if w.smartMode {
-		w.processWithSmartFeatures(facts, tokenCount)
-	} else {
-		// Basic processing without smart features
-		for _, fact := range facts {
-			if err := w.client.CreateFact(w.projectID, fact); err != nil {
-				log.Printf("Failed to create fact: %v", err)
-			} else if w.verbose {
-				log.Printf("Created fact: %s (%s)", fact.Content, fact.Type)
-			}
+		w.handoffMu.Lock()
+		defer w.handoffMu.Unlock()
+
+		scored := w.processWithSmartFeatures(event.SessionID, event.Facts, event.TokenCount, event.Metadata)
+		w.recordSnapshot(scored, event.TokenCount)
+		if event.SessionEnded {
+			w.createHandoffLocked(true)
 		}
+		return
 	}
 
-	if w.verbose {
-		log.Printf("Token count: %d", tokenCount)
+	// Basic processing without smart features
+	facts, _ := w.capFacts(event.Facts)
+	for _, fact := range facts {
+		if w.explain {
+			log.Printf("Explain: %s fact %q: %s", fact.Type, fact.Content, fact.Explain())
+		}
+		w.postFact(fact)
+	}
+	w.recordSnapshot(facts, event.TokenCount)
+}
+
+// capFacts keeps the maxFactsPerPass most important of facts, in their
+// original order, returning them and how many were dropped
+func (w *Watcher) capFacts(facts []extractor.Fact) ([]extractor.Fact, int) {
+	if w.maxFactsPerPass <= 0 || len(facts) <= w.maxFactsPerPass {
+		return facts, 0
+	}
+
+	rank := func(fact extractor.Fact) float64 {
+		if fact.Score > 0 {
+			return fact.Score
+		}
+		return float64(fact.Importance)
+	}
+	order := make([]int, len(facts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rank(facts[order[i]]) > rank(facts[order[j]])
+	})
+	keep := make(map[int]bool, w.maxFactsPerPass)
+	for _, i := range order[:w.maxFactsPerPass] {
+		keep[i] = true
+	}
+
+	kept := make([]extractor.Fact, 0, w.maxFactsPerPass)
+	for i, fact := range facts {
+		if keep[i] {
+			kept = append(kept, fact)
+		}
 	}
+	dropped := len(facts) - len(kept)
+	log.Printf("Warning: a pass extracted %d facts; keeping the %d most important and dropping %d",
+		len(facts), len(kept), dropped)
+	return kept, dropped
 }
 
-func (w *Watcher) processWithSmartFeatures(facts []extractor.Fact, tokenCount int) {
+// endsWithSessionEndMarker reports whether the conversation's last message is a
+// session end marker that hasn't already triggered a handoff
+func (w *Watcher) endsWithSessionEndMarker(path string, conv *types.Conversation) bool {
+	count := len(conv.Messages)
+	if count == 0 || w.endMarkersSeen[path] == count {
+		return false
+	}
+
+	last := strings.TrimSpace(conv.Messages[count-1].Content)
+	for _, marker := range sessionEndMarkers {
+		if strings.EqualFold(last, marker) {
+			w.endMarkersSeen[path] = count
+			return true
+		}
+	}
+	return false
+}
+
+// processWithSmartFeatures scores, posts, and ledgers facts from a pass of
+// the given session, returning them with their scored importance. The
+// session is the pass's own rather than the current one, which may have
+// moved on while the pass was queued. Callers must hold handoffMu.
+func (w *Watcher) processWithSmartFeatures(sessionID string, facts []extractor.Fact, tokenCount int, meta extractor.Metadata) []extractor.Fact {
 	// Check if we should create pre-compact handoff
 	if w.compactDetector.ShouldCreateHandoff(tokenCount) {
-		w.createHandoffIfNeeded(false)
+		w.createHandoffLocked(false)
 	}
 
-	// Apply importance scoring and create facts
-	enhancedFacts := make([]ledger.Fact, 0, len(facts))
+	// Apply importance scoring
+	scored := make([]extractor.Fact, 0, len(facts))
 	for _, fact := range facts {
 		// Calculate importance
-		importance := w.importanceScorer.CalculateImportance(
+		breakdown := w.importanceScorer.Explain(
 			fact.Type,
 			fact.Content,
 			time.Now(),
 		)
-		fact.Importance = importance
+		fact.Importance = breakdown.Score
+		fact.Score = breakdown.Raw
+		if w.explain {
+			log.Printf("Explain: %s fact %q: %s; %s", fact.Type, fact.Content, fact.Explain(), breakdown)
+		}
+		scored = append(scored, fact)
+	}
+	scored, dropped := w.capFacts(scored)
 
+	// Create facts
+	enhancedFacts := make([]ledger.Fact, 0, len(scored))
+	for _, fact := range scored {
 		// Create fact in PocketBase
-		if err := w.client.CreateFact(w.projectID, fact); err != nil {
-			log.Printf("Failed to create fact: %v", err)
-		} else if w.verbose {
-			log.Printf("Created fact (importance: %d): %s (%s)", importance, fact.Content, fact.Type)
+		w.postFact(fact)
+
+		if fact.Importance < w.ledgerMinImportance[fact.Type] {
+			if w.explain {
+				log.Printf("Explain: %s fact %q left out of the ledger: importance %d is below %d",
+					fact.Type, fact.Content, fact.Importance, w.ledgerMinImportance[fact.Type])
+			}
+			continue
 		}
 
 		// Add to enhanced facts for ledger
 		enhancedFacts = append(enhancedFacts, ledger.Fact{
 			Type:       fact.Type,
 			Content:    fact.Content,
-			Importance: importance,
+			Importance: fact.Importance,
+			Score:      fact.Score,
 			Timestamp:  time.Now(),
 		})
 	}
 
+	resolvedBlockers := w.trackBlockers(facts)
+
 	// Update continuity ledger
 	entry := ledger.LedgerEntry{
-		Timestamp:   time.Now(),
-		SessionID:   w.sessionID,
-		ProjectID:   w.projectID,
-		TokenCount:  tokenCount,
-		Facts:       enhancedFacts,
-		Context:     make(map[string]interface{}),
-		Decisions:   w.filterFactsByType(enhancedFacts, "decision"),
-		NextSteps:   w.filterFactsByType(enhancedFacts, "todo"),
-		Blockers:    w.filterFactsByType(enhancedFacts, "blocker"),
-		FileChanges: w.filterFactsByType(enhancedFacts, "file_change"),
-	}
-
-	if err := w.ledger.AppendEntry(entry); err != nil && w.verbose {
+		Timestamp:        time.Now(),
+		SessionID:        sessionID,
+		ProjectID:        w.projectID,
+		TokenCount:       tokenCount,
+		Facts:            enhancedFacts,
+		Context:          make(map[string]interface{}),
+		Decisions:        w.filterFactsByType(enhancedFacts, "decision"),
+		NextSteps:        w.filterFactsByType(enhancedFacts, "todo"),
+		Blockers:         blockerContents(enhancedFacts),
+		FileChanges:      w.filterFactsByType(enhancedFacts, "file_change"),
+		ResolvedBlockers: resolvedBlockers,
+		Model:            meta.Model,
+		Cwd:              meta.Cwd,
+		Branch:           meta.Branch,
+		DroppedFacts:     dropped,
+	}
+	if !meta.StartTime.IsZero() {
+		entry.SessionStart = &meta.StartTime
+	}
+	// Transcripts rarely name their branch; the repo's checkout usually is it
+	if entry.Branch == "" && w.repoPath != "" {
+		entry.Branch = detect.GitBranch(w.repoPath)
+	}
+
+	if w.ledger == nil {
+		w.latestEntry = &entry
+	} else if err := w.ledger.AppendEntry(entry); err != nil && w.verbose {
 		log.Printf("Failed to update ledger: %v", err)
 	}
 
@@ -247,31 +914,89 @@ func (w *Watcher) processWithSmartFeatures(facts []extractor.Fact, tokenCount in
 		log.Printf("Smart features: %d facts processed, %d tokens remaining until compact",
 			len(facts), remaining)
 	}
+
+	return scored
+}
+
+// trackBlockers opens newly seen blockers and resolves open ones that this
+// pass reports as fixed, returning the resolved blockers with their blocked time
+func (w *Watcher) trackBlockers(facts []extractor.Fact) []ledger.ResolvedBlocker {
+	now := time.Now()
+
+	var resolved []ledger.ResolvedBlocker
+	for _, fact := range facts {
+		for _, blocker := range w.blockers.Resolve(fact.Content, now) {
+			resolved = append(resolved, ledger.ResolvedBlocker{
+				Content:        blocker.Content,
+				OpenedAt:       blocker.OpenedAt,
+				ResolvedAt:     blocker.ResolvedAt,
+				BlockedSeconds: int64(blocker.BlockedFor().Seconds()),
+			})
+			if w.verbose {
+				log.Printf("Blocker resolved after %s: %s", blocker.BlockedFor().Round(time.Second), blocker.Content)
+			}
+		}
+
+		// Blockers that already read as resolved are not opened
+		if isBlocking(fact.Type, fact.Content) && !w.staleDetector.IsStale(fact.Type, now, fact.Content) {
+			w.blockers.Open(fact.Content, now)
+		}
+	}
+	return resolved
 }
 
 func (w *Watcher) createHandoffIfNeeded(force bool) {
+	w.handoffMu.Lock()
+	defer w.handoffMu.Unlock()
+
+	w.createHandoffLocked(force)
+}
+
+// createHandoffLocked writes a handoff from the latest ledger entry, for that
+// entry's session. Callers must hold handoffMu.
+func (w *Watcher) createHandoffLocked(force bool) {
 	// Don't create handoffs too frequently (minimum 30 min apart)
 	if !force && time.Since(w.lastHandoff) < 30*time.Minute {
 		return
 	}
 
-	// Get latest ledger entry
-	latest, err := w.ledger.GetLatestEntry()
-	if err != nil {
-		if w.verbose {
-			log.Printf("Failed to get latest ledger entry: %v", err)
-		}
+	latest, ok := w.latestLedgerEntry()
+	if !ok {
 		return
 	}
 
-	// Create handoff document
-	summary := w.generateHandoffSummary(latest)
-	if err := w.ledger.CreateHandoff(w.sessionID, summary, latest.Facts); err != nil {
-		log.Printf("Failed to create handoff: %v", err)
-		return
+	// Create handoff document, unless there's no ledger to keep it with
+	summary := w.redactText(RenderSummary(w.summaryTemplate, latest))
+	w.mu.Lock()
+	sourceFiles := w.handoffSourceFiles()
+	w.mu.Unlock()
+	if w.ledger != nil {
+		if err := w.ledger.CreateHandoff(latest.SessionID, summary, latest.Facts, sourceFiles, w.carryForward(latest)); err != nil {
+			log.Printf("Failed to create handoff: %v", err)
+			return
+		}
+	}
+
+	// Record the handoff as a session checkpoint in PocketBase
+	if w.recordSessions {
+		meta := extractor.Metadata{Model: latest.Model, Cwd: latest.Cwd, Branch: latest.Branch}
+		if latest.SessionStart != nil {
+			meta.StartTime = *latest.SessionStart
+		}
+		if err := w.client.CreateSession(w.projectID, summary, latest.TokenCount, sourceFiles, meta); err != nil {
+			log.Printf("Failed to record session: %v", err)
+		}
+	}
+
+	if w.events != nil {
+		w.events.HandoffCreated(latest.SessionID, summary)
 	}
 
 	w.lastHandoff = time.Now()
+	w.mu.Lock()
+	w.pendingActivity = false
+	w.sourceFiles = make(map[string]bool)
+	w.mu.Unlock()
 
 	if w.verbose || force {
 		log.Printf("✓ Handoff created: %s (tokens: %d, facts: %d)",
@@ -279,26 +1004,125 @@ func (w *Watcher) createHandoffIfNeeded(force bool) {
 	}
 }
 
-func (w *Watcher) generateHandoffSummary(entry *ledger.LedgerEntry) string {
-	summary := ""
+// latestLedgerEntry returns the entry a handoff describes: the latest in the
+// ledger, or under NoLedger the one kept in memory. It reports false when
+// there is nothing to hand off yet or the ledger can't be read. Callers must
+// hold handoffMu.
+func (w *Watcher) latestLedgerEntry() (*ledger.LedgerEntry, bool) {
+	if w.ledger == nil {
+		return w.latestEntry, w.latestEntry != nil
+	}
+
+	// Batched entries must be on disk before the handoff reads the ledger,
+	// or it would describe an earlier state. A failed flush keeps them
+	// pending, so the handoff is left for the next attempt rather than
+	// written stale.
+	if err := w.ledger.Flush(); err != nil {
+		log.Printf("Warning: not creating handoff, failed to flush ledger: %v", err)
+		return nil, false
+	}
+
+	latest, err := w.ledger.GetLatestEntry()
+	if err != nil {
+		if w.verbose {
+			log.Printf("Failed to get latest ledger entry: %v", err)
+		}
+		return nil, false
+	}
+	// A nil entry means nothing has been recorded yet
+	return latest, latest != nil
+}
+
+// carryForward recommends which of entry's facts to carry into the next
+// session, when the session is close enough to compacting to need it
+func (w *Watcher) carryForward(entry *ledger.LedgerEntry) *ledger.CarryForward {
+	if w.carryForwardTokens <= 0 || !w.compactDetector.ShouldCreateHandoff(entry.TokenCount) {
+		return nil
+	}
+
+	facts := make([]smart.CompressibleFact, 0, len(entry.Facts))
+	for _, fact := range entry.Facts {
+		facts = append(facts, smart.CompressibleFact{
+			Type:       fact.Type,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+			Score:      fact.Score,
+			Created:    fact.Timestamp,
+		})
+	}
+	tokens := func(fact smart.CompressibleFact) int {
+		return w.parser.TextTokens(fmt.Sprintf("- [%s] %s\n", fact.Type, fact.Content))
+	}
+
+	carry := &ledger.CarryForward{Budget: w.carryForwardTokens}
+	compressor := smart.NewContextCompressor(carryForwardPerType)
+	for _, fact := range compressor.FitBudget(facts, w.carryForwardTokens, tokens) {
+		carry.Tokens += tokens(fact)
+		carry.Facts = append(carry.Facts, ledger.Fact{
+			Type:       fact.Type,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+			Score:      fact.Score,
+			Timestamp:  fact.Created,
+		})
+	}
+	return carry
+}
+
+func (w *Watcher) notifyFact(fact extractor.Fact) {
+	if w.events != nil {
+		w.events.FactCreated(w.projectID, fact)
+	}
+}
 
-	if len(entry.Decisions) > 0 {
-		summary += "Made architectural decisions. "
+func (w *Watcher) redactText(text string) string {
+	if w.redactor == nil {
+		return text
 	}
+	return w.redactor.Redact(text)
+}
 
-	if len(entry.Blockers) > 0 {
-		summary += "Encountered blockers. "
+// handoffSourceFiles returns the log files processed since the last handoff,
+// sorted. Callers must hold mu.
+func (w *Watcher) handoffSourceFiles() []string {
+	files := make([]string, 0, len(w.sourceFiles))
+	for file := range w.sourceFiles {
+		files = append(files, file)
 	}
+	sort.Strings(files)
+	return files
+}
 
-	if len(entry.FileChanges) > 0 {
-		summary += "Modified codebase. "
+// isOwnOutput reports whether path is inside one of the daemon's output
+// directories, to avoid feeding handoffs and ledgers back in as transcripts
+func (w *Watcher) isOwnOutput(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
 	}
+	for _, dir := range w.outputDirs {
+		if abs == dir || strings.HasPrefix(abs, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
 
-	if summary == "" {
-		summary = "Continued development work."
+func absPaths(paths ...string) []string {
+	var result []string
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			result = append(result, abs)
+		}
 	}
+	return result
+}
 
-	return summary
+func (w *Watcher) relativeLogPath(path string) string {
+	if rel, err := filepath.Rel(w.logPath, path); err == nil {
+		return rel
+	}
+	return path
 }
 
 func (w *Watcher) filterFactsByType(facts []ledger.Fact, factType string) []string {
@@ -311,4 +1135,22 @@ func (w *Watcher) filterFactsByType(facts []ledger.Fact, factType string) []stri
 	return result
 }
 
+// isBlocking reports whether a fact holds up work: a blocker, or a failing
+// test run or build
+func isBlocking(factType, content string) bool {
+	return factType == "blocker" || factType == "test_status" && extractor.IsTestFailure(content)
+}
+
+// blockerContents lists the facts that block work, escalating failing test
+// statuses into the handoff's blockers
+func blockerContents(facts []ledger.Fact) []string {
+	var result []string
+	for _, fact := range facts {
+		if isBlocking(fact.Type, fact.Content) {
+			result = append(result, fact.Content)
+		}
+	}
+	return result
+}
+
 // Conversation and Message types moved to types package
@@ -0,0 +1,64 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const placeholder = "[REDACTED]"
+
+type rule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// builtinRules cover common credential formats seen in transcripts
+var builtinRules = []rule{
+	// AWS access key IDs
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), placeholder},
+	// AWS secret access keys in assignments
+	{regexp.MustCompile(`(?i)(aws_secret_access_key\s*[=:]\s*)\S+`), "${1}" + placeholder},
+	// Authorization bearer tokens
+	{regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + placeholder},
+	// GitHub tokens
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), placeholder},
+	// Anthropic/OpenAI style API keys
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9\-_]{20,}\b`), placeholder},
+	// Slack tokens
+	{regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9\-]{10,}\b`), placeholder},
+	// PEM private keys
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), placeholder},
+	// password=... / password: ...
+	{regexp.MustCompile(`(?i)\b(password|passwd|pwd)(\s*[=:]\s*)\S+`), "${1}${2}" + placeholder},
+}
+
+// Redactor masks sensitive values such as API keys, tokens, and passwords
+type Redactor struct {
+	rules []rule
+}
+
+// NewRedactor returns a redactor using the built-in rules plus any extra
+// regular expressions. Matches of extra patterns are replaced entirely.
+func NewRedactor(extraPatterns []string) (*Redactor, error) {
+	r := &Redactor{
+		rules: append([]rule(nil), builtinRules...),
+	}
+
+	for _, pattern := range extraPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.rules = append(r.rules, rule{re, placeholder})
+	}
+
+	return r, nil
+}
+
+// Redact replaces every sensitive match in text with [REDACTED]
+func (r *Redactor) Redact(text string) string {
+	for _, rule := range r.rules {
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+	return text
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+)
+
+// clientBuffer is how many events a slow SSE client may fall behind before
+// further events are dropped for it
+const clientBuffer = 32
+
+// Event is a single server-sent event
+type Event struct {
+	Type string
+	Data interface{}
+}
+
+// Broadcaster fans out watcher events to connected SSE clients. It
+// implements monitor.EventSink.
+type Broadcaster struct {
+	mu      sync.Mutex
+	clients map[chan Event]bool
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		clients: make(map[chan Event]bool),
+	}
+}
+
+// FactCreated publishes a fact once it has been stored
+func (b *Broadcaster) FactCreated(projectID string, fact extractor.Fact) {
+	b.publish(Event{
+		Type: "fact",
+		Data: map[string]interface{}{
+			"project":    projectID,
+			"type":       fact.Type,
+			"content":    fact.Content,
+			"importance": fact.Importance,
+		},
+	})
+}
+
+// HandoffCreated publishes a handoff once it has been written
+func (b *Broadcaster) HandoffCreated(sessionID, summary string) {
+	b.publish(Event{
+		Type: "handoff",
+		Data: map[string]interface{}{
+			"session_id": sessionID,
+			"summary":    summary,
+		},
+	})
+}
+
+func (b *Broadcaster) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for client := range b.clients {
+		select {
+		case client <- event:
+		default:
+			// Client isn't keeping up; drop rather than block the watcher
+		}
+	}
+}
+
+func (b *Broadcaster) subscribe() chan Event {
+	client := make(chan Event, clientBuffer)
+
+	b.mu.Lock()
+	b.clients[client] = true
+	b.mu.Unlock()
+
+	return client
+}
+
+func (b *Broadcaster) unsubscribe(client chan Event) {
+	b.mu.Lock()
+	delete(b.clients, client)
+	b.mu.Unlock()
+}
+
+// ServeHTTP streams events to the client until it disconnects
+func (b *Broadcaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	client := b.subscribe()
+	defer b.unsubscribe(client)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-client:
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
+)
+
+// clientCount returns how many SSE clients are subscribed
+func (b *Broadcaster) clientCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+// waitForClients waits until n SSE clients are subscribed
+func waitForClients(t *testing.T, b *Broadcaster, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for b.clientCount() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d SSE clients, want %d", b.clientCount(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventsStreamsFacts(t *testing.T) {
+	b := NewBroadcaster()
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	waitForClients(t, b, 1)
+	b.FactCreated("proj1", extractor.Fact{Type: "decision", Content: "Use Postgres", Importance: 4})
+
+	reader := bufio.NewReader(resp.Body)
+	readLine := func() string {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event: %v", err)
+		}
+		return strings.TrimSuffix(line, "\n")
+	}
+	if line := readLine(); line != "event: fact" {
+		t.Fatalf("got %q, want the fact event", line)
+	}
+	data, ok := strings.CutPrefix(readLine(), "data: ")
+	if !ok {
+		t.Fatal("event has no data line")
+	}
+	var fact map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &fact); err != nil {
+		t.Fatalf("event data %q: %v", data, err)
+	}
+	if fact["project"] != "proj1" || fact["type"] != "decision" || fact["content"] != "Use Postgres" || fact["importance"] != 4.0 {
+		t.Errorf("event data = %v, want the fact", fact)
+	}
+
+	// A client that disconnects is unsubscribed
+	cancel()
+	waitForClients(t, b, 0)
+}
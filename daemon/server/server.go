@@ -0,0 +1,207 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+	"github.com/angelfreak/ccd/daemon/monitor"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// Server exposes daemon health and diagnostics over HTTP
+type Server struct {
+	addr   string
+	ledger *ledger.Ledger
+	events *Broadcaster
+	status SnapshotSource
+	srv    *http.Server
+}
+
+// SnapshotSource provides the live session state served at /status and the
+// watched-file inventory served at /watched
+type SnapshotSource interface {
+	Snapshot() smart.SessionSnapshot
+	Inventory() monitor.Inventory
+}
+
+type statusResponse struct {
+	SessionID   string       `json:"session_id"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	TokenCount  int          `json:"token_count"`
+	FactCount   int          `json:"fact_count"`
+	Facts       []statusFact `json:"facts"`
+	FileChanges []string     `json:"file_changes"`
+}
+
+type statusFact struct {
+	Type       string `json:"type"`
+	Content    string `json:"content"`
+	Importance int    `json:"importance"`
+}
+
+// NewServer creates a server bound to addr. ledger may be nil when smart
+// features are disabled, in which case ledger endpoints report unavailable.
+// events backs the /events SSE stream and status backs /status and /watched.
+func NewServer(addr string, l *ledger.Ledger, events *Broadcaster, status SnapshotSource) *Server {
+	s := &Server{
+		addr:   addr,
+		ledger: l,
+		events: events,
+		status: status,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/watched", s.handleWatched)
+	mux.HandleFunc("/metrics/ledger", s.handleLedgerMetrics)
+	mux.Handle("/events", events)
+	mux.HandleFunc("/diff/handoff", s.handleHandoffDiff)
+
+	s.srv = &http.Server{Handler: mux}
+	return s
+}
+
+// Start begins listening in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP server error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Stop() {
+	s.srv.Close()
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleStatus reports the current session's cumulative state
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	snapshot := s.status.Snapshot()
+
+	facts := make([]statusFact, 0, len(snapshot.Facts))
+	for _, fact := range snapshot.Facts {
+		facts = append(facts, statusFact{
+			Type:       fact.Type,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+		})
+	}
+
+	fileChanges := snapshot.FileChanges
+	if fileChanges == nil {
+		fileChanges = []string{}
+	}
+
+	writeJSON(w, http.StatusOK, statusResponse{
+		SessionID:   snapshot.SessionID,
+		UpdatedAt:   snapshot.Timestamp,
+		TokenCount:  snapshot.TokenCount,
+		FactCount:   len(facts),
+		Facts:       facts,
+		FileChanges: fileChanges,
+	})
+}
+
+// handleWatched lists the watched directories and files with their
+// processing progress
+func (s *Server) handleWatched(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status.Inventory())
+}
+
+func (s *Server) handleLedgerMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.ledger == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "ledger disabled"})
+		return
+	}
+
+	metrics, err := s.ledger.GetMetrics()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+// handleHandoffDiff renders what changed between the latest handoff and the
+// latest ledger entry, i.e. what has happened since context was last captured
+func (s *Server) handleHandoffDiff(w http.ResponseWriter, r *http.Request) {
+	if s.ledger == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "ledger disabled"})
+		return
+	}
+
+	handoff, err := s.ledger.LatestHandoff()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if handoff == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no handoffs yet"})
+		return
+	}
+
+	latest, err := s.ledger.GetLatestEntry()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if latest == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no ledger entries yet"})
+		return
+	}
+
+	previous := smart.SessionSnapshot{
+		SessionID: "handoff " + handoff.SessionID,
+		Timestamp: handoff.Timestamp,
+		Facts:     compressibleFacts(handoff.Facts),
+	}
+	current := smart.SessionSnapshot{
+		SessionID:  latest.SessionID,
+		Timestamp:  latest.Timestamp,
+		Facts:      compressibleFacts(latest.Facts),
+		TokenCount: latest.TokenCount,
+	}
+
+	generator := smart.NewDiffGenerator()
+	diff := generator.GenerateDiff(previous, current)
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Write([]byte(generator.FormatDiff(diff, previous, current)))
+}
+
+func compressibleFacts(facts []ledger.Fact) []smart.CompressibleFact {
+	result := make([]smart.CompressibleFact, 0, len(facts))
+	for _, fact := range facts {
+		result = append(result, smart.CompressibleFact{
+			Type:       fact.Type,
+			Content:    fact.Content,
+			Importance: fact.Importance,
+			Score:      fact.Score,
+			Created:    fact.Timestamp,
+		})
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/monitor"
+	"github.com/angelfreak/ccd/daemon/smart"
+)
+
+// stubStatus serves a fixed snapshot and inventory
+type stubStatus struct {
+	snapshot  smart.SessionSnapshot
+	inventory monitor.Inventory
+}
+
+func (s stubStatus) Snapshot() smart.SessionSnapshot { return s.snapshot }
+func (s stubStatus) Inventory() monitor.Inventory    { return s.inventory }
+
+// getJSON requests path from s and decodes the JSON response into v,
+// returning the status code
+func getJSON(t *testing.T, s *Server, path string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("GET %s: %v in %q", path, err, rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestStatusReportsSnapshot(t *testing.T) {
+	status := stubStatus{snapshot: smart.SessionSnapshot{
+		SessionID:  "s1",
+		Timestamp:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		TokenCount: 1800,
+		Facts: []smart.CompressibleFact{
+			{Type: "decision", Content: "Use Postgres", Importance: 4},
+			{Type: "blocker", Content: "CI is red", Importance: 5},
+		},
+	}}
+	s := NewServer("127.0.0.1:0", nil, NewBroadcaster(), status)
+
+	var got statusResponse
+	if code := getJSON(t, s, "/status", &got); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if got.SessionID != "s1" || got.TokenCount != 1800 || got.FactCount != 2 || !got.UpdatedAt.Equal(status.snapshot.Timestamp) {
+		t.Errorf("status = %+v, want the snapshot's session, tokens, and facts", got)
+	}
+	if got.Facts[1] != (statusFact{Type: "blocker", Content: "CI is red", Importance: 5}) {
+		t.Errorf("second fact = %+v, want the blocker", got.Facts[1])
+	}
+	if got.FileChanges == nil {
+		t.Error("file_changes is null, want an empty list")
+	}
+}
+
+func TestWatchedListsInventory(t *testing.T) {
+	status := stubStatus{inventory: monitor.Inventory{
+		Directories:  []string{"/logs"},
+		IncludeGlobs: []string{"*.log"},
+		Files: []monitor.WatchedFile{
+			{Path: "/logs/session.log", Size: 120, Included: true, Messages: 4},
+			{Path: "/logs/notes.txt", Size: 10},
+		},
+	}}
+	s := NewServer("127.0.0.1:0", nil, NewBroadcaster(), status)
+
+	var got monitor.Inventory
+	if code := getJSON(t, s, "/watched", &got); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if !reflect.DeepEqual(got, status.inventory) {
+		t.Errorf("inventory = %+v, want %+v", got, status.inventory)
+	}
+}
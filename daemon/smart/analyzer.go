@@ -1,53 +1,124 @@
 package smart
 
 import (
+	"fmt"
 	"math"
 	"strings"
 	"time"
+
+	"github.com/angelfreak/ccd/daemon/extractor"
 )
 
 // ImportanceScorer calculates importance scores for facts
 type ImportanceScorer struct {
 	weights map[string]float64
+
+	// floors are the lowest scores critical types can receive, however
+	// tersely they're phrased
+	floors map[string]int
 }
 
 func NewImportanceScorer() *ImportanceScorer {
 	return &ImportanceScorer{
 		weights: map[string]float64{
-			"blocker":     1.0,  // Highest priority
-			"decision":    0.9,  // Critical architectural choices
-			"dependency":  0.7,  // Important but not urgent
-			"todo":        0.6,  // Task tracking
-			"insight":     0.5,  // Learning outcomes
-			"file_change": 0.4,  // Implementation details
+			"blocker":       1.0, // Highest priority
+			"decision":      0.9, // Critical architectural choices
+			"config_change": 0.8, // Environment drift breaks things silently
+			"dependency":    0.7, // Important but not urgent
+			"todo":          0.6, // Task tracking
+			"test_status":   0.6, // Passing runs; failures score as blockers
+			"insight":       0.5, // Learning outcomes
+			"file_change":   0.4, // Implementation details
 		},
+		floors: map[string]int{
+			"blocker":  4,
+			"decision": 3,
+		},
+	}
+}
+
+// SetFloor sets the lowest score a fact type can receive. Zero removes the
+// type's floor.
+func (s *ImportanceScorer) SetFloor(factType string, floor int) {
+	if floor <= 0 {
+		delete(s.floors, factType)
+		return
+	}
+	s.floors[factType] = floor
+}
+
+// ScoreBreakdown shows how an importance score was reached
+type ScoreBreakdown struct {
+	// Type, Content, and Recency are the points from each component
+	Type    float64
+	Content float64
+	Recency float64
+	// Floor is the fact type's minimum score, or 0 if it has none
+	Floor int
+	// Score is the final 1-5 importance
+	Score int
+	// Raw is the unrounded 0-5 importance, with the floor applied, for
+	// ranking facts that round to the same Score
+	Raw float64
+}
+
+func (b ScoreBreakdown) String() string {
+	explanation := fmt.Sprintf("importance %d = round(type %.1f + content %.1f + recency %.1f)",
+		b.Score, b.Type, b.Content, b.Recency)
+	if b.Floor > 0 {
+		explanation += fmt.Sprintf(", floor %d", b.Floor)
 	}
+	return explanation
 }
 
 // CalculateImportance returns a score from 1-5
 func (s *ImportanceScorer) CalculateImportance(factType, content string, recency time.Time) int {
-	score := 0.0
+	return s.Explain(factType, content, recency).Score
+}
+
+// Explain scores a fact like CalculateImportance, returning the points
+// each component contributed
+func (s *ImportanceScorer) Explain(factType, content string, recency time.Time) ScoreBreakdown {
+	var b ScoreBreakdown
+
+	// A red build or failing test run holds up work like any blocker, so
+	// it is weighted and floored as one
+	weightType := factType
+	if factType == "test_status" && extractor.IsTestFailure(content) {
+		weightType = "blocker"
+	}
 
 	// Base weight from type
-	if w, ok := s.weights[factType]; ok {
-		score += w * 3.0 // Max 3 points from type
+	if w, ok := s.weights[weightType]; ok {
+		b.Type = w * 3.0 // Max 3 points from type
 	}
 
 	// Content analysis (max 1.5 points)
-	score += s.analyzeContent(content)
+	b.Content = s.analyzeContent(content)
 
 	// Recency bonus (max 0.5 points)
-	score += s.recencyBonus(recency)
+	b.Recency = s.recencyBonus(recency)
 
 	// Convert to 1-5 scale
-	normalized := int(math.Round(score))
-	if normalized < 1 {
-		return 1
+	raw := b.Type + b.Content + b.Recency
+	normalized := int(math.Round(raw))
+	if floor, ok := s.floors[weightType]; ok {
+		b.Floor = floor
+		if normalized < floor {
+			normalized = floor
+		}
+		raw = math.Max(raw, float64(floor))
 	}
-	if normalized > 5 {
-		return 5
+	b.Raw = math.Min(math.Max(raw, 0), 5)
+	switch {
+	case normalized < 1:
+		b.Score = 1
+	case normalized > 5:
+		b.Score = 5
+	default:
+		b.Score = normalized
 	}
-	return normalized
+	return b
 }
 
 func (s *ImportanceScorer) analyzeContent(content string) float64 {
@@ -95,23 +166,41 @@ func (s *ImportanceScorer) recencyBonus(t time.Time) float64 {
 // StaleDetector identifies facts that are no longer relevant
 type StaleDetector struct {
 	staleDays map[string]int
+
+	// model, when set, replaces the per-type thresholds
+	model *StaleModel
 }
 
 func NewStaleDetector() *StaleDetector {
 	return &StaleDetector{
 		staleDays: map[string]int{
-			"blocker":     3,   // Blockers resolved quickly or abandoned
-			"todo":        7,   // Todos either done or deprioritized
-			"file_change": 14,  // Implementation details fade
-			"dependency":  30,  // Dependencies stable after install
-			"decision":    90,  // Decisions remain relevant longer
-			"insight":     60,  // Insights useful for a while
+			"blocker":       3,  // Blockers resolved quickly or abandoned
+			"test_status":   2,  // The next run supersedes it
+			"todo":          7,  // Todos either done or deprioritized
+			"file_change":   14, // Implementation details fade
+			"dependency":    30, // Dependencies stable after install
+			"config_change": 30, // Config settles like dependencies
+			"decision":      90, // Decisions remain relevant longer
+			"insight":       60, // Insights useful for a while
 		},
 	}
 }
 
 // IsStale checks if a fact is outdated
 func (d *StaleDetector) IsStale(factType string, created time.Time, content string) bool {
+	// The model is asked about a mid-importance fact when the caller
+	// doesn't know the importance
+	return d.IsStaleFact(CompressibleFact{Type: factType, Content: content, Created: created, Importance: 3})
+}
+
+// IsStaleFact checks if a fact is outdated, using the trained model if one
+// is in use
+func (d *StaleDetector) IsStaleFact(fact CompressibleFact) bool {
+	if d.model != nil {
+		return d.model.Probability(staleFeatures(fact, time.Now())) >= 0.5
+	}
+
+	factType, created, content := fact.Type, fact.Created, fact.Content
 	days, ok := d.staleDays[factType]
 	if !ok {
 		days = 30 // Default
@@ -147,24 +236,50 @@ type CompressibleFact struct {
 	Type       string
 	Content    string
 	Importance int
-	Created    time.Time
-	Stale      bool
+	// Score is the unrounded 0-5 importance, when known. Zero means only
+	// Importance is available.
+	Score   float64
+	Created time.Time
+	Stale   bool
+	// Verified facts have been confirmed by a human and are kept ahead of
+	// unverified ones
+	Verified bool
+}
+
+// Rank is the fact's importance for ordering: its unrounded score when
+// known, otherwise the 1-5 importance
+func (f CompressibleFact) Rank() float64 {
+	if f.Score > 0 {
+		return f.Score
+	}
+	return float64(f.Importance)
 }
 
 // Compress reduces fact count while preserving important information
 func (c *ContextCompressor) Compress(facts []CompressibleFact) []CompressibleFact {
-	// Group by type
+	// Group by type, dropping duplicates but keeping their highest importance
 	grouped := make(map[string][]CompressibleFact)
+	index := make(map[string]int)
 	for _, fact := range facts {
-		if !fact.Stale {
-			grouped[fact.Type] = append(grouped[fact.Type], fact)
+		if fact.Stale {
+			continue
+		}
+		key := FactKey(fact.Type, fact.Content)
+		if i, ok := index[key]; ok {
+			kept := &grouped[fact.Type][i]
+			kept.Importance = max(kept.Importance, fact.Importance)
+			kept.Score = max(kept.Score, fact.Score)
+			kept.Verified = kept.Verified || fact.Verified
+			continue
 		}
+		index[key] = len(grouped[fact.Type])
+		grouped[fact.Type] = append(grouped[fact.Type], fact)
 	}
 
-	// Keep top N per type by importance and recency
+	// Keep top N per type, verified first, by importance and recency
 	var compressed []CompressibleFact
 	for _, typeFacts := range grouped {
-		// Sort by importance (desc) then recency
+		// Sort verified first, then by importance (desc) then recency
 		sorted := c.sortByImportance(typeFacts)
 
 		// Take top N
@@ -185,8 +300,14 @@ func (c *ContextCompressor) sortByImportance(facts []CompressibleFact) []Compres
 
 	for i := 0; i < len(sorted); i++ {
 		for j := i + 1; j < len(sorted); j++ {
-			if sorted[i].Importance < sorted[j].Importance ||
-				(sorted[i].Importance == sorted[j].Importance && sorted[i].Created.Before(sorted[j].Created)) {
+			if sorted[i].Verified != sorted[j].Verified {
+				if sorted[j].Verified {
+					sorted[i], sorted[j] = sorted[j], sorted[i]
+				}
+				continue
+			}
+			if sorted[i].Rank() < sorted[j].Rank() ||
+				(sorted[i].Rank() == sorted[j].Rank() && sorted[i].Created.Before(sorted[j].Created)) {
 				sorted[i], sorted[j] = sorted[j], sorted[i]
 			}
 		}
@@ -0,0 +1,98 @@
+package smart
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportanceFloors(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	s := NewImportanceScorer()
+
+	b := s.Explain("blocker", "error: x", old)
+	if b.Score != 4 || b.Floor != 4 {
+		t.Errorf("terse blocker = %s, want its floor of 4", b)
+	}
+	if b.Raw < 4 {
+		t.Errorf("terse blocker raw score %.2f, want at least the floor", b.Raw)
+	}
+	if got := s.CalculateImportance("decision", "ok", old); got != 3 {
+		t.Errorf("terse decision scored %d, want its floor of 3", got)
+	}
+	if got := s.CalculateImportance("test_status", "FAIL: x", old); got != 4 {
+		t.Errorf("terse test failure scored %d, want the blocker floor of 4", got)
+	}
+
+	// Without the floor the same blocker scores lower
+	s.SetFloor("blocker", 0)
+	if got := s.CalculateImportance("blocker", "error: x", old); got >= 4 {
+		t.Errorf("unfloored terse blocker scored %d, want below 4", got)
+	}
+
+	// Floors are configurable per type
+	s.SetFloor("todo", 5)
+	if got := s.CalculateImportance("todo", "x", old); got != 5 {
+		t.Errorf("todo with floor 5 scored %d", got)
+	}
+}
+
+func TestCompressorRanksByFloatImportance(t *testing.T) {
+	now := time.Now()
+	facts := []CompressibleFact{
+		{Type: "todo", Content: "Tidy the README", Importance: 3, Score: 2.6, Created: now},
+		{Type: "todo", Content: "Add integration tests", Importance: 3, Score: 3.4, Created: now.Add(-time.Hour)},
+		{Type: "todo", Content: "Bump dependencies", Importance: 3, Created: now.Add(-2 * time.Hour)},
+	}
+
+	// Both round to 3; the higher score wins despite being older
+	compressed := NewContextCompressor(1).Compress(facts[:2])
+	if len(compressed) != 1 || compressed[0].Content != "Add integration tests" {
+		t.Errorf("compressed to %+v, want the todo scoring 3.4", compressed)
+	}
+
+	// Without a score a fact ranks by its importance, between the two
+	sorted := NewContextCompressor(10).sortByImportance(facts)
+	var got []float64
+	for _, fact := range sorted {
+		got = append(got, fact.Rank())
+	}
+	if want := []float64{3.4, 3, 2.6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ranks in order = %v, want %v", got, want)
+	}
+}
+
+func TestTestFailuresScoreAsBlockers(t *testing.T) {
+	now := time.Now()
+	s := NewImportanceScorer()
+
+	failing := s.Explain("test_status", "CI is red on main", now)
+	blocker := s.Explain("blocker", "CI is red on main", now)
+	if failing.Type != blocker.Type || failing.Score != blocker.Score {
+		t.Errorf("failing test status = %s, want it scored like the blocker %s", failing, blocker)
+	}
+
+	passing := s.Explain("test_status", "CI is green on main", now)
+	if passing.Score >= failing.Score {
+		t.Errorf("passing test status scored %d, want below the failure's %d", passing.Score, failing.Score)
+	}
+}
+
+func TestCompressorKeepsVerifiedFirst(t *testing.T) {
+	now := time.Now()
+	facts := []CompressibleFact{
+		{Type: "decision", Content: "Use Redis for the cache", Importance: 5, Created: now},
+		{Type: "decision", Content: "Use Postgres for sessions", Importance: 3, Created: now.Add(-time.Hour), Verified: true},
+		{Type: "decision", Content: "Use gRPC internally", Importance: 4, Created: now},
+	}
+
+	// A verified fact outranks more important unverified ones
+	compressed := NewContextCompressor(2).Compress(facts)
+	var got []string
+	for _, fact := range compressed {
+		got = append(got, fact.Content)
+	}
+	if want := []string{"Use Postgres for sessions", "Use Redis for the cache"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("compressed to %q, want %q", got, want)
+	}
+}
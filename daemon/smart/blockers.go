@@ -0,0 +1,113 @@
+package smart
+
+import (
+	"strings"
+	"time"
+)
+
+// resolutionKeywords indicate that a later message reports a fix
+var resolutionKeywords = []string{"resolved", "fixed", "unblocked", "works now", "no longer blocked", "passing", "green"}
+
+// minSharedWords is how many significant words a resolution must share with a
+// blocker before it is considered to be about the same problem
+const minSharedWords = 2
+
+// ResolvedBlocker records how long a blocker stayed open
+type ResolvedBlocker struct {
+	Content    string
+	OpenedAt   time.Time
+	ResolvedAt time.Time
+}
+
+// BlockedFor returns the time between the blocker appearing and its resolution
+func (r ResolvedBlocker) BlockedFor() time.Duration {
+	return r.ResolvedAt.Sub(r.OpenedAt)
+}
+
+// BlockerTracker remembers when blockers were first seen so the blocked time
+// can be measured once a later message resolves them
+type BlockerTracker struct {
+	open     map[string]openBlocker
+	resolved map[string]bool
+}
+
+type openBlocker struct {
+	content  string
+	openedAt time.Time
+}
+
+func NewBlockerTracker() *BlockerTracker {
+	return &BlockerTracker{
+		open:     make(map[string]openBlocker),
+		resolved: make(map[string]bool),
+	}
+}
+
+// Open starts tracking a blocker. Re-opening a blocker keeps its original
+// time, and blockers that were already resolved are not reopened.
+func (t *BlockerTracker) Open(content string, at time.Time) {
+	key := strings.ToLower(strings.TrimSpace(content))
+	if key == "" || t.resolved[key] {
+		return
+	}
+	if _, ok := t.open[key]; !ok {
+		t.open[key] = openBlocker{content: content, openedAt: at}
+	}
+}
+
+// Resolve closes every open blocker that text reports as fixed and returns
+// them with their blocked duration
+func (t *BlockerTracker) Resolve(text string, at time.Time) []ResolvedBlocker {
+	lower := strings.ToLower(text)
+	if !containsAnyKeyword(lower, resolutionKeywords) {
+		return nil
+	}
+
+	words := significantWords(lower)
+	var resolved []ResolvedBlocker
+	for key, blocker := range t.open {
+		if sharedWords(words, significantWords(key)) < minSharedWords {
+			continue
+		}
+		resolved = append(resolved, ResolvedBlocker{
+			Content:    blocker.content,
+			OpenedAt:   blocker.openedAt,
+			ResolvedAt: at,
+		})
+		delete(t.open, key)
+		t.resolved[key] = true
+	}
+	return resolved
+}
+
+func containsAnyKeyword(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// significantWords returns the distinct words of four or more letters
+func significantWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_')
+	}) {
+		if len(word) >= 4 {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+func sharedWords(a, b map[string]bool) int {
+	count := 0
+	for word := range a {
+		if b[word] {
+			count++
+		}
+	}
+	return count
+}
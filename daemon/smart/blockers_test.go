@@ -0,0 +1,47 @@
+package smart
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockerTrackerMeasuresBlockedTime(t *testing.T) {
+	opened := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewBlockerTracker()
+	tracker.Open("Blocked by the flaky payment webhook tests", opened)
+	tracker.Open("Error: staging database unreachable", opened.Add(time.Minute))
+
+	// A fix for something else resolves nothing
+	if resolved := tracker.Resolve("Fixed the typo in the README", opened.Add(time.Hour)); len(resolved) != 0 {
+		t.Errorf("unrelated fix resolved %+v", resolved)
+	}
+
+	resolved := tracker.Resolve("The payment webhook tests are passing now", opened.Add(2*time.Hour))
+	if len(resolved) != 1 {
+		t.Fatalf("resolved %d blockers, want 1", len(resolved))
+	}
+	if resolved[0].Content != "Blocked by the flaky payment webhook tests" {
+		t.Errorf("resolved %q, want the webhook blocker", resolved[0].Content)
+	}
+	if got := resolved[0].BlockedFor(); got != 2*time.Hour {
+		t.Errorf("blocked for %s, want 2h", got)
+	}
+
+	// A resolved blocker isn't opened again, nor resolved twice
+	tracker.Open("Blocked by the flaky payment webhook tests", opened.Add(3*time.Hour))
+	if again := tracker.Resolve("payment webhook tests fixed", opened.Add(4*time.Hour)); len(again) != 0 {
+		t.Errorf("resolved blocker was reopened: %+v", again)
+	}
+}
+
+func TestBlockerTrackerKeepsFirstOpenedTime(t *testing.T) {
+	opened := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewBlockerTracker()
+	tracker.Open("Error: staging database unreachable", opened)
+	tracker.Open("error: Staging database unreachable ", opened.Add(time.Hour))
+
+	resolved := tracker.Resolve("staging database resolved", opened.Add(2*time.Hour))
+	if len(resolved) != 1 || resolved[0].BlockedFor() != 2*time.Hour {
+		t.Errorf("resolved %+v, want one blocker open for 2h", resolved)
+	}
+}
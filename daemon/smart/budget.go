@@ -0,0 +1,61 @@
+package smart
+
+import "sort"
+
+// carryPriority orders fact types when carrying context into a new session:
+// open blockers and decisions are the costliest to lose, then outstanding
+// work. Other types come after these.
+var carryPriority = map[string]int{
+	"blocker":     0,
+	"decision":    1,
+	"test_status": 2,
+	"todo":        3,
+}
+
+// FitBudget picks the facts worth carrying into the next session that fit
+// within budget tokens, costing each fact with tokens. The facts are first
+// compressed, then taken blockers first, then decisions, test status, todos,
+// and the rest, each by verification and importance. A fact too large for
+// the remaining budget is skipped in favor of smaller ones after it. The
+// result is in that priority order.
+func (c *ContextCompressor) FitBudget(facts []CompressibleFact, budget int, tokens func(CompressibleFact) int) []CompressibleFact {
+	candidates := c.Compress(facts)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if pa, pb := typePriority(a.Type), typePriority(b.Type); pa != pb {
+			return pa < pb
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Verified != b.Verified {
+			return a.Verified
+		}
+		if a.Rank() != b.Rank() {
+			return a.Rank() > b.Rank()
+		}
+		if !a.Created.Equal(b.Created) {
+			return a.Created.After(b.Created)
+		}
+		return a.Content < b.Content
+	})
+
+	var picked []CompressibleFact
+	used := 0
+	for _, fact := range candidates {
+		cost := tokens(fact)
+		if used+cost > budget {
+			continue
+		}
+		picked = append(picked, fact)
+		used += cost
+	}
+	return picked
+}
+
+func typePriority(factType string) int {
+	if priority, ok := carryPriority[factType]; ok {
+		return priority
+	}
+	return len(carryPriority)
+}
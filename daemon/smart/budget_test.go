@@ -0,0 +1,65 @@
+package smart
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFitBudgetPrioritizesBlockersAndDecisions(t *testing.T) {
+	now := time.Now()
+	facts := []CompressibleFact{
+		{Type: "insight", Content: "The cache hit rate is 92%", Importance: 5, Created: now},
+		{Type: "todo", Content: "Add migrations", Importance: 4, Created: now},
+		{Type: "decision", Content: "Use Postgres", Importance: 3, Created: now},
+		{Type: "decision", Content: "Shard the session store by tenant once it passes a million rows", Importance: 5, Created: now},
+		{Type: "blocker", Content: "CI is red on main", Importance: 4, Created: now},
+		{Type: "file_change", Content: "Updated server.go", Importance: 2, Created: now},
+	}
+	// A token per word keeps the arithmetic readable
+	tokens := func(fact CompressibleFact) int {
+		words := 1
+		for _, r := range fact.Content {
+			if r == ' ' {
+				words++
+			}
+		}
+		return words
+	}
+
+	picked := NewContextCompressor(10).FitBudget(facts, 12, tokens)
+
+	var got []string
+	used := 0
+	for _, fact := range picked {
+		got = append(got, fact.Content)
+		used += tokens(fact)
+	}
+	if used > 12 {
+		t.Errorf("carried %d tokens, over the budget of 12", used)
+	}
+	// The blocker comes first. The long decision (12 words) doesn't fit
+	// after it, so the short one does, then the todo; the insight is too
+	// large for what's left and the file change fits
+	want := []string{"CI is red on main", "Use Postgres", "Add migrations", "Updated server.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("carried %q, want %q", got, want)
+	}
+
+	// With room for everything, all of it is carried in priority order
+	picked = NewContextCompressor(10).FitBudget(facts, 1000, tokens)
+	var types []string
+	for _, fact := range picked {
+		types = append(types, fact.Type)
+	}
+	if want := []string{"blocker", "decision", "decision", "todo", "file_change", "insight"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("carried types %q, want %q", types, want)
+	}
+	if picked[1].Content != "Shard the session store by tenant once it passes a million rows" {
+		t.Errorf("first decision %q, want the more important one", picked[1].Content)
+	}
+
+	if picked := NewContextCompressor(10).FitBudget(facts, 0, tokens); len(picked) != 0 {
+		t.Errorf("carried %d facts in a zero budget", len(picked))
+	}
+}
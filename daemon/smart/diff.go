@@ -2,6 +2,7 @@ package smart
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -15,6 +16,9 @@ type SessionSnapshot struct {
 	Facts       []CompressibleFact
 	TokenCount  int
 	FileChanges []string
+	// FactsUnavailable marks sessions recorded before facts were tracked, so
+	// an empty Facts slice isn't mistaken for "every fact was resolved"
+	FactsUnavailable bool
 }
 
 type Diff struct {
@@ -23,6 +27,9 @@ type Diff struct {
 	Modified   []CompressibleFact
 	Summary    string
 	TokenDelta int
+	// FactlessSessions lists compared sessions that had no fact data; when
+	// non-empty only the token comparison is meaningful
+	FactlessSessions []string
 }
 
 func NewDiffGenerator() *DiffGenerator {
@@ -35,18 +42,28 @@ func (d *DiffGenerator) GenerateDiff(previous, current SessionSnapshot) Diff {
 		TokenDelta: current.TokenCount - previous.TokenCount,
 	}
 
+	for _, snapshot := range []SessionSnapshot{previous, current} {
+		if snapshot.FactsUnavailable {
+			diff.FactlessSessions = append(diff.FactlessSessions, snapshot.SessionID)
+		}
+	}
+
+	// Without facts on both sides a fact-level comparison would be misleading
+	if len(diff.FactlessSessions) > 0 {
+		diff.Summary = d.generateSummary(diff)
+		return diff
+	}
+
 	// Create maps for quick lookup
 	prevMap := make(map[string]CompressibleFact)
 	currMap := make(map[string]CompressibleFact)
 
 	for _, fact := range previous.Facts {
-		key := fact.Type + ":" + fact.Content
-		prevMap[key] = fact
+		prevMap[FactKey(fact.Type, fact.Content)] = fact
 	}
 
 	for _, fact := range current.Facts {
-		key := fact.Type + ":" + fact.Content
-		currMap[key] = fact
+		currMap[FactKey(fact.Type, fact.Content)] = fact
 	}
 
 	// Find added facts
@@ -63,12 +80,31 @@ func (d *DiffGenerator) GenerateDiff(previous, current SessionSnapshot) Diff {
 		}
 	}
 
+	// Map iteration order is random, so sort for stable output
+	sortDiffFacts(diff.Added)
+	sortDiffFacts(diff.Removed)
+	sortDiffFacts(diff.Modified)
+
 	// Generate summary
 	diff.Summary = d.generateSummary(diff)
 
 	return diff
 }
 
+// sortDiffFacts orders facts by type, then most important first, then by
+// content
+func sortDiffFacts(facts []CompressibleFact) {
+	sort.Slice(facts, func(i, j int) bool {
+		if facts[i].Type != facts[j].Type {
+			return facts[i].Type < facts[j].Type
+		}
+		if facts[i].Rank() != facts[j].Rank() {
+			return facts[i].Rank() > facts[j].Rank()
+		}
+		return facts[i].Content < facts[j].Content
+	})
+}
+
 func (d *DiffGenerator) generateSummary(diff Diff) string {
 	var parts []string
 
@@ -87,10 +123,17 @@ func (d *DiffGenerator) generateSummary(diff Diff) string {
 	}
 
 	if len(parts) == 0 {
+		if len(diff.FactlessSessions) > 0 {
+			return "No token change (fact data unavailable)"
+		}
 		return "No significant changes"
 	}
 
-	return strings.Join(parts, ", ")
+	summary := strings.Join(parts, ", ")
+	if len(diff.FactlessSessions) > 0 {
+		summary += " (fact data unavailable)"
+	}
+	return summary
 }
 
 // FormatDiff creates a markdown representation of the diff
@@ -102,6 +145,11 @@ func (d *DiffGenerator) FormatDiff(diff Diff, previous, current SessionSnapshot)
 	md.WriteString(fmt.Sprintf("**Current**: %s (%s)\n\n", current.SessionID, current.Timestamp.Format(time.RFC3339)))
 	md.WriteString(fmt.Sprintf("**Summary**: %s\n\n", diff.Summary))
 
+	if len(diff.FactlessSessions) > 0 {
+		md.WriteString(fmt.Sprintf("> No fact data recorded for: %s. Showing token comparison only.\n\n",
+			strings.Join(diff.FactlessSessions, ", ")))
+	}
+
 	if len(diff.Added) > 0 {
 		md.WriteString("## ➕ Added Facts\n\n")
 		for _, fact := range diff.Added {
@@ -0,0 +1,183 @@
+package smart
+
+import (
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionComparison is one diff between consecutive sessions in a report
+type SessionComparison struct {
+	Previous SessionSnapshot
+	Current  SessionSnapshot
+	Diff     Diff
+}
+
+// htmlReport is the data rendered by diffHTMLTemplate
+type htmlReport struct {
+	Title       string
+	Generated   time.Time
+	Comparisons []SessionComparison
+	Tokens      []tokenBar
+}
+
+// tokenBar is one session's bar in the report's token chart
+type tokenBar struct {
+	Label   string
+	Tokens  int
+	Percent float64
+}
+
+var diffHTMLTemplate = template.Must(template.New("diff").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string {
+		if t.IsZero() {
+			return "unknown time"
+		}
+		return t.Local().Format("Jan 2, 2006 3:04 PM")
+	},
+	"byImportance": factsByImportance,
+	"join":         strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+h1 { border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; }
+.meta, .note { color: #59636e; }
+.comparison { border: 1px solid #d0d7de; border-radius: 6px; padding: 0 1rem 1rem; margin: 1.5rem 0; }
+.summary { font-weight: 600; }
+ul.facts { list-style: none; padding: 0; }
+ul.facts li { padding: .25rem .5rem; margin: .15rem 0; border-radius: 4px; }
+.added li { background: #dafbe1; }
+.removed li { background: #ffebe9; }
+.modified li { background: #fff8c5; }
+.type { font-family: ui-monospace, Menlo, monospace; font-size: .85em; color: #59636e; }
+.chart { margin: 1rem 0 2rem; }
+.bar-row { display: flex; align-items: center; margin: .3rem 0; }
+.bar-label { width: 12rem; font-size: .85em; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.bar { background: #54aeff; height: 1rem; border-radius: 3px; min-width: 2px; }
+.bar-value { margin-left: .5rem; font-size: .85em; color: #59636e; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Generated {{timestamp .Generated}}</p>
+{{- if .Tokens}}
+<h2>Token Usage</h2>
+<div class="chart">
+{{- range .Tokens}}
+<div class="bar-row"><span class="bar-label" title="{{.Label}}">{{.Label}}</span><div class="bar" style="width: {{printf "%.1f" .Percent}}%"></div><span class="bar-value">{{.Tokens}}</span></div>
+{{- end}}
+</div>
+{{- end}}
+{{- range .Comparisons}}
+<section class="comparison">
+<h2>{{.Current.SessionID}}</h2>
+<p class="meta">Compared with {{.Previous.SessionID}} ({{timestamp .Previous.Timestamp}}) &rarr; {{timestamp .Current.Timestamp}}</p>
+<p class="summary">{{.Diff.Summary}}</p>
+{{- if .Diff.FactlessSessions}}
+<p class="note">No fact data recorded for: {{join .Diff.FactlessSessions ", "}}. Showing token comparison only.</p>
+{{- end}}
+{{- if .Diff.Added}}
+<h3>Added Facts</h3>
+<ul class="facts added">
+{{- range byImportance .Diff.Added}}
+<li><span class="type">[{{.Type}}]</span> {{.Content}} <span class="type">(importance: {{.Importance}})</span></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Diff.Removed}}
+<h3>Removed/Resolved Facts</h3>
+<ul class="facts removed">
+{{- range byImportance .Diff.Removed}}
+<li><span class="type">[{{.Type}}]</span> {{.Content}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Diff.Modified}}
+<h3>Modified Facts</h3>
+<ul class="facts modified">
+{{- range byImportance .Diff.Modified}}
+<li><span class="type">[{{.Type}}]</span> {{.Content}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Diff.TokenDelta}}
+<p class="meta">Token change: {{printf "%+d" .Diff.TokenDelta}}</p>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// FormatHTML renders comparisons as a standalone HTML page, with styles
+// inline so the file can be shared on its own. The token chart shows each
+// session compared, oldest first.
+func (d *DiffGenerator) FormatHTML(title string, comparisons []SessionComparison) (string, error) {
+	report := htmlReport{
+		Title:       title,
+		Generated:   time.Now(),
+		Comparisons: comparisons,
+		Tokens:      tokenBars(comparisons),
+	}
+
+	var out strings.Builder
+	if err := diffHTMLTemplate.Execute(&out, report); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// tokenBars lists each distinct session in the comparisons, oldest first,
+// sized relative to the largest token count
+func tokenBars(comparisons []SessionComparison) []tokenBar {
+	seen := make(map[string]bool)
+	var sessions []SessionSnapshot
+	for _, c := range comparisons {
+		for _, snapshot := range []SessionSnapshot{c.Previous, c.Current} {
+			if !seen[snapshot.SessionID] {
+				seen[snapshot.SessionID] = true
+				sessions = append(sessions, snapshot)
+			}
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Timestamp.Before(sessions[j].Timestamp)
+	})
+
+	peak := 0
+	for _, s := range sessions {
+		if s.TokenCount > peak {
+			peak = s.TokenCount
+		}
+	}
+	if peak == 0 {
+		return nil
+	}
+
+	bars := make([]tokenBar, 0, len(sessions))
+	for _, s := range sessions {
+		bars = append(bars, tokenBar{
+			Label:   s.SessionID,
+			Tokens:  s.TokenCount,
+			Percent: float64(s.TokenCount) / float64(peak) * 100,
+		})
+	}
+	return bars
+}
+
+// factsByImportance returns a copy of facts, most important first
+func factsByImportance(facts []CompressibleFact) []CompressibleFact {
+	sorted := append([]CompressibleFact(nil), facts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Rank() != sorted[j].Rank() {
+			return sorted[i].Rank() > sorted[j].Rank()
+		}
+		return sorted[i].Content < sorted[j].Content
+	})
+	return sorted
+}
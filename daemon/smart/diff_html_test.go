@@ -0,0 +1,62 @@
+package smart
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// generatedLine is the report line holding the time it was rendered
+var generatedLine = regexp.MustCompile(`<p class="meta">Generated [^<]*</p>`)
+
+func TestFormatHTMLGolden(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s1 := SessionSnapshot{
+		SessionID:  "s1",
+		Timestamp:  at,
+		TokenCount: 4000,
+		Facts: []CompressibleFact{
+			{Type: "decision", Content: "Use Postgres", Importance: 4},
+			{Type: "blocker", Content: "CI is red on main", Importance: 5},
+		},
+	}
+	s2 := SessionSnapshot{
+		SessionID:  "s2",
+		Timestamp:  at.Add(3 * time.Hour),
+		TokenCount: 8000,
+		Facts: []CompressibleFact{
+			{Type: "decision", Content: "Use Postgres", Importance: 4},
+			{Type: "todo", Content: "Keep p99 latency < 200ms", Importance: 3},
+			{Type: "decision", Content: "Cache sessions in Redis", Importance: 4},
+		},
+	}
+	s3 := SessionSnapshot{SessionID: "s3", Timestamp: at.Add(26 * time.Hour), TokenCount: 2000, FactsUnavailable: true}
+
+	generator := NewDiffGenerator()
+	html, err := generator.FormatHTML("Changes in app", []SessionComparison{
+		{Previous: s1, Current: s2, Diff: generator.GenerateDiff(s1, s2)},
+		{Previous: s2, Current: s3, Diff: generator.GenerateDiff(s2, s3)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !generatedLine.MatchString(html) {
+		t.Fatalf("report has no generated time:\n%s", html)
+	}
+	got := generatedLine.ReplaceAllString(html, `<p class="meta">Generated GENERATED</p>`)
+
+	want, err := os.ReadFile(filepath.Join("testdata", "golden", "diff_report.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("HTML report:\n%s\nwant:\n%s", got, want)
+	}
+}
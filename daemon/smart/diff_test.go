@@ -0,0 +1,107 @@
+package smart
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateDiffWithFactlessSession(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rich := SessionSnapshot{
+		SessionID:  "s1",
+		Timestamp:  at,
+		TokenCount: 1000,
+		Facts: []CompressibleFact{
+			{Type: "decision", Content: "Use Postgres", Importance: 4},
+			{Type: "blocker", Content: "CI is red", Importance: 5},
+		},
+	}
+	factless := SessionSnapshot{
+		SessionID:        "s2",
+		Timestamp:        at.Add(time.Hour),
+		TokenCount:       1500,
+		FactsUnavailable: true,
+	}
+
+	generator := NewDiffGenerator()
+	diff := generator.GenerateDiff(rich, factless)
+
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("got %d added and %d removed facts, want none against a fact-less session", len(diff.Added), len(diff.Removed))
+	}
+	if want := []string{"s2"}; !reflect.DeepEqual(diff.FactlessSessions, want) {
+		t.Errorf("FactlessSessions = %v, want %v", diff.FactlessSessions, want)
+	}
+	if diff.TokenDelta != 500 {
+		t.Errorf("TokenDelta = %d, want 500", diff.TokenDelta)
+	}
+	if want := "+500 tokens (fact data unavailable)"; diff.Summary != want {
+		t.Errorf("Summary = %q, want %q", diff.Summary, want)
+	}
+
+	md := generator.FormatDiff(diff, rich, factless)
+	for _, want := range []string{"> No fact data recorded for: s2. Showing token comparison only.", "Change: +500 tokens"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("FormatDiff output lacks %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Removed/Resolved Facts") {
+		t.Errorf("FormatDiff reported the fact-less session's facts as resolved:\n%s", md)
+	}
+
+	// Between two sessions with facts the fact-level diff is shown in full
+	current := SessionSnapshot{
+		SessionID:  "s3",
+		Timestamp:  at.Add(2 * time.Hour),
+		TokenCount: 1000,
+		Facts: []CompressibleFact{
+			{Type: "decision", Content: "Use Postgres", Importance: 4},
+			{Type: "todo", Content: "Add migrations", Importance: 3},
+		},
+	}
+	diff = generator.GenerateDiff(rich, current)
+	if len(diff.FactlessSessions) != 0 {
+		t.Errorf("FactlessSessions = %v, want none", diff.FactlessSessions)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Content != "Add migrations" {
+		t.Errorf("Added = %+v, want the todo", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Content != "CI is red" {
+		t.Errorf("Removed = %+v, want the blocker", diff.Removed)
+	}
+	if want := "1 new facts, 1 resolved"; diff.Summary != want {
+		t.Errorf("Summary = %q, want %q", diff.Summary, want)
+	}
+}
+
+func TestFormatDiffIsDeterministic(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	previous := SessionSnapshot{SessionID: "s1", Timestamp: at, TokenCount: 1000}
+	current := SessionSnapshot{SessionID: "s2", Timestamp: at.Add(time.Hour), TokenCount: 2000}
+	for i := 0; i < 12; i++ {
+		previous.Facts = append(previous.Facts, CompressibleFact{Type: []string{"todo", "blocker"}[i%2], Content: fmt.Sprintf("Old fact %02d", i), Importance: 1 + i%5})
+		current.Facts = append(current.Facts, CompressibleFact{Type: []string{"decision", "todo", "insight"}[i%3], Content: fmt.Sprintf("New fact %02d", i), Importance: 1 + i%4})
+	}
+
+	generator := NewDiffGenerator()
+	diff := generator.GenerateDiff(previous, current)
+	want := generator.FormatDiff(diff, previous, current)
+
+	// Added facts are ordered by type, then importance, then content
+	for i := 1; i < len(diff.Added); i++ {
+		a, b := diff.Added[i-1], diff.Added[i]
+		if a.Type > b.Type || (a.Type == b.Type && (a.Importance < b.Importance || (a.Importance == b.Importance && a.Content > b.Content))) {
+			t.Errorf("added facts out of order: %+v before %+v", a, b)
+		}
+	}
+
+	for run := 0; run < 50; run++ {
+		diff := generator.GenerateDiff(previous, current)
+		if got := generator.FormatDiff(diff, previous, current); got != want {
+			t.Fatalf("run %d formatted differently:\n%s\nwant:\n%s", run, got, want)
+		}
+	}
+}
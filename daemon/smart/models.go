@@ -0,0 +1,50 @@
+package smart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ModelContextWindows maps model names to their context window in tokens
+var ModelContextWindows = map[string]int{
+	"opus":      200000,
+	"sonnet":    200000,
+	"haiku":     200000,
+	"sonnet-1m": 1000000,
+}
+
+// ContextWindow returns a model's context window. Besides the short names
+// in ModelContextWindows, full model IDs such as "claude-sonnet-4-5" are
+// recognized by the family they contain.
+func ContextWindow(model string) (int, error) {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if window, ok := ModelContextWindows[name]; ok {
+		return window, nil
+	}
+	for _, family := range []string{"opus", "sonnet", "haiku"} {
+		if strings.Contains(name, family) {
+			return ModelContextWindows[family], nil
+		}
+	}
+
+	names := make([]string, 0, len(ModelContextWindows))
+	for name := range ModelContextWindows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return 0, fmt.Errorf("unknown model %q (known: %s)", model, strings.Join(names, ", "))
+}
+
+// CompactThresholdFor returns the compact threshold at fraction of a
+// model's context window
+func CompactThresholdFor(model string, fraction float64) (int, error) {
+	if fraction <= 0 || fraction > 1 {
+		return 0, fmt.Errorf("compact fraction must be between 0 and 1, got %g", fraction)
+	}
+	window, err := ContextWindow(model)
+	if err != nil {
+		return 0, err
+	}
+	return int(float64(window) * fraction), nil
+}
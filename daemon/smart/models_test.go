@@ -0,0 +1,37 @@
+package smart
+
+import "testing"
+
+func TestCompactThresholdFor(t *testing.T) {
+	tests := []struct {
+		model    string
+		fraction float64
+		want     int
+	}{
+		{"sonnet", 0.85, 170000},
+		{"Opus", 0.5, 100000},
+		{"sonnet-1m", 0.9, 900000},
+		{"claude-haiku-4-5", 0.75, 150000},
+	}
+	for _, tt := range tests {
+		got, err := CompactThresholdFor(tt.model, tt.fraction)
+		if err != nil {
+			t.Errorf("CompactThresholdFor(%q, %g): %v", tt.model, tt.fraction, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CompactThresholdFor(%q, %g) = %d, want %d", tt.model, tt.fraction, got, tt.want)
+		}
+	}
+}
+
+func TestCompactThresholdForRejectsBadInput(t *testing.T) {
+	if _, err := CompactThresholdFor("gpt-4", 0.85); err == nil {
+		t.Error("an unknown model was accepted")
+	}
+	for _, fraction := range []float64{0, -0.5, 1.5} {
+		if _, err := CompactThresholdFor("sonnet", fraction); err == nil {
+			t.Errorf("fraction %g was accepted", fraction)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package smart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeLevel sets how aggressively fact content is normalized before
+// facts are compared
+type NormalizeLevel int
+
+const (
+	// NormalizeExact compares content as written
+	NormalizeExact NormalizeLevel = iota
+	// NormalizeBasic ignores case, runs of whitespace, and trailing punctuation
+	NormalizeBasic
+	// NormalizeStem also reduces words to a rough stem, so "added tests" and
+	// "adds test" match
+	NormalizeStem
+)
+
+// normalization is shared by every fact comparison so the compressor, diffs,
+// and snapshot dedup always agree on which facts are the same
+var normalization = NormalizeBasic
+
+// SetNormalization changes the level used by NormalizeContent. It's meant to
+// be called once at startup, before facts are compared.
+func SetNormalization(level NormalizeLevel) {
+	normalization = level
+}
+
+// ParseNormalizeLevel parses "exact", "basic", or "stem"
+func ParseNormalizeLevel(s string) (NormalizeLevel, error) {
+	switch strings.ToLower(s) {
+	case "exact":
+		return NormalizeExact, nil
+	case "basic":
+		return NormalizeBasic, nil
+	case "stem":
+		return NormalizeStem, nil
+	}
+	return 0, fmt.Errorf("unknown normalization %q (want exact, basic, or stem)", s)
+}
+
+// NormalizeContent returns the form of content used to compare facts
+func NormalizeContent(content string) string {
+	return normalizeContent(content, normalization)
+}
+
+// FactKey identifies a fact for dedup and diffs
+func FactKey(factType, content string) string {
+	return factType + ":" + NormalizeContent(content)
+}
+
+func normalizeContent(content string, level NormalizeLevel) string {
+	if level == NormalizeExact {
+		return content
+	}
+
+	words := strings.Fields(strings.ToLower(content))
+	if len(words) > 0 {
+		last := len(words) - 1
+		words[last] = strings.TrimRight(words[last], ".!?,;:")
+		if words[last] == "" {
+			words = words[:last]
+		}
+	}
+
+	if level == NormalizeStem {
+		for i, word := range words {
+			words[i] = stem(word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// stemSuffixes are stripped longest first
+var stemSuffixes = []string{"ing", "ed", "es", "s"}
+
+// stem strips a common English suffix, keeping at least three letters
+func stem(word string) string {
+	for _, suffix := range stemSuffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
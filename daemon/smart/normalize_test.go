@@ -0,0 +1,69 @@
+package smart
+
+import "testing"
+
+func TestNormalizeContentLevels(t *testing.T) {
+	tests := []struct {
+		level NormalizeLevel
+		in    string
+		want  string
+	}{
+		{NormalizeExact, "  Use Postgres. ", "  Use Postgres. "},
+		{NormalizeBasic, "  Use   Postgres. ", "use postgres"},
+		{NormalizeBasic, "Fix the build!?", "fix the build"},
+		{NormalizeBasic, "Done .", "done"},
+		{NormalizeStem, "Added tests for caching", "add test for cach"},
+		{NormalizeStem, "Adds test for caches.", "add test for cach"},
+		{NormalizeStem, "Uses bus", "use bus"},
+	}
+	for _, tt := range tests {
+		if got := normalizeContent(tt.in, tt.level); got != tt.want {
+			t.Errorf("normalizeContent(%q, %d) = %q, want %q", tt.in, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizationConsistentAcrossConsumers(t *testing.T) {
+	defer SetNormalization(normalization)
+
+	// Each pair is the same fact at the levels listed, and different facts below them
+	variants := []struct {
+		first, second CompressibleFact
+		sameFrom      NormalizeLevel
+	}{
+		{
+			CompressibleFact{Type: "decision", Content: "Use Postgres", Importance: 3},
+			CompressibleFact{Type: "decision", Content: "use  postgres.", Importance: 4},
+			NormalizeBasic,
+		},
+		{
+			CompressibleFact{Type: "todo", Content: "Add tests for caching", Importance: 3},
+			CompressibleFact{Type: "todo", Content: "Added test for caches", Importance: 3},
+			NormalizeStem,
+		},
+	}
+
+	for _, level := range []NormalizeLevel{NormalizeExact, NormalizeBasic, NormalizeStem} {
+		SetNormalization(level)
+		for _, v := range variants {
+			same := level >= v.sameFrom
+
+			if keysMatch := FactKey(v.first.Type, v.first.Content) == FactKey(v.second.Type, v.second.Content); keysMatch != same {
+				t.Errorf("level %d: FactKey match for %q and %q = %v, want %v", level, v.first.Content, v.second.Content, keysMatch, same)
+			}
+
+			compressed := NewContextCompressor(10).Compress([]CompressibleFact{v.first, v.second})
+			if deduped := len(compressed) == 1; deduped != same {
+				t.Errorf("level %d: compressor kept %d of %q and %q", level, len(compressed), v.first.Content, v.second.Content)
+			}
+
+			diff := NewDiffGenerator().GenerateDiff(
+				SessionSnapshot{SessionID: "s1", Facts: []CompressibleFact{v.first}},
+				SessionSnapshot{SessionID: "s2", Facts: []CompressibleFact{v.second}},
+			)
+			if unchanged := len(diff.Added) == 0 && len(diff.Removed) == 0; unchanged != same {
+				t.Errorf("level %d: diff of %q to %q has %d added and %d removed", level, v.first.Content, v.second.Content, len(diff.Added), len(diff.Removed))
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package smart
+
+import (
+	"math"
+	"sort"
+)
+
+// ProjectBlockers is a project's open blocker count alongside its current priority
+type ProjectBlockers struct {
+	ProjectID string
+	Blockers  int
+	Priority  int
+}
+
+// PrioritizeByBlockers ranks projects by open blocker count and returns the
+// new priority for each project ID. Higher priority values sort first, so the
+// project with the most blockers gets the highest value (the project count)
+// and the one with the fewest gets 1. Ties keep their current relative order.
+// With blendCurrent, each result is the rounded average of the blocker-based
+// priority and the current one.
+func PrioritizeByBlockers(projects []ProjectBlockers, blendCurrent bool) map[string]int {
+	ranked := append([]ProjectBlockers(nil), projects...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Blockers != ranked[j].Blockers {
+			return ranked[i].Blockers > ranked[j].Blockers
+		}
+		return ranked[i].Priority > ranked[j].Priority
+	})
+
+	priorities := make(map[string]int, len(ranked))
+	for rank, project := range ranked {
+		priority := len(ranked) - rank
+		if blendCurrent {
+			priority = int(math.Round(float64(priority+project.Priority) / 2))
+		}
+		priorities[project.ProjectID] = priority
+	}
+	return priorities
+}
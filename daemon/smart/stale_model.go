@@ -0,0 +1,297 @@
+package smart
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// staleTrainingEpochs and staleLearningRate tune the gradient descent
+	staleTrainingEpochs = 2000
+	staleLearningRate   = 0.5
+	// staleValidationShare of the labels is held out to measure accuracy
+	staleValidationShare = 0.2
+	// minStaleLabels is the fewest labels worth training on
+	minStaleLabels = 10
+)
+
+// StaleFeatures names the model's inputs in coefficient order
+var StaleFeatures = []string{"age_days", "type_encoded", "importance", "content_length", "has_resolved_keyword"}
+
+// staleTypeCodes orders fact types from longest to shortest lived so the
+// encoding roughly tracks the default stale thresholds
+var staleTypeCodes = map[string]float64{
+	"decision":      0,
+	"insight":       1,
+	"dependency":    2,
+	"config_change": 2,
+	"file_change":   3,
+	"todo":          4,
+	"blocker":       5,
+	"test_status":   5,
+}
+
+// resolvedKeywords mark a fact as no longer needing attention
+var resolvedKeywords = []string{"resolved", "done", "fixed", "completed", "no longer"}
+
+// StaleLabel is a fact a user judged stale or fresh
+type StaleLabel struct {
+	FactID   string           `json:"fact_id"`
+	Fact     CompressibleFact `json:"fact"`
+	WasStale bool             `json:"was_stale"`
+	// LabeledAt is when the judgement was made; age is measured up to it.
+	// Zero means now.
+	LabeledAt time.Time `json:"labeled_at"`
+}
+
+// StaleModel is a logistic regression over StaleFeatures
+type StaleModel struct {
+	Coefficients []float64 `json:"coefficients"`
+	Intercept    float64   `json:"intercept"`
+	TrainedAt    time.Time `json:"trained_at"`
+	Samples      int       `json:"samples"`
+	// Accuracy is measured on labels held out of training
+	Accuracy float64 `json:"accuracy"`
+}
+
+// DefaultStaleModelPath is where the trained model is kept
+func DefaultStaleModelPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "ccd", "stale_model.json"), nil
+}
+
+// DefaultStaleLabelsPath is where labels collected for training are kept
+func DefaultStaleLabelsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "ccd", "stale_labels.jsonl"), nil
+}
+
+// AppendStaleLabel adds a label to the JSON lines file at path
+func AppendStaleLabel(path string, label StaleLabel) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(label)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadStaleLabels reads the labels at path. A fact labeled more than once
+// keeps only its latest label. A missing file has no labels.
+func LoadStaleLabels(path string) ([]StaleLabel, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []StaleLabel
+	index := make(map[string]int)
+	for n, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var label StaleLabel
+		if err := json.Unmarshal([]byte(line), &label); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+		if i, ok := index[label.FactID]; ok && label.FactID != "" {
+			labels[i] = label
+			continue
+		}
+		index[label.FactID] = len(labels)
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// LoadStaleModel reads a model saved by SaveStaleModel
+func LoadStaleModel(path string) (*StaleModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var model StaleModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(model.Coefficients) != len(StaleFeatures) {
+		return nil, fmt.Errorf("%s has %d coefficients, expected %d", path, len(model.Coefficients), len(StaleFeatures))
+	}
+	return &model, nil
+}
+
+// SaveStaleModel writes model to path, creating its directory
+func SaveStaleModel(path string, model *StaleModel) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Probability returns how likely a fact with these features is stale
+func (m *StaleModel) Probability(features []float64) float64 {
+	z := m.Intercept
+	for i, x := range features {
+		z += m.Coefficients[i] * x
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+// UseModel makes IsStale consult model instead of the per-type thresholds.
+// A nil model restores the thresholds.
+func (d *StaleDetector) UseModel(model *StaleModel) {
+	d.model = model
+}
+
+// Model returns the model in use, or nil
+func (d *StaleDetector) Model() *StaleModel {
+	return d.model
+}
+
+// TrainFromFeedback fits a model to labeled facts and starts using it. A
+// share of the labels is held out of training to measure the model's
+// accuracy.
+func (d *StaleDetector) TrainFromFeedback(labeled []StaleLabel) error {
+	if len(labeled) < minStaleLabels {
+		return fmt.Errorf("need at least %d labeled facts to train, have %d", minStaleLabels, len(labeled))
+	}
+
+	stale := 0
+	for _, label := range labeled {
+		if label.WasStale {
+			stale++
+		}
+	}
+	if stale == 0 || stale == len(labeled) {
+		return fmt.Errorf("labels must include both stale and fresh facts")
+	}
+
+	// Shuffle deterministically so retraining on the same labels gives the
+	// same model
+	shuffled := make([]StaleLabel, len(labeled))
+	copy(shuffled, labeled)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	holdout := int(math.Ceil(float64(len(shuffled)) * staleValidationShare))
+	validation, training := shuffled[:holdout], shuffled[holdout:]
+
+	inputs := make([][]float64, len(training))
+	for i, label := range training {
+		inputs[i] = staleFeatures(label.Fact, labelTime(label))
+	}
+
+	model := &StaleModel{
+		Coefficients: make([]float64, len(StaleFeatures)),
+		TrainedAt:    time.Now(),
+		Samples:      len(labeled),
+	}
+
+	// Batch gradient descent on the log loss
+	n := float64(len(training))
+	for epoch := 0; epoch < staleTrainingEpochs; epoch++ {
+		gradient := make([]float64, len(StaleFeatures))
+		interceptGradient := 0.0
+		for i, label := range training {
+			err := model.Probability(inputs[i]) - boolFloat(label.WasStale)
+			for j, x := range inputs[i] {
+				gradient[j] += err * x
+			}
+			interceptGradient += err
+		}
+		for j := range model.Coefficients {
+			model.Coefficients[j] -= staleLearningRate * gradient[j] / n
+		}
+		model.Intercept -= staleLearningRate * interceptGradient / n
+	}
+
+	correct := 0
+	for _, label := range validation {
+		predicted := model.Probability(staleFeatures(label.Fact, labelTime(label))) >= 0.5
+		if predicted == label.WasStale {
+			correct++
+		}
+	}
+	model.Accuracy = float64(correct) / float64(len(validation))
+
+	d.model = model
+	return nil
+}
+
+// staleFeatures scales a fact's features to roughly 0..1 so no single
+// feature dominates the gradient
+func staleFeatures(fact CompressibleFact, now time.Time) []float64 {
+	age := now.Sub(fact.Created).Hours() / 24
+	if age < 0 {
+		age = 0
+	}
+
+	typeCode, ok := staleTypeCodes[fact.Type]
+	if !ok {
+		typeCode = staleTypeCodes["dependency"]
+	}
+
+	return []float64{
+		math.Min(age/90, 2),
+		typeCode / 5,
+		float64(fact.Importance) / 5,
+		math.Min(float64(len(fact.Content))/500, 2),
+		boolFloat(hasResolvedKeyword(fact.Content)),
+	}
+}
+
+func hasResolvedKeyword(content string) bool {
+	lower := strings.ToLower(content)
+	for _, keyword := range resolvedKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func labelTime(label StaleLabel) time.Time {
+	if label.LabeledAt.IsZero() {
+		return time.Now()
+	}
+	return label.LabeledAt
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package smart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+)
+
+// summaryFactLength is how much of a fact SummarizeSession quotes
+const summaryFactLength = 80
+
+// SummarizeSession summarizes a ledger entry in a sentence from its facts:
+// the most important decision, the most important blocker still open, and
+// how many todos remain, such as "Top decision: use PocketBase; open
+// blocker: missing credentials; 3 todos remain." An entry with none of these falls
+// back to its file changes. Ties in importance go to the earlier fact, so
+// the same entry always gets the same summary.
+func SummarizeSession(entry *ledger.LedgerEntry) string {
+	resolved := make(map[string]bool)
+	for _, blocker := range entry.ResolvedBlockers {
+		resolved[normalizeSummaryFact(blocker.Content)] = true
+	}
+
+	var decisions, blockers []ledger.Fact
+	todos := 0
+	for _, fact := range entry.Facts {
+		switch fact.Type {
+		case "decision":
+			decisions = append(decisions, fact)
+		case "blocker":
+			if !resolved[normalizeSummaryFact(fact.Content)] {
+				blockers = append(blockers, fact)
+			}
+		case "todo":
+			todos++
+		}
+	}
+
+	var parts []string
+	if top, ok := topFact(decisions); ok {
+		parts = append(parts, "Top decision: "+summaryQuote(top.Content))
+	}
+	if top, ok := topFact(blockers); ok {
+		part := "open blocker: " + summaryQuote(top.Content)
+		if len(blockers) > 1 {
+			part += fmt.Sprintf(" (and %d more)", len(blockers)-1)
+		}
+		parts = append(parts, part)
+	}
+	switch {
+	case todos == 1:
+		parts = append(parts, "1 todo remains")
+	case todos > 1:
+		parts = append(parts, fmt.Sprintf("%d todos remain", todos))
+	}
+	if len(parts) == 0 && len(entry.FileChanges) > 0 {
+		parts = append(parts, fmt.Sprintf("Modified the codebase (%d file change(s))", len(entry.FileChanges)))
+	}
+	if len(parts) == 0 {
+		return "Continued development work."
+	}
+
+	summary := strings.Join(parts, "; ")
+	return strings.ToUpper(summary[:1]) + summary[1:] + "."
+}
+
+// topFact returns the most important of facts, the earliest among equals
+func topFact(facts []ledger.Fact) (ledger.Fact, bool) {
+	if len(facts) == 0 {
+		return ledger.Fact{}, false
+	}
+	sorted := append([]ledger.Fact(nil), facts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Rank() != b.Rank() {
+			return a.Rank() > b.Rank()
+		}
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.Content < b.Content
+	})
+	return sorted[0], true
+}
+
+// summaryQuote shortens a fact for the summary, dropping its closing
+// punctuation, which the summary supplies
+func summaryQuote(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	return strings.TrimRight(ledger.ClipFact(content, summaryFactLength), " .;:")
+}
+
+func normalizeSummaryFact(content string) string {
+	return strings.ToLower(strings.TrimSpace(content))
+}
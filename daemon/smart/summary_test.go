@@ -0,0 +1,64 @@
+package smart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/angelfreak/ccd/daemon/ledger"
+)
+
+func TestSummarizeSessionMentionsTopDecisionAndBlocker(t *testing.T) {
+	now := time.Now()
+	entry := &ledger.LedgerEntry{
+		Facts: []ledger.Fact{
+			{Type: "decision", Content: "Use SQLite for the test suite", Importance: 3, Timestamp: now},
+			{Type: "blocker", Content: "Flaky websocket test", Importance: 4, Timestamp: now},
+			{Type: "decision", Content: "Use Postgres for sessions.", Importance: 5, Timestamp: now.Add(time.Second)},
+			{Type: "blocker", Content: "Missing staging credentials", Importance: 5, Timestamp: now},
+			{Type: "blocker", Content: "CI is red on main", Importance: 5, Timestamp: now},
+			{Type: "todo", Content: "Add migrations", Importance: 3, Timestamp: now},
+			{Type: "todo", Content: "Write the runbook", Importance: 3, Timestamp: now},
+		},
+		// Resolved blockers don't count as open, however important
+		ResolvedBlockers: []ledger.ResolvedBlocker{{Content: "missing staging credentials"}},
+	}
+
+	want := "Top decision: Use Postgres for sessions; open blocker: CI is red on main (and 1 more); 2 todos remain."
+	if got := SummarizeSession(entry); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+
+	// Shuffling facts of equal rank and time doesn't change the summary
+	entry.Facts[3], entry.Facts[4] = entry.Facts[4], entry.Facts[3]
+	entry.ResolvedBlockers = nil
+	first := SummarizeSession(entry)
+	entry.Facts[3], entry.Facts[4] = entry.Facts[4], entry.Facts[3]
+	if second := SummarizeSession(entry); first != second {
+		t.Errorf("summary depends on fact order: %q vs %q", first, second)
+	}
+}
+
+func TestSummarizeSessionFallbacks(t *testing.T) {
+	tests := map[string]struct {
+		entry *ledger.LedgerEntry
+		want  string
+	}{
+		"one todo": {
+			&ledger.LedgerEntry{Facts: []ledger.Fact{{Type: "todo", Content: "Add migrations", Importance: 3}}},
+			"1 todo remains.",
+		},
+		"file changes only": {
+			&ledger.LedgerEntry{FileChanges: []string{"server.go", "db.go"}},
+			"Modified the codebase (2 file change(s)).",
+		},
+		"nothing": {
+			&ledger.LedgerEntry{},
+			"Continued development work.",
+		},
+	}
+	for name, tt := range tests {
+		if got := SummarizeSession(tt.entry); got != tt.want {
+			t.Errorf("%s: summary = %q, want %q", name, got, tt.want)
+		}
+	}
+}
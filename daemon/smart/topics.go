@@ -0,0 +1,98 @@
+package smart
+
+import "strings"
+
+// detailSeparator joins detail appended to an earlier fact
+const detailSeparator = "; "
+
+// TopicTracker remembers the facts posted this session so that a later fact
+// on the same topic can enrich the earlier one instead of becoming another
+// fact. A fact is on the same topic as an earlier one of its type when they
+// share at least minSharedWords significant words, and at least half of
+// those of the shorter fact.
+type TopicTracker struct {
+	maxLen int
+	byType map[string][]*TopicFact
+}
+
+// TopicFact is a posted fact as the tracker knows it
+type TopicFact struct {
+	ID         string
+	Type       string
+	Content    string
+	Importance int
+}
+
+// NewTopicTracker tracks topics, letting facts grow to maxLen bytes through
+// appended detail
+func NewTopicTracker(maxLen int) *TopicTracker {
+	return &TopicTracker{
+		maxLen: maxLen,
+		byType: make(map[string][]*TopicFact),
+	}
+}
+
+// Enrich finds the earlier fact that content is on the same topic as and
+// returns it updated: content either replaces it, when it restates and
+// extends it, or is appended to it, and the importance is raised to
+// importance if that's higher. The bool is false when there is no such
+// fact or the detail would take it past the length cap, in which case
+// content should be posted as a fact of its own. The tracker is unchanged
+// until Track records the update.
+func (t *TopicTracker) Enrich(factType, content string, importance int) (TopicFact, bool) {
+	words := significantWords(strings.ToLower(content))
+	if len(words) < minSharedWords {
+		return TopicFact{}, false
+	}
+
+	var best *TopicFact
+	bestShared := 0
+	// Later facts win ties, so detail goes to the most recent mention
+	for _, fact := range t.byType[factType] {
+		known := significantWords(strings.ToLower(fact.Content))
+		shared := sharedWords(words, known)
+		if shared < minSharedWords || shared*2 < min(len(words), len(known)) {
+			continue
+		}
+		if shared >= bestShared {
+			best, bestShared = fact, shared
+		}
+	}
+	if best == nil {
+		return TopicFact{}, false
+	}
+
+	updated := *best
+	updated.Importance = max(best.Importance, importance)
+	existing := strings.ToLower(best.Content)
+	added := strings.ToLower(content)
+	switch {
+	case strings.Contains(existing, added):
+		// Nothing new beyond a restatement
+	case strings.HasPrefix(added, existing):
+		updated.Content = content
+	default:
+		updated.Content = best.Content + detailSeparator + content
+	}
+	if len(updated.Content) > t.maxLen {
+		return TopicFact{}, false
+	}
+	return updated, true
+}
+
+// Track records a posted or enriched fact, replacing any earlier version
+// with the same ID
+func (t *TopicTracker) Track(fact TopicFact) {
+	for _, known := range t.byType[fact.Type] {
+		if known.ID == fact.ID {
+			*known = fact
+			return
+		}
+	}
+	t.byType[fact.Type] = append(t.byType[fact.Type], &fact)
+}
+
+// Reset forgets every tracked fact, as when a new session starts
+func (t *TopicTracker) Reset() {
+	t.byType = make(map[string][]*TopicFact)
+}
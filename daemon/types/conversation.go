@@ -4,10 +4,15 @@ import "time"
 
 type Conversation struct {
 	Messages []Message `json:"messages"`
+	// Metadata holds transcript header values such as model and cwd, keyed
+	// by lowercased name. Most transcripts have none.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 type Message struct {
 	Role      string    `json:"role"`
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
+	// Model is the model that wrote an assistant message, when recorded
+	Model string `json:"model,omitempty"`
 }
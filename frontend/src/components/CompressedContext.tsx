@@ -53,6 +53,8 @@ export default function CompressedContext({ projectId }: CompressedContextProps)
     file_change: 'File Changes',
     dependency: 'Dependencies',
     insight: 'Insights',
+    config_change: 'Config Changes',
+    test_status: 'Test & CI Status',
   };
 
   const factTypeColors: Record<string, string> = {
@@ -62,6 +64,8 @@ export default function CompressedContext({ projectId }: CompressedContextProps)
     file_change: 'bg-purple-100 text-purple-800',
     dependency: 'bg-green-100 text-green-800',
     insight: 'bg-indigo-100 text-indigo-800',
+    config_change: 'bg-orange-100 text-orange-800',
+    test_status: 'bg-teal-100 text-teal-800',
   };
 
   if (loading) {
@@ -1,7 +1,7 @@
 import { useState, useEffect } from 'react';
 import pb from '../lib/pocketbase';
 import { ExtractedFact, FactType } from '../types';
-import { AlertCircle, FileCode, Lightbulb, Ban, Package, CheckSquare } from 'lucide-react';
+import { AlertCircle, FileCode, Lightbulb, Ban, Package, CheckSquare, Settings, FlaskConical } from 'lucide-react';
 
 interface FactsListProps {
   projectId: string;
@@ -19,7 +19,7 @@ export default function FactsList({ projectId }: FactsListProps) {
     try {
       const records = await pb.collection('extracted_facts').getFullList<ExtractedFact>({
         filter: `project="${projectId}" && stale=false`,
-        sort: '-importance,-created',
+        sort: '-verified,-importance,-created',
       });
       setFacts(records);
     } catch (err) {
@@ -43,6 +43,10 @@ export default function FactsList({ projectId }: FactsListProps) {
         return <AlertCircle size={16} />;
       case 'insight':
         return <Lightbulb size={16} />;
+      case 'config_change':
+        return <Settings size={16} />;
+      case 'test_status':
+        return <FlaskConical size={16} />;
     }
   };
 
@@ -60,6 +64,10 @@ export default function FactsList({ projectId }: FactsListProps) {
         return 'bg-yellow-100 text-yellow-800';
       case 'insight':
         return 'bg-indigo-100 text-indigo-800';
+      case 'config_change':
+        return 'bg-orange-100 text-orange-800';
+      case 'test_status':
+        return 'bg-teal-100 text-teal-800';
     }
   };
 
@@ -91,6 +99,9 @@ export default function FactsList({ projectId }: FactsListProps) {
                   <span className="text-xs text-gray-400">
                     {'★'.repeat(fact.importance)}
                   </span>
+                  {fact.verified && (
+                    <span className="text-xs text-green-700">✓ verified</span>
+                  )}
                 </div>
                 <p className="text-sm text-gray-700">{fact.content}</p>
               </div>
@@ -2,7 +2,7 @@ export type ProjectStatus = 'active' | 'paused' | 'idea' | 'archived';
 
 export type SectionType = 'architecture' | 'current_state' | 'next_steps' | 'gotchas' | 'decisions' | 'custom';
 
-export type FactType = 'decision' | 'blocker' | 'file_change' | 'dependency' | 'todo' | 'insight';
+export type FactType = 'decision' | 'blocker' | 'file_change' | 'dependency' | 'todo' | 'insight' | 'config_change' | 'test_status';
 
 export interface Project {
   id: string;
@@ -48,5 +48,7 @@ export interface ExtractedFact {
   content: string;
   importance: number;
   stale: boolean;
+  pinned?: boolean;
+  verified?: boolean;
   created: string;
 }
@@ -0,0 +1,22 @@
+// Adds the list of contributing log files to session history records
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('session_history');
+
+  collection.schema.addField(new SchemaField({
+    name: 'source_files',
+    type: 'json',
+    required: false,
+  }));
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('session_history');
+  const field = collection.schema.getFieldByName('source_files');
+  if (field) {
+    collection.schema.removeField(field.id);
+  }
+  return dao.saveCollection(collection);
+});
@@ -0,0 +1,19 @@
+// Adds the config_change fact type
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+  const field = collection.schema.getFieldByName('fact_type');
+
+  field.options.values = [...field.options.values, 'config_change'];
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+  const field = collection.schema.getFieldByName('fact_type');
+
+  field.options.values = field.options.values.filter((value) => value !== 'config_change');
+
+  return dao.saveCollection(collection);
+});
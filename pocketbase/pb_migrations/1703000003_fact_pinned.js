@@ -0,0 +1,22 @@
+// Adds a pinned flag so facts can be exempted from retention cleanup
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+
+  collection.schema.addField(new SchemaField({
+    name: 'pinned',
+    type: 'bool',
+    required: false,
+  }));
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+  const field = collection.schema.getFieldByName('pinned');
+  if (field) {
+    collection.schema.removeField(field.id);
+  }
+  return dao.saveCollection(collection);
+});
@@ -0,0 +1,22 @@
+// Adds an embedding vector to facts for semantic search
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+
+  collection.schema.addField(new SchemaField({
+    name: 'embedding',
+    type: 'json',
+    required: false,
+  }));
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+  const field = collection.schema.getFieldByName('embedding');
+  if (field) {
+    collection.schema.removeField(field.id);
+  }
+  return dao.saveCollection(collection);
+});
@@ -0,0 +1,82 @@
+// Tracks the history of context sections: each push that changes a section
+// records its content in context_section_versions and its hash on the section
+migrate((db) => {
+  const dao = new Dao(db);
+  const sections = dao.findCollectionByNameOrId('context_sections');
+
+  sections.schema.addField(new SchemaField({
+    name: 'snapshot_hash',
+    type: 'text',
+    required: false,
+  }));
+  dao.saveCollection(sections);
+
+  const projects = dao.findCollectionByNameOrId('projects');
+  const sessions = dao.findCollectionByNameOrId('session_history');
+
+  const versions = new Collection({
+    name: 'context_section_versions',
+    type: 'base',
+    schema: [
+      {
+        name: 'section',
+        type: 'relation',
+        required: true,
+        options: {
+          collectionId: sections.id,
+          cascadeDelete: true,
+        },
+      },
+      {
+        name: 'project',
+        type: 'relation',
+        required: true,
+        options: {
+          collectionId: projects.id,
+          cascadeDelete: true,
+        },
+      },
+      {
+        name: 'session',
+        type: 'relation',
+        required: false,
+        options: {
+          collectionId: sessions.id,
+          cascadeDelete: false,
+        },
+      },
+      {
+        name: 'title',
+        type: 'text',
+        required: true,
+      },
+      {
+        name: 'content',
+        type: 'text',
+        required: false,
+      },
+      {
+        name: 'snapshot_hash',
+        type: 'text',
+        required: true,
+      },
+    ],
+    indexes: [
+      'CREATE INDEX idx_section_versions ON context_section_versions(section)',
+      'CREATE INDEX idx_project_section_versions ON context_section_versions(project)',
+    ],
+  });
+
+  return dao.saveCollection(versions);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  dao.deleteCollection('context_section_versions');
+
+  const sections = dao.findCollectionByNameOrId('context_sections');
+  const field = sections.schema.getFieldByName('snapshot_hash');
+  if (field) {
+    sections.schema.removeField(field.id);
+  }
+  return dao.saveCollection(sections);
+});
@@ -0,0 +1,33 @@
+// Adds the model and working directory read from transcript metadata to
+// session history records
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('session_history');
+
+  collection.schema.addField(new SchemaField({
+    name: 'model',
+    type: 'text',
+    required: false,
+  }));
+
+  collection.schema.addField(new SchemaField({
+    name: 'cwd',
+    type: 'text',
+    required: false,
+  }));
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('session_history');
+  const model = collection.schema.getFieldByName('model');
+  if (model) {
+    collection.schema.removeField(model.id);
+  }
+  const cwd = collection.schema.getFieldByName('cwd');
+  if (cwd) {
+    collection.schema.removeField(cwd.id);
+  }
+  return dao.saveCollection(collection);
+});
@@ -0,0 +1,33 @@
+// Adds the git branch and a user-chosen tag (e.g. a ticket) to session
+// history records, so sessions can be grouped by workstream
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('session_history');
+
+  collection.schema.addField(new SchemaField({
+    name: 'branch',
+    type: 'text',
+    required: false,
+  }));
+
+  collection.schema.addField(new SchemaField({
+    name: 'tag',
+    type: 'text',
+    required: false,
+  }));
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('session_history');
+  const branch = collection.schema.getFieldByName('branch');
+  if (branch) {
+    collection.schema.removeField(branch.id);
+  }
+  const tag = collection.schema.getFieldByName('tag');
+  if (tag) {
+    collection.schema.removeField(tag.id);
+  }
+  return dao.saveCollection(collection);
+});
@@ -0,0 +1,19 @@
+// Adds the test_status fact type for test and CI results
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+  const field = collection.schema.getFieldByName('fact_type');
+
+  field.options.values = [...field.options.values, 'test_status'];
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+  const field = collection.schema.getFieldByName('fact_type');
+
+  field.options.values = field.options.values.filter((value) => value !== 'test_status');
+
+  return dao.saveCollection(collection);
+});
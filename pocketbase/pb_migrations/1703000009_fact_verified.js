@@ -0,0 +1,22 @@
+// Adds a verified flag for facts a human has confirmed or corrected
+migrate((db) => {
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+
+  collection.schema.addField(new SchemaField({
+    name: 'verified',
+    type: 'bool',
+    required: false,
+  }));
+
+  return dao.saveCollection(collection);
+}, (db) => {
+  // Revert
+  const dao = new Dao(db);
+  const collection = dao.findCollectionByNameOrId('extracted_facts');
+  const field = collection.schema.getFieldByName('verified');
+  if (field) {
+    collection.schema.removeField(field.id);
+  }
+  return dao.saveCollection(collection);
+});